@@ -5,22 +5,30 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 
 	"github.com/dployr-io/dployr/pkg/auth"
+	"github.com/dployr-io/dployr/pkg/core/audit"
 	"github.com/dployr-io/dployr/pkg/core/cluster"
 	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/core/jobs"
 	"github.com/dployr-io/dployr/pkg/core/proxy"
+	"github.com/dployr-io/dployr/pkg/core/runs"
 	"github.com/dployr-io/dployr/pkg/core/service"
 	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/core/terminal"
 	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/core/webhooks"
 	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
 	"github.com/dployr-io/dployr/pkg/version"
 
 	dockerclient "github.com/docker/docker/client"
@@ -28,13 +36,20 @@ import (
 	_auth "github.com/dployr-io/dployr/internal/auth"
 	"github.com/dployr-io/dployr/internal/db"
 	_deploy "github.com/dployr-io/dployr/internal/deploy"
+	_jobs "github.com/dployr-io/dployr/internal/jobs"
+	"github.com/dployr-io/dployr/internal/lifecycle"
+	_logs "github.com/dployr-io/dployr/internal/logs"
+	"github.com/dployr-io/dployr/internal/ports"
 	_proxy "github.com/dployr-io/dployr/internal/proxy"
+	_runs "github.com/dployr-io/dployr/internal/runs"
 	_service "github.com/dployr-io/dployr/internal/service"
 	_storage "github.com/dployr-io/dployr/internal/storage"
 	_store "github.com/dployr-io/dployr/internal/store"
+	"github.com/dployr-io/dployr/internal/svc_runtime"
 	_system "github.com/dployr-io/dployr/internal/system"
 	_terminal "github.com/dployr-io/dployr/internal/terminal"
 	"github.com/dployr-io/dployr/internal/web"
+	_webhooks "github.com/dployr-io/dployr/internal/webhooks"
 	"github.com/dployr-io/dployr/internal/worker"
 	pkgstorage "github.com/dployr-io/dployr/pkg/core/storage"
 )
@@ -62,22 +77,53 @@ func main() {
 		log.Fatal(err)
 	}
 
+	sealer, err := _store.NewSealer(_store.SecretKeyPath())
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	logger := shared.NewLogger()
-	ds := _store.NewDeploymentStore(conn)
+	ds := _store.NewDeploymentStore(conn, sealer)
+	if err := ds.SealStoredSecrets(context.Background()); err != nil {
+		log.Fatalf("Failed to encrypt stored secrets: %s", err)
+	}
 	ss := _store.NewServiceStore(conn, ds)
 	is := _store.NewInstanceStore(conn)
 	trs := _store.NewTaskResultStore(conn)
+	jrs := _store.NewJobRunStore(conn)
+	srs := _store.NewServiceRunStore(conn)
+	auditStore := _store.NewAuditStore(conn)
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	proxyState := _proxy.LoadState()
 	ps := _proxy.Init(proxyState, logger)
 
-	workerMaxConcurrent := 5
+	// Services deployed before host ports were reserved keep the ports
+	// they are bound to.
+	portAlloc := ports.NewAllocator(_store.NewPortStore(conn))
+	for offset := 0; ; offset += 100 {
+		svcs, err := ss.ListServices(ctx, 100, offset)
+		if err != nil {
+			logger.Error("failed to list services for port reservation", "error", err)
+			break
+		}
+		if err := portAlloc.Migrate(ctx, svcs); err != nil {
+			logger.Warn("some service ports could not be reserved", "error", err)
+		}
+		if len(svcs) < 100 {
+			break
+		}
+	}
+
+	workerMaxConcurrent := max(cfg.MaxWorkers, 1)
 	w := worker.New(workerMaxConcurrent, cfg, logger, ds, ss, is, ps)
+	w.SetPortAllocator(portAlloc)
 
 	as := _auth.Init(cfg, is)
 	am := auth.NewMiddleware(as)
+	am.SetAuditLog(auditStore)
 
 	dockerCli, err := dockerclient.NewClientWithOpts(
 		dockerclient.FromEnv,
@@ -88,6 +134,8 @@ func main() {
 	}
 
 	api := _deploy.Init(cfg, logger, ds, w, dockerCli)
+	api.SetSliceLimits(_system.ClusterLimits)
+	w.SetSliceLimits(_system.ClusterLimits)
 	deployer := deploy.NewDeployer(cfg, logger, ds, api)
 	dh := deploy.NewDeploymentHandler(deployer, logger)
 	bh := deploy.NewBuildHandler(deployer, logger)
@@ -105,25 +153,95 @@ func main() {
 		if err != nil || dep == nil {
 			return fmt.Errorf("no deployment found for service %s: %w", name, err)
 		}
+		bp := dep.Blueprint
+		if bp.Type == store.TypeStatic {
+			// The site's files stay in the live workspace; there is no
+			// container to recreate.
+			return nil
+		}
+		if bp.Secrets, err = ds.OpenSecrets(bp.Secrets); err != nil {
+			return fmt.Errorf("failed to decrypt secrets for service %s: %w", name, err)
+		}
+		var hostPorts []int
+		if bp.Type != store.TypeStatic && bp.Type != store.TypeJob {
+			if hostPorts, err = portAlloc.Replicas(ctx, svc.Name, name, bp.Replicas); err != nil {
+				return fmt.Errorf("failed to reserve host ports for service %s: %w", name, err)
+			}
+			if svc.HostPort != 0 {
+				// Stay on the port the proxy routes to, which a blue/green
+				// redeploy may have moved to the service's second slot.
+				hostPorts[0] = svc.HostPort
+			}
+		}
 		logPath := filepath.Join(coreutils.GetDataDir(), ".dployr", "logs") + "/"
-		return _deploy.DeployApp(dep.Blueprint, name, logPath, cfg, dockerCli)
+		return _deploy.DeployApp(ctx, bp, name, logPath, hostPorts, cfg, dockerCli)
 	}
 
-	services := _service.Init(cfg, logger, ss, ps, redeployFn)
+	services := _service.Init(cfg, logger, ss, ps, redeployFn, w.Scale, w.Resize)
 	servicer := service.NewServicer(cfg, logger, ss, services)
 	sh := service.NewServiceHandler(servicer, logger)
 
+	var jobsH *jobs.Handler
+	var scheduler *_jobs.Scheduler
+	if svcMgr, err := svc_runtime.SvcRuntime(); err != nil {
+		log.Printf("warning: job scheduler disabled: %v", err)
+	} else {
+		scheduler = _jobs.NewScheduler(logger, ss, jrs, svcMgr)
+		jobsH = jobs.NewHandler(scheduler, logger)
+	}
+
+	var runsH *runs.Handler
+	var runner *_runs.Runner
+	if dockerCli != nil {
+		runner = _runs.NewRunner(logger, cfg, ss, ds, srs, dockerCli)
+		runner.Recover(ctx)
+		runsH = runs.NewHandler(runner, logger)
+	}
+
 	sysSvc := _system.NewDefaultService(cfg, is, trs)
+	sysSvc.SetMaintenanceHandler(func(on bool, domains []string) error {
+		var m *proxy.Maintenance
+		if on {
+			m = &proxy.Maintenance{Domains: domains, Page: cfg.MaintenancePage}
+		}
+		if err := ps.SetMaintenance(m); err != nil {
+			return err
+		}
+		w.Hold(on)
+		return nil
+	})
+	// Caddy keeps serving the maintenance page across restarts, so the
+	// daemon picks maintenance mode back up before it takes deployments.
+	if m := ps.Maintenance(); m != nil {
+		if _, err := sysSvc.SetMode(ctx, system.SetModeRequest{Mode: system.ModeMaintenance, Domains: m.Domains}); err != nil {
+			logger.Error("failed to restore maintenance mode", "error", err)
+		}
+	}
 	sysH := system.NewServiceHandler(sysSvc)
 	mh := _system.NewMetrics(cfg, is, trs)
 
 	fs := _system.NewFS()
+	// The secret key would unseal every secret in the database, so neither
+	// is reachable through the filesystem API, nor are the deploy keys.
+	dbPath := db.Path()
+	fs.Deny(_store.SecretKeyPath(), dbPath, dbPath+"-wal", dbPath+"-shm", _deploy.KeysDir())
 	fsH := system.NewFSHandler(fs, logger)
 
 	topCollector := _system.NewTopCollector()
 	topH := system.NewTopHandler(topCollector, logger)
 
 	terminalH := _terminal.NewHandler(logger)
+	recorder := _terminal.NewRecorder(filepath.Join(coreutils.GetDataDir(), "recordings"), cfg.TerminalRecordingRetention, cfg.TerminalRecordingMaxBytes)
+	recorder.SetRecordInput(cfg.TerminalRecordInput)
+	if cfg.TerminalRecord {
+		terminalH.SetRecorder(recorder)
+	}
+	if dockerCli != nil {
+		terminalH.SetDocker(dockerCli)
+	}
+	if err := recorder.Prune(); err != nil {
+		logger.Warn("failed to prune terminal recordings", "error", err)
+	}
 
 	storageMounter := _storage.NewMounter(logger)
 	storageH := pkgstorage.NewHandler(storageMounter, logger)
@@ -143,7 +261,20 @@ func main() {
 		MetricsH: mh,
 		StorageH: storageH,
 		ClusterH: clusterH,
+		AuditH:   audit.NewHandler(auditStore, logger),
+		TermH:    terminal.NewHandler(recorder, logger),
+	}
+	if jobsH != nil {
+		wh.JobsH = jobsH
 	}
+	if runsH != nil {
+		wh.RunsH = runsH
+	}
+	hooks := _webhooks.NewReceiver(cfg, logger, ss, ds, func(ctx context.Context, name, commit string) error {
+		_, err := api.DeployCommit(ctx, name, commit)
+		return err
+	})
+	wh.HooksH = webhooks.NewHandler(hooks, logger)
 
 	mux := wh.BuildMux(cfg)
 
@@ -151,10 +282,20 @@ func main() {
 	w.SetCompletionHandler(func(id string) {
 		syncer.RequestFullSync()
 	})
+	syncer.SetPortAllocator(portAlloc)
+	if cfg.Role == store.NodeRoleBuild {
+		syncer.SetBuildQueue(api.BuildQueue)
+	}
 	syncer.Executor().SetTerminalHandler(terminalH)
+	syncer.Executor().SetAuditLog(auditStore)
+	if runner != nil {
+		runner.SetLogSink(syncer.Executor().SendLogChunk)
+	}
 
+	srv := wh.NewServer(cfg)
 	go func() {
-		if err := wh.NewServer(cfg); err != nil {
+		log.Printf("Listening on port %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("server error: %v", err)
 		}
 	}()
@@ -167,9 +308,55 @@ func main() {
 		syncer.Start(ctx)
 	}()
 
+	if scheduler != nil {
+		go scheduler.Start(ctx)
+	}
+
+	if dockerCli != nil {
+		go _logs.NewCollector(logger, cfg, dockerCli).Start(ctx)
+	}
+
+	// Shutdown steps run in this order. Reporting the first one puts the
+	// daemon in draining mode, so no new task is taken from base.
+	lc := lifecycle.New(logger, cfg.ShutdownGracePeriod, _system.ReportShutdown)
+	lc.OnShutdown("worker", w.Drain)
+	if runner != nil {
+		lc.OnShutdown("runs", runner.Drain)
+	}
+	if scheduler != nil {
+		lc.OnShutdown("jobs", scheduler.Drain)
+	}
+	lc.OnShutdown("task_results", syncer.Flush)
+	lc.OnShutdown("terminals", func(context.Context) error {
+		if n := terminalH.CloseAll(); n > 0 {
+			logger.Info("closed terminal sessions", "count", n)
+		}
+		return nil
+	})
+	lc.OnShutdown("fs_watcher", func(context.Context) error {
+		return fs.Close()
+	})
+	lc.OnShutdown("http", srv.Shutdown)
+	// After http, so no push arrives once the debounced ones are queued.
+	lc.OnShutdown("webhooks", hooks.Flush)
+	lc.OnShutdown("background", func(context.Context) error {
+		cancel()
+		return nil
+	})
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 
-	log.Println("shutting down gracefully...")
+	log.Printf("shutting down gracefully (grace period %s)...", cfg.ShutdownGracePeriod)
+	go func() {
+		<-stop
+		log.Println("second signal received, exiting now")
+		os.Exit(1)
+	}()
+	if err := lc.Shutdown(context.Background()); err != nil {
+		log.Printf("shutdown finished with errors: %v", err)
+		return
+	}
+	log.Println("shutdown complete")
 }
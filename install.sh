@@ -1031,6 +1031,8 @@ StandardOutput=append:/var/log/dployrd/dployrd.log
 StandardError=append:/var/log/dployrd/dployrd.log
 Restart=always
 RestartSec=5
+# Leave room for SHUTDOWN_GRACE_PERIOD (60s by default) and the steps after it.
+TimeoutStopSec=120
 
 [Install]
 WantedBy=multi-user.target
@@ -52,3 +52,9 @@ func (c *Client) CreateDeployment(ctx context.Context, req CreateDeploymentReque
 func (c *Client) DeleteDeployment(ctx context.Context, id string) error {
 	return del(ctx, c, "/deployments/"+id)
 }
+
+// CancelDeployment stops the in-flight deployment or build of a service.
+// Partial containers and workspaces are cleaned up by the instance.
+func (c *Client) CancelDeployment(ctx context.Context, name string) error {
+	return postNoContent(ctx, c, "/deployments/"+name+"/cancel", c.clusterQuery(), nil)
+}
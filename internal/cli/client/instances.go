@@ -35,6 +35,39 @@ func (c *Client) PingInstance(ctx context.Context, name string) error {
 	return postNoContent(ctx, c, fmt.Sprintf("/instances/%s/ping", name), c.clusterQuery(), nil)
 }
 
+// ListInstanceAudit returns the audit log entries an instance recorded since
+// the given time, which is a duration such as "24h" or an RFC3339 timestamp.
+func (c *Client) ListInstanceAudit(ctx context.Context, tag, since string, limit int) (AuditLog, error) {
+	q := c.clusterQuery()
+	if q == nil {
+		q = url.Values{}
+	}
+	if since != "" {
+		q.Set("since", since)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	return get[AuditLog](ctx, c, fmt.Sprintf("/instances/%s/audit", tag), q)
+}
+
+// ListInstanceSessions returns the terminal sessions recorded on an
+// instance, newest first.
+func (c *Client) ListInstanceSessions(ctx context.Context, tag string) ([]TerminalRecording, error) {
+	return get[[]TerminalRecording](ctx, c, fmt.Sprintf("/instances/%s/terminal/recordings", tag), c.clusterQuery())
+}
+
+// GetInstanceSession fetches one recorded terminal session with its
+// asciicast content.
+func (c *Client) GetInstanceSession(ctx context.Context, tag, id string) (TerminalRecordingCast, error) {
+	q := c.clusterQuery()
+	if q == nil {
+		q = url.Values{}
+	}
+	q.Set("id", id)
+	return get[TerminalRecordingCast](ctx, c, fmt.Sprintf("/instances/%s/terminal/recording", tag), q)
+}
+
 // DeleteInstance removes an instance.
 func (c *Client) DeleteInstance(ctx context.Context, id string) error {
 	return del(ctx, c, "/instances/"+id)
@@ -50,6 +83,17 @@ func (c *Client) SystemRestart(ctx context.Context, instanceID string, force boo
 	return postNoContent(ctx, c, fmt.Sprintf("/instances/%s/system/restart", instanceID), c.clusterQuery(), map[string]bool{"force": force})
 }
 
+// SetInstanceMaintenance puts an instance into maintenance mode, or takes it
+// back to ready when on is false. domains limits the maintenance page to
+// those domains; with none it covers every domain the instance serves.
+func (c *Client) SetInstanceMaintenance(ctx context.Context, tag string, on bool, domains []string) error {
+	body := map[string]any{"mode": "ready"}
+	if on {
+		body = map[string]any{"mode": "maintenance", "domains": domains}
+	}
+	return postNoContent(ctx, c, fmt.Sprintf("/instances/%s/system/mode", tag), c.clusterQuery(), body)
+}
+
 // RotateInstanceToken rotates the access token for an instance.
 func (c *Client) RotateInstanceToken(ctx context.Context, instanceID string) error {
 	return postNoContent(ctx, c, fmt.Sprintf("/instances/%s/tokens/rotate", instanceID), c.clusterQuery(), nil)
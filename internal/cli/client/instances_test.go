@@ -2,13 +2,14 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 )
 
-// systemMethodCase drives table tests for the three system POST methods.
+// systemMethodCase drives table tests for the system POST methods.
 type systemMethodCase struct {
 	name     string
 	call     func(*Client) error
@@ -27,6 +28,11 @@ func systemCases(tag string) []systemMethodCase {
 			call:     func(c *Client) error { return c.SystemRestart(context.Background(), tag, false) },
 			wantPath: "/v1/instances/" + tag + "/system/restart",
 		},
+		{
+			name:     "SetInstanceMaintenance",
+			call:     func(c *Client) error { return c.SetInstanceMaintenance(context.Background(), tag, true, nil) },
+			wantPath: "/v1/instances/" + tag + "/system/mode",
+		},
 	}
 }
 
@@ -113,3 +119,83 @@ func TestClusterQuery_ReturnsClusterIdWhenSet(t *testing.T) {
 		t.Errorf("clusterQuery()[clusterId] = %q, want cl-xyz", got)
 	}
 }
+
+func TestListInstanceAudit_PassesWindow(t *testing.T) {
+	var gotPath, gotSince, gotLimit string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotSince, gotLimit = r.URL.Path, r.URL.Query().Get("since"), r.URL.Query().Get("limit")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{"entries":[
+			{"seq":3,"time":"2025-06-01T12:00:00Z","subject":"alice","role":"admin","taskId":"t1","method":"POST","route":"/system/reboot","status":202,"outcome":"succeeded"}
+		],"intact":false,"brokenAt":2}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	log, err := c.ListInstanceAudit(context.Background(), "prod-1", "2h", 50)
+	if err != nil {
+		t.Fatalf("ListInstanceAudit error: %v", err)
+	}
+	if gotPath != "/v1/instances/prod-1/audit" || gotSince != "2h" || gotLimit != "50" {
+		t.Errorf("request = %s?since=%s&limit=%s, want /v1/instances/prod-1/audit?since=2h&limit=50", gotPath, gotSince, gotLimit)
+	}
+	if log.Intact || log.BrokenAt != 2 {
+		t.Errorf("chain = intact %v broken at %d, want broken at 2", log.Intact, log.BrokenAt)
+	}
+	if len(log.Entries) != 1 || log.Entries[0].TaskID != "t1" || log.Entries[0].Outcome != "succeeded" {
+		t.Errorf("entries = %+v", log.Entries)
+	}
+}
+
+func TestGetInstanceSession_DecodesCast(t *testing.T) {
+	var gotPath, gotID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotID = r.URL.Path, r.URL.Query().Get("id")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{"id":"s1","subject":"alice","cols":80,"rows":24,"startedAt":"2025-06-01T12:00:00Z","endedAt":"2025-06-01T12:05:00Z","bytes":64,"cast":"{\"version\":2}\n"}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	rec, err := c.GetInstanceSession(context.Background(), "prod-1", "s1")
+	if err != nil {
+		t.Fatalf("GetInstanceSession error: %v", err)
+	}
+	if gotPath != "/v1/instances/prod-1/terminal/recording" || gotID != "s1" {
+		t.Errorf("request = %s?id=%s, want /v1/instances/prod-1/terminal/recording?id=s1", gotPath, gotID)
+	}
+	if rec.ID != "s1" || rec.Subject != "alice" || rec.EndedAt == nil || rec.Cast != "{\"version\":2}\n" {
+		t.Errorf("recording = %+v", rec)
+	}
+}
+
+func TestSetInstanceMaintenance_SendsMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		on      bool
+		domains []string
+		want    string
+	}{
+		{"on for some domains", true, []string{"shop.example.com"}, `{"domains":["shop.example.com"],"mode":"maintenance"}`},
+		{"off", false, nil, `{"mode":"ready"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t, srv.URL)
+			if err := c.SetInstanceMaintenance(context.Background(), "prod-1", tt.on, tt.domains); err != nil {
+				t.Fatalf("SetInstanceMaintenance error: %v", err)
+			}
+			body, _ := json.Marshal(got)
+			if string(body) != tt.want {
+				t.Errorf("body = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
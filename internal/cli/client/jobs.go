@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type jobRunListData struct {
+	Runs []JobRun `json:"runs"`
+}
+
+type jobRunData struct {
+	Run JobRun `json:"run"`
+}
+
+// ListJobRuns returns a scheduled job's runs, newest first.
+func (c *Client) ListJobRuns(ctx context.Context, id string, limit int) ([]JobRun, error) {
+	q := c.clusterQuery()
+	if limit > 0 {
+		if q == nil {
+			q = url.Values{}
+		}
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	r, err := get[jobRunListData](ctx, c, fmt.Sprintf("/jobs/%s/runs", id), q)
+	if err != nil {
+		return nil, err
+	}
+	return r.Runs, nil
+}
+
+// TriggerJob starts a run of a scheduled job now. The job's concurrency
+// policy still applies, so a forbid job that is already running refuses.
+func (c *Client) TriggerJob(ctx context.Context, id string) (JobRun, error) {
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/jobs/%s/trigger", id), c.clusterQuery(), nil)
+	if err != nil {
+		return JobRun{}, err
+	}
+	r, err := decodeResponse[jobRunData](resp)
+	if err != nil {
+		return JobRun{}, err
+	}
+	return r.Run, nil
+}
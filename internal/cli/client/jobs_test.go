@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListJobRuns_DecodesRuns(t *testing.T) {
+	var gotPath, gotLimit string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotLimit = r.URL.Path, r.URL.Query().Get("limit")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{"runs":[
+			{"id":"r2","trigger":"manual","status":"running","startedAt":1750000060},
+			{"id":"r1","trigger":"schedule","status":"failed","exitCode":2,"durationMs":1500,"startedAt":1750000000,"finishedAt":1750000001}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	runs, err := c.ListJobRuns(context.Background(), "report", 5)
+	if err != nil {
+		t.Fatalf("ListJobRuns error: %v", err)
+	}
+	if gotPath != "/v1/jobs/report/runs" || gotLimit != "5" {
+		t.Errorf("request = %s?limit=%s, want /v1/jobs/report/runs?limit=5", gotPath, gotLimit)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	if runs[0].ExitCode != nil || runs[0].FinishedAt != nil {
+		t.Errorf("running run = %+v, want no exit code or finish time", runs[0])
+	}
+	if runs[1].ExitCode == nil || *runs[1].ExitCode != 2 {
+		t.Errorf("exit code = %v, want 2", runs[1].ExitCode)
+	}
+}
+
+func TestTriggerJob_PostsToJob(t *testing.T) {
+	var gotMethod, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"success":true,"data":{"run":{"id":"r3","trigger":"manual","status":"running"}}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	run, err := c.TriggerJob(context.Background(), "report")
+	if err != nil {
+		t.Fatalf("TriggerJob error: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/v1/jobs/report/trigger" {
+		t.Errorf("request = %s %s, want POST /v1/jobs/report/trigger", gotMethod, gotPath)
+	}
+	if run.ID != "r3" {
+		t.Errorf("run ID = %q, want r3", run.ID)
+	}
+}
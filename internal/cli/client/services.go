@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 )
@@ -11,6 +12,14 @@ type serviceData struct {
 	Service Service `json:"service"`
 }
 
+type releaseListData struct {
+	Releases []Release `json:"releases"`
+}
+
+type serviceRunData struct {
+	Run ServiceRun `json:"run"`
+}
+
 // ListServices returns services in the active cluster.
 func (c *Client) ListServices(ctx context.Context, limit int) ([]Service, error) {
 	q := url.Values{}
@@ -50,3 +59,83 @@ func (c *Client) StartService(ctx context.Context, id string) error {
 func (c *Client) DeleteService(ctx context.Context, id string) error {
 	return del(ctx, c, "/services/"+id)
 }
+
+// ListReleases returns a service's release history, newest first.
+func (c *Client) ListReleases(ctx context.Context, id string, limit int) ([]Release, error) {
+	q := c.clusterQuery()
+	if limit > 0 {
+		if q == nil {
+			q = url.Values{}
+		}
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	r, err := get[releaseListData](ctx, c, fmt.Sprintf("/services/%s/releases", id), q)
+	if err != nil {
+		return nil, err
+	}
+	return r.Releases, nil
+}
+
+// RollbackService redeploys a previous release of a service from its already
+// built image. release may be a release ID or version number; empty selects
+// the release before the one currently live.
+func (c *Client) RollbackService(ctx context.Context, id, release string) (CreateDeploymentResult, error) {
+	body := map[string]any{}
+	if release != "" {
+		body["release"] = release
+	}
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/services/%s/rollback", id), c.clusterQuery(), body)
+	if err != nil {
+		return CreateDeploymentResult{}, err
+	}
+	return decodeResponse[CreateDeploymentResult](resp)
+}
+
+// ScaleService sets how many replicas of a service run. The running image is
+// reused; nothing is rebuilt.
+func (c *Client) ScaleService(ctx context.Context, id string, replicas int) error {
+	body := map[string]any{"replicas": replicas}
+	return postNoContent(ctx, c, fmt.Sprintf("/services/%s/scale", id), c.clusterQuery(), body)
+}
+
+// UpdateServiceResources changes a running service's container limits in
+// place, without a rebuild or restart.
+func (c *Client) UpdateServiceResources(ctx context.Context, id string, r ServiceResources) error {
+	resp, err := c.do(ctx, http.MethodPatch, "/services/"+id, c.clusterQuery(), map[string]any{"resources": r})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return readAPIError(resp)
+}
+
+// RunService starts a one-off command in a fresh container from a service's
+// current image. timeout is a Go duration; empty uses the node's default.
+func (c *Client) RunService(ctx context.Context, id string, command []string, timeout string) (ServiceRun, error) {
+	body := map[string]any{"command": command}
+	if timeout != "" {
+		body["timeout"] = timeout
+	}
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/services/%s/run", id), c.clusterQuery(), body)
+	if err != nil {
+		return ServiceRun{}, err
+	}
+	r, err := decodeResponse[serviceRunData](resp)
+	if err != nil {
+		return ServiceRun{}, err
+	}
+	return r.Run, nil
+}
+
+// GetServiceRun returns a run with the output it has written past offset.
+func (c *Client) GetServiceRun(ctx context.Context, id, runID string, offset int64) (ServiceRunOutput, error) {
+	q := c.clusterQuery()
+	if q == nil {
+		q = url.Values{}
+	}
+	q.Set("offset", strconv.FormatInt(offset, 10))
+	return get[ServiceRunOutput](ctx, c, fmt.Sprintf("/services/%s/runs/%s", id, runID), q)
+}
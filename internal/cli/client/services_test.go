@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// releaseHistory is a node deployer with one release on record.
+type releaseHistory struct {
+	deploy.HandleDeployment
+	name  string
+	limit int
+}
+
+func (h *releaseHistory) ListReleases(_ context.Context, name string, limit int) ([]*store.Release, error) {
+	h.name, h.limit = name, limit
+	return []*store.Release{{
+		ID:           "r2",
+		Name:         name,
+		Version:      2,
+		DeploymentID: "d1",
+		Image:        "registry.local/my-app:abc123",
+		CommitHash:   "abc123",
+		Status:       store.StatusCompleted,
+		CreatedAt:    time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+func TestListReleases_DecodesNodeResponse(t *testing.T) {
+	api := &releaseHistory{}
+	logger := shared.NewLogger()
+	node := deploy.NewDeploymentHandler(deploy.NewDeployer(&shared.Config{}, logger, nil, api), logger)
+
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		// The base runs services/releases:get on the node and returns its
+		// result as the data of the response envelope.
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/services/"), "/releases")
+		rec := httptest.NewRecorder()
+		node.ListReleases(rec, httptest.NewRequest(http.MethodGet, "/services/releases?name="+name+"&limit="+r.URL.Query().Get("limit"), nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("node responded %d: %s", rec.Code, rec.Body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":` + rec.Body.String() + `}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	releases, err := c.ListReleases(context.Background(), "my-app", 5)
+	if err != nil {
+		t.Fatalf("ListReleases error: %v", err)
+	}
+	if gotPath != "/v1/services/my-app/releases" {
+		t.Errorf("path = %q, want /v1/services/my-app/releases", gotPath)
+	}
+	if api.name != "my-app" || api.limit != 5 {
+		t.Errorf("node listed %q limit %d, want my-app limit 5", api.name, api.limit)
+	}
+	if len(releases) != 1 {
+		t.Fatalf("releases = %+v, want one", releases)
+	}
+	got := releases[0]
+	if got.ID != "r2" || got.Version != 2 || got.DeploymentID != "d1" || got.CommitHash != "abc123" || got.Image == "" || got.Status != "completed" {
+		t.Errorf("release = %+v", got)
+	}
+	if !got.CreatedAt.Time().Equal(time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("createdAt = %v", got.CreatedAt.Time())
+	}
+}
+
+func TestRollbackService_SendsRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		release     string
+		wantRelease any
+	}{
+		{name: "explicit release", release: "12", wantRelease: "12"},
+		{name: "previous release", release: "", wantRelease: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotMethod, gotPath string
+			var gotBody map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotPath = r.Method, r.URL.Path
+				_ = json.NewDecoder(r.Body).Decode(&gotBody)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+				_, _ = w.Write([]byte(`{"success":true,"data":{"taskId":"task-1"}}`))
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t, srv.URL)
+			result, err := c.RollbackService(context.Background(), "my-app", tc.release)
+			if err != nil {
+				t.Fatalf("RollbackService error: %v", err)
+			}
+			if gotMethod != http.MethodPost || gotPath != "/v1/services/my-app/rollback" {
+				t.Errorf("request = %s %s, want POST /v1/services/my-app/rollback", gotMethod, gotPath)
+			}
+			if gotBody["release"] != tc.wantRelease {
+				t.Errorf("release = %v, want %v", gotBody["release"], tc.wantRelease)
+			}
+			if result.TaskID != "task-1" {
+				t.Errorf("taskId = %q, want task-1", result.TaskID)
+			}
+		})
+	}
+}
+
+func TestScaleService_SendsReplicas(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.ScaleService(context.Background(), "svc-1", 3); err != nil {
+		t.Fatalf("ScaleService error: %v", err)
+	}
+	if gotPath != "/v1/services/svc-1/scale" {
+		t.Errorf("path = %q, want /v1/services/svc-1/scale", gotPath)
+	}
+	if gotBody["replicas"] != float64(3) {
+		t.Errorf("replicas = %v, want 3", gotBody["replicas"])
+	}
+}
+
+func TestUpdateServiceResources_PatchesService(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBody map[string]map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if err := c.UpdateServiceResources(context.Background(), "svc-1", ServiceResources{MemoryMB: 512}); err != nil {
+		t.Fatalf("UpdateServiceResources error: %v", err)
+	}
+	if gotMethod != http.MethodPatch || gotPath != "/v1/services/svc-1" {
+		t.Errorf("request = %s %s, want PATCH /v1/services/svc-1", gotMethod, gotPath)
+	}
+	res := gotBody["resources"]
+	if res["memory_mb"] != float64(512) {
+		t.Errorf("memory_mb = %v, want 512", res["memory_mb"])
+	}
+	if _, ok := res["cpu_millicores"]; ok {
+		t.Error("unset cpu limit was sent")
+	}
+}
+
+func TestRunService_PostsCommand(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"success":true,"data":{"run":{"id":"r1","service":"api","command":["rake","db:migrate"],"status":"running"}}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	run, err := c.RunService(context.Background(), "svc-1", []string{"rake", "db:migrate"}, "5m")
+	if err != nil {
+		t.Fatalf("RunService error: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/v1/services/svc-1/run" {
+		t.Errorf("request = %s %s, want POST /v1/services/svc-1/run", gotMethod, gotPath)
+	}
+	if cmd, _ := gotBody["command"].([]any); len(cmd) != 2 || cmd[0] != "rake" {
+		t.Errorf("command = %v, want [rake db:migrate]", gotBody["command"])
+	}
+	if gotBody["timeout"] != "5m" {
+		t.Errorf("timeout = %v, want 5m", gotBody["timeout"])
+	}
+	if run.ID != "r1" || run.Status != "running" {
+		t.Errorf("run = %+v", run)
+	}
+}
+
+func TestGetServiceRun_SendsOffset(t *testing.T) {
+	var gotPath, gotOffset string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotOffset = r.URL.Path, r.URL.Query().Get("offset")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{
+			"run":{"id":"r1","status":"failed","exitCode":1},
+			"entries":[{"time":"2025-06-15T12:00:00Z","msg":"boom","stream":"stderr"}],
+			"offset":512
+		}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	out, err := c.GetServiceRun(context.Background(), "svc-1", "r1", 128)
+	if err != nil {
+		t.Fatalf("GetServiceRun error: %v", err)
+	}
+	if gotPath != "/v1/services/svc-1/runs/r1" || gotOffset != "128" {
+		t.Errorf("request = %s?offset=%s, want /v1/services/svc-1/runs/r1?offset=128", gotPath, gotOffset)
+	}
+	if out.Offset != 512 || len(out.Entries) != 1 || out.Entries[0].Stream != "stderr" {
+		t.Errorf("output = %+v", out)
+	}
+	if out.Run.ExitCode == nil || *out.Run.ExitCode != 1 {
+		t.Errorf("exit code = %v, want 1", out.Run.ExitCode)
+	}
+}
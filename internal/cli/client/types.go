@@ -31,6 +31,14 @@ type Service struct {
 	UpdatedAt      UnixTime  `json:"updatedAt"`
 }
 
+// ServiceResources are per-container limits. Zero fields are left unchanged
+// on update.
+type ServiceResources struct {
+	MemoryMB int `json:"memory_mb,omitempty"`
+	CPU      int `json:"cpu_millicores,omitempty"`
+	Pids     int `json:"pids,omitempty"`
+}
+
 type Deployment struct {
 	ID               string    `json:"id"`
 	ClusterID        string    `json:"clusterId"`
@@ -39,7 +47,7 @@ type Deployment struct {
 	Name             string    `json:"name"`
 	Type             string    `json:"type"`
 	Source           string    `json:"source"` // remote | image
-	Status           string    `json:"status"` // pending | running | success | failed
+	Status           string    `json:"status"` // pending | running | success | failed | cancelled
 	Description      string    `json:"description"`
 	RunCmd           string    `json:"runCmd"`
 	BuildCmd         string    `json:"buildCmd"`
@@ -58,6 +66,104 @@ type Deployment struct {
 	FinishedAt       *UnixTime `json:"finishedAt,omitempty"`
 }
 
+// Release is one entry in a service's deployment history, as the node
+// reports it.
+type Release struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Version      int      `json:"version"`
+	DeploymentID string   `json:"deployment_id"`
+	Image        string   `json:"image,omitempty"`
+	CommitHash   string   `json:"commit_hash,omitempty"`
+	Status       string   `json:"status"` // pending | in_progress | completed | failed
+	CreatedAt    UnixTime `json:"created_at"`
+}
+
+// JobRun is one execution of a scheduled job.
+type JobRun struct {
+	ID         string    `json:"id"`
+	Trigger    string    `json:"trigger"` // schedule | manual
+	Status     string    `json:"status"`  // running | succeeded | failed | skipped | cancelled
+	ExitCode   *int      `json:"exitCode,omitempty"`
+	Output     string    `json:"output,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	StartedAt  UnixTime  `json:"startedAt"`
+	FinishedAt *UnixTime `json:"finishedAt,omitempty"`
+}
+
+// ServiceRun is a one-off command run against a deployed service.
+type ServiceRun struct {
+	ID         string    `json:"id"`
+	Service    string    `json:"service"`
+	Command    []string  `json:"command"`
+	Image      string    `json:"image"`
+	Status     string    `json:"status"` // running | succeeded | failed | timed_out
+	ExitCode   *int      `json:"exitCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	TimeoutMs  int64     `json:"timeoutMs"`
+	DurationMs int64     `json:"durationMs"`
+	StartedAt  UnixTime  `json:"startedAt"`
+	FinishedAt *UnixTime `json:"finishedAt,omitempty"`
+}
+
+// ServiceRunEntry is one line of a run's output.
+type ServiceRunEntry struct {
+	Time   string `json:"time"`
+	Msg    string `json:"msg"`
+	Stream string `json:"stream,omitempty"` // stdout | stderr; empty for the closing entry
+}
+
+// ServiceRunOutput is a run with the output it has produced past an offset.
+// Offset is where the next read continues.
+type ServiceRunOutput struct {
+	Run     ServiceRun        `json:"run"`
+	Entries []ServiceRunEntry `json:"entries"`
+	Offset  int64             `json:"offset"`
+}
+
+// AuditEntry is one privileged action recorded in an instance's audit log.
+type AuditEntry struct {
+	Seq        int64    `json:"seq"`
+	Time       UnixTime `json:"time"`
+	Subject    string   `json:"subject"`
+	Role       string   `json:"role,omitempty"`
+	TaskID     string   `json:"taskId,omitempty"`
+	Method     string   `json:"method"`
+	Route      string   `json:"route"`
+	Status     int      `json:"status"`
+	Outcome    string   `json:"outcome"` // succeeded | denied | failed
+	ArgsDigest string   `json:"argsDigest"`
+	Hash       string   `json:"hash"`
+}
+
+// AuditLog is a window of an instance's audit log. Intact reports whether
+// the whole hash chain on the instance verified; when it did not, BrokenAt
+// is the sequence number of the first entry that failed.
+type AuditLog struct {
+	Entries  []AuditEntry `json:"entries"`
+	Intact   bool         `json:"intact"`
+	BrokenAt int64        `json:"brokenAt,omitempty"`
+}
+
+// TerminalRecording describes a terminal session recorded on an instance.
+type TerminalRecording struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`
+	Service   string    `json:"service,omitempty"`
+	Cols      uint16    `json:"cols"`
+	Rows      uint16    `json:"rows"`
+	StartedAt UnixTime  `json:"startedAt"`
+	EndedAt   *UnixTime `json:"endedAt,omitempty"`
+	Bytes     int64     `json:"bytes"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// TerminalRecordingCast is a recording with its asciicast v2 content.
+type TerminalRecordingCast struct {
+	TerminalRecording
+	Cast string `json:"cast"`
+}
+
 type CreateDeploymentResult struct {
 	TaskID string `json:"taskId"`
 	Cached bool   `json:"cached,omitempty"`
@@ -77,13 +183,25 @@ type CreateDeploymentRequest struct {
 	StaticDir        string            `json:"staticDir,omitempty"`
 	HealthCheck      string            `json:"healthCheck,omitempty"`
 	Image            string            `json:"image,omitempty"`
-	Domain           string            `json:"domain,omitempty"`
+	Domains          []Domain          `json:"domains,omitempty"`
 	RemoteURL        string            `json:"remoteUrl,omitempty"`
 	RemoteBranch     string            `json:"remoteBranch,omitempty"`
 	RemoteCommitHash string            `json:"remoteCommitHash,omitempty"`
+	RemoteSubmodules bool              `json:"remoteSubmodules,omitempty"`
+	RemoteLFS        bool              `json:"remoteLfs,omitempty"`
 	EnvVars          map[string]string `json:"envVars,omitempty"`
 	Secrets          map[string]string `json:"secrets,omitempty"`
 	ForceRebuild     bool              `json:"forceRebuild,omitempty"`
+	AutoDeploy       bool              `json:"autoDeploy,omitempty"`
+}
+
+// Domain is a custom host name for a deployment. TLS is acme, internal or
+// custom; a custom certificate is read from CertFile and KeyFile on the node.
+type Domain struct {
+	Host     string `json:"host"`
+	TLS      string `json:"tls,omitempty"`
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
 }
 
 type Instance struct {
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type castHeader struct {
+	Version int `json:"version"`
+	Width   int `json:"width"`
+	Height  int `json:"height"`
+}
+
+// playCast writes the output events of an asciicast v2 recording to w at
+// their recorded pace, divided by speed. Pauses are capped at idleLimit so
+// a session left idle does not stall playback; zero leaves them uncapped.
+// Input and resize events are skipped.
+func playCast(w io.Writer, cast string, speed float64, idleLimit time.Duration, sleep func(time.Duration)) error {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(cast))
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+
+	if !sc.Scan() {
+		return fmt.Errorf("empty recording")
+	}
+	var header castHeader
+	if err := json.Unmarshal(sc.Bytes(), &header); err != nil {
+		return fmt.Errorf("invalid recording header: %w", err)
+	}
+	if header.Version != 2 {
+		return fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+
+	var last float64
+	for sc.Scan() {
+		var event []any
+		if err := json.Unmarshal(sc.Bytes(), &event); err != nil || len(event) != 3 {
+			continue
+		}
+		at, _ := event[0].(float64)
+		kind, _ := event[1].(string)
+		data, _ := event[2].(string)
+		if kind != "o" {
+			continue
+		}
+
+		pause := time.Duration((at - last) / speed * float64(time.Second))
+		if idleLimit > 0 && pause > idleLimit {
+			pause = idleLimit
+		}
+		if pause > 0 {
+			sleep(pause)
+		}
+		last = at
+
+		if _, err := io.WriteString(w, data); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPlayCast(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24,"timestamp":1750000000}
+[0.5,"o","$ "]
+[1.0,"i","ls\r"]
+[1.2,"o","ls\r\n"]
+[61.2,"o","file.txt\r\n"]
+[61.3,"r","100x30"]
+`
+	var out bytes.Buffer
+	var pauses []time.Duration
+	if err := playCast(&out, cast, 2, 2*time.Second, func(d time.Duration) { pauses = append(pauses, d) }); err != nil {
+		t.Fatalf("playCast: %v", err)
+	}
+
+	if out.String() != "$ ls\r\nfile.txt\r\n" {
+		t.Errorf("output = %q, want only output events", out.String())
+	}
+	want := []time.Duration{250 * time.Millisecond, 350 * time.Millisecond, 2 * time.Second}
+	if len(pauses) != len(want) {
+		t.Fatalf("pauses = %v, want %v", pauses, want)
+	}
+	for i := range want {
+		if diff := pauses[i] - want[i]; diff > time.Millisecond || diff < -time.Millisecond {
+			t.Errorf("pause %d = %v, want %v", i, pauses[i], want[i])
+		}
+	}
+}
+
+func TestPlayCast_RejectsOtherVersions(t *testing.T) {
+	if err := playCast(&bytes.Buffer{}, `{"version":1}`, 1, 0, func(time.Duration) {}); err == nil {
+		t.Error("playCast accepted an asciicast v1 file")
+	}
+}
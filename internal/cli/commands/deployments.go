@@ -20,6 +20,7 @@ func newDeploymentsCmd(makeDeps makeDepsFunc) *cobra.Command {
 	cmd.AddCommand(newDeploymentsGetCmd(makeDeps))
 	cmd.AddCommand(newDeploymentsCreateCmd(makeDeps))
 	cmd.AddCommand(newDeploymentsDeleteCmd(makeDeps))
+	cmd.AddCommand(newDeploymentsCancelCmd(makeDeps))
 	return cmd
 }
 
@@ -145,13 +146,19 @@ func newDeploymentsCreateCmd(makeDeps makeDepsFunc) *cobra.Command {
 		staticDir        string
 		healthCheck      string
 		image            string
-		domain           string
+		domains          []string
+		tlsMode          string
+		tlsCert          string
+		tlsKey           string
 		remoteURL        string
 		remoteBranch     string
 		remoteCommitHash string
+		submodules       bool
+		lfs              bool
 		envVars          []string
 		secrets          []string
 		forceRebuild     bool
+		autoDeploy       bool
 	)
 
 	cmd := &cobra.Command{
@@ -164,9 +171,19 @@ Examples:
   dployr deployments create --name my-api --source remote --runtime nodejs \
     --remote https://github.com/user/repo --branch main
 
+  # Redeploy on every push to main (point the repository's push webhook at
+  # the build node's /webhooks/git):
+  dployr deployments create --name my-api --source remote --runtime nodejs \
+    --remote https://github.com/user/repo --branch main --auto-deploy
+
   # Deploy a pre-built Docker image:
   dployr deployments create --name my-api --source image --runtime nodejs \
-    --image registry.example.com/my-api:latest`,
+    --image registry.example.com/my-api:latest
+
+  # Serve on custom domains with a certificate from an ACME CA:
+  dployr deployments create --name my-api --source image --runtime nodejs \
+    --image registry.example.com/my-api:latest \
+    --domain api.example.com --domain www.example.com --tls acme`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			d, err := makeDeps(cmd)
 			if err != nil {
@@ -191,6 +208,16 @@ Examples:
 			if source == "image" && image == "" {
 				return fmt.Errorf("--image is required when --source=image")
 			}
+			if autoDeploy && source != "remote" {
+				return fmt.Errorf("--auto-deploy requires --source=remote")
+			}
+			if (submodules || lfs) && source != "remote" {
+				return fmt.Errorf("--submodules and --lfs require --source=remote")
+			}
+			reqDomains, err := parseDomains(domains, tlsMode, tlsCert, tlsKey)
+			if err != nil {
+				return err
+			}
 
 			req := client.CreateDeploymentRequest{
 				Name:             name,
@@ -206,13 +233,16 @@ Examples:
 				StaticDir:        staticDir,
 				HealthCheck:      healthCheck,
 				Image:            image,
-				Domain:           domain,
+				Domains:          reqDomains,
 				RemoteURL:        remoteURL,
 				RemoteBranch:     remoteBranch,
 				RemoteCommitHash: remoteCommitHash,
+				RemoteSubmodules: submodules,
+				RemoteLFS:        lfs,
 				EnvVars:          parseEnvVars(envVars),
 				Secrets:          parseEnvVars(secrets),
 				ForceRebuild:     forceRebuild,
+				AutoDeploy:       autoDeploy,
 			}
 
 			result, err := d.client.CreateDeployment(context.Background(), req)
@@ -247,16 +277,22 @@ Examples:
 	cmd.Flags().StringVar(&buildCmd, "build-cmd", "", "command to build the application")
 	cmd.Flags().IntVarP(&port, "port", "p", 0, "application port")
 	cmd.Flags().StringVar(&workingDir, "working-dir", "", "working directory inside the container")
-	cmd.Flags().StringVar(&staticDir, "static-dir", "", "directory to serve as static files")
+	cmd.Flags().StringVar(&staticDir, "static-dir", "", "directory to serve as static files: the build output with --build-cmd, or the directory copied out of the image with --source image")
 	cmd.Flags().StringVar(&healthCheck, "health-check", "", "HTTP path for health checks (e.g. /health)")
 	cmd.Flags().StringVar(&image, "image", "", "Docker image (required when --source=image)")
-	cmd.Flags().StringVar(&domain, "domain", "", "custom domain name")
+	cmd.Flags().StringArrayVar(&domains, "domain", nil, "custom domain name (repeatable)")
+	cmd.Flags().StringVar(&tlsMode, "tls", "", "certificate for the custom domains: acme, internal, or custom (default acme)")
+	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "certificate file on the node (required with --tls custom)")
+	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "key file on the node (required with --tls custom)")
 	cmd.Flags().StringVar(&remoteURL, "remote", "", "git remote URL (required when --source=remote)")
 	cmd.Flags().StringVar(&remoteBranch, "branch", "", "git branch (default: repository default)")
 	cmd.Flags().StringVar(&remoteCommitHash, "commit", "", "specific commit hash to deploy")
+	cmd.Flags().BoolVar(&submodules, "submodules", false, "fetch git submodules recursively")
+	cmd.Flags().BoolVar(&lfs, "lfs", false, "pull Git LFS objects")
 	cmd.Flags().StringArrayVarP(&envVars, "env", "e", nil, "environment variables in KEY=VALUE format (repeatable)")
 	cmd.Flags().StringArrayVar(&secrets, "secret", nil, "secrets in KEY=VALUE format (repeatable, stored encrypted)")
 	cmd.Flags().BoolVar(&forceRebuild, "force-rebuild", false, "force a fresh build even if a cached image exists")
+	cmd.Flags().BoolVar(&autoDeploy, "auto-deploy", false, "redeploy on every push to --branch (needs a push webhook to the build node)")
 	return cmd
 }
 
@@ -299,7 +335,65 @@ func newDeploymentsDeleteCmd(makeDeps makeDepsFunc) *cobra.Command {
 	return cmd
 }
 
+func newDeploymentsCancelCmd(makeDeps makeDepsFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <name>",
+		Short: "cancel an in-flight deployment or build",
+		Long: `Cancel the queued or running deployment of a service, or its build.
+The clone, image build or deploy step is aborted and any partial container
+or workspace is removed. The deployment is recorded as cancelled.
+
+Example:
+  dployr deployments cancel api`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			if err := d.client.CancelDeployment(context.Background(), args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("deployment %s cancelled\n", args[0])
+			return nil
+		},
+	}
+}
+
 // parseEnvVars converts KEY=VALUE strings into a map.
+// parseDomains builds the custom domains of a deployment, all served with
+// the same TLS mode.
+func parseDomains(hosts []string, tlsMode, certFile, keyFile string) ([]client.Domain, error) {
+	if len(hosts) == 0 {
+		if tlsMode != "" || certFile != "" || keyFile != "" {
+			return nil, fmt.Errorf("--tls, --tls-cert and --tls-key require --domain")
+		}
+		return nil, nil
+	}
+	switch tlsMode {
+	case "", "acme", "internal":
+		if certFile != "" || keyFile != "" {
+			return nil, fmt.Errorf("--tls-cert and --tls-key require --tls custom")
+		}
+	case "custom":
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("--tls custom requires --tls-cert and --tls-key")
+		}
+	default:
+		return nil, fmt.Errorf("--tls must be acme, internal, or custom")
+	}
+
+	domains := make([]client.Domain, len(hosts))
+	for i, h := range hosts {
+		domains[i] = client.Domain{Host: h, TLS: tlsMode, CertFile: certFile, KeyFile: keyFile}
+	}
+	return domains, nil
+}
+
 func parseEnvVars(pairs []string) map[string]string {
 	if len(pairs) == 0 {
 		return nil
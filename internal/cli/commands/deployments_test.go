@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/dployr-io/dployr/internal/cli/client"
+)
+
+func TestParseDomains(t *testing.T) {
+	got, err := parseDomains([]string{"api.example.com", "www.example.com"}, "internal", "", "")
+	if err != nil {
+		t.Fatalf("parseDomains: %v", err)
+	}
+	want := []client.Domain{{Host: "api.example.com", TLS: "internal"}, {Host: "www.example.com", TLS: "internal"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseDomains = %+v, want %+v", got, want)
+	}
+
+	got, err = parseDomains([]string{"api.example.com"}, "custom", "/etc/ssl/api.pem", "/etc/ssl/api.key")
+	if err != nil || got[0].CertFile != "/etc/ssl/api.pem" || got[0].KeyFile != "/etc/ssl/api.key" {
+		t.Errorf("custom = %+v, %v", got, err)
+	}
+
+	for _, tc := range []struct {
+		hosts               []string
+		mode, cert, keyFile string
+	}{
+		{nil, "acme", "", ""},
+		{[]string{"api.example.com"}, "letsencrypt", "", ""},
+		{[]string{"api.example.com"}, "custom", "/etc/ssl/api.pem", ""},
+		{[]string{"api.example.com"}, "acme", "/etc/ssl/api.pem", "/etc/ssl/api.key"},
+	} {
+		if _, err := parseDomains(tc.hosts, tc.mode, tc.cert, tc.keyFile); err == nil {
+			t.Errorf("parseDomains(%v, %q, %q, %q) accepted", tc.hosts, tc.mode, tc.cert, tc.keyFile)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dployr-io/dployr/internal/cli/client"
 	"github.com/dployr-io/dployr/internal/cli/output"
@@ -20,6 +24,9 @@ func newInstancesCmd(makeDeps makeDepsFunc) *cobra.Command {
 	cmd.AddCommand(newInstancesPingCmd(makeDeps))
 	cmd.AddCommand(newInstancesDeleteCmd(makeDeps))
 	cmd.AddCommand(newInstancesSystemCmd(makeDeps))
+	cmd.AddCommand(newInstancesAuditCmd(makeDeps))
+	cmd.AddCommand(newInstancesSessionsCmd(makeDeps))
+	cmd.AddCommand(newInstancesMaintenanceCmd(makeDeps))
 	return cmd
 }
 
@@ -194,6 +201,256 @@ func newInstancesDeleteCmd(makeDeps makeDepsFunc) *cobra.Command {
 	return cmd
 }
 
+func newInstancesAuditCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var (
+		since string
+		limit int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit <tag>",
+		Short: "show privileged actions recorded on an instance (requires admin)",
+		Long: `List the privileged actions an instance recorded in its audit log, oldest
+first: who made each request, the task that carried it, what it targeted and
+how it ended. Entries are hash-chained on the instance; a warning is printed
+if the chain no longer verifies.
+
+--since takes a duration or an RFC3339 timestamp.
+
+Example:
+  dployr instances audit prod-1
+  dployr instances audit prod-1 --since 2h`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			auditLog, err := d.client.ListInstanceAudit(context.Background(), args[0], since, limit)
+			if err != nil {
+				return err
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(auditLog)
+			}
+
+			if !auditLog.Intact {
+				fmt.Fprintf(os.Stderr, "warning: audit log on %s failed verification at entry %d; it may have been tampered with\n", args[0], auditLog.BrokenAt)
+			}
+			if len(auditLog.Entries) == 0 {
+				fmt.Println("no audit entries found")
+				return nil
+			}
+
+			rows := make([][]string, len(auditLog.Entries))
+			for i, e := range auditLog.Entries {
+				task := e.TaskID
+				if task == "" {
+					task = "-"
+				}
+				rows[i] = []string{
+					e.Time.Time().Local().Format(time.DateTime),
+					e.Subject,
+					e.Role,
+					e.Method + " " + e.Route,
+					strconv.Itoa(e.Status),
+					e.Outcome,
+					task,
+				}
+			}
+			d.out.Table([]string{"TIME", "SUBJECT", "ROLE", "ACTION", "STATUS", "OUTCOME", "TASK"}, rows)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "24h", "show entries recorded since this duration ago or RFC3339 time")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 500, "maximum number of entries to return")
+	return cmd
+}
+
+// --- sessions subcommand ---
+
+func newInstancesSessionsCmd(makeDeps makeDepsFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "list and replay recorded terminal sessions (requires admin)",
+		Long: `Terminal sessions are recorded on instances that run with
+TERMINAL_RECORD=true, as asciicast v2 files kept under the data directory.`,
+	}
+
+	cmd.AddCommand(newInstancesSessionsListCmd(makeDeps))
+	cmd.AddCommand(newInstancesSessionsReplayCmd(makeDeps))
+	return cmd
+}
+
+func newInstancesSessionsListCmd(makeDeps makeDepsFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <tag>",
+		Short: "list recorded terminal sessions, newest first",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			recordings, err := d.client.ListInstanceSessions(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(recordings)
+			}
+
+			if len(recordings) == 0 {
+				fmt.Println("no recorded sessions found")
+				return nil
+			}
+
+			rows := make([][]string, len(recordings))
+			for i, r := range recordings {
+				duration := "open"
+				if r.EndedAt != nil {
+					duration = r.EndedAt.Time().Sub(r.StartedAt.Time()).Round(time.Second).String()
+				}
+				size := fmt.Sprintf("%.1f KB", float64(r.Bytes)/1024)
+				if r.Truncated {
+					size += " (truncated)"
+				}
+				target := "host"
+				if r.Service != "" {
+					target = r.Service
+				}
+				rows[i] = []string{r.ID, r.Subject, target, fmt.Sprintf("%dx%d", r.Cols, r.Rows), duration, size, timeAgo(r.StartedAt)}
+			}
+			d.out.Table([]string{"SESSION", "SUBJECT", "TARGET", "TERMINAL", "DURATION", "SIZE", "STARTED"}, rows)
+			return nil
+		},
+	}
+}
+
+func newInstancesSessionsReplayCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var (
+		speed     float64
+		idleLimit time.Duration
+		raw       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <tag> <id>",
+		Short: "play back a recorded terminal session",
+		Long: `Play a recorded terminal session back in this terminal at the pace it was
+recorded. Long pauses are shortened to --idle-limit. Pass --raw to print the
+asciicast file instead, for asciinema or another player.
+
+Example:
+  dployr instances sessions replay prod-1 01JZZ4K3T7Q9
+  dployr instances sessions replay prod-1 01JZZ4K3T7Q9 --speed 2
+  dployr instances sessions replay prod-1 01JZZ4K3T7Q9 --raw > session.cast`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			rec, err := d.client.GetInstanceSession(context.Background(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(rec)
+			}
+			if raw {
+				fmt.Print(rec.Cast)
+				return nil
+			}
+
+			if err := playCast(os.Stdout, rec.Cast, speed, idleLimit, time.Sleep); err != nil {
+				return err
+			}
+			fmt.Printf("\n\nend of session %s recorded for %s\n", rec.ID, rec.Subject)
+			if rec.Truncated {
+				fmt.Println("the recording was truncated; later output was not kept")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Float64Var(&speed, "speed", 1, "playback speed multiplier")
+	cmd.Flags().DurationVar(&idleLimit, "idle-limit", 2*time.Second, "longest pause between output, 0 to keep recorded pauses")
+	cmd.Flags().BoolVar(&raw, "raw", false, "print the asciicast file instead of playing it")
+	return cmd
+}
+
+// --- maintenance subcommand ---
+
+func newInstancesMaintenanceCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var domains []string
+
+	cmd := &cobra.Command{
+		Use:   "maintenance on|off <tag>",
+		Short: "put an instance into or take it out of maintenance mode",
+		Long: `In maintenance mode an instance holds new deployments in its queue, stops
+reporting services as degraded, and serves a 503 maintenance page in place of
+its routes. Turning maintenance off restores the routes and starts the held
+deployments.
+
+Example:
+  dployr instances maintenance on prod-1
+  dployr instances maintenance on prod-1 --domain shop.example.com
+  dployr instances maintenance off prod-1`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 || (args[0] != "on" && args[0] != "off") {
+				return fmt.Errorf("usage: dployr instances maintenance on|off <tag>")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			on, tag := args[0] == "on", args[1]
+			if !on && len(domains) > 0 {
+				return fmt.Errorf("--domain only applies when turning maintenance on")
+			}
+			if err := d.client.SetInstanceMaintenance(context.Background(), tag, on, domains); err != nil {
+				return err
+			}
+			switch {
+			case !on:
+				fmt.Printf("instance %s is out of maintenance\n", tag)
+			case len(domains) > 0:
+				fmt.Printf("instance %s is in maintenance for %s\n", tag, strings.Join(domains, ", "))
+			default:
+				fmt.Printf("instance %s is in maintenance\n", tag)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&domains, "domain", nil, "serve the maintenance page on this domain only (repeatable; defaults to all)")
+	return cmd
+}
+
 // --- system subcommand ---
 
 func newInstancesSystemCmd(makeDeps makeDepsFunc) *cobra.Command {
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dployr-io/dployr/internal/cli/output"
+	"github.com/spf13/cobra"
+)
+
+func newJobsCmd(makeDeps makeDepsFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "manage scheduled jobs",
+	}
+
+	cmd.AddCommand(newJobsRunsCmd(makeDeps))
+	cmd.AddCommand(newJobsTriggerCmd(makeDeps))
+	return cmd
+}
+
+func newJobsRunsCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var (
+		limit int
+		runID string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "runs <name>",
+		Short: "list a job's run history",
+		Long: `List the runs of a scheduled job, newest first, with each run's exit code
+and duration. Pass --run to print the captured output of one run.
+
+Example:
+  dployr jobs runs nightly-report
+  dployr jobs runs nightly-report --run 01JZZ4K3T7Q9`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			runs, err := d.client.ListJobRuns(context.Background(), args[0], limit)
+			if err != nil {
+				return err
+			}
+
+			if runID != "" {
+				for _, r := range runs {
+					if r.ID == runID {
+						if d.out.Format() == output.FormatJSON {
+							return d.out.JSON(r)
+						}
+						fmt.Print(r.Output)
+						return nil
+					}
+				}
+				return fmt.Errorf("run %s not found in the last %d runs of %s", runID, limit, args[0])
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(runs)
+			}
+
+			if len(runs) == 0 {
+				fmt.Println("no runs found")
+				return nil
+			}
+
+			rows := make([][]string, len(runs))
+			for i, r := range runs {
+				exit := "-"
+				if r.ExitCode != nil {
+					exit = strconv.Itoa(*r.ExitCode)
+				}
+				duration := "-"
+				if r.FinishedAt != nil {
+					duration = (time.Duration(r.DurationMs) * time.Millisecond).Round(time.Second).String()
+				}
+				rows[i] = []string{r.ID, r.Trigger, r.Status, exit, duration, timeAgo(r.StartedAt)}
+			}
+			d.out.Table([]string{"RUN", "TRIGGER", "STATUS", "EXIT", "DURATION", "STARTED"}, rows)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "maximum number of runs to return")
+	cmd.Flags().StringVar(&runID, "run", "", "print the output of this run")
+	return cmd
+}
+
+func newJobsTriggerCmd(makeDeps makeDepsFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "trigger <name>",
+		Short: "run a scheduled job now",
+		Long: `Start a run of a scheduled job outside its schedule. The job's concurrency
+policy still applies: a job with policy forbid is not started while a run is
+in progress, and one with policy replace stops the run in progress first.
+
+Example:
+  dployr jobs trigger nightly-report`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			run, err := d.client.TriggerJob(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(run)
+			}
+			fmt.Printf("job %s started (run %s)\n", args[0], run.ID)
+			return nil
+		},
+	}
+}
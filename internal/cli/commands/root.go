@@ -112,6 +112,8 @@ Manage clusters, services, deployments, and instances from your terminal.`,
 	root.AddCommand(newClustersCmd(makeDeps))
 	root.AddCommand(newServicesCmd(makeDeps))
 	root.AddCommand(newDeploymentsCmd(makeDeps))
+	root.AddCommand(newJobsCmd(makeDeps))
+	root.AddCommand(newRunCmd(makeDeps))
 	root.AddCommand(newInstancesCmd(makeDeps))
 	root.AddCommand(newLogsCmd(makeDeps))
 
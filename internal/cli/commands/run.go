@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dployr-io/dployr/internal/cli/output"
+	"github.com/spf13/cobra"
+)
+
+// runPollInterval is how often `dployr run` asks for new output.
+const runPollInterval = time.Second
+
+// ExitError carries the exit code of a remote command so the CLI can exit
+// with it. Its message is empty when the command itself reported the failure.
+type ExitError struct {
+	Code int
+	Msg  string
+}
+
+func (e *ExitError) Error() string {
+	if e.Msg != "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func newRunCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var timeout string
+
+	cmd := &cobra.Command{
+		Use:   "run <service> -- <command...>",
+		Short: "run a one-off command against a service",
+		Long: `Run a command in a fresh container started from a service's current image,
+with the same environment, secrets and resource limits as the service. Output
+is printed as it arrives and dployr exits with the command's exit code.
+
+The container is removed when the command exits or its timeout runs out.
+
+Example:
+  dployr run api -- rake db:migrate
+  dployr run api --timeout 2h -- python manage.py rebuild_index`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+				return fmt.Errorf("usage: dployr run <service> -- <command...>")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			service := args[0]
+			run, err := d.client.RunService(ctx, service, args[1:], timeout)
+			if err != nil {
+				return err
+			}
+
+			jsonOut := d.out.Format() == output.FormatJSON
+			var offset int64
+			for {
+				out, err := d.client.GetServiceRun(ctx, service, run.ID, offset)
+				if err != nil {
+					return err
+				}
+				if !jsonOut {
+					for _, e := range out.Entries {
+						switch e.Stream {
+						case "stdout":
+							fmt.Fprintln(os.Stdout, e.Msg)
+						case "stderr":
+							fmt.Fprintln(os.Stderr, e.Msg)
+						}
+					}
+				}
+				offset = out.Offset
+				run = out.Run
+
+				// Keep reading until the run is over and its log is drained.
+				if run.Status != "running" && len(out.Entries) == 0 {
+					break
+				}
+				if len(out.Entries) == 0 {
+					time.Sleep(runPollInterval)
+				}
+			}
+
+			if jsonOut {
+				if err := d.out.JSON(run); err != nil {
+					return err
+				}
+			}
+			switch {
+			case run.ExitCode != nil && *run.ExitCode == 0:
+				return nil
+			case run.ExitCode != nil:
+				return &ExitError{Code: *run.ExitCode}
+			default:
+				return &ExitError{Code: 1, Msg: fmt.Sprintf("run %s %s: %s", run.ID, run.Status, run.Error)}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&timeout, "timeout", "", "kill the command after this long, e.g. 10m (defaults to the node's limit)")
+	return cmd
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/dployr-io/dployr/internal/cli/client"
 	"github.com/dployr-io/dployr/internal/cli/output"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,10 @@ func newServicesCmd(makeDeps makeDepsFunc) *cobra.Command {
 	cmd.AddCommand(newServicesStopCmd(makeDeps))
 	cmd.AddCommand(newServicesStartCmd(makeDeps))
 	cmd.AddCommand(newServicesDeleteCmd(makeDeps))
+	cmd.AddCommand(newServicesReleasesCmd(makeDeps))
+	cmd.AddCommand(newServicesRollbackCmd(makeDeps))
+	cmd.AddCommand(newServicesScaleCmd(makeDeps))
+	cmd.AddCommand(newServicesUpdateCmd(makeDeps))
 	return cmd
 }
 
@@ -202,6 +207,179 @@ func newServicesDeleteCmd(makeDeps makeDepsFunc) *cobra.Command {
 	return cmd
 }
 
+func newServicesReleasesCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "releases <name>",
+		Short: "list a service's release history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			releases, err := d.client.ListReleases(context.Background(), args[0], limit)
+			if err != nil {
+				return err
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(releases)
+			}
+
+			if len(releases) == 0 {
+				fmt.Println("no releases found")
+				return nil
+			}
+
+			rows := make([][]string, len(releases))
+			for i, r := range releases {
+				commit := "-"
+				if r.CommitHash != "" {
+					commit = shortHash(r.CommitHash)
+				}
+				image := r.Image
+				if image == "" {
+					image = "-"
+				}
+				rows[i] = []string{fmt.Sprintf("%d", r.Version), r.Status, commit, image, timeAgo(r.CreatedAt)}
+			}
+			d.out.Table([]string{"RELEASE", "STATUS", "COMMIT", "IMAGE", "CREATED"}, rows)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "l", 20, "maximum number of releases to return")
+	return cmd
+}
+
+func newServicesRollbackCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "rollback <name>",
+		Short: "redeploy a previous release of a service",
+		Long: `Redeploy a previous release of a service from its already built image.
+No rebuild happens; the image is pulled and started through the normal deploy path.
+
+Without --to, the service returns to the release before the one currently live.
+
+Example:
+  dployr services rollback api
+  dployr services rollback api --to 12`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+
+			result, err := d.client.RollbackService(context.Background(), args[0], to)
+			if err != nil {
+				return err
+			}
+
+			if d.out.Format() == output.FormatJSON {
+				return d.out.JSON(result)
+			}
+
+			target := "previous release"
+			if to != "" {
+				target = "release " + to
+			}
+			fmt.Printf("rolling back %s to %s (task %s)\n", args[0], target, result.TaskID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "release version or ID to roll back to")
+	return cmd
+}
+
+func newServicesScaleCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var replicas int
+
+	cmd := &cobra.Command{
+		Use:   "scale <name>",
+		Short: "change how many replicas of a service run",
+		Long: `Change how many replicas of a web or worker service run, without a rebuild.
+New replicas start from the image already deployed; web replicas are added to
+the load balancer once they pass the service's health check.
+
+Example:
+  dployr services scale api --replicas 3`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+			if replicas < 1 {
+				return fmt.Errorf("--replicas must be at least 1")
+			}
+			if err := d.client.ScaleService(context.Background(), args[0], replicas); err != nil {
+				return err
+			}
+			fmt.Printf("service %s scaled to %d replicas\n", args[0], replicas)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&replicas, "replicas", 0, "number of replicas to run")
+	_ = cmd.MarkFlagRequired("replicas")
+	return cmd
+}
+
+func newServicesUpdateCmd(makeDeps makeDepsFunc) *cobra.Command {
+	var r client.ServiceResources
+
+	cmd := &cobra.Command{
+		Use:   "update <name>",
+		Short: "change a service's resource limits",
+		Long: `Change the memory, CPU or process limits of a running service in place.
+No rebuild or restart happens; limits not given keep their current value.
+The service's replicas together must fit within the cluster's limits.
+
+Example:
+  dployr services update api --memory 512 --cpu 500
+  dployr services update worker --pids 256`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := makeDeps(cmd)
+			if err != nil {
+				return err
+			}
+			if err := requireAuth(d.cfg); err != nil {
+				return err
+			}
+			if r == (client.ServiceResources{}) {
+				return fmt.Errorf("nothing to update; pass --memory, --cpu or --pids")
+			}
+			if err := d.client.UpdateServiceResources(context.Background(), args[0], r); err != nil {
+				return err
+			}
+			fmt.Printf("service %s updated\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&r.MemoryMB, "memory", 0, "memory limit per container in MB")
+	cmd.Flags().IntVar(&r.CPU, "cpu", 0, "CPU limit per container in millicores (1000 = one core)")
+	cmd.Flags().IntVar(&r.Pids, "pids", 0, "maximum number of processes per container")
+	return cmd
+}
+
 // shortHash returns the first 8 characters of a commit hash for display.
 func shortHash(h string) string {
 	if len(h) > 8 {
@@ -36,7 +36,25 @@ func newTokensCreateCmd(makeDeps makeDepsFunc) *cobra.Command {
 The token is shown only once — save it immediately.
 
 Available scopes:
-  oidc:bind   Allow registering OIDC bindings (e.g. GitHub Actions bootstrap)
+  oidc:bind          Allow registering OIDC bindings (e.g. GitHub Actions bootstrap)
+  deployments:read   List deployments and release history
+  deployments:write  Create, cancel and roll back deployments
+  services:read      List and inspect services
+  services:write     Delete, sleep, wake and ice services
+  builds:write       Build and publish images
+  proxy:read         Read proxy status
+  proxy:write        Add, remove and restart proxy routes
+  system:read        Read node status, tasks, metrics and diagnostics
+  system:write       Install, restart, reboot and reconfigure the node
+  fs:read            List and read files on the node
+  fs:write           Write, create and delete files on the node
+  logs:read          Stream deployment and service logs
+  terminal:open      Open a shell on the node
+  terminal:exec      Open a shell inside a service's container
+  terminal:read      List and replay recorded terminal sessions
+
+A write scope also grants the matching read scope; "<resource>:*" grants both.
+A scoped token is refused by every endpoint its scopes do not cover.
 
 Example:
   dployr auth tokens create --name "github-actions" --scope oidc:bind`,
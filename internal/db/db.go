@@ -21,13 +21,20 @@ import (
 var migrationFiles embed.FS
 
 func Open() (*sql.DB, error) {
-	dataDir := utils.GetDataDir()
-	dbPath := filepath.Join(dataDir, "data.db")
-
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
+	if err := os.MkdirAll(utils.GetDataDir(), 0755); err != nil {
 		return nil, err
 	}
+	return OpenFile(Path())
+}
+
+// Path returns where the daemon's database is kept. SQLite keeps its
+// write-ahead log and shared memory beside it, suffixed -wal and -shm.
+func Path() string {
+	return filepath.Join(utils.GetDataDir(), "data.db")
+}
 
+// OpenFile opens the database at dbPath and brings its schema up to date.
+func OpenFile(dbPath string) (*sql.DB, error) {
 	// Connection string with WAL mode and busy timeout to prevent SQLITE_BUSY errors
 	dsn := fmt.Sprintf("%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", dbPath)
 	db, err := sql.Open("sqlite", dsn)
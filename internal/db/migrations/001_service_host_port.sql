@@ -0,0 +1,6 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Host port the live container is bound to. Blue/green redeploys alternate
+-- between two slots, so this can differ from the hashed default.
+ALTER TABLE services ADD COLUMN host_port INTEGER;
@@ -0,0 +1,46 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- RELEASES TABLE
+-- Append-only history of every deployment of a service. Unlike deployments,
+-- rows are never overwritten, so earlier images stay available for rollback.
+CREATE TABLE IF NOT EXISTS releases (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    deployment_id TEXT NOT NULL,
+    config JSON NOT NULL DEFAULT '{}',
+    image TEXT,
+    commit_hash TEXT,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'failed', 'completed')),
+    created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    UNIQUE (name, version)
+);
+
+CREATE INDEX idx_releases_name ON releases(name, version DESC);
+
+-- Only status and updated_at may change once a release is recorded
+CREATE TRIGGER trg_releases_immutable
+BEFORE UPDATE ON releases
+FOR EACH ROW
+BEGIN
+    SELECT
+        CASE
+            WHEN NEW.name IS NOT OLD.name
+                 OR NEW.version IS NOT OLD.version
+                 OR NEW.deployment_id IS NOT OLD.deployment_id
+                 OR NEW.config IS NOT OLD.config
+                 OR NEW.image IS NOT OLD.image
+                 OR NEW.commit_hash IS NOT OLD.commit_hash
+                 OR NEW.created_at IS NOT OLD.created_at THEN
+                RAISE(ABORT, 'releases are append-only; only status may be updated')
+        END;
+END;
+
+CREATE TRIGGER trg_releases_no_delete
+BEFORE DELETE ON releases
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'releases are append-only and cannot be deleted');
+END;
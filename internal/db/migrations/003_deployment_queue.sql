@@ -0,0 +1,16 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- DEPLOYMENT QUEUE TABLE
+-- Durable backing for the worker queue. A row exists from submission until the
+-- worker finishes the deployment, so jobs survive a daemon restart.
+CREATE TABLE IF NOT EXISTS deployment_queue (
+    deployment_id TEXT PRIMARY KEY REFERENCES deployments(id) ON DELETE CASCADE,
+    enqueued_at INTEGER NOT NULL DEFAULT (unixepoch())
+);
+
+CREATE INDEX idx_deployment_queue_enqueued_at ON deployment_queue(enqueued_at);
+
+-- Deployments left pending by earlier versions were only ever held in memory.
+INSERT OR IGNORE INTO deployment_queue (deployment_id)
+SELECT id FROM deployments WHERE status = 'pending';
@@ -0,0 +1,71 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Allow the 'cancelled' status on deployments and releases. SQLite cannot alter
+-- a CHECK constraint, so both tables are rebuilt with their rows copied over.
+
+-- DEPLOYMENTS TABLE
+CREATE TABLE deployments_new (
+    id TEXT PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    config JSON NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'failed', 'completed', 'cancelled')),
+    metadata JSON NOT NULL DEFAULT '{}',
+    user_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO deployments_new (id, name, config, status, metadata, user_id, created_at, updated_at)
+SELECT id, name, config, status, metadata, user_id, created_at, updated_at FROM deployments;
+
+DROP TABLE deployments;
+ALTER TABLE deployments_new RENAME TO deployments;
+
+-- RELEASES TABLE
+CREATE TABLE releases_new (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    deployment_id TEXT NOT NULL,
+    config JSON NOT NULL DEFAULT '{}',
+    image TEXT,
+    commit_hash TEXT,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'failed', 'completed', 'cancelled')),
+    created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    updated_at INTEGER NOT NULL DEFAULT (unixepoch()),
+    UNIQUE (name, version)
+);
+
+INSERT INTO releases_new (id, name, version, deployment_id, config, image, commit_hash, status, created_at, updated_at)
+SELECT id, name, version, deployment_id, config, image, commit_hash, status, created_at, updated_at FROM releases;
+
+DROP TABLE releases;
+ALTER TABLE releases_new RENAME TO releases;
+
+CREATE INDEX idx_releases_name ON releases(name, version DESC);
+
+-- Dropping the table dropped its triggers; restore them unchanged
+CREATE TRIGGER trg_releases_immutable
+BEFORE UPDATE ON releases
+FOR EACH ROW
+BEGIN
+    SELECT
+        CASE
+            WHEN NEW.name IS NOT OLD.name
+                 OR NEW.version IS NOT OLD.version
+                 OR NEW.deployment_id IS NOT OLD.deployment_id
+                 OR NEW.config IS NOT OLD.config
+                 OR NEW.image IS NOT OLD.image
+                 OR NEW.commit_hash IS NOT OLD.commit_hash
+                 OR NEW.created_at IS NOT OLD.created_at THEN
+                RAISE(ABORT, 'releases are append-only; only status may be updated')
+        END;
+END;
+
+CREATE TRIGGER trg_releases_no_delete
+BEFORE DELETE ON releases
+FOR EACH ROW
+BEGIN
+    SELECT RAISE(ABORT, 'releases are append-only and cannot be deleted');
+END;
@@ -0,0 +1,19 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- JOB RUNS TABLE
+-- One row per execution of a job service, whether started by its schedule or
+-- triggered by hand.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    trigger TEXT NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed', 'skipped', 'cancelled')),
+    exit_code INTEGER,
+    output TEXT NOT NULL DEFAULT '',
+    started_at INTEGER NOT NULL,
+    finished_at INTEGER,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_job_runs_name_started_at ON job_runs(name, started_at DESC);
@@ -0,0 +1,22 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- SERVICE RUNS TABLE
+-- One row per one-off command run against a deployed service in an
+-- ephemeral container. The command is stored as a JSON array of arguments.
+CREATE TABLE IF NOT EXISTS service_runs (
+    id TEXT PRIMARY KEY,
+    service TEXT NOT NULL,
+    command TEXT NOT NULL,
+    image TEXT NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status IN ('running', 'succeeded', 'failed', 'timed_out')),
+    exit_code INTEGER,
+    error TEXT NOT NULL DEFAULT '',
+    timeout_ms INTEGER NOT NULL DEFAULT 0,
+    started_at INTEGER NOT NULL,
+    finished_at INTEGER,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_service_runs_service_started_at ON service_runs(service, started_at DESC);
@@ -0,0 +1,14 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- PORT RESERVATIONS TABLE
+-- Host ports handed out to service containers, one row per container slot.
+-- Rows are removed with their service.
+CREATE TABLE IF NOT EXISTS port_reservations (
+    port INTEGER PRIMARY KEY CHECK (port BETWEEN 61000 AND 64999),
+    container TEXT UNIQUE NOT NULL,
+    service TEXT NOT NULL,
+    created_at INTEGER NOT NULL DEFAULT (unixepoch())
+);
+
+CREATE INDEX idx_port_reservations_service ON port_reservations(service);
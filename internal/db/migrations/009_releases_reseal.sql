@@ -0,0 +1,26 @@
+-- Copyright 2025 Emmanuel Madehin
+-- SPDX-License-Identifier: Apache-2.0
+
+-- Let releases written before secrets were encrypted have them sealed in
+-- place. The config of a release may change only in its secrets, and only to
+-- sealed values; everything else stays append-only.
+DROP TRIGGER IF EXISTS trg_releases_immutable;
+
+CREATE TRIGGER trg_releases_immutable
+BEFORE UPDATE ON releases
+FOR EACH ROW
+BEGIN
+    SELECT
+        CASE
+            WHEN NEW.name IS NOT OLD.name
+                 OR NEW.version IS NOT OLD.version
+                 OR NEW.deployment_id IS NOT OLD.deployment_id
+                 OR NEW.image IS NOT OLD.image
+                 OR NEW.commit_hash IS NOT OLD.commit_hash
+                 OR NEW.created_at IS NOT OLD.created_at
+                 OR (NEW.config IS NOT OLD.config
+                     AND (json_remove(NEW.config, '$.secrets') IS NOT json_remove(OLD.config, '$.secrets')
+                          OR EXISTS (SELECT 1 FROM json_each(NEW.config, '$.secrets') WHERE value NOT LIKE 'enc:v1:%'))) THEN
+                RAISE(ABORT, 'releases are append-only; only status may be updated')
+        END;
+END;
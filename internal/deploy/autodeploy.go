@@ -0,0 +1,59 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// DeployCommit queues a build of commit for a service that opted into
+// auto-deploys. Everything else comes from the service's current blueprint,
+// so the new deployment differs from the live one only in the commit it
+// checks out, and it runs as the user who created the live one. A push that
+// lands while the service's previous deployment is still running is built
+// once that one finishes.
+func (d *Deployer) DeployCommit(ctx context.Context, name, commit string) (*deploy.DeployResponse, error) {
+	live, err := d.store.GetDeploymentByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deployment for %s: %w", name, err)
+	}
+
+	bp := live.Blueprint
+	if bp.Source != store.SourceRemote || !bp.AutoDeploy {
+		return nil, fmt.Errorf("service %s does not auto-deploy from a git remote", name)
+	}
+	bp.Remote.CommitHash = commit
+
+	deployment := &store.Deployment{
+		ID:        ulid.Make().String(),
+		Status:    store.StatusPending,
+		Name:      bp.Name,
+		Blueprint: bp,
+		UserId:    live.UserId,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := d.store.UpsertDeployment(ctx, deployment); err != nil {
+		return nil, fmt.Errorf("failed to upsert deployment: %w", err)
+	}
+
+	d.logger.Info("auto-deploying pushed commit", "service", name, "commit", commit, "deployment_id", deployment.ID)
+	if err := d.submit(ctx, deployment.ID); err != nil {
+		return nil, err
+	}
+
+	return &deploy.DeployResponse{
+		ID:        deployment.ID,
+		Name:      deployment.Blueprint.Name,
+		Success:   true,
+		CreatedAt: deployment.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"archive/tar"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/archive"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// buildHash fingerprints everything that goes into an image: the files of
+// the build context (names, modes and contents, not timestamps) and the
+// build options. Two builds with the same hash produce the same image, so
+// the hash doubles as the image tag.
+func buildHash(srcDir string, excludes []string, opts BuildOpts) (string, error) {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "runtime=%s\nversion=%s\nbuilder=%s\nrunner=%s\nbuild=%s\nrun=%s\nport=%d\nnext=%t\n",
+		opts.Runtime, opts.Version, opts.BuilderImage, opts.RunnerImage, opts.BuildCmd, opts.RunCmd, opts.Port, opts.IsNextJS)
+	args := buildArgs(opts.Env)
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "arg %s=%s\n", k, *args[k])
+	}
+
+	rc, err := archive.TarWithOptions(srcDir, &archive.TarOptions{ExcludePatterns: excludes})
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%c %s %o %s\n", hdr.Typeflag, hdr.Name, hdr.Mode&0o777, hdr.Linkname)
+		if _, err := io.Copy(h, tr); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildExcludes turns DockerIgnoreContent into archive exclude patterns.
+// Dockerfile and .dockerignore stay in the context so the daemon sees them.
+func buildExcludes() []string {
+	var excludes []string
+	for line := range strings.SplitSeq(DockerIgnoreContent, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || line == "Dockerfile" || line == ".dockerignore" {
+			continue
+		}
+		excludes = append(excludes, line)
+	}
+	return excludes
+}
+
+// buildArgs returns the NEXT_PUBLIC_ variables, which Next.js inlines at
+// build time, as docker build args. Other variables only matter at run time.
+func buildArgs(env map[string]string) map[string]*string {
+	var args map[string]*string
+	for k, v := range env {
+		if strings.HasPrefix(k, "NEXT_PUBLIC_") {
+			if args == nil {
+				args = map[string]*string{}
+			}
+			val := v
+			args[k] = &val
+		}
+	}
+	return args
+}
+
+// imageInRegistry reports whether ref is already pushed. Any error, from a
+// missing tag to an unreachable registry, counts as not there and the image
+// is built.
+func imageInRegistry(ctx context.Context, ref, auth string, dockerCli deployDockerAPI) bool {
+	_, err := dockerCli.DistributionInspect(ctx, ref, auth)
+	return err == nil
+}
+
+// pruneBuildCache keeps the newest built images on this node, up to
+// cfg.BuildCacheSize MB, as layer cache for the next build, and removes the
+// rest. keep, the image just pushed, always stays. With the cache disabled
+// only keep is removed, as every build used to do.
+func pruneBuildCache(ctx context.Context, cfg *shared.Config, keep string, dockerCli deployDockerAPI) error {
+	if cfg.BuildCacheSize <= 0 {
+		_, err := dockerCli.ImageRemove(ctx, keep, image.RemoveOptions{Force: true, PruneChildren: true})
+		return err
+	}
+
+	images, err := dockerCli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return err
+	}
+	prefix := strings.TrimRight(cfg.RegistryURL, "/") + "/apps:"
+	built := slices.DeleteFunc(images, func(img image.Summary) bool {
+		return !slices.ContainsFunc(img.RepoTags, func(t string) bool { return strings.HasPrefix(t, prefix) })
+	})
+	slices.SortFunc(built, func(a, b image.Summary) int { return cmp.Compare(b.Created, a.Created) })
+
+	limit := int64(cfg.BuildCacheSize) * 1024 * 1024
+	var total int64
+	var errs []error
+	for _, img := range built {
+		total += img.Size
+		if total <= limit || slices.Contains(img.RepoTags, keep) {
+			continue
+		}
+		if _, err := dockerCli.ImageRemove(ctx, img.ID, image.RemoveOptions{Force: true, PruneChildren: true}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// buildFake plays a registry holding the refs in pushed and records builds.
+type buildFake struct {
+	*fakeDocker
+	pushed  []string
+	images  []image.Summary
+	builds  []dockertypes.ImageBuildOptions
+	imgGone []string
+}
+
+func (f *buildFake) DistributionInspect(_ context.Context, ref, _ string) (registry.DistributionInspect, error) {
+	if slices.Contains(f.pushed, ref) {
+		return registry.DistributionInspect{}, nil
+	}
+	return registry.DistributionInspect{}, errors.New("manifest unknown")
+}
+
+func (f *buildFake) ImageBuild(_ context.Context, _ io.Reader, opts dockertypes.ImageBuildOptions) (dockertypes.ImageBuildResponse, error) {
+	f.builds = append(f.builds, opts)
+	return dockertypes.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(`{"stream":"Step 1/1 : FROM scratch"}`))}, nil
+}
+
+func (f *buildFake) ImagePush(_ context.Context, ref string, _ image.PushOptions) (io.ReadCloser, error) {
+	f.pushed = append(f.pushed, ref)
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (f *buildFake) ImageList(context.Context, image.ListOptions) ([]image.Summary, error) {
+	return f.images, nil
+}
+
+func (f *buildFake) ImageRemove(_ context.Context, id string, _ image.RemoveOptions) ([]image.DeleteResponse, error) {
+	f.imgGone = append(f.imgGone, id)
+	return nil, nil
+}
+
+func writeSource(t *testing.T, dir, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildHash_FollowsContentNotTimestamps(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, "package main\n")
+	opts := BuildOpts{Runtime: "golang", Version: "1.25"}
+
+	first, err := buildHash(dir, buildExcludes(), opts)
+	if err != nil {
+		t.Fatalf("buildHash: %v", err)
+	}
+
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(dir, "main.go"), later, later)
+	if got, _ := buildHash(dir, buildExcludes(), opts); got != first {
+		t.Error("touching a file changed the hash")
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+	if got, _ := buildHash(dir, buildExcludes(), opts); got != first {
+		t.Error("an excluded file changed the hash")
+	}
+
+	opts.Env = map[string]string{"NEXT_PUBLIC_API": "https://api.example.com", "SECRET": "x"}
+	withArg, _ := buildHash(dir, buildExcludes(), opts)
+	if withArg == first {
+		t.Error("a build arg did not change the hash")
+	}
+	opts.Env["SECRET"] = "y"
+	if got, _ := buildHash(dir, buildExcludes(), opts); got != withArg {
+		t.Error("a run-time variable changed the hash")
+	}
+
+	writeSource(t, dir, "package main\n\nfunc main() {}\n")
+	if got, _ := buildHash(dir, buildExcludes(), opts); got == withArg {
+		t.Error("editing a file did not change the hash")
+	}
+}
+
+func TestBuildImage_ReusesPushedImage(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, "package main\n")
+	cfg := &shared.Config{RegistryURL: "registry.local", BuildCacheSize: 1024}
+	opts := BuildOpts{Runtime: "golang", Version: "1.25"}
+	docker := &buildFake{fakeDocker: newFakeDocker()}
+
+	ref, cached, err := BuildImage(context.Background(), "my_app", dir, cfg, opts, docker, "my_app", "")
+	if err != nil || cached {
+		t.Fatalf("first build = %q, %t, %v; want a fresh build", ref, cached, err)
+	}
+	if !strings.HasPrefix(ref, "registry.local/apps:my-app-") {
+		t.Errorf("ref = %q", ref)
+	}
+
+	again, cached, err := BuildImage(context.Background(), "my_app", dir, cfg, opts, docker, "my_app", "")
+	if err != nil || !cached || again != ref {
+		t.Fatalf("second build = %q, %t, %v; want the cached %q", again, cached, err, ref)
+	}
+	if len(docker.builds) != 1 {
+		t.Errorf("built %d times, want 1", len(docker.builds))
+	}
+
+	opts.ForceRebuild = true
+	forced, cached, err := BuildImage(context.Background(), "my_app", dir, cfg, opts, docker, "my_app", "")
+	if err != nil || cached || forced != ref {
+		t.Fatalf("forced build = %q, %t, %v; want a fresh build of %q", forced, cached, err, ref)
+	}
+	if len(docker.builds) != 2 || !docker.builds[1].NoCache || !docker.builds[1].PullParent {
+		t.Errorf("forced build options = %+v, want no layer cache", docker.builds[len(docker.builds)-1])
+	}
+	if len(docker.imgGone) != 0 {
+		t.Errorf("removed %v, want images kept as cache", docker.imgGone)
+	}
+}
+
+func TestPruneBuildCache(t *testing.T) {
+	const mb = 1024 * 1024
+	docker := &buildFake{fakeDocker: newFakeDocker(), images: []image.Summary{
+		{ID: "old", Created: 1, Size: 300 * mb, RepoTags: []string{"registry.local/apps:api-aaa"}},
+		{ID: "base", Created: 2, Size: 900 * mb, RepoTags: []string{"node:22"}},
+		{ID: "mid", Created: 3, Size: 300 * mb, RepoTags: []string{"registry.local/apps:web-bbb"}},
+		{ID: "new", Created: 4, Size: 300 * mb, RepoTags: []string{"registry.local/apps:api-ccc"}},
+	}}
+	cfg := &shared.Config{RegistryURL: "registry.local/", BuildCacheSize: 700}
+
+	if err := pruneBuildCache(context.Background(), cfg, "registry.local/apps:api-ccc", docker); err != nil {
+		t.Fatalf("pruneBuildCache: %v", err)
+	}
+	if !slices.Equal(docker.imgGone, []string{"old"}) {
+		t.Errorf("removed %v, want only the oldest build past the limit", docker.imgGone)
+	}
+
+	docker.imgGone = nil
+	cfg.BuildCacheSize = 0
+	pruneBuildCache(context.Background(), cfg, "registry.local/apps:api-ccc", docker)
+	if !slices.Equal(docker.imgGone, []string{"registry.local/apps:api-ccc"}) {
+		t.Errorf("removed %v, want just the pushed image with the cache off", docker.imgGone)
+	}
+}
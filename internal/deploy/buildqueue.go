@@ -0,0 +1,263 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/core/system"
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+)
+
+// buildQueue hands out the build slots of a build node. Builds that find no
+// free slot wait in a FIFO per cluster and clusters take turns, so one
+// cluster pushing many builds cannot hold back the others. A service has at
+// most one waiting build: a request for the same commit joins it and a
+// request for another commit replaces it.
+type buildQueue struct {
+	mu      sync.Mutex
+	slots   int
+	running int
+	waiting map[string][]*queuedBuild // cluster ID → builds in arrival order
+	turns   []string                  // clusters with waiting builds, next first
+	now     func() time.Time
+}
+
+// queuedBuild is one build, waited on by the request that queued it and any
+// that joined it.
+type queuedBuild struct {
+	service  string
+	cluster  string
+	commit   string
+	force    bool
+	queuedAt time.Time
+	start    chan struct{} // closed when the build gets a slot
+	done     chan struct{} // closed once resp and err are final
+	started  time.Time
+	resp     *deploy.BuildResponse
+	err      error
+}
+
+// buildTicket is a request's place in the queue.
+type buildTicket struct {
+	build    *queuedBuild
+	owner    bool // the request that runs the build, not one that joined it
+	position int  // 1-based place on arrival, 0 when a slot was free
+	arrived  time.Time
+}
+
+func newBuildQueue(slots int) *buildQueue {
+	return &buildQueue{
+		slots:   max(slots, 1),
+		waiting: make(map[string][]*queuedBuild),
+		now:     time.Now,
+	}
+}
+
+// add queues a build for req, or takes a slot straight away when one is free
+// and nothing is waiting.
+func (q *buildQueue) add(req *deploy.BuildRequest) *buildTicket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	b := &queuedBuild{
+		service:  coreutils.FormatName(req.Name),
+		cluster:  req.ClusterId,
+		commit:   req.Remote.CommitHash,
+		force:    req.ForceRebuild,
+		queuedAt: now,
+		start:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if q.running < q.slots && len(q.turns) == 0 {
+		q.running++
+		b.started = now
+		close(b.start)
+		return &buildTicket{build: b, owner: true, arrived: now}
+	}
+
+	if old, i := q.find(b.service); old != nil {
+		if old.commit == b.commit && (old.force || !b.force) {
+			return &buildTicket{build: old, position: q.position(old), arrived: now}
+		}
+		// The newer request keeps the place the older one had earned.
+		b.queuedAt = old.queuedAt
+		q.waiting[old.cluster][i] = b
+		b.cluster = old.cluster
+		old.finish(nil, deploy.ErrBuildSuperseded)
+	} else {
+		if len(q.waiting[b.cluster]) == 0 {
+			q.turns = append(q.turns, b.cluster)
+		}
+		q.waiting[b.cluster] = append(q.waiting[b.cluster], b)
+	}
+	return &buildTicket{build: b, owner: true, position: q.position(b), arrived: now}
+}
+
+// wait blocks until the ticket's build has run and returns its result. The
+// owner runs build itself once it has a slot; a request that joined waits for
+// the owner. Leaving the queue through ctx gives up the place.
+func (q *buildQueue) wait(ctx context.Context, t *buildTicket, build func(context.Context) (*deploy.BuildResponse, error)) (*deploy.BuildResponse, error) {
+	b := t.build
+	if !t.owner {
+		select {
+		case <-b.done:
+			return t.result()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case <-b.start:
+	case <-b.done:
+		return nil, b.err
+	case <-ctx.Done():
+		q.leave(b, ctx.Err())
+		return nil, ctx.Err()
+	}
+
+	resp, err := build(ctx)
+	b.finish(resp, err)
+	q.release()
+	return t.result()
+}
+
+// cancel drops the waiting build of service, if any.
+func (q *buildQueue) cancel(service string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b, i := q.find(service)
+	if b == nil {
+		return false
+	}
+	q.remove(b.cluster, i)
+	b.finish(nil, context.Canceled)
+	return true
+}
+
+// status reports the slots and the waiting builds in the order they will run.
+func (q *buildQueue) status() *system.BuildsDiag {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	diag := &system.BuildsDiag{Slots: q.slots, Running: q.running, Queued: []system.QueuedBuildDiag{}}
+	for i, b := range q.order() {
+		diag.Queued = append(diag.Queued, system.QueuedBuildDiag{
+			Service:   b.service,
+			ClusterID: b.cluster,
+			Commit:    b.commit,
+			Position:  i + 1,
+			WaitMs:    now.Sub(b.queuedAt).Milliseconds(),
+		})
+	}
+	return diag
+}
+
+// leave gives up a place whose owner stopped waiting. If the slot was handed
+// over in the meantime it is passed on.
+func (q *buildQueue) leave(b *queuedBuild, err error) {
+	q.mu.Lock()
+	if found, i := q.find(b.service); found == b {
+		q.remove(b.cluster, i)
+		q.mu.Unlock()
+		b.finish(nil, err)
+		return
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-b.start:
+		b.finish(nil, err)
+		q.release()
+	default:
+	}
+}
+
+// release frees a slot and hands it to the next waiting build.
+func (q *buildQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running--
+	if len(q.turns) == 0 {
+		return
+	}
+	cluster := q.turns[0]
+	b := q.waiting[cluster][0]
+	q.remove(cluster, 0)
+	if len(q.waiting[cluster]) > 0 {
+		q.turns = append(slices.Delete(q.turns, 0, 1), cluster)
+	}
+	q.running++
+	b.started = q.now()
+	close(b.start)
+}
+
+// order lists the waiting builds in dispatch order: one from each cluster in
+// turn, each cluster's builds first come first served.
+func (q *buildQueue) order() []*queuedBuild {
+	var out []*queuedBuild
+	for round := 0; ; round++ {
+		added := false
+		for _, c := range q.turns {
+			if round < len(q.waiting[c]) {
+				out = append(out, q.waiting[c][round])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
+
+func (q *buildQueue) position(b *queuedBuild) int {
+	return slices.Index(q.order(), b) + 1
+}
+
+func (q *buildQueue) find(service string) (*queuedBuild, int) {
+	for _, c := range q.turns {
+		for i, b := range q.waiting[c] {
+			if b.service == service {
+				return b, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+func (q *buildQueue) remove(cluster string, i int) {
+	q.waiting[cluster] = slices.Delete(q.waiting[cluster], i, i+1)
+	if len(q.waiting[cluster]) == 0 {
+		delete(q.waiting, cluster)
+		q.turns = slices.DeleteFunc(q.turns, func(c string) bool { return c == cluster })
+	}
+}
+
+func (b *queuedBuild) finish(resp *deploy.BuildResponse, err error) {
+	b.resp, b.err = resp, err
+	close(b.done)
+}
+
+// result is the build's outcome as seen by one request, with its own place
+// and time in the queue.
+func (t *buildTicket) result() (*deploy.BuildResponse, error) {
+	b := t.build
+	if b.err != nil {
+		return nil, b.err
+	}
+	resp := *b.resp
+	resp.QueuePosition = t.position
+	resp.QueueWaitMs = max(b.started.Sub(t.arrived), 0).Milliseconds()
+	return &resp, nil
+}
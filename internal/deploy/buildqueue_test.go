@@ -0,0 +1,161 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+func buildReq(name, cluster, commit string) *deploy.BuildRequest {
+	req := &deploy.BuildRequest{}
+	req.Name = name
+	req.ClusterId = cluster
+	req.Remote = store.RemoteObj{CommitHash: commit}
+	return req
+}
+
+func started(b *queuedBuild) bool {
+	select {
+	case <-b.start:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestBuildQueue_BoundsConcurrentBuilds(t *testing.T) {
+	q := newBuildQueue(2)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	running, peak := 0, 0
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.wait(context.Background(), q.add(buildReq(name, "c1", "x")), func(context.Context) (*deploy.BuildResponse, error) {
+				mu.Lock()
+				running++
+				peak = max(peak, running)
+				mu.Unlock()
+				<-release
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return &deploy.BuildResponse{Image: name}, nil
+			})
+		}()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for q.status().Running < 2 || len(q.status().Queued) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %+v, want 2 running and 3 queued", q.status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("peak concurrent builds = %d, want 2", peak)
+	}
+	if s := q.status(); s.Running != 0 || len(s.Queued) != 0 {
+		t.Errorf("status after all builds = %+v, want empty", s)
+	}
+}
+
+func TestBuildQueue_ClustersTakeTurns(t *testing.T) {
+	q := newBuildQueue(1)
+	if tk := q.add(buildReq("running", "c1", "x")); tk.position != 0 || !started(tk.build) {
+		t.Fatalf("first build did not get the free slot: %+v", tk)
+	}
+
+	a1 := q.add(buildReq("a1", "c1", "x"))
+	a2 := q.add(buildReq("a2", "c1", "x"))
+	b1 := q.add(buildReq("b1", "c2", "x"))
+	if a1.position != 1 || a2.position != 2 || b1.position != 2 {
+		t.Errorf("positions on arrival = %d, %d, %d; want 1, 2, 2", a1.position, a2.position, b1.position)
+	}
+
+	var order []string
+	for _, b := range q.status().Queued {
+		order = append(order, b.Service)
+	}
+	if want := []string{"a1", "b1", "a2"}; !slices.Equal(order, want) {
+		t.Errorf("queue order = %v, want %v", order, want)
+	}
+
+	for _, want := range []*buildTicket{a1, b1, a2} {
+		q.release()
+		if !started(want.build) {
+			t.Fatalf("%s did not get the freed slot", want.build.service)
+		}
+	}
+}
+
+func TestBuildQueue_SupersedesAndJoins(t *testing.T) {
+	q := newBuildQueue(1)
+	running := q.add(buildReq("other", "c1", "x"))
+
+	old := q.add(buildReq("web", "c1", "aaa"))
+	newer := q.add(buildReq("web", "c1", "bbb"))
+	if _, err := q.wait(context.Background(), old, nil); !errors.Is(err, deploy.ErrBuildSuperseded) {
+		t.Fatalf("older build err = %v, want ErrBuildSuperseded", err)
+	}
+	if newer.position != 1 {
+		t.Errorf("newer build position = %d, want the older one's place 1", newer.position)
+	}
+
+	same := q.add(buildReq("web", "c1", "bbb"))
+	if same.owner || same.build != newer.build {
+		t.Fatal("a request for the queued commit did not join the queued build")
+	}
+
+	go q.wait(context.Background(), running, func(context.Context) (*deploy.BuildResponse, error) {
+		return &deploy.BuildResponse{Image: "other"}, nil
+	})
+	go q.wait(context.Background(), newer, func(context.Context) (*deploy.BuildResponse, error) {
+		return &deploy.BuildResponse{Image: "web:bbb"}, nil
+	})
+	resp, err := q.wait(context.Background(), same, nil)
+	if err != nil || resp.Image != "web:bbb" || resp.QueuePosition != 1 {
+		t.Errorf("joined build = %+v, %v; want web:bbb from position 1", resp, err)
+	}
+}
+
+func TestBuildQueue_CancelQueued(t *testing.T) {
+	q := newBuildQueue(1)
+	q.add(buildReq("other", "c1", "x"))
+	tk := q.add(buildReq("web", "c1", "aaa"))
+
+	if !q.cancel("web") {
+		t.Fatal("cancel found no queued build")
+	}
+	if _, err := q.wait(context.Background(), tk, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled build err = %v, want context.Canceled", err)
+	}
+	if q.cancel("web") {
+		t.Error("cancelled build is still queued")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tk = q.add(buildReq("api", "c2", "x"))
+	cancel()
+	if _, err := q.wait(ctx, tk, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait err = %v, want context.Canceled", err)
+	}
+	if s := q.status(); len(s.Queued) != 0 {
+		t.Errorf("queued after the caller left = %+v", s.Queued)
+	}
+}
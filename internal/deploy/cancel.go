@@ -0,0 +1,83 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// Cancel stops whatever is in flight for a service: its queued or running
+// deployment, a queued or running build on this node, or both. Cleanup of partial containers and
+// workspaces happens where the work was running.
+func (d *Deployer) Cancel(ctx context.Context, req *deploy.CancelRequest) (*deploy.CancelResponse, error) {
+	if _, err := shared.UserFromContext(ctx); err != nil {
+		return nil, fmt.Errorf("unauthenticated cancel attempt")
+	}
+
+	resp := &deploy.CancelResponse{Name: req.Name}
+	queued := d.queue.cancel(coreutils.FormatName(req.Name))
+	resp.Build = d.cancelBuild(coreutils.FormatName(req.Name)) || queued
+
+	dep, err := d.store.GetDeploymentByName(ctx, req.Name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if dep != nil && (dep.Status == store.StatusPending || dep.Status == store.StatusInProgress) {
+		if d.job.Cancel(dep.ID) {
+			resp.DeploymentID = dep.ID
+		}
+	}
+
+	if !resp.Build && resp.DeploymentID == "" {
+		return nil, fmt.Errorf("%w for %s", deploy.ErrNothingToCancel, req.Name)
+	}
+
+	d.logger.Info("cancelled service work", "service", req.Name, "deployment_id", resp.DeploymentID, "build", resp.Build)
+	return resp, nil
+}
+
+// buildRun is the cancel handle of one in-flight build.
+type buildRun struct {
+	cancel context.CancelFunc
+}
+
+// trackBuild derives a cancellable context for a build of svcName. The
+// returned func must be called when the build ends.
+func (d *Deployer) trackBuild(ctx context.Context, svcName string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	run := &buildRun{cancel: cancel}
+
+	d.buildsMu.Lock()
+	d.builds[svcName] = run
+	d.buildsMu.Unlock()
+
+	return ctx, func() {
+		d.buildsMu.Lock()
+		// A newer build of the same service may have replaced this one.
+		if d.builds[svcName] == run {
+			delete(d.builds, svcName)
+		}
+		d.buildsMu.Unlock()
+		cancel()
+	}
+}
+
+func (d *Deployer) cancelBuild(svcName string) bool {
+	d.buildsMu.Lock()
+	defer d.buildsMu.Unlock()
+
+	run, ok := d.builds[svcName]
+	if ok {
+		run.cancel()
+	}
+	return ok
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
 
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
 	"github.com/dployr-io/dployr/pkg/store"
 )
 
@@ -18,17 +20,22 @@ import (
 // typed SDK structs — no shell invocations, fully testable.
 type ContainerConfig struct {
 	Name        string
+	Service     string // owning service, recorded as a label for log collection
+	RunOf       string // service a one-off run belongs to; set instead of Service
 	Image       string
 	Port        int      // container port; 0 skips port binding
 	HostPort    int      // host port; 0 skips port binding
 	Env         []string // KEY=value entries passed inline to the container
 	Description string
 	Type        store.ServiceType
-	RunCmd      string // optional CMD override
-	Memory      int    // MB; 0 = no limit
-	CPU         int    // millicores; 0 = no limit
-	Storage     int    // GB; 0 = no limit
-	ClusterID   string // when set, container is placed in the cluster's cgroup slice
+	RunCmd      string   // optional CMD override
+	Cmd         []string // one-off command; replaces the image CMD, keeping its entrypoint
+	WorkDir     string   // working directory inside the container; empty keeps the image's
+	Memory      int      // MB; 0 = no limit
+	CPU         int      // millicores; 0 = no limit
+	Storage     int      // GB; 0 = no limit
+	Pids        int      // max processes; 0 = no limit
+	ClusterID   string   // when set, container is placed in the cluster's cgroup slice
 }
 
 // ContainerCfg returns the container.Config for docker ContainerCreate.
@@ -42,6 +49,16 @@ func (c *ContainerConfig) ContainerCfg() container.Config {
 	if c.Description != "" {
 		cfg.Labels["description"] = c.Description
 	}
+	if c.Service != "" {
+		cfg.Labels[coreutils.ServiceLabel] = c.Service
+	}
+	if c.RunOf != "" {
+		cfg.Labels[coreutils.RunLabel] = c.RunOf
+	}
+
+	if c.WorkDir != "" {
+		cfg.WorkingDir = c.WorkDir
+	}
 
 	if c.Port > 0 {
 		cfg.ExposedPorts = nat.PortSet{
@@ -49,7 +66,9 @@ func (c *ContainerConfig) ContainerCfg() container.Config {
 		}
 	}
 
-	if c.RunCmd != "" {
+	if len(c.Cmd) > 0 {
+		cfg.Cmd = c.Cmd
+	} else if c.RunCmd != "" {
 		cfg.Entrypoint = []string{"/bin/sh"}
 		cfg.Cmd = []string{"-c", c.RunCmd}
 	}
@@ -62,6 +81,10 @@ func (c *ContainerConfig) HostCfg() container.HostConfig {
 	hc := container.HostConfig{
 		RestartPolicy: container.RestartPolicy{Name: "unless-stopped"},
 	}
+	if c.RunOf != "" {
+		// A one-off run exits once and is removed by whoever started it.
+		hc.RestartPolicy = container.RestartPolicy{Name: container.RestartPolicyDisabled}
+	}
 
 	if c.Port > 0 && c.HostPort > 0 {
 		hc.PortBindings = nat.PortMap{
@@ -71,15 +94,7 @@ func (c *ContainerConfig) HostCfg() container.HostConfig {
 		}
 	}
 
-	if c.Memory > 0 {
-		memBytes := int64(c.Memory) * 1024 * 1024
-		hc.Resources.Memory = memBytes
-		hc.Resources.MemorySwap = memBytes
-	}
-	if c.CPU > 0 {
-		// CPUQuota µs per 100ms period: 500 millicores → 50000 µs
-		hc.Resources.CPUQuota = int64(c.CPU) * 100
-	}
+	hc.Resources = c.resources()
 	if c.Storage > 0 {
 		hc.StorageOpt = map[string]string{"size": fmt.Sprintf("%dg", c.Storage)}
 	}
@@ -90,6 +105,59 @@ func (c *ContainerConfig) HostCfg() container.HostConfig {
 	return hc
 }
 
+// resources returns the runtime limits shared by HostCfg and live updates.
+func (c *ContainerConfig) resources() container.Resources {
+	var r container.Resources
+	if c.Memory > 0 {
+		memBytes := int64(c.Memory) * 1024 * 1024
+		r.Memory = memBytes
+		r.MemorySwap = memBytes
+	}
+	if c.CPU > 0 {
+		// CPUQuota µs per 100ms period: 500 millicores → 50000 µs
+		r.CPUQuota = int64(c.CPU) * 100
+	}
+	if c.Pids > 0 {
+		pids := int64(c.Pids)
+		r.PidsLimit = &pids
+	}
+	return r
+}
+
+// UpdateCfg returns the container.UpdateConfig that applies c's limits to a
+// running container. Storage cannot be changed this way.
+func (c *ContainerConfig) UpdateCfg() container.UpdateConfig {
+	return container.UpdateConfig{Resources: c.resources()}
+}
+
+// setResources fills in the container limits: the blueprint's own request
+// where set, the node-wide default otherwise.
+func (c *ContainerConfig) setResources(bp store.Blueprint, cfg *shared.Config) {
+	r := effectiveResources(bp.Resources, cfg)
+	c.Memory = r.MemoryMB
+	c.CPU = r.CPU
+	c.Pids = r.Pids
+	c.Storage = r.StorageGB
+}
+
+// effectiveResources overlays a service's requested limits on the node
+// defaults from config.
+func effectiveResources(r store.Resources, cfg *shared.Config) store.Resources {
+	if cfg == nil {
+		return r
+	}
+	if r.MemoryMB == 0 {
+		r.MemoryMB = cfg.ContainerMemory
+	}
+	if r.CPU == 0 {
+		r.CPU = cfg.ContainerCPU
+	}
+	if r.StorageGB == 0 {
+		r.StorageGB = cfg.ContainerStorage
+	}
+	return r
+}
+
 // resolveStaticDir returns the absolute host path for the static content directory.
 // Relative paths are joined with workDir; absolute paths are returned unchanged.
 // Empty staticDir returns workDir itself.
@@ -7,6 +7,8 @@ import (
 	"slices"
 	"testing"
 
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
 	"github.com/dployr-io/dployr/pkg/store"
 )
 
@@ -91,6 +93,32 @@ func TestContainerConfig_ResourceLimits(t *testing.T) {
 	}
 }
 
+func TestContainerConfig_PidsLimit(t *testing.T) {
+	cfg := &ContainerConfig{Name: "app", Image: "img", Type: store.TypeWeb, Pids: 128, Memory: 256}
+	hc := cfg.HostCfg()
+	if hc.Resources.PidsLimit == nil || *hc.Resources.PidsLimit != 128 {
+		t.Errorf("PidsLimit = %v, want 128", hc.Resources.PidsLimit)
+	}
+
+	uc := cfg.UpdateCfg()
+	if uc.Resources.Memory != hc.Resources.Memory || uc.Resources.PidsLimit == nil {
+		t.Errorf("UpdateCfg resources = %+v, want the same limits as HostCfg", uc.Resources)
+	}
+}
+
+func TestContainerConfig_SetResourcesOverridesNodeDefaults(t *testing.T) {
+	node := &shared.Config{ContainerMemory: 1024, ContainerCPU: 1000, ContainerStorage: 20}
+	cc := &ContainerConfig{}
+	cc.setResources(store.Blueprint{Resources: store.Resources{MemoryMB: 256, Pids: 64}}, node)
+
+	if cc.Memory != 256 || cc.Pids != 64 {
+		t.Errorf("requested limits not applied: memory=%d pids=%d", cc.Memory, cc.Pids)
+	}
+	if cc.CPU != 1000 || cc.Storage != 20 {
+		t.Errorf("node defaults not used for unset limits: cpu=%d storage=%d", cc.CPU, cc.Storage)
+	}
+}
+
 func TestContainerConfig_NoResourceFlagsWhenZero(t *testing.T) {
 	cfg := &ContainerConfig{Name: "app", Image: "img", Type: store.TypeWeb}
 	hc := cfg.HostCfg()
@@ -125,6 +153,13 @@ func TestContainerConfig_NoLabelWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestContainerConfig_ServiceLabel(t *testing.T) {
+	cfg := replicaConfig(store.Blueprint{Image: "img", Type: store.TypeWeb}, "app", 2, 3000, nil, nil)
+	if got := cfg.ContainerCfg().Labels[coreutils.ServiceLabel]; got != "app" {
+		t.Errorf("Labels[%s] = %q, want app", coreutils.ServiceLabel, got)
+	}
+}
+
 func TestResolveStaticDir_Empty(t *testing.T) {
 	if got := ResolveStaticDir("/workdir", ""); got != "/workdir" {
 		t.Errorf("got %q, want /workdir", got)
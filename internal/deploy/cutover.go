@@ -0,0 +1,171 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+var (
+	cutoverProbeTimeout  = 2 * time.Minute
+	cutoverProbeInterval = 2 * time.Second
+)
+
+// probeHealth reports whether the container bound to hostPort answers path.
+// Mirrors the WatchDog rule: any response below 500 counts as healthy.
+var probeHealth = func(ctx context.Context, hostPort int, path string) bool {
+	url := fmt.Sprintf("http://localhost:%d%s", hostPort, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+// Cutover carries what a blue/green redeploy needs from the caller.
+type Cutover struct {
+	// PrevHostPort is the host port the live container is bound to.
+	PrevHostPort int
+	// HostPort is the host port the replacement binds. When zero it is the
+	// hashed slot AlternateHostPort picks opposite PrevHostPort.
+	HostPort int
+	// Switch repoints the proxy at the replacement container. It runs only
+	// after the replacement has passed its health check.
+	Switch func(hostPort int) error
+}
+
+// CutoverApp replaces a running web container without downtime. The new
+// container starts as "<name>-next" on the other host port and is probed
+// on bp.HealthCheck; only once it answers is traffic switched and the old
+// container retired. If the probe or the switch fails the replacement is
+// removed and the old container keeps serving. Returns the host port now live.
+func CutoverApp(ctx context.Context, bp store.Blueprint, name, logPath string, cfg *shared.Config, dockerCli deployDockerAPI, c Cutover) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
+	defer cancel()
+
+	probePath, err := coreutils.NormaliseHealthPath(bp.HealthCheck)
+	if err != nil {
+		return 0, err
+	}
+
+	port := bp.Port
+	if port == 0 {
+		port = 3000
+	}
+
+	if err := WriteEnvFile(bp.WorkingDir, bp, port); err != nil {
+		return 0, fmt.Errorf("failed to write env file: %w", err)
+	}
+
+	if bp.Image != "" {
+		if err := PullImage(ctx, bp.Image, cfg, dockerCli); err != nil {
+			return 0, fmt.Errorf("failed to pull image: %w", err)
+		}
+	}
+
+	nextName := name + "-next"
+	hostPort := c.HostPort
+	if hostPort == 0 {
+		hostPort = coreutils.AlternateHostPort(name, c.PrevHostPort)
+	}
+
+	cc := &ContainerConfig{
+		Name:        nextName,
+		Service:     name,
+		Image:       bp.Image,
+		Port:        port,
+		HostPort:    hostPort,
+		Env:         buildEnv(bp, port),
+		Description: bp.Desc,
+		Type:        bp.Type,
+		RunCmd:      bp.RunCmd,
+		ClusterID:   bp.ClusterID,
+	}
+	cc.setResources(bp, cfg)
+
+	// Leftover from an interrupted cutover (best-effort).
+	dockerCli.ContainerRemove(ctx, nextName, container.RemoveOptions{Force: true}) //nolint:errcheck
+
+	resp, err := dockerCli.ContainerCreate(ctx, ptr(cc.ContainerCfg()), ptr(cc.HostCfg()), nil, nil, nextName)
+	if err != nil {
+		if ctx.Err() != nil {
+			dockerCli.ContainerRemove(context.Background(), nextName, container.RemoveOptions{Force: true}) //nolint:errcheck
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, fmt.Errorf("docker create timed out")
+		}
+		return 0, fmt.Errorf("docker create failed: %w", err)
+	}
+
+	discard := func() {
+		dockerCli.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true}) //nolint:errcheck
+	}
+
+	if err := dockerCli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		discard()
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, fmt.Errorf("docker start timed out")
+		}
+		return 0, fmt.Errorf("docker start failed: %w", err)
+	}
+
+	shared.LogInfoF(name, logPath, fmt.Sprintf("candidate container started on host port %d, probing %s", hostPort, probePath))
+	if !waitHealthy(ctx, hostPort, probePath) {
+		discard()
+		return 0, fmt.Errorf("new container failed health check on %s after %s; previous version left serving", probePath, cutoverProbeTimeout)
+	}
+
+	shared.LogInfoF(name, logPath, "health check passed, switching traffic")
+	if c.Switch != nil {
+		if err := c.Switch(hostPort); err != nil {
+			discard()
+			return 0, fmt.Errorf("failed to switch traffic: %w", err)
+		}
+	}
+
+	if err := dockerCli.ContainerRemove(ctx, name, container.RemoveOptions{Force: true}); err != nil {
+		shared.LogWarnF(name, logPath, fmt.Sprintf("failed to retire previous container: %s", err))
+	}
+	if err := dockerCli.ContainerRename(ctx, resp.ID, name); err != nil {
+		shared.LogWarnF(name, logPath, fmt.Sprintf("failed to rename %s to %s: %s", nextName, name, err))
+	}
+	// A service scaled down to one container keeps no extra replicas.
+	removeReplicas(ctx, name, logPath, 1, store.MaxReplicas, dockerCli)
+
+	shared.LogInfoF(name, logPath, fmt.Sprintf("cutover complete: %s now serving on host port %d", resp.ID, hostPort))
+	return hostPort, nil
+}
+
+// waitHealthy polls the candidate until it answers or cutoverProbeTimeout elapses.
+func waitHealthy(ctx context.Context, hostPort int, path string) bool {
+	deadline := time.Now().Add(cutoverProbeTimeout)
+	for {
+		if probeHealth(ctx, hostPort, path) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(cutoverProbeInterval):
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// fakeDocker records container lifecycle calls made through deployDockerAPI.
+type fakeDocker struct {
+	mu      sync.Mutex
+	created []string
+	removed []string
+	renamed map[string]string
+	ports   map[string]string // container name → bound host port
+	updated map[string]container.UpdateConfig
+}
+
+func newFakeDocker() *fakeDocker {
+	return &fakeDocker{renamed: map[string]string{}, ports: map[string]string{}, updated: map[string]container.UpdateConfig{}}
+}
+
+func (f *fakeDocker) ContainerCreate(_ context.Context, _ *container.Config, hc *container.HostConfig, _ *network.NetworkingConfig, _ *specs.Platform, name string) (container.CreateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, name)
+	for _, b := range hc.PortBindings {
+		if len(b) > 0 {
+			f.ports[name] = b[0].HostPort
+		}
+	}
+	return container.CreateResponse{ID: "id-" + name}, nil
+}
+
+func (f *fakeDocker) ContainerStart(context.Context, string, container.StartOptions) error {
+	return nil
+}
+
+func (f *fakeDocker) ContainerRemove(_ context.Context, id string, _ container.RemoveOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removed = append(f.removed, id)
+	return nil
+}
+
+func (f *fakeDocker) ContainerRename(_ context.Context, id, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.renamed[id] = name
+	return nil
+}
+
+func (f *fakeDocker) ContainerWait(context.Context, string, container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	res := make(chan container.WaitResponse, 1)
+	res <- container.WaitResponse{}
+	return res, make(chan error)
+}
+
+func (f *fakeDocker) ContainerLogs(context.Context, string, container.LogsOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (f *fakeDocker) CopyToContainer(context.Context, string, string, io.Reader, container.CopyToContainerOptions) error {
+	return nil
+}
+
+func (f *fakeDocker) CopyFromContainer(context.Context, string, string) (io.ReadCloser, container.PathStat, error) {
+	return nil, container.PathStat{}, errors.New("not implemented")
+}
+
+func (f *fakeDocker) ContainerUpdate(_ context.Context, id string, uc container.UpdateConfig) (container.ContainerUpdateOKBody, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updated[id] = uc
+	return container.ContainerUpdateOKBody{}, nil
+}
+
+func (f *fakeDocker) ImagePull(context.Context, string, image.PullOptions) (io.ReadCloser, error) {
+	return io.NopCloser(nil), nil
+}
+
+func (f *fakeDocker) ImageBuild(context.Context, io.Reader, dockertypes.ImageBuildOptions) (dockertypes.ImageBuildResponse, error) {
+	return dockertypes.ImageBuildResponse{}, nil
+}
+
+func (f *fakeDocker) ImagePush(context.Context, string, image.PushOptions) (io.ReadCloser, error) {
+	return io.NopCloser(nil), nil
+}
+
+func (f *fakeDocker) ImageRemove(context.Context, string, image.RemoveOptions) ([]image.DeleteResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeDocker) ImageList(context.Context, image.ListOptions) ([]image.Summary, error) {
+	return nil, nil
+}
+
+func (f *fakeDocker) DistributionInspect(context.Context, string, string) (registry.DistributionInspect, error) {
+	return registry.DistributionInspect{}, nil
+}
+
+// stubProbe swaps probeHealth for the duration of a test.
+func stubProbe(t *testing.T, healthy bool) *[]string {
+	t.Helper()
+	var paths []string
+	origProbe, origTimeout, origInterval := probeHealth, cutoverProbeTimeout, cutoverProbeInterval
+	probeHealth = func(_ context.Context, _ int, path string) bool {
+		paths = append(paths, path)
+		return healthy
+	}
+	cutoverProbeTimeout = 10 * time.Millisecond
+	cutoverProbeInterval = time.Millisecond
+	t.Cleanup(func() {
+		probeHealth, cutoverProbeTimeout, cutoverProbeInterval = origProbe, origTimeout, origInterval
+	})
+	return &paths
+}
+
+func cutoverBlueprint(t *testing.T) store.Blueprint {
+	return store.Blueprint{
+		Name:        "my-app",
+		Type:        store.TypeWeb,
+		Port:        8080,
+		WorkingDir:  t.TempDir(),
+		HealthCheck: "healthz/",
+	}
+}
+
+func TestCutoverApp_SwitchesAfterHealthyProbe(t *testing.T) {
+	paths := stubProbe(t, true)
+	docker := newFakeDocker()
+	prev := coreutils.ComputeHostPort("my-app")
+
+	var switchedTo int
+	port, err := CutoverApp(context.Background(), cutoverBlueprint(t), "my-app", t.TempDir()+"/", nil, docker, Cutover{
+		PrevHostPort: prev,
+		Switch:       func(p int) error { switchedTo = p; return nil },
+	})
+	if err != nil {
+		t.Fatalf("CutoverApp() error: %v", err)
+	}
+
+	if port == prev {
+		t.Errorf("new host port = %d, must differ from live port", port)
+	}
+	if switchedTo != port {
+		t.Errorf("switched to %d, want %d", switchedTo, port)
+	}
+	if len(*paths) == 0 || (*paths)[0] != "/healthz" {
+		t.Errorf("probe paths = %v, want normalised /healthz", *paths)
+	}
+	if !slices.Contains(docker.removed, "my-app") {
+		t.Errorf("old container not retired, removed = %v", docker.removed)
+	}
+	if docker.renamed["id-my-app-next"] != "my-app" {
+		t.Errorf("candidate not renamed to canonical name, renamed = %v", docker.renamed)
+	}
+}
+
+func TestCutoverApp_FailedProbeKeepsOldContainer(t *testing.T) {
+	stubProbe(t, false)
+	docker := newFakeDocker()
+
+	switched := false
+	_, err := CutoverApp(context.Background(), cutoverBlueprint(t), "my-app", t.TempDir()+"/", nil, docker, Cutover{
+		PrevHostPort: coreutils.ComputeHostPort("my-app"),
+		Switch:       func(int) error { switched = true; return nil },
+	})
+	if err == nil {
+		t.Fatal("expected error when candidate fails its health check")
+	}
+
+	if switched {
+		t.Error("traffic must not be switched to an unhealthy container")
+	}
+	if slices.Contains(docker.removed, "my-app") {
+		t.Error("old container must keep serving after a failed cutover")
+	}
+	if !slices.Contains(docker.removed, "id-my-app-next") {
+		t.Errorf("unhealthy candidate not cleaned up, removed = %v", docker.removed)
+	}
+	if len(docker.renamed) != 0 {
+		t.Errorf("no rename expected, got %v", docker.renamed)
+	}
+}
+
+func TestCutoverApp_AlternatesBackToPrimaryPort(t *testing.T) {
+	stubProbe(t, true)
+	docker := newFakeDocker()
+	primary := coreutils.ComputeHostPort("my-app")
+	secondary := coreutils.AlternateHostPort("my-app", primary)
+
+	port, err := CutoverApp(context.Background(), cutoverBlueprint(t), "my-app", t.TempDir()+"/", nil, docker, Cutover{PrevHostPort: secondary})
+	if err != nil {
+		t.Fatalf("CutoverApp() error: %v", err)
+	}
+	if port != primary {
+		t.Errorf("host port = %d, want primary %d", port, primary)
+	}
+}
@@ -5,12 +5,16 @@ package deploy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/dployr-io/dployr/internal/version_resolver"
 	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/core/system"
 	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
 	"github.com/dployr-io/dployr/pkg/shared"
 	"github.com/dployr-io/dployr/pkg/store"
@@ -19,7 +23,12 @@ import (
 )
 
 type Dispatcher interface {
-	Submit(id string)
+	// Submit queues a deployment. It must not block; a saturated queue is
+	// reported as deploy.ErrQueueFull.
+	Submit(id string) error
+	// Cancel stops a queued or running deployment, reporting whether there
+	// was one to stop.
+	Cancel(id string) bool
 }
 
 type Deployer struct {
@@ -29,6 +38,11 @@ type Deployer struct {
 	job       Dispatcher
 	dockerCli deployDockerAPI
 	resolver  *version_resolver.Resolver
+	buildsMu  sync.Mutex
+	builds    map[string]*buildRun // service name → in-flight build
+	queue     *buildQueue          // builds waiting for a slot
+	keysMu    sync.Mutex           // serialises deploy key generation
+	slice     SliceLimitsFunc      // nil = cluster limits not checked
 }
 
 // Init creates a new Deployer instance. dockerCli must satisfy deployDockerAPI
@@ -38,12 +52,20 @@ func Init(c *shared.Config, l *shared.Logger, s store.DeploymentStore, j Dispatc
 		cfg:       c,
 		logger:    l,
 		store:     s,
+		builds:    make(map[string]*buildRun),
+		queue:     newBuildQueue(c.BuildSlots),
 		job:       j,
 		dockerCli: dockerCli,
 		resolver:  version_resolver.New(version_resolver.NewHTTPClient()),
 	}
 }
 
+// SetSliceLimits sets how the deployer looks up cluster slice limits when
+// validating requested container resources.
+func (d *Deployer) SetSliceLimits(fn SliceLimitsFunc) {
+	d.slice = fn
+}
+
 func (d *Deployer) Deploy(ctx context.Context, req *deploy.DeployRequest) (*deploy.DeployResponse, error) {
 	requestID, err := shared.TraceFromContext(ctx)
 	if err != nil {
@@ -70,6 +92,21 @@ func (d *Deployer) Deploy(ctx context.Context, req *deploy.DeployRequest) (*depl
 		userID = req.UserId
 	}
 
+	domains, err := resolveDomains(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateReplicas(store.ServiceType(req.Type), req.Replicas); err != nil {
+		return nil, err
+	}
+	if err := ValidateResources(req.Resources, req.Replicas, req.ClusterId, d.slice); err != nil {
+		return nil, err
+	}
+	concurrency, err := ValidateSchedule(store.ServiceType(req.Type), req.Schedule, req.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
 	deployment := &store.Deployment{
 		ID:     ulid.Make().String(),
 		Status: store.StatusPending,
@@ -91,6 +128,12 @@ func (d *Deployer) Deploy(ctx context.Context, req *deploy.DeployRequest) (*depl
 			Source:      store.Source(req.Source),
 			HealthCheck: req.HealthCheck,
 			ClusterID:   req.ClusterId,
+			Domains:     domains,
+			Replicas:    req.Replicas,
+			Resources:   req.Resources,
+			Schedule:    req.Schedule,
+			Concurrency: concurrency,
+			AutoDeploy:  req.AutoDeploy,
 		},
 		UserId:    &userID,
 		CreatedAt: time.Now(),
@@ -104,11 +147,10 @@ func (d *Deployer) Deploy(ctx context.Context, req *deploy.DeployRequest) (*depl
 		return nil, fmt.Errorf("node role %q cannot accept source=remote deployments; expected source=image", d.cfg.Role)
 	}
 
-	// Guard: TypeStatic + source=image has no mechanism to extract files to disk.
-	// Static sites must be deployed from source (source=remote) so the files are
-	// cloned to the working directory that Caddy serves directly.
-	if store.ServiceType(req.Type) == store.TypeStatic && store.Source(req.Source) == store.SourceImage {
-		return nil, fmt.Errorf("static sites must use source=remote; source=image is not supported for TypeStatic")
+	// Guard: a static site from an image is the one directory copied out of it,
+	// so it has to be named.
+	if store.ServiceType(req.Type) == store.TypeStatic && store.Source(req.Source) == store.SourceImage && req.StaticDir == "" {
+		return nil, fmt.Errorf("static sites with source=image must set static_dir to the directory in the image to serve")
 	}
 
 	if err := d.store.UpsertDeployment(ctx, deployment); err != nil {
@@ -123,7 +165,10 @@ func (d *Deployer) Deploy(ctx context.Context, req *deploy.DeployRequest) (*depl
 		"trace_id", traceID,
 	).Info("upserted deployment", "deployment_id", deployment.ID)
 
-	d.job.Submit(deployment.ID)
+	if err := d.submit(ctx, deployment.ID); err != nil {
+		d.logger.With("request_id", requestID, "trace_id", traceID).Error("failed to queue deployment", "deployment_id", deployment.ID, "error", err)
+		return nil, err
+	}
 
 	return &deploy.DeployResponse{
 		ID:        deployment.ID,
@@ -133,10 +178,38 @@ func (d *Deployer) Deploy(ctx context.Context, req *deploy.DeployRequest) (*depl
 	}, nil
 }
 
+// Build waits for one of the node's BUILD_SLOTS and then builds and pushes
+// the image. A request for a service whose build is still queued joins that
+// build when it is for the same commit and replaces it otherwise.
 func (d *Deployer) Build(ctx context.Context, req *deploy.BuildRequest) (*deploy.BuildResponse, error) {
 	logDir := filepath.Join(coreutils.GetDataDir(), ".dployr", "logs")
 	svcName := coreutils.FormatName(req.Name)
 
+	t := d.queue.add(req)
+	if t.position > 0 {
+		shared.LogInfoF(svcName, logDir, fmt.Sprintf("waiting for a build slot, position %d in queue", t.position))
+	}
+	resp, err := d.queue.wait(ctx, t, func(ctx context.Context) (*deploy.BuildResponse, error) {
+		return d.build(ctx, req)
+	})
+	if errors.Is(err, deploy.ErrBuildSuperseded) {
+		shared.LogWarnF(svcName, logDir, "queued build superseded by a newer commit")
+	}
+	return resp, err
+}
+
+// BuildQueue reports the build slots and the builds waiting for one.
+func (d *Deployer) BuildQueue() *system.BuildsDiag {
+	return d.queue.status()
+}
+
+func (d *Deployer) build(ctx context.Context, req *deploy.BuildRequest) (*deploy.BuildResponse, error) {
+	logDir := filepath.Join(coreutils.GetDataDir(), ".dployr", "logs")
+	svcName := coreutils.FormatName(req.Name)
+
+	ctx, untrack := d.trackBuild(ctx, svcName)
+	defer untrack()
+
 	shared.LogInfoF(svcName, logDir, "starting build")
 
 	workDir, err := SetupDir(req.Name)
@@ -144,9 +217,16 @@ func (d *Deployer) Build(ctx context.Context, req *deploy.BuildRequest) (*deploy
 		shared.LogErrF(svcName, logDir, err)
 		return nil, fmt.Errorf("failed to setup working directory: %w", err)
 	}
+	// The image is all a build leaves behind; its workspace goes either way.
+	defer func() {
+		if ctx.Err() == context.Canceled {
+			shared.LogWarnF(svcName, logDir, "build cancelled, removing workspace")
+		}
+		os.RemoveAll(workDir) //nolint:errcheck
+	}()
 
 	shared.LogInfoF(svcName, logDir, "cloning repository")
-	if err := CloneRepo(req.Remote, workDir, d.cfg); err != nil {
+	if err := CloneRepo(ctx, req.Name, req.Remote, workDir, d.cfg); err != nil {
 		shared.LogErrF(svcName, logDir, fmt.Errorf("clone failed: %w", err))
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
@@ -184,7 +264,7 @@ func (d *Deployer) Build(ctx context.Context, req *deploy.BuildRequest) (*deploy
 	}
 
 	shared.LogInfoF(svcName, logDir, "building image")
-	image, err := BuildImage(req.Name, buildDir, d.cfg, BuildOpts{
+	image, cached, err := BuildImage(ctx, req.Name, buildDir, d.cfg, BuildOpts{
 		Runtime:      req.Runtime,
 		Version:      resolution.Version,
 		BuilderImage: resolution.BuilderImage,
@@ -194,14 +274,19 @@ func (d *Deployer) Build(ctx context.Context, req *deploy.BuildRequest) (*deploy
 		Port:         req.Port,
 		IsNextJS:     req.Runtime == "nodejs" && detectNextJS(buildDir),
 		Env:          env,
+		ForceRebuild: req.ForceRebuild,
 	}, d.dockerCli, svcName, logDir)
 	if err != nil {
 		shared.LogErrF(svcName, logDir, fmt.Errorf("build failed: %w", err))
 		return nil, fmt.Errorf("build failed: %w", err)
 	}
 
-	shared.LogInfoF(svcName, logDir, "image pushed, build complete")
-	return &deploy.BuildResponse{Image: image}, nil
+	if cached {
+		shared.LogInfoF(svcName, logDir, fmt.Sprintf("%s is already in the registry, skipped build", image))
+	} else {
+		shared.LogInfoF(svcName, logDir, "image pushed, build complete")
+	}
+	return &deploy.BuildResponse{Image: image, Cached: cached}, nil
 }
 
 func (d *Deployer) Publish(ctx context.Context, req *deploy.PublishRequest) (*deploy.DeployResponse, error) {
@@ -211,6 +296,19 @@ func (d *Deployer) Publish(ctx context.Context, req *deploy.PublishRequest) (*de
 	return d.Deploy(ctx, &deployReq)
 }
 
+// submit hands a stored deployment to the worker. A deployment the worker
+// refused is marked failed so it does not sit in pending forever.
+func (d *Deployer) submit(ctx context.Context, id string) error {
+	err := d.job.Submit(id)
+	if err == nil {
+		return nil
+	}
+	if uerr := d.store.UpdateDeploymentStatus(ctx, id, string(store.StatusFailed)); uerr != nil {
+		d.logger.Warn("failed to mark unqueued deployment as failed", "deployment_id", id, "error", uerr)
+	}
+	return fmt.Errorf("failed to queue deployment: %w", err)
+}
+
 func (d *Deployer) GetDeployment(ctx context.Context, id string) (*store.Deployment, error) {
 	return d.store.GetDeployment(ctx, id)
 }
@@ -234,7 +332,7 @@ func (d *Deployer) UpdateDeploymentStatus(ctx context.Context, id string, status
 		return fmt.Errorf("%s", msg)
 	}
 
-	if status == store.StatusCompleted || status == store.StatusFailed {
+	if status == store.StatusCompleted || status == store.StatusFailed || status == store.StatusCancelled {
 		d.logger.With("request_id", requestID).Error("rejected attempt to set terminal status via API", "status", status)
 		return fmt.Errorf("terminal status %q can only be set by the worker, not via the API", status)
 	}
@@ -5,6 +5,8 @@ package deploy
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"maps"
 	"sync"
 	"testing"
@@ -18,6 +20,7 @@ import (
 type mockDeployStore struct {
 	mu          sync.Mutex
 	deployments map[string]*store.Deployment
+	releases    []*store.Release // newest first, as ListReleases returns them
 }
 
 func (m *mockDeployStore) UpsertDeployment(_ context.Context, d *store.Deployment) error {
@@ -33,14 +36,87 @@ func (m *mockDeployStore) GetDeployment(_ context.Context, id string) (*store.De
 	return m.deployments[id], nil
 }
 
+func (m *mockDeployStore) GetDeploymentByName(_ context.Context, name string) (*store.Deployment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.deployments {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
 func (m *mockDeployStore) ListDeployments(_ context.Context, _, _ int) ([]*store.Deployment, error) {
 	return nil, nil
 }
 
-func (m *mockDeployStore) UpdateDeploymentStatus(_ context.Context, _, _ string) error {
+func (m *mockDeployStore) UpdateDeploymentStatus(_ context.Context, id, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deployments[id]; ok {
+		d.Status = store.Status(status)
+	}
+	return nil
+}
+
+func (m *mockDeployStore) UpdateDeploymentReplicas(_ context.Context, id string, replicas int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deployments[id]; ok {
+		d.Blueprint.Replicas = replicas
+	}
+	return nil
+}
+
+func (m *mockDeployStore) UpdateDeploymentResources(_ context.Context, id string, r store.Resources) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deployments[id]; ok {
+		d.Blueprint.Resources = r
+	}
 	return nil
 }
 
+func (m *mockDeployStore) RecordRelease(_ context.Context, r *store.Release) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.Version = len(m.releases) + 1
+	m.releases = append([]*store.Release{r}, m.releases...)
+	return nil
+}
+
+func (m *mockDeployStore) UpdateReleaseStatus(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m *mockDeployStore) ListReleases(_ context.Context, name string, _ int) ([]*store.Release, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*store.Release
+	for _, r := range m.releases {
+		if r.Name == name {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockDeployStore) EnqueueDeployment(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockDeployStore) DequeueDeployment(_ context.Context, _ string) error { return nil }
+func (m *mockDeployStore) ListQueuedDeployments(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+func (m *mockDeployStore) FailInterruptedDeployments(_ context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockDeployStore) OpenSecrets(secrets map[string]string) (map[string]string, error) {
+	return secrets, nil
+}
+
 func (m *mockDeployStore) snapshot() map[string]*store.Deployment {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -52,12 +128,25 @@ func (m *mockDeployStore) snapshot() map[string]*store.Deployment {
 type mockDisp struct {
 	mu        sync.Mutex
 	submitted []string
+	cancelled []string
+	err       error // returned by Submit when set
 }
 
-func (m *mockDisp) Submit(id string) {
+func (m *mockDisp) Submit(id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
 	m.submitted = append(m.submitted, id)
+	return nil
+}
+
+func (m *mockDisp) Cancel(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cancelled = append(m.cancelled, id)
+	return true
 }
 
 func (m *mockDisp) count() int {
@@ -201,3 +290,347 @@ func TestPublish_ForcesSrcImageAndQueues(t *testing.T) {
 		}
 	}
 }
+
+func seedReleases(ds *mockDeployStore, releases ...*store.Release) {
+	for _, r := range releases {
+		r.Name = "my-app"
+		r.Blueprint = store.Blueprint{Name: "my-app", Type: store.TypeWeb, Source: store.SourceRemote, Image: r.Image}
+		ds.RecordRelease(context.Background(), r)
+	}
+}
+
+// Rollback without a selector must redeploy the newest completed release whose
+// image differs from the live one, as source=image so nothing is rebuilt.
+func TestRollback_DefaultsToPreviousImage(t *testing.T) {
+	d, ds, disp := newDeployer(store.NodeRoleInstance)
+	seedReleases(ds,
+		&store.Release{ID: "r1", Image: "reg/my-app:1", Status: store.StatusCompleted},
+		&store.Release{ID: "r2", Image: "reg/my-app:2", Status: store.StatusCompleted},
+		&store.Release{ID: "r3", Image: "reg/my-app:3", Status: store.StatusFailed},
+	)
+
+	resp, err := d.Rollback(newDeployCtx(), &coredeploy.RollbackRequest{Name: "my-app"})
+	if err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	if resp.Release.ID != "r1" {
+		t.Errorf("rolled back to %q, want r1", resp.Release.ID)
+	}
+	if disp.count() != 1 {
+		t.Errorf("expected 1 dispatcher submission, got %d", disp.count())
+	}
+	for _, dep := range ds.snapshot() {
+		if dep.Blueprint.Source != store.SourceImage || dep.Blueprint.Image != "reg/my-app:1" {
+			t.Errorf("deployment source/image = %q/%q, want image/reg/my-app:1", dep.Blueprint.Source, dep.Blueprint.Image)
+		}
+	}
+}
+
+func TestRollback_ExplicitRelease(t *testing.T) {
+	d, ds, _ := newDeployer(store.NodeRoleInstance)
+	seedReleases(ds,
+		&store.Release{ID: "r1", Image: "reg/my-app:1", Status: store.StatusCompleted},
+		&store.Release{ID: "r2", Image: "reg/my-app:2", Status: store.StatusCompleted},
+		&store.Release{ID: "r3", Image: "reg/my-app:3", Status: store.StatusCompleted},
+	)
+
+	for _, sel := range []string{"2", "r2"} {
+		resp, err := d.Rollback(newDeployCtx(), &coredeploy.RollbackRequest{Name: "my-app", Release: sel})
+		if err != nil {
+			t.Fatalf("Rollback(%q) failed: %v", sel, err)
+		}
+		if resp.Release.Image != "reg/my-app:2" {
+			t.Errorf("Rollback(%q) image = %q, want reg/my-app:2", sel, resp.Release.Image)
+		}
+	}
+}
+
+func TestRollback_NoTarget(t *testing.T) {
+	cases := []struct {
+		name     string
+		releases []*store.Release
+		selector string
+		want     error
+	}{
+		{name: "no history", want: coredeploy.ErrReleaseNotFound},
+		{name: "single release", releases: []*store.Release{{ID: "r1", Image: "reg/my-app:1", Status: store.StatusCompleted}}, want: coredeploy.ErrReleaseNotFound},
+		{name: "unknown selector", releases: []*store.Release{{ID: "r1", Image: "reg/my-app:1", Status: store.StatusCompleted}}, selector: "9", want: coredeploy.ErrReleaseNotFound},
+		{name: "release without image", releases: []*store.Release{{ID: "r1", Status: store.StatusCompleted}}, selector: "1", want: coredeploy.ErrReleaseNotBuilt},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, ds, disp := newDeployer(store.NodeRoleInstance)
+			seedReleases(ds, tc.releases...)
+
+			_, err := d.Rollback(newDeployCtx(), &coredeploy.RollbackRequest{Name: "my-app", Release: tc.selector})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Rollback() error = %v, want %v", err, tc.want)
+			}
+			if disp.count() != 0 {
+				t.Errorf("expected no dispatcher submissions, got %d", disp.count())
+			}
+		})
+	}
+}
+
+// A saturated worker queue must surface ErrQueueFull and not leave the
+// deployment stuck in pending.
+func TestDeploy_QueueFull(t *testing.T) {
+	d, ds, disp := newDeployer(store.NodeRoleInstance)
+	disp.err = coredeploy.ErrQueueFull
+
+	_, err := d.Deploy(newDeployCtx(), imageReq())
+	if !errors.Is(err, coredeploy.ErrQueueFull) {
+		t.Fatalf("Deploy() error = %v, want ErrQueueFull", err)
+	}
+
+	deps := ds.snapshot()
+	if len(deps) != 1 {
+		t.Fatalf("expected 1 stored deployment, got %d", len(deps))
+	}
+	for _, dep := range deps {
+		if dep.Status != store.StatusFailed {
+			t.Errorf("status = %q, want %q", dep.Status, store.StatusFailed)
+		}
+	}
+}
+
+// The legacy Domain field and Domains are merged into the blueprint, with
+// ACME as the default TLS mode.
+func TestDeploy_CarriesDomains(t *testing.T) {
+	d, ds, _ := newDeployer(store.NodeRoleInstance)
+	req := imageReq()
+	req.Domain = "App.Example.com"
+	req.Domains = []store.Domain{
+		{Host: "app.example.com"},
+		{Host: "app.corp.lan", TLS: store.TLSInternal},
+	}
+
+	if _, err := d.Deploy(newDeployCtx(), req); err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+
+	for _, dep := range ds.snapshot() {
+		want := []store.Domain{
+			{Host: "app.example.com", TLS: store.TLSAcme},
+			{Host: "app.corp.lan", TLS: store.TLSInternal},
+		}
+		if len(dep.Blueprint.Domains) != len(want) {
+			t.Fatalf("domains = %+v, want %+v", dep.Blueprint.Domains, want)
+		}
+		for i := range want {
+			if dep.Blueprint.Domains[i] != want[i] {
+				t.Errorf("domain %d = %+v, want %+v", i, dep.Blueprint.Domains[i], want[i])
+			}
+		}
+	}
+}
+
+func TestDeploy_InvalidDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain store.Domain
+	}{
+		{"scheme", store.Domain{Host: "https://app.example.com"}},
+		{"port", store.Domain{Host: "app.example.com:8080"}},
+		{"single label", store.Domain{Host: "localhost"}},
+		{"managed suffix", store.Domain{Host: "other.dployr.run"}},
+		{"unknown tls", store.Domain{Host: "app.example.com", TLS: "selfsigned"}},
+		{"custom without files", store.Domain{Host: "app.example.com", TLS: store.TLSCustom}},
+		{"relative cert", store.Domain{Host: "app.example.com", TLS: store.TLSCustom, CertFile: "app.crt", KeyFile: "/etc/app.key"}},
+		{"files without custom", store.Domain{Host: "app.example.com", CertFile: "/etc/app.crt", KeyFile: "/etc/app.key"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ds, disp := newDeployer(store.NodeRoleInstance)
+			req := imageReq()
+			req.Domains = []store.Domain{tt.domain}
+
+			_, err := d.Deploy(newDeployCtx(), req)
+			if !errors.Is(err, coredeploy.ErrInvalidDomain) {
+				t.Fatalf("Deploy() error = %v, want ErrInvalidDomain", err)
+			}
+			if len(ds.snapshot()) != 0 || disp.count() != 0 {
+				t.Error("invalid deployment was stored or queued")
+			}
+		})
+	}
+}
+
+func TestDeploy_Replicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		replicas int
+		wantErr  bool
+	}{
+		{"single", "web", 0, false},
+		{"web replicas", "web", 3, false},
+		{"worker replicas", "worker", 2, false},
+		{"at limit", "web", store.MaxReplicas, false},
+		{"over limit", "web", store.MaxReplicas + 1, true},
+		{"negative", "web", -1, true},
+		{"job", "job", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ds, _ := newDeployer(store.NodeRoleInstance)
+			req := imageReq()
+			req.Type = tt.typ
+			req.Replicas = tt.replicas
+
+			_, err := d.Deploy(newDeployCtx(), req)
+			if tt.wantErr {
+				if !errors.Is(err, coredeploy.ErrInvalidReplicas) {
+					t.Fatalf("Deploy() error = %v, want ErrInvalidReplicas", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Deploy() error = %v", err)
+			}
+			for _, dep := range ds.snapshot() {
+				if dep.Blueprint.Replicas != tt.replicas {
+					t.Errorf("blueprint replicas = %d, want %d", dep.Blueprint.Replicas, tt.replicas)
+				}
+			}
+		})
+	}
+}
+
+func TestDeploy_ResourcesExceedClusterSlice(t *testing.T) {
+	d, ds, disp := newDeployer(store.NodeRoleInstance)
+	d.SetSliceLimits(func(string) (store.Resources, bool) {
+		return store.Resources{MemoryMB: 512}, true
+	})
+
+	req := imageReq()
+	req.ClusterId = "c1"
+	req.Replicas = 2
+	req.Resources = store.Resources{MemoryMB: 384}
+
+	_, err := d.Deploy(newDeployCtx(), req)
+	if !errors.Is(err, coredeploy.ErrInvalidResources) {
+		t.Fatalf("Deploy() error = %v, want ErrInvalidResources", err)
+	}
+	if len(ds.snapshot()) != 0 || disp.count() != 0 {
+		t.Error("invalid deployment was stored or queued")
+	}
+
+	req.Resources = store.Resources{MemoryMB: 256, Pids: 100}
+	if _, err := d.Deploy(newDeployCtx(), req); err != nil {
+		t.Fatalf("Deploy() error = %v", err)
+	}
+	for _, dep := range ds.snapshot() {
+		if dep.Blueprint.Resources != req.Resources {
+			t.Errorf("blueprint resources = %+v, want %+v", dep.Blueprint.Resources, req.Resources)
+		}
+	}
+}
+
+func TestCancel_RunningDeployment(t *testing.T) {
+	d, ds, disp := newDeployer(store.NodeRoleInstance)
+	ctx := newDeployCtx()
+	ds.UpsertDeployment(ctx, &store.Deployment{ID: "dep-1", Name: "my-app", Status: store.StatusInProgress})
+
+	resp, err := d.Cancel(ctx, &coredeploy.CancelRequest{Name: "my-app"})
+	if err != nil {
+		t.Fatalf("Cancel() error: %v", err)
+	}
+	if resp.DeploymentID != "dep-1" || resp.Build {
+		t.Errorf("response = %+v, want deployment dep-1 and no build", resp)
+	}
+	if len(disp.cancelled) != 1 || disp.cancelled[0] != "dep-1" {
+		t.Errorf("dispatcher cancelled %v, want [dep-1]", disp.cancelled)
+	}
+}
+
+func TestCancel_InFlightBuild(t *testing.T) {
+	d, _, _ := newDeployer(store.NodeRoleBuild)
+
+	buildCtx, untrack := d.trackBuild(context.Background(), "my-app")
+	defer untrack()
+
+	resp, err := d.Cancel(newDeployCtx(), &coredeploy.CancelRequest{Name: "my-app"})
+	if err != nil {
+		t.Fatalf("Cancel() error: %v", err)
+	}
+	if !resp.Build {
+		t.Error("expected the build to be reported as cancelled")
+	}
+	if buildCtx.Err() != context.Canceled {
+		t.Errorf("build context err = %v, want context.Canceled", buildCtx.Err())
+	}
+}
+
+func TestCancel_NothingInFlight(t *testing.T) {
+	tests := []struct {
+		name string
+		dep  *store.Deployment
+	}{
+		{name: "unknown service"},
+		{name: "finished deployment", dep: &store.Deployment{ID: "dep-1", Name: "my-app", Status: store.StatusCompleted}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, ds, disp := newDeployer(store.NodeRoleInstance)
+			ctx := newDeployCtx()
+			if tc.dep != nil {
+				ds.UpsertDeployment(ctx, tc.dep)
+			}
+
+			_, err := d.Cancel(ctx, &coredeploy.CancelRequest{Name: "my-app"})
+			if !errors.Is(err, coredeploy.ErrNothingToCancel) {
+				t.Errorf("Cancel() error = %v, want ErrNothingToCancel", err)
+			}
+			if len(disp.cancelled) != 0 {
+				t.Errorf("dispatcher must not be asked to cancel, got %v", disp.cancelled)
+			}
+		})
+	}
+}
+
+func TestDeployCommit_RebuildsPushedCommit(t *testing.T) {
+	d, ds, disp := newDeployer(store.NodeRoleBuild)
+	req := remoteReq()
+	req.AutoDeploy = true
+	req.Secrets = map[string]any{"API_KEY": "s3cret"}
+	first, err := d.Deploy(newDeployCtx(), req)
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	live := ds.snapshot()[first.ID]
+
+	resp, err := d.DeployCommit(context.Background(), "my-app", "0d1a26e")
+	if err != nil {
+		t.Fatalf("DeployCommit: %v", err)
+	}
+	got := ds.snapshot()[resp.ID]
+	if got == nil || got.ID == first.ID {
+		t.Fatalf("no new deployment recorded for the pushed commit")
+	}
+	if got.Blueprint.Remote.CommitHash != "0d1a26e" || got.Blueprint.Remote.Branch != "main" {
+		t.Errorf("remote = %+v, want main at the pushed commit", got.Blueprint.Remote)
+	}
+	if *got.UserId != *live.UserId || got.Blueprint.Secrets["API_KEY"] != "s3cret" {
+		t.Errorf("deployment = %+v, want the live deployment's user and secrets", got)
+	}
+	if disp.count() != 2 {
+		t.Errorf("expected 2 dispatcher submissions, got %d", disp.count())
+	}
+}
+
+func TestDeployCommit_RequiresOptIn(t *testing.T) {
+	d, _, disp := newDeployer(store.NodeRoleBuild)
+	if _, err := d.Deploy(newDeployCtx(), remoteReq()); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if _, err := d.DeployCommit(context.Background(), "my-app", "0d1a26e"); err == nil {
+		t.Fatal("DeployCommit succeeded for a service that did not opt in")
+	}
+	if disp.count() != 1 {
+		t.Errorf("expected only the original submission, got %d", disp.count())
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// KeysDir holds the SSH deploy keys of every service and the known hosts
+// of the git servers they are fetched from.
+func KeysDir() string {
+	return filepath.Join(coreutils.GetDataDir(), ".dployr", "keys")
+}
+
+// DeployKeyPath returns where the private SSH deploy key of a service is
+// kept. The public half sits beside it with a .pub suffix.
+func DeployKeyPath(name string) string {
+	return filepath.Join(KeysDir(), coreutils.FormatName(name), "id_ed25519")
+}
+
+// knownHostsPath records the host keys of git servers, trusted on first use.
+func knownHostsPath() string {
+	return filepath.Join(KeysDir(), "known_hosts")
+}
+
+// DeployKey returns the public half of a service's SSH deploy key, generating
+// the key pair on first use. The private key never leaves this node; clones
+// of the service's SSH remote authenticate with it.
+func (d *Deployer) DeployKey(ctx context.Context, req *deploy.DeployKeyRequest) (*deploy.DeployKeyResponse, error) {
+	if _, err := shared.UserFromContext(ctx); err != nil {
+		return nil, fmt.Errorf("unauthenticated deploy key request")
+	}
+
+	d.keysMu.Lock()
+	defer d.keysMu.Unlock()
+
+	svcName := coreutils.FormatName(req.Name)
+	pub, created, err := ensureKey(ctx, DeployKeyPath(req.Name), "dployr-"+svcName, req.Rotate)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		d.logger.Info("generated deploy key", "service", svcName, "rotated", req.Rotate)
+	}
+	return &deploy.DeployKeyResponse{Name: req.Name, PublicKey: pub, Created: created}, nil
+}
+
+// ensureKey returns the public half of the ed25519 key pair at path,
+// generating it with ssh-keygen when it is missing or rotate is set. A new
+// pair is written beside the old one and renamed over it, so a clone in
+// flight never reads a half-written key.
+func ensureKey(ctx context.Context, path, comment string, rotate bool) (string, bool, error) {
+	if !rotate && fileExists(path) {
+		if pub, err := os.ReadFile(path + ".pub"); err == nil {
+			return strings.TrimSpace(string(pub)), false, nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", false, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	tmp := path + ".new"
+	os.Remove(tmp)          //nolint:errcheck
+	os.Remove(tmp + ".pub") //nolint:errcheck
+
+	c := exec.CommandContext(ctx, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", comment, "-f", tmp)
+	if out, err := c.CombinedOutput(); err != nil {
+		return "", false, fmt.Errorf("ssh-keygen failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if err := os.Rename(tmp+".pub", path+".pub"); err != nil {
+		return "", false, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", false, err
+	}
+
+	pub, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(pub)), true, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnsureKey(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skipf("ssh-keygen not available: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keys", "shop", "id_ed25519")
+
+	pub, created, err := ensureKey(context.Background(), path, "dployr-shop", false)
+	if err != nil || !created {
+		t.Fatalf("first ensureKey = %t, %v; want a new key", created, err)
+	}
+	if !strings.HasPrefix(pub, "ssh-ed25519 ") || !strings.HasSuffix(pub, " dployr-shop") {
+		t.Errorf("public key = %q", pub)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("private key stat = %v, %v; want mode 0600", fi, err)
+	}
+
+	again, created, err := ensureKey(context.Background(), path, "dployr-shop", false)
+	if err != nil || created || again != pub {
+		t.Errorf("second ensureKey = %q, %t, %v; want the existing key", again, created, err)
+	}
+
+	rotated, created, err := ensureKey(context.Background(), path, "dployr-shop", true)
+	if err != nil || !created || rotated == pub {
+		t.Errorf("rotated ensureKey = %t, %v; want a different key", created, err)
+	}
+	if _, err := os.Stat(path + ".new"); !os.IsNotExist(err) {
+		t.Error("temporary key left behind")
+	}
+}
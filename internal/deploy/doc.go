@@ -8,19 +8,59 @@
 //
 // Build pipeline (build nodes only):
 //
-//   - BuildImage(name, srcDir, cfg) builds a Docker image from a cloned source
-//     directory, pushes it to cfg.RegistryURL, removes the local copy to reclaim
-//     disk, and returns the fully-qualified image reference. The image tag is a
-//     millisecond Unix timestamp for natural sort order and uniqueness.
-//     Requires REGISTRY_URL in config.toml. Set REGISTRY_AUTH to a base64-encoded
-//     JSON credential string ({"username":"…","password":"…"}) for authenticated
+//   - BuildImage(ctx, name, srcDir, cfg) builds a Docker image from a cloned source
+//     directory, pushes it to cfg.RegistryURL and returns the fully-qualified
+//     image reference. The tag is a hash of the build context and options, so
+//     an image already in the registry is reused without building. Built images
+//     stay on the node as layer cache, oldest pruned past BUILD_CACHE_SIZE MB;
+//     a force rebuild skips the registry lookup and the layer cache. Requires
+//     REGISTRY_URL in config.toml. Set REGISTRY_AUTH to a base64-encoded JSON
+//     credential string ({"username":"…","password":"…"}) for authenticated
 //     registries such as DigitalOcean Container Registry.
 //
-//   - imageRef(registryURL, name) constructs the image reference used as the tag
-//     for both docker build and docker push.
+//   - imageRef(registryURL, name, hash) constructs the image reference used as
+//     the tag for both docker build and docker push.
 //
-// buildAuthUrl injects the provided token into the HTTPS clone URL so git can
-// authenticate against private repositories without interactive prompts.
+//   - Deployer.Build runs at most BUILD_SLOTS builds at once. The rest wait in
+//     a FIFO per cluster, clusters taking turns; a newer commit of a service
+//     replaces its queued build and the same commit joins it.
+//
+// Redeploys of a running web service go through CutoverApp: the replacement
+// starts as "<name>-next" on the alternate host port, is probed on the
+// blueprint's HealthCheck path, and only then takes over the proxy route.
+//
+// A blueprint with Replicas > 1 runs "<name>", "<name>-r1", … each on the host
+// port internal/ports reserved for it; web replicas are replaced one at a
+// time, each probed before the next. ScaleApp adds or removes replicas
+// without a rebuild.
+//
+// Every run of a deployment or build clones into its own workspace,
+// .dployr/workspaces/<name>/<ulid>, so a redeploy never touches the live
+// tree. A deployment that succeeds becomes the live one: .dployr/services/<name>
+// is a symlink to it, swapped atomically, and the workspaces of older
+// deployments are removed.
+//
+// A static site is served by the proxy straight from the live workspace. One
+// with a BuildCmd is built in a throwaway container of its runtime's builder
+// image, and StaticDir of the result is copied out to .dployr-site; a static
+// site from an image has StaticDir copied out of the image the same way.
+//
+// A job with a Schedule is installed by the deploy script as a one-shot
+// template unit and not started; the scheduler in internal/jobs runs it.
+//
+// Every step that clones, builds, pulls or starts takes the caller's context,
+// so cancelling a deployment or build aborts it mid-step. Shell steps are run
+// in their own process group and are killed as a whole.
+//
+// CloneRepo runs git directly, with no shell, and fetches only the commit
+// being built; submodules and Git LFS objects are fetched when the remote asks
+// for them. SSH remotes authenticate with the service's deploy key, generated
+// on the build node through /builds/deploy-key and kept under .dployr/keys.
+//
+// HTTPS remotes authenticate with a token. buildAuthUrl injects it into a URL
+// so git can authenticate against private repositories without interactive
+// prompts; CloneRepo applies it to the repository's host through git config
+// in the environment, so it is never written to .git/config.
 //
 // Token semantics per provider:
 //   - GitHub  → x-access-token:{token}  (GitHub App installation token)
@@ -0,0 +1,78 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+var hostLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// resolveDomains merges the legacy single Domain field into Domains and
+// validates the result. A domain without a TLS mode gets an ACME certificate.
+func resolveDomains(req *deploy.DeployRequest) ([]store.Domain, error) {
+	requested := req.Domains
+	if req.Domain != "" {
+		requested = append([]store.Domain{{Host: req.Domain}}, requested...)
+	}
+
+	seen := make(map[string]bool, len(requested))
+	domains := make([]store.Domain, 0, len(requested))
+	for _, d := range requested {
+		d.Host = strings.ToLower(strings.TrimSpace(d.Host))
+		if err := validateDomain(&d); err != nil {
+			return nil, fmt.Errorf("%w %q: %s", deploy.ErrInvalidDomain, d.Host, err)
+		}
+		if seen[d.Host] {
+			continue
+		}
+		seen[d.Host] = true
+		domains = append(domains, d)
+	}
+	if len(domains) == 0 {
+		return nil, nil
+	}
+	return domains, nil
+}
+
+func validateDomain(d *store.Domain) error {
+	if d.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if strings.HasSuffix(d.Host, ".dployr.run") {
+		return fmt.Errorf("dployr.run addresses are assigned automatically")
+	}
+	labels := strings.Split(d.Host, ".")
+	if len(labels) < 2 || len(d.Host) > 253 {
+		return fmt.Errorf("not a fully qualified host name")
+	}
+	for _, l := range labels {
+		if !hostLabel.MatchString(l) {
+			return fmt.Errorf("not a valid host name")
+		}
+	}
+
+	if d.TLS == "" {
+		d.TLS = store.TLSAcme
+	}
+	switch d.TLS {
+	case store.TLSAcme, store.TLSInternal:
+		if d.CertFile != "" || d.KeyFile != "" {
+			return fmt.Errorf("cert_file and key_file are only used with tls %q", store.TLSCustom)
+		}
+	case store.TLSCustom:
+		if !filepath.IsAbs(d.CertFile) || !filepath.IsAbs(d.KeyFile) {
+			return fmt.Errorf("tls %q needs absolute cert_file and key_file paths", store.TLSCustom)
+		}
+	default:
+		return fmt.Errorf("unknown tls mode %q", d.TLS)
+	}
+	return nil
+}
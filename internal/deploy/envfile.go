@@ -12,9 +12,10 @@ import (
 	"github.com/dployr-io/dployr/pkg/store"
 )
 
-// WriteEnvFile writes <workDir>/.env from the blueprint's env vars and secrets,
-// with PORT always written first. Duplicate keys are skipped — PORT wins over any
-// env var named PORT, and env vars win over secrets with the same key.
+// WriteEnvFile writes <workDir>/.env from the blueprint's env vars, with PORT
+// always written first. Duplicate keys are skipped — PORT wins over any env var
+// named PORT. Secrets are never written to disk; containers receive them inline
+// at start (see buildEnv).
 func WriteEnvFile(workDir string, bp store.Blueprint, port int) error {
 	var b strings.Builder
 	written := map[string]bool{}
@@ -31,9 +32,6 @@ func WriteEnvFile(workDir string, bp store.Blueprint, port int) error {
 	for k, v := range bp.EnvVars {
 		write(k, v)
 	}
-	for k, v := range bp.Secrets {
-		write(k, v)
-	}
 
 	return os.WriteFile(filepath.Join(workDir, ".env"), []byte(b.String()), 0600)
 }
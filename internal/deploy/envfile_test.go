@@ -75,7 +75,7 @@ func TestWriteEnvFile_SecretsDontDuplicateEnvVars(t *testing.T) {
 	}
 }
 
-func TestWriteEnvFile_SecretsWrittenWhenNoEnvVarConflict(t *testing.T) {
+func TestWriteEnvFile_SecretsNotWritten(t *testing.T) {
 	dir := t.TempDir()
 	bp := store.Blueprint{
 		Secrets: map[string]string{"DB_PASS": "hunter2"},
@@ -83,8 +83,35 @@ func TestWriteEnvFile_SecretsWrittenWhenNoEnvVarConflict(t *testing.T) {
 	if err := WriteEnvFile(dir, bp, 3000); err != nil {
 		t.Fatalf("WriteEnvFile: %v", err)
 	}
-	if !strings.Contains(mustReadEnvFile(t, dir), "DB_PASS=hunter2\n") {
-		t.Error("secret DB_PASS not written to .env")
+	if content := mustReadEnvFile(t, dir); strings.Contains(content, "DB_PASS") {
+		t.Errorf("secret DB_PASS written to .env\ngot:\n%s", content)
+	}
+}
+
+func TestWriteServiceConfig_SecretsNotWritten(t *testing.T) {
+	bp := store.Blueprint{
+		WorkingDir: t.TempDir(),
+		EnvVars:    map[string]string{"APP_ENV": "production"},
+		Secrets:    map[string]string{"DB_PASS": "hunter2"},
+	}
+	if err := writeServiceConfig(bp); err != nil {
+		t.Fatalf("writeServiceConfig: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(bp.WorkingDir, "config.toml"))
+	if err != nil {
+		t.Fatalf("read config.toml: %v", err)
+	}
+	if content := string(data); !strings.Contains(content, `APP_ENV = "production"`) || strings.Contains(content, "hunter2") {
+		t.Errorf("config.toml should hold env vars only\ngot:\n%s", content)
+	}
+
+	env, err := secretsEnv(bp)
+	if err != nil || len(env) != 1 || env[0] != "DPLOYR_SECRET_DB_PASS=hunter2" {
+		t.Errorf("secretsEnv = %v, %v", env, err)
+	}
+	bp.Secrets["BAD-NAME"] = "x"
+	if _, err := secretsEnv(bp); err == nil {
+		t.Error("secretsEnv accepted a name bash cannot hold")
 	}
 }
 
@@ -0,0 +1,166 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+var commitHashRegex = regexp.MustCompile(`^[0-9a-fA-F]{4,64}$`)
+
+// CloneRepo fetches remote into destDir, checked out at remote.CommitHash,
+// or the tip of remote.Branch when no commit is pinned. git is run directly,
+// never through a shell, and only the one commit is fetched. name selects the
+// service's deploy key for SSH remotes. Cancelling ctx kills the git process.
+func CloneRepo(ctx context.Context, name string, remote store.RemoteObj, destDir string, config *shared.Config) error {
+	if remote.Url == "" || strings.HasPrefix(remote.Url, "-") {
+		return fmt.Errorf("invalid repository url %q", remote.Url)
+	}
+	if remote.CommitHash != "" && !commitHashRegex.MatchString(remote.CommitHash) {
+		return fmt.Errorf("invalid commit hash %q", remote.CommitHash)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	// Always start from an empty directory. Re-using an existing checkout is
+	// fragile (detached HEAD, partial state from a failed previous build).
+	// destDir belongs to this one build, so nothing else is reading it.
+	if err := os.RemoveAll(destDir); err != nil {
+		return fmt.Errorf("failed to clean destination directory: %s", err)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %s", err)
+	}
+
+	env := gitEnv(name, remote)
+	git := func(args ...string) error {
+		return runGit(ctx, destDir, env, remote.Token, args...)
+	}
+
+	if err := git("init", "--quiet"); err != nil {
+		return err
+	}
+	// origin carries the plain URL; credentials are applied per command and
+	// never written to .git/config.
+	if err := git("remote", "add", "origin", remote.Url); err != nil {
+		return err
+	}
+
+	branch := "HEAD"
+	if remote.Branch != "" {
+		branch = "refs/heads/" + remote.Branch
+	}
+	rev := "FETCH_HEAD"
+	if remote.CommitHash == "" {
+		if err := git("fetch", "--quiet", "--no-tags", "--depth", "1", "origin", branch); err != nil {
+			return err
+		}
+	} else if err := git("fetch", "--quiet", "--no-tags", "--depth", "1", "origin", remote.CommitHash); err != nil {
+		// Short hashes, and servers that only hand out branch tips, need the
+		// branch history to find the commit in.
+		if err := git("fetch", "--quiet", "--no-tags", "origin", branch); err != nil {
+			return err
+		}
+		rev = remote.CommitHash
+	}
+	if err := git("checkout", "--quiet", "--detach", rev); err != nil {
+		return err
+	}
+
+	if remote.Submodules {
+		if err := git("submodule", "update", "--quiet", "--init", "--recursive", "--depth", "1"); err != nil {
+			return err
+		}
+	}
+	if remote.LFS {
+		if err := git("lfs", "install", "--local"); err != nil {
+			return err
+		}
+		if err := git("lfs", "pull", "origin"); err != nil {
+			return err
+		}
+	}
+
+	// Write .dockerignore to exclude build artifacts and dependencies
+	if err := writeDockerIgnore(destDir); err != nil {
+		return fmt.Errorf("failed to write .dockerignore: %s", err)
+	}
+
+	return nil
+}
+
+// gitEnv returns the environment that authenticates git against remote. An
+// SSH remote uses the service's deploy key when one exists. A token is
+// applied to every HTTPS URL on the repository's host, so submodules hosted
+// alongside it are fetched with it too. Passing it through GIT_CONFIG_*
+// keeps it out of the process list.
+func gitEnv(name string, remote store.RemoteObj) []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+
+	if isSSHRemote(remote.Url) {
+		if key := DeployKeyPath(name); fileExists(key) {
+			env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i '%s' -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new -o UserKnownHostsFile='%s'", key, knownHostsPath()))
+		}
+		return env
+	}
+
+	u, err := url.Parse(remote.Url)
+	if err != nil || u.Host == "" || u.User != nil {
+		return env
+	}
+	host := u.Scheme + "://" + u.Host + "/"
+	if auth, err := buildAuthUrl(host, remote.Token); err == nil && auth != host {
+		env = append(env, "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=url."+auth+".insteadOf", "GIT_CONFIG_VALUE_0="+host)
+	}
+	return env
+}
+
+// isSSHRemote matches ssh:// URLs and the scp-like git@host:path form.
+func isSSHRemote(u string) bool {
+	if strings.HasPrefix(u, "ssh://") {
+		return true
+	}
+	at, colon := strings.Index(u, "@"), strings.Index(u, ":")
+	return !strings.Contains(u, "://") && at > 0 && colon > at
+}
+
+// runGit runs one git command in dir. Its stderr, with secret masked, is
+// returned as the error.
+func runGit(ctx context.Context, dir string, env []string, secret string, args ...string) error {
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Dir = dir
+	c.Env = append(os.Environ(), env...)
+	shared.KillGroupOnCancel(c)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("git %s timed out after 5 minutes", args[0])
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if secret != "" {
+			msg = strings.ReplaceAll(msg, secret, "***")
+		}
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, msg)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
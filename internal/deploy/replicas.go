@@ -0,0 +1,178 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// ValidateReplicas checks a requested replica count. Only long-running
+// container services can be scaled out; 0 and 1 both mean a single container.
+func ValidateReplicas(t store.ServiceType, replicas int) error {
+	if replicas < 0 || replicas > store.MaxReplicas {
+		return fmt.Errorf("%w: %d is outside 1-%d", deploy.ErrInvalidReplicas, replicas, store.MaxReplicas)
+	}
+	if replicas > 1 && t != store.TypeWeb && t != store.TypeWorker {
+		return fmt.Errorf("%w: %s services run a single instance", deploy.ErrInvalidReplicas, t)
+	}
+	return nil
+}
+
+// replicaCount returns how many containers bp runs.
+func replicaCount(bp store.Blueprint) int {
+	return max(bp.Replicas, 1)
+}
+
+// replicaHostPort returns the host port of replica i: the one reserved for
+// it in hostPorts, or its hashed port when none was passed.
+func replicaHostPort(hostPorts []int, name string, i int) int {
+	if i < len(hostPorts) && hostPorts[i] != 0 {
+		return hostPorts[i]
+	}
+	return coreutils.ComputeHostPort(coreutils.ReplicaName(name, i))
+}
+
+// replicaConfig describes the container for replica i of a service. Each
+// replica binds its own host port so Caddy can balance across them.
+func replicaConfig(bp store.Blueprint, name string, i, port int, hostPorts []int, cfg *shared.Config) *ContainerConfig {
+	rname := coreutils.ReplicaName(name, i)
+	cc := &ContainerConfig{
+		Name:        rname,
+		Service:     name,
+		Image:       bp.Image,
+		Port:        port,
+		HostPort:    replicaHostPort(hostPorts, name, i),
+		Env:         buildEnv(bp, port),
+		Description: bp.Desc,
+		Type:        bp.Type,
+		RunCmd:      bp.RunCmd,
+		ClusterID:   bp.ClusterID,
+	}
+	cc.setResources(bp, cfg)
+	return cc
+}
+
+// runContainer replaces any container named cc.Name with a fresh one and
+// starts it. Returns the new container ID.
+func runContainer(ctx context.Context, cc *ContainerConfig, dockerCli deployDockerAPI) (string, error) {
+	// Remove any pre-existing container with the same name (best-effort).
+	dockerCli.ContainerRemove(ctx, cc.Name, container.RemoveOptions{Force: true}) //nolint:errcheck
+
+	// A create or start aborted by cancellation may still leave a container
+	// behind; remove it so the next deployment starts clean.
+	discardPartial := func() {
+		if ctx.Err() != nil {
+			dockerCli.ContainerRemove(context.Background(), cc.Name, container.RemoveOptions{Force: true}) //nolint:errcheck
+		}
+	}
+
+	resp, err := dockerCli.ContainerCreate(ctx, ptr(cc.ContainerCfg()), ptr(cc.HostCfg()), nil, nil, cc.Name)
+	if err != nil {
+		discardPartial()
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("docker create timed out")
+		}
+		return "", fmt.Errorf("docker create failed: %w", err)
+	}
+
+	if err := dockerCli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		discardPartial()
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("docker start timed out")
+		}
+		return "", fmt.Errorf("docker start failed: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// startReplicas starts replicas [from, to) of a service. With more than one
+// web replica each is probed before the next is replaced, so a rolling update
+// never takes every upstream down at once.
+func startReplicas(ctx context.Context, bp store.Blueprint, name, logPath string, from, to, port int, hostPorts []int, cfg *shared.Config, dockerCli deployDockerAPI) error {
+	probe := bp.Type == store.TypeWeb && replicaCount(bp) > 1
+	probePath, err := coreutils.NormaliseHealthPath(bp.HealthCheck)
+	if probe && err != nil {
+		return err
+	}
+
+	for i := from; i < to; i++ {
+		cc := replicaConfig(bp, name, i, port, hostPorts, cfg)
+		id, err := runContainer(ctx, cc, dockerCli)
+		if err != nil {
+			if to-from > 1 {
+				return fmt.Errorf("replica %d: %w", i, err)
+			}
+			return err
+		}
+		shared.LogInfoF(name, logPath, fmt.Sprintf("container started: %s", id))
+
+		if probe && !waitHealthy(ctx, cc.HostPort, probePath) {
+			return fmt.Errorf("replica %s failed health check on %s after %s", cc.Name, probePath, cutoverProbeTimeout)
+		}
+	}
+	return nil
+}
+
+// removeReplicas removes replicas [from, to) of a service (best-effort).
+// Replica containers that were never started are ignored.
+func removeReplicas(ctx context.Context, name, logPath string, from, to int, dockerCli deployDockerAPI) {
+	for i := from; i < to; i++ {
+		rname := coreutils.ReplicaName(name, i)
+		if err := dockerCli.ContainerRemove(ctx, rname, container.RemoveOptions{Force: true}); err == nil && logPath != "" {
+			shared.LogInfoF(name, logPath, fmt.Sprintf("removed replica %s", rname))
+		}
+	}
+}
+
+// ScaleApp changes the number of running replicas of a deployed service from
+// `from` to `to` without rebuilding it. Added replicas run the same blueprint
+// as the existing ones; surplus replicas are removed from the highest index
+// down. hostPorts is as for DeployApp. The caller is responsible for
+// repointing the proxy.
+func ScaleApp(ctx context.Context, bp store.Blueprint, name, logPath string, from, to int, hostPorts []int, cfg *shared.Config, dockerCli deployDockerAPI) error {
+	if err := ValidateReplicas(bp.Type, to); err != nil {
+		return err
+	}
+	from, to = max(from, 1), max(to, 1)
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
+	defer cancel()
+
+	if to < from {
+		shared.LogInfoF(name, logPath, fmt.Sprintf("scaling %s down from %d to %d replicas", name, from, to))
+		removeReplicas(ctx, name, logPath, to, from, dockerCli)
+		return nil
+	}
+	if to == from {
+		return nil
+	}
+
+	port := bp.Port
+	if port == 0 {
+		port = 3000
+	}
+	if bp.Image != "" {
+		if err := PullImage(ctx, bp.Image, cfg, dockerCli); err != nil {
+			return fmt.Errorf("failed to pull image: %w", err)
+		}
+	}
+
+	shared.LogInfoF(name, logPath, fmt.Sprintf("scaling %s up from %d to %d replicas", name, from, to))
+	bp.Replicas = to
+	if err := startReplicas(ctx, bp, name, logPath, from, to, port, hostPorts, cfg, dockerCli); err != nil {
+		// Leave the service at its previous size rather than half scaled.
+		removeReplicas(context.Background(), name, "", from, to, dockerCli)
+		return err
+	}
+	return nil
+}
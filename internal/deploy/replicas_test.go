@@ -0,0 +1,119 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+func TestDeployDocker_StartsEachReplicaOnItsOwnPort(t *testing.T) {
+	paths := stubProbe(t, true)
+	docker := newFakeDocker()
+	bp := cutoverBlueprint(t)
+	bp.Replicas = 3
+
+	if err := deployDocker(context.Background(), bp, "my-app", t.TempDir()+"/", nil, nil, docker); err != nil {
+		t.Fatalf("deployDocker() error: %v", err)
+	}
+
+	want := []string{"my-app", "my-app-r1", "my-app-r2"}
+	if !slices.Equal(docker.created, want) {
+		t.Fatalf("created = %v, want %v", docker.created, want)
+	}
+	for _, name := range want {
+		if docker.ports[name] != strconv.Itoa(coreutils.ComputeHostPort(name)) {
+			t.Errorf("%s bound to %s, want its hashed host port", name, docker.ports[name])
+		}
+	}
+	if len(*paths) != 3 {
+		t.Errorf("probed %d times, want once per replica", len(*paths))
+	}
+	if !slices.Contains(docker.removed, "my-app-r3") {
+		t.Errorf("surplus replicas not cleaned up, removed = %v", docker.removed)
+	}
+}
+
+func TestDeployDocker_BindsReservedPorts(t *testing.T) {
+	stubProbe(t, true)
+	docker := newFakeDocker()
+	bp := cutoverBlueprint(t)
+	bp.Replicas = 2
+
+	if err := deployDocker(context.Background(), bp, "my-app", t.TempDir()+"/", []int{61500, 61501}, nil, docker); err != nil {
+		t.Fatalf("deployDocker() error: %v", err)
+	}
+	if docker.ports["my-app"] != "61500" || docker.ports["my-app-r1"] != "61501" {
+		t.Errorf("ports = %v, want the reserved ones", docker.ports)
+	}
+}
+
+func TestDeployDocker_SingleContainerIsNotProbed(t *testing.T) {
+	paths := stubProbe(t, false)
+	docker := newFakeDocker()
+
+	if err := deployDocker(context.Background(), cutoverBlueprint(t), "my-app", t.TempDir()+"/", nil, nil, docker); err != nil {
+		t.Fatalf("deployDocker() error: %v", err)
+	}
+	if !slices.Equal(docker.created, []string{"my-app"}) {
+		t.Errorf("created = %v, want only my-app", docker.created)
+	}
+	if len(*paths) != 0 {
+		t.Errorf("single container was probed: %v", *paths)
+	}
+}
+
+func TestDeployDocker_UnhealthyReplicaStopsRollout(t *testing.T) {
+	stubProbe(t, false)
+	docker := newFakeDocker()
+	bp := cutoverBlueprint(t)
+	bp.Replicas = 3
+
+	if err := deployDocker(context.Background(), bp, "my-app", t.TempDir()+"/", nil, nil, docker); err == nil {
+		t.Fatal("expected error when a replica fails its health check")
+	}
+	if !slices.Equal(docker.created, []string{"my-app"}) {
+		t.Errorf("rollout continued past an unhealthy replica, created = %v", docker.created)
+	}
+}
+
+func TestScaleApp(t *testing.T) {
+	stubProbe(t, true)
+
+	t.Run("up", func(t *testing.T) {
+		docker := newFakeDocker()
+		if err := ScaleApp(context.Background(), cutoverBlueprint(t), "my-app", t.TempDir()+"/", 1, 3, nil, nil, docker); err != nil {
+			t.Fatalf("ScaleApp() error: %v", err)
+		}
+		if want := []string{"my-app-r1", "my-app-r2"}; !slices.Equal(docker.created, want) {
+			t.Errorf("created = %v, want %v", docker.created, want)
+		}
+	})
+
+	t.Run("down", func(t *testing.T) {
+		docker := newFakeDocker()
+		if err := ScaleApp(context.Background(), cutoverBlueprint(t), "my-app", t.TempDir()+"/", 3, 1, nil, nil, docker); err != nil {
+			t.Fatalf("ScaleApp() error: %v", err)
+		}
+		if len(docker.created) != 0 {
+			t.Errorf("scaling down created %v", docker.created)
+		}
+		if want := []string{"my-app-r1", "my-app-r2"}; !slices.Equal(docker.removed, want) {
+			t.Errorf("removed = %v, want %v", docker.removed, want)
+		}
+	})
+
+	t.Run("static", func(t *testing.T) {
+		bp := cutoverBlueprint(t)
+		bp.Type = store.TypeStatic
+		if err := ScaleApp(context.Background(), bp, "my-app", t.TempDir()+"/", 1, 2, nil, nil, newFakeDocker()); err == nil {
+			t.Error("expected error scaling a static service")
+		}
+	})
+}
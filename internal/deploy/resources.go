@@ -0,0 +1,72 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// Docker refuses a memory limit below 6 MB and a CPU quota below 1ms per
+// 100ms period.
+const (
+	minMemoryMB = 6
+	minCPU      = 10
+)
+
+// SliceLimitsFunc reports the memory and CPU limits of a cluster's cgroup
+// slice on this node. ok is false when the cluster has no slice here.
+type SliceLimitsFunc func(clusterID string) (limits store.Resources, ok bool)
+
+// ValidateResources checks a service's per-container limits. When the
+// service runs inside a cluster slice, the limits of all its replicas
+// together must fit within the slice.
+func ValidateResources(r store.Resources, replicas int, clusterID string, slice SliceLimitsFunc) error {
+	if r.MemoryMB < 0 || r.CPU < 0 || r.Pids < 0 || r.StorageGB < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", deploy.ErrInvalidResources)
+	}
+	if r.MemoryMB > 0 && r.MemoryMB < minMemoryMB {
+		return fmt.Errorf("%w: memory must be at least %d MB", deploy.ErrInvalidResources, minMemoryMB)
+	}
+	if r.CPU > 0 && r.CPU < minCPU {
+		return fmt.Errorf("%w: cpu must be at least %d millicores", deploy.ErrInvalidResources, minCPU)
+	}
+
+	if clusterID == "" || slice == nil {
+		return nil
+	}
+	limits, ok := slice(clusterID)
+	if !ok {
+		return nil
+	}
+	n := max(replicas, 1)
+	if limits.MemoryMB > 0 && r.MemoryMB*n > limits.MemoryMB {
+		return fmt.Errorf("%w: %d x %d MB exceeds the cluster's %d MB", deploy.ErrInvalidResources, n, r.MemoryMB, limits.MemoryMB)
+	}
+	if limits.CPU > 0 && r.CPU*n > limits.CPU {
+		return fmt.Errorf("%w: %d x %d millicores exceeds the cluster's %d millicores", deploy.ErrInvalidResources, n, r.CPU, limits.CPU)
+	}
+	return nil
+}
+
+// UpdateResources applies bp.Resources to every running replica of a
+// service in place. Memory, CPU and pids change without a restart; storage
+// is fixed when a container is created.
+func UpdateResources(ctx context.Context, bp store.Blueprint, name, logPath string, cfg *shared.Config, dockerCli deployDockerAPI) error {
+	for i := range replicaCount(bp) {
+		cc := &ContainerConfig{Name: coreutils.ReplicaName(name, i)}
+		cc.setResources(bp, cfg)
+		if _, err := dockerCli.ContainerUpdate(ctx, cc.Name, cc.UpdateCfg()); err != nil {
+			return fmt.Errorf("failed to update %s: %w", cc.Name, err)
+		}
+	}
+	shared.LogInfoF(name, logPath, fmt.Sprintf("resource limits updated: memory=%dMB cpu=%dm pids=%d",
+		bp.Resources.MemoryMB, bp.Resources.CPU, bp.Resources.Pids))
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	coredeploy "github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+func TestValidateResources(t *testing.T) {
+	slice := func(id string) (store.Resources, bool) {
+		if id != "c1" {
+			return store.Resources{}, false
+		}
+		return store.Resources{MemoryMB: 1024, CPU: 2000}, true
+	}
+
+	tests := []struct {
+		name      string
+		r         store.Resources
+		replicas  int
+		clusterID string
+		wantErr   bool
+	}{
+		{"no limits", store.Resources{}, 1, "c1", false},
+		{"fits slice", store.Resources{MemoryMB: 512, CPU: 1000}, 2, "c1", false},
+		{"replicas exceed memory", store.Resources{MemoryMB: 512}, 3, "c1", true},
+		{"exceeds cpu", store.Resources{CPU: 2500}, 1, "c1", true},
+		{"no slice on node", store.Resources{MemoryMB: 4096}, 1, "c2", false},
+		{"no cluster", store.Resources{MemoryMB: 4096}, 1, "", false},
+		{"negative", store.Resources{Pids: -1}, 1, "", true},
+		{"memory below docker minimum", store.Resources{MemoryMB: 4}, 1, "", true},
+		{"cpu below docker minimum", store.Resources{CPU: 5}, 1, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResources(tt.r, tt.replicas, tt.clusterID, slice)
+			if tt.wantErr && !errors.Is(err, coredeploy.ErrInvalidResources) {
+				t.Errorf("error = %v, want ErrInvalidResources", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateResources_AppliesToEveryReplica(t *testing.T) {
+	docker := newFakeDocker()
+	bp := store.Blueprint{Type: store.TypeWeb, Replicas: 2, Resources: store.Resources{MemoryMB: 256, CPU: 500}}
+
+	if err := UpdateResources(context.Background(), bp, "my-app", t.TempDir()+"/", nil, docker); err != nil {
+		t.Fatalf("UpdateResources() error: %v", err)
+	}
+	for _, name := range []string{"my-app", "my-app-r1"} {
+		uc, ok := docker.updated[name]
+		if !ok {
+			t.Errorf("%s not updated", name)
+			continue
+		}
+		if uc.Resources.Memory != 256*1024*1024 || uc.Resources.CPUQuota != 50000 {
+			t.Errorf("%s resources = %+v", name, uc.Resources)
+		}
+	}
+}
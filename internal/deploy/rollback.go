@@ -0,0 +1,117 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// releaseHistoryLimit bounds how far back a rollback can reach.
+const releaseHistoryLimit = 100
+
+// ErrNoRollbackTarget is returned when a service has no earlier release with a
+// built image to return to.
+var ErrNoRollbackTarget = fmt.Errorf("%w: no earlier release to roll back to", deploy.ErrReleaseNotFound)
+
+// Rollback redeploys a previous release's image through the worker. The image
+// was already built and pushed, so the rollback skips cloning and building
+// entirely and only pulls and starts it.
+func (d *Deployer) Rollback(ctx context.Context, req *deploy.RollbackRequest) (*deploy.RollbackResponse, error) {
+	user, err := shared.UserFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unauthenticated rollback attempt")
+	}
+
+	releases, err := d.store.ListReleases(ctx, req.Name, releaseHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	target, err := pickRollbackTarget(releases, req.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	bp := target.Blueprint
+	bp.Source = store.SourceImage
+	bp.Image = target.Image
+
+	userID := user.ID
+	deployment := &store.Deployment{
+		ID:        ulid.Make().String(),
+		Status:    store.StatusPending,
+		Name:      bp.Name,
+		Blueprint: bp,
+		UserId:    &userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := d.store.UpsertDeployment(ctx, deployment); err != nil {
+		return nil, fmt.Errorf("failed to upsert deployment: %w", err)
+	}
+
+	d.logger.Info("rolling back service", "service", req.Name, "release", target.Version, "image", target.Image, "deployment_id", deployment.ID)
+	if err := d.submit(ctx, deployment.ID); err != nil {
+		return nil, err
+	}
+
+	return &deploy.RollbackResponse{
+		DeployResponse: deploy.DeployResponse{
+			ID:        deployment.ID,
+			Name:      deployment.Blueprint.Name,
+			Success:   true,
+			CreatedAt: deployment.CreatedAt,
+		},
+		Release: deploy.SummarizeRelease(target),
+	}, nil
+}
+
+func (d *Deployer) ListReleases(ctx context.Context, name string, limit int) ([]*store.Release, error) {
+	return d.store.ListReleases(ctx, name, limit)
+}
+
+// pickRollbackTarget resolves which release to redeploy from a newest-first
+// history. An explicit selector matches a release ID or version number.
+// Without one, the target is the newest completed release whose image differs
+// from the one currently live.
+func pickRollbackTarget(releases []*store.Release, selector string) (*store.Release, error) {
+	if selector != "" {
+		version, _ := strconv.Atoi(selector)
+		for _, r := range releases {
+			if r.ID != selector && (version == 0 || r.Version != version) {
+				continue
+			}
+			if r.Image == "" {
+				return nil, fmt.Errorf("%w: release %d cannot be rolled back to", deploy.ErrReleaseNotBuilt, r.Version)
+			}
+			return r, nil
+		}
+		return nil, fmt.Errorf("%w: %q", deploy.ErrReleaseNotFound, selector)
+	}
+
+	var live *store.Release
+	for _, r := range releases {
+		if r.Status != store.StatusCompleted || r.Image == "" {
+			continue
+		}
+		if live == nil {
+			live = r
+			continue
+		}
+		if r.Image != live.Image {
+			return r, nil
+		}
+	}
+	return nil, ErrNoRollbackTarget
+}
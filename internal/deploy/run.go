@@ -0,0 +1,126 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// RunDockerAPI is the subset of the Docker client used for one-off runs.
+type RunDockerAPI interface {
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
+	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+}
+
+// RunName returns the container name of a one-off run of a service.
+func RunName(name, runID string) string {
+	return coreutils.FormatName(name) + "-run-" + strings.ToLower(runID)
+}
+
+// RunConfig describes the ephemeral container for a one-off run of cmd
+// against a service. It gets the service's image, environment, limits and
+// cluster slice, but no port binding, restart policy or service label.
+func RunConfig(bp store.Blueprint, name, runID string, cmd []string, cfg *shared.Config) *ContainerConfig {
+	port := bp.Port
+	if port == 0 {
+		port = 3000
+	}
+	cc := &ContainerConfig{
+		Name:        RunName(name, runID),
+		RunOf:       coreutils.FormatName(name),
+		Image:       bp.Image,
+		Env:         buildEnv(bp, port),
+		Description: fmt.Sprintf("one-off run %s of %s", runID, name),
+		Type:        bp.Type,
+		Cmd:         cmd,
+		ClusterID:   bp.ClusterID,
+	}
+	cc.setResources(bp, cfg)
+	return cc
+}
+
+// RunOneOff runs cc to completion and returns the command's exit code. Its
+// stdout and stderr are copied to the given writers as they are produced.
+// The container is removed afterwards, including when ctx ends first, in
+// which case ctx's error is returned.
+func RunOneOff(ctx context.Context, cc *ContainerConfig, stdout, stderr io.Writer, dockerCli RunDockerAPI) (int, error) {
+	// Removal must outlive ctx, which may be why the run is ending.
+	remove := func() {
+		dockerCli.ContainerRemove(context.Background(), cc.Name, container.RemoveOptions{Force: true}) //nolint:errcheck
+	}
+	defer remove()
+
+	resp, err := dockerCli.ContainerCreate(ctx, ptr(cc.ContainerCfg()), ptr(cc.HostCfg()), nil, nil, cc.Name)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("docker create failed: %w", err)
+	}
+	return runToExit(ctx, resp.ID, stdout, stderr, remove, dockerCli)
+}
+
+// runToExit starts the created container id and waits for it to exit,
+// copying its output to stdout and stderr. remove removes the container; it
+// is called early when waiting fails, to end the log stream.
+func runToExit(ctx context.Context, id string, stdout, stderr io.Writer, remove func(), dockerCli RunDockerAPI) (int, error) {
+	// Waiting from before the start means an instant exit cannot be missed.
+	waitCh, waitErrCh := dockerCli.ContainerWait(ctx, id, container.WaitConditionNextExit)
+
+	if err := dockerCli.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("docker start failed: %w", err)
+	}
+
+	rc, err := dockerCli.ContainerLogs(ctx, id, container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("docker logs failed: %w", err)
+	}
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		defer rc.Close()
+		// No TTY is allocated, so the output is multiplexed.
+		stdcopy.StdCopy(stdout, stderr, rc) //nolint:errcheck
+	}()
+
+	select {
+	case res := <-waitCh:
+		// The log stream ends once the container has stopped; let it drain.
+		<-copied
+		if res.Error != nil {
+			return -1, fmt.Errorf("docker wait failed: %s", res.Error.Message)
+		}
+		return int(res.StatusCode), nil
+	case err := <-waitErrCh:
+		// Removing the container ends its log stream, so no output is
+		// written after returning.
+		remove()
+		<-copied
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("docker wait failed: %w", err)
+	}
+}
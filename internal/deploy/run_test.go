@@ -0,0 +1,174 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// runFake plays a container that writes stdout and stderr and exits with
+// code, or never exits when hang is set.
+type runFake struct {
+	stdout, stderr string
+	code           int64
+	hang           bool
+
+	mu      sync.Mutex
+	config  *container.Config
+	host    *container.HostConfig
+	removed []string
+}
+
+func (f *runFake) ContainerCreate(_ context.Context, cfg *container.Config, hc *container.HostConfig, _ *network.NetworkingConfig, _ *specs.Platform, name string) (container.CreateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config, f.host = cfg, hc
+	return container.CreateResponse{ID: "id-" + name}, nil
+}
+
+func (f *runFake) ContainerStart(context.Context, string, container.StartOptions) error {
+	return nil
+}
+
+func (f *runFake) ContainerWait(ctx context.Context, _ string, _ container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	res := make(chan container.WaitResponse, 1)
+	errs := make(chan error, 1)
+	if f.hang {
+		go func() {
+			<-ctx.Done()
+			errs <- ctx.Err()
+		}()
+	} else {
+		res <- container.WaitResponse{StatusCode: f.code}
+	}
+	return res, errs
+}
+
+func (f *runFake) ContainerLogs(ctx context.Context, _ string, _ container.LogsOptions) (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(f.stdout))
+	stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(f.stderr))
+	if !f.hang {
+		return io.NopCloser(&buf), nil
+	}
+	// A hanging container keeps its log stream open until the run ends.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write(buf.Bytes())
+		<-ctx.Done()
+		pw.Close()
+	}()
+	return pr, nil
+}
+
+func (f *runFake) ContainerRemove(_ context.Context, id string, _ container.RemoveOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removed = append(f.removed, id)
+	return nil
+}
+
+func TestRunConfig(t *testing.T) {
+	bp := store.Blueprint{
+		Name:      "My API",
+		Image:     "registry.local/apps:api-1",
+		Type:      store.TypeWeb,
+		Port:      8080,
+		RunCmd:    "node server.js",
+		ClusterID: "c1",
+		EnvVars:   map[string]string{"MODE": "prod"},
+		Secrets:   map[string]string{"DB_URL": "postgres://"},
+		Resources: store.Resources{MemoryMB: 256},
+	}
+	cc := RunConfig(bp, "My API", "01JZZ4K3T7Q9", []string{"rake", "db:migrate"}, nil)
+
+	if cc.Name != "my-api-run-01jzz4k3t7q9" {
+		t.Errorf("Name = %q", cc.Name)
+	}
+	cfg := cc.ContainerCfg()
+	if !slices.Equal(cfg.Cmd, []string{"rake", "db:migrate"}) || cfg.Entrypoint != nil {
+		t.Errorf("Cmd = %v, Entrypoint = %v; want the command with the image entrypoint", cfg.Cmd, cfg.Entrypoint)
+	}
+	if cfg.Labels[coreutils.RunLabel] != "my-api" {
+		t.Errorf("run label = %q", cfg.Labels[coreutils.RunLabel])
+	}
+	if _, ok := cfg.Labels[coreutils.ServiceLabel]; ok {
+		t.Error("a run must not carry the service label")
+	}
+	for _, want := range []string{"PORT=8080", "MODE=prod", "DB_URL=postgres://"} {
+		if !slices.Contains(cfg.Env, want) {
+			t.Errorf("Env = %v, missing %s", cfg.Env, want)
+		}
+	}
+
+	hc := cc.HostCfg()
+	if hc.RestartPolicy.Name != container.RestartPolicyDisabled {
+		t.Errorf("RestartPolicy = %q", hc.RestartPolicy.Name)
+	}
+	if len(hc.PortBindings) != 0 {
+		t.Errorf("PortBindings = %v, want none", hc.PortBindings)
+	}
+	if hc.CgroupParent != "dployr-cluster-c1.slice" {
+		t.Errorf("CgroupParent = %q", hc.CgroupParent)
+	}
+	if hc.Memory != 256*1024*1024 {
+		t.Errorf("Memory = %d", hc.Memory)
+	}
+}
+
+func TestRunOneOff_ExitCodeAndOutput(t *testing.T) {
+	f := &runFake{stdout: "migrated\n", stderr: "warning\n", code: 3}
+	cc := &ContainerConfig{Name: "api-run-1", Image: "img", Cmd: []string{"migrate"}}
+
+	var stdout, stderr bytes.Buffer
+	code, err := RunOneOff(context.Background(), cc, &stdout, &stderr, f)
+	if err != nil {
+		t.Fatalf("RunOneOff: %v", err)
+	}
+	if code != 3 {
+		t.Errorf("code = %d, want 3", code)
+	}
+	if stdout.String() != "migrated\n" || stderr.String() != "warning\n" {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+	if !slices.Contains(f.removed, "api-run-1") {
+		t.Errorf("removed = %v, want the run container", f.removed)
+	}
+}
+
+func TestRunOneOff_Timeout(t *testing.T) {
+	f := &runFake{stdout: "working\n", hang: true}
+	cc := &ContainerConfig{Name: "api-run-2", Image: "img", Cmd: []string{"sleep", "infinity"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var stdout bytes.Buffer
+	_, err := RunOneOff(ctx, cc, &stdout, io.Discard, f)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if stdout.String() != "working\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !slices.Contains(f.removed, "api-run-2") {
+		t.Errorf("removed = %v, want the run container", f.removed)
+	}
+}
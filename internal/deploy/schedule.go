@@ -0,0 +1,39 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/dployr-io/dployr/internal/jobs"
+	"github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// ValidateSchedule checks a job's cron schedule and concurrency policy and
+// returns the policy to store. A scheduled job without a policy allows
+// overlapping runs.
+func ValidateSchedule(t store.ServiceType, schedule, policy string) (store.ConcurrencyPolicy, error) {
+	if schedule == "" {
+		if policy != "" {
+			return "", fmt.Errorf("%w: concurrency_policy needs a schedule", deploy.ErrInvalidSchedule)
+		}
+		return "", nil
+	}
+	if t != store.TypeJob {
+		return "", fmt.Errorf("%w: only job services can have a schedule", deploy.ErrInvalidSchedule)
+	}
+	if _, err := jobs.ParseSchedule(schedule); err != nil {
+		return "", fmt.Errorf("%w: %q: %v", deploy.ErrInvalidSchedule, schedule, err)
+	}
+
+	switch p := store.ConcurrencyPolicy(policy); p {
+	case "":
+		return store.ConcurrencyAllow, nil
+	case store.ConcurrencyAllow, store.ConcurrencyForbid, store.ConcurrencyReplace:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: concurrency_policy must be allow, forbid or replace", deploy.ErrInvalidSchedule)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"errors"
+	"testing"
+
+	coredeploy "github.com/dployr-io/dployr/pkg/core/deploy"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        store.ServiceType
+		schedule   string
+		policy     string
+		wantPolicy store.ConcurrencyPolicy
+		wantErr    bool
+	}{
+		{"unscheduled job", store.TypeJob, "", "", "", false},
+		{"defaults to allow", store.TypeJob, "*/5 * * * *", "", store.ConcurrencyAllow, false},
+		{"forbid", store.TypeJob, "@daily", "forbid", store.ConcurrencyForbid, false},
+		{"replace", store.TypeJob, "0 3 * * mon", "replace", store.ConcurrencyReplace, false},
+		{"unknown policy", store.TypeJob, "@daily", "queue", "", true},
+		{"policy without schedule", store.TypeJob, "", "forbid", "", true},
+		{"bad expression", store.TypeJob, "61 * * * *", "", "", true},
+		{"web service", store.TypeWeb, "@hourly", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateSchedule(tt.typ, tt.schedule, tt.policy)
+			if tt.wantErr {
+				if !errors.Is(err, coredeploy.ErrInvalidSchedule) {
+					t.Errorf("error = %v, want ErrInvalidSchedule", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantPolicy {
+				t.Errorf("policy = %q, want %q", got, tt.wantPolicy)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/internal/version_resolver"
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// siteDir holds the files of a built static site, inside the workspace of
+// the deployment that built it.
+const siteDir = ".dployr-site"
+
+// resolveRuntime picks the image a static site's build command runs in.
+// Tests replace it to stay off the network.
+var resolveRuntime = version_resolver.New(version_resolver.NewHTTPClient()).Resolve
+
+// StaticRoot returns the directory the proxy serves for a static service:
+// the built site when its deployment produced one, otherwise StaticDir of
+// the live workspace.
+func StaticRoot(svc *store.Service) string {
+	return staticRoot(WorkspaceDir(svc.Name), svc)
+}
+
+// staticRoot is StaticRoot with the live workspace at root.
+func staticRoot(root string, svc *store.Service) string {
+	if svc.Source != store.SourceImage && svc.WorkingDir != "" {
+		root = filepath.Join(root, svc.WorkingDir)
+	}
+	if site := filepath.Join(root, siteDir); fileExists(site) {
+		return site
+	}
+	return ResolveStaticDir(root, svc.StaticDir)
+}
+
+// deployStatic puts the files of a static site in place under bp.WorkingDir.
+// An image site has StaticDir copied out of the image. A remote site with a
+// BuildCmd is built in a throwaway container of its runtime's builder image,
+// and StaticDir of the result is copied out. Either lands in siteDir. A
+// remote site without a BuildCmd is served from the clone as it is.
+func deployStatic(ctx context.Context, bp store.Blueprint, name, logPath string, cfg *shared.Config, dockerCli deployDockerAPI) error {
+	out := filepath.Join(bp.WorkingDir, siteDir)
+	// A directory of that name in the repository must not pass for a build.
+	if err := os.RemoveAll(out); err != nil {
+		return fmt.Errorf("failed to clear site directory: %w", err)
+	}
+
+	if bp.Source == store.SourceImage {
+		if bp.StaticDir == "" {
+			return fmt.Errorf("static sites from an image need the directory to serve set in static_dir")
+		}
+		// The container is only a handle on the image's filesystem and is
+		// never started, so its command does not need to exist.
+		cc := staticContainer(bp, name, bp.Image, []string{"true"}, cfg)
+		id, remove, err := createContainer(ctx, cc, dockerCli)
+		if err != nil {
+			return err
+		}
+		defer remove()
+
+		shared.LogInfoF(name, logPath, "copying site out of image")
+		return copyDirOut(ctx, id, ResolveStaticDir("/", bp.StaticDir), out, dockerCli)
+	}
+
+	if bp.BuildCmd == "" {
+		return nil
+	}
+
+	shared.LogInfoF(name, logPath, "resolving runtime version")
+	res, err := resolveRuntime(string(bp.Runtime.Type), bp.Runtime.Version)
+	if err != nil {
+		return fmt.Errorf("unsupported runtime or version: %w", err)
+	}
+	if res.Warning != "" {
+		shared.LogWarnF(name, logPath, res.Warning)
+	}
+	if err := PullImage(ctx, res.BuilderImage, cfg, dockerCli); err != nil {
+		return fmt.Errorf("failed to pull builder image: %w", err)
+	}
+
+	cc := staticContainer(bp, name, res.BuilderImage, []string{"/bin/sh", "-c", bp.BuildCmd}, cfg)
+	cc.WorkDir = "/app"
+	id, remove, err := createContainer(ctx, cc, dockerCli)
+	if err != nil {
+		return err
+	}
+	defer remove()
+
+	src, err := sourceTar(bp.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to archive source: %w", err)
+	}
+	defer src.Close()
+	if err := dockerCli.CopyToContainer(ctx, id, "/", src, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy source into build container: %w", err)
+	}
+
+	shared.LogInfoF(name, logPath, fmt.Sprintf("building site with %s", res.BuilderImage))
+	var output io.Writer = io.Discard
+	if f, err := os.OpenFile(filepath.Join(logPath, strings.ToLower(name)+".log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+		defer f.Close()
+		output = f
+	}
+	code, err := runToExit(ctx, id, output, output, remove, dockerCli)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("build command exited with code %d", code)
+	}
+
+	shared.LogInfoF(name, logPath, "copying site out of build container")
+	return copyDirOut(ctx, id, ResolveStaticDir("/app", bp.StaticDir), out, dockerCli)
+}
+
+// staticContainer describes the short-lived container a static site is
+// built in or copied out of. It carries the run label, so one left behind
+// by a crash is removed at the next start.
+func staticContainer(bp store.Blueprint, name, img string, cmd []string, cfg *shared.Config) *ContainerConfig {
+	port := bp.Port
+	if port == 0 {
+		port = 3000
+	}
+	cc := &ContainerConfig{
+		Name:        coreutils.FormatName(name) + "-site-" + strings.ToLower(ulid.Make().String()),
+		RunOf:       coreutils.FormatName(name),
+		Image:       img,
+		Env:         buildEnv(bp, port),
+		Description: fmt.Sprintf("static site build of %s", name),
+		Type:        bp.Type,
+		Cmd:         cmd,
+		ClusterID:   bp.ClusterID,
+	}
+	cc.setResources(bp, cfg)
+	return cc
+}
+
+// createContainer creates cc and returns its ID with a func that removes
+// it, which works after ctx has ended.
+func createContainer(ctx context.Context, cc *ContainerConfig, dockerCli deployDockerAPI) (string, func(), error) {
+	resp, err := dockerCli.ContainerCreate(ctx, ptr(cc.ContainerCfg()), ptr(cc.HostCfg()), nil, nil, cc.Name)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		return "", nil, fmt.Errorf("docker create failed: %w", err)
+	}
+	remove := func() {
+		dockerCli.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true}) //nolint:errcheck
+	}
+	return resp.ID, remove, nil
+}
+
+// sourceTar archives dir as the directory app, to be copied to the root of
+// a build container. The git history and any earlier build are left out.
+func sourceTar(dir string) (io.ReadCloser, error) {
+	dir = filepath.Clean(dir)
+	base := filepath.Base(dir)
+	return archive.TarWithOptions(filepath.Dir(dir), &archive.TarOptions{
+		IncludeFiles:    []string{base},
+		RebaseNames:     map[string]string{base: "app"},
+		ExcludePatterns: []string{base + "/.git", base + "/" + siteDir},
+	})
+}
+
+// copyDirOut copies the directory src out of container id to dst, which
+// must not exist. Files are unpacked beside dst and renamed into place, so
+// a failed copy leaves nothing behind.
+func copyDirOut(ctx context.Context, id, src, dst string, dockerCli deployDockerAPI) error {
+	rc, stat, err := dockerCli.CopyFromContainer(ctx, id, src)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s from container: %w", src, err)
+	}
+	defer rc.Close()
+	if !stat.Mode.IsDir() {
+		return fmt.Errorf("%s is not a directory", src)
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dst), siteDir+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	// The files belong to the node's user, not whichever one the container
+	// wrote them as.
+	if err := archive.Untar(rc, tmp, &archive.TarOptions{NoLchown: true}); err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", src, err)
+	}
+	return os.Rename(filepath.Join(tmp, path.Base(src)), dst)
+}
@@ -0,0 +1,237 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/dployr-io/dployr/internal/version_resolver"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// staticFake plays a container whose build exits with code and whose
+// filesystem holds files, keyed by absolute path.
+type staticFake struct {
+	*fakeDocker
+	code  int64
+	files map[string]string
+
+	config *container.Config
+	copied []string // names in the archive copied into the container
+	pulled []string
+	asked  string // path copied out of the container
+}
+
+func (f *staticFake) ContainerCreate(ctx context.Context, cfg *container.Config, hc *container.HostConfig, nc *network.NetworkingConfig, p *specs.Platform, name string) (container.CreateResponse, error) {
+	f.config = cfg
+	return f.fakeDocker.ContainerCreate(ctx, cfg, hc, nc, p, name)
+}
+
+func (f *staticFake) ImagePull(_ context.Context, ref string, _ image.PullOptions) (io.ReadCloser, error) {
+	f.pulled = append(f.pulled, ref)
+	return io.NopCloser(bytes.NewReader(nil)), nil
+}
+
+func (f *staticFake) ContainerWait(context.Context, string, container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	res := make(chan container.WaitResponse, 1)
+	res <- container.WaitResponse{StatusCode: f.code}
+	return res, make(chan error)
+}
+
+func (f *staticFake) CopyToContainer(_ context.Context, _, _ string, content io.Reader, _ container.CopyToContainerOptions) error {
+	tr := tar.NewReader(content)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.copied = append(f.copied, h.Name)
+	}
+}
+
+func (f *staticFake) CopyFromContainer(_ context.Context, _, src string) (io.ReadCloser, container.PathStat, error) {
+	f.asked = src
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	base := path.Base(src)
+	tw.WriteHeader(&tar.Header{Name: base + "/", Typeflag: tar.TypeDir, Mode: 0755})
+	for name, body := range f.files {
+		if rel, ok := stripDir(name, src); ok {
+			tw.WriteHeader(&tar.Header{Name: base + "/" + rel, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(body))})
+			tw.Write([]byte(body))
+		}
+	}
+	tw.Close()
+	return io.NopCloser(&buf), container.PathStat{Name: base, Mode: os.ModeDir | 0755}, nil
+}
+
+func stripDir(name, dir string) (string, bool) {
+	prefix := dir + "/"
+	if len(name) > len(prefix) && name[:len(prefix)] == prefix {
+		return name[len(prefix):], true
+	}
+	return "", false
+}
+
+func stubResolver(t *testing.T) {
+	t.Helper()
+	orig := resolveRuntime
+	resolveRuntime = func(runtime, version string) (version_resolver.Resolution, error) {
+		return version_resolver.Resolution{BuilderImage: "node:22-bookworm", Version: "22"}, nil
+	}
+	t.Cleanup(func() { resolveRuntime = orig })
+}
+
+func TestDeployStatic_BuildCmd(t *testing.T) {
+	stubResolver(t)
+	dir := filepath.Join(t.TempDir(), "01A")
+	for _, p := range []string{"package.json", "src/main.js", ".git/HEAD", siteDir + "/stale.html"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0755)
+		os.WriteFile(filepath.Join(dir, p), []byte(p), 0644)
+	}
+
+	f := &staticFake{fakeDocker: newFakeDocker(), files: map[string]string{"/app/dist/index.html": "<h1>built</h1>"}}
+	bp := store.Blueprint{
+		Name:       "docs",
+		Type:       store.TypeStatic,
+		Source:     store.SourceRemote,
+		Runtime:    store.RuntimeObj{Type: store.RuntimeNodeJS, Version: "22"},
+		BuildCmd:   "npm ci && npm run build",
+		StaticDir:  "dist",
+		WorkingDir: dir,
+	}
+	if err := DeployApp(context.Background(), bp, "docs", t.TempDir(), nil, nil, f); err != nil {
+		t.Fatalf("DeployApp: %v", err)
+	}
+
+	if !slices.Equal(f.pulled, []string{"node:22-bookworm"}) || f.config.Image != "node:22-bookworm" {
+		t.Errorf("pulled %v, ran %q; want the resolved builder image", f.pulled, f.config.Image)
+	}
+	if !slices.Equal(f.config.Cmd, []string{"/bin/sh", "-c", bp.BuildCmd}) || f.config.WorkingDir != "/app" {
+		t.Errorf("Cmd = %v in %q", f.config.Cmd, f.config.WorkingDir)
+	}
+	for _, want := range []string{"app/package.json", "app/src/main.js"} {
+		if !slices.Contains(f.copied, want) {
+			t.Errorf("source archive %v lacks %s", f.copied, want)
+		}
+	}
+	for _, name := range f.copied {
+		if name == "app/.git/HEAD" || name == "app/"+siteDir+"/stale.html" {
+			t.Errorf("source archive holds %s", name)
+		}
+	}
+	if f.asked != "/app/dist" {
+		t.Errorf("copied out %q, want /app/dist", f.asked)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, siteDir, "index.html")); string(got) != "<h1>built</h1>" {
+		t.Errorf("site index = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, siteDir, "stale.html")); !os.IsNotExist(err) {
+		t.Error("site directory from the repository survived the build")
+	}
+	if len(f.removed) != 1 || f.removed[0] != "id-"+f.created[0] {
+		t.Errorf("removed %v, want the build container %v", f.removed, f.created)
+	}
+}
+
+func TestDeployStatic_BuildFails(t *testing.T) {
+	stubResolver(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644)
+
+	f := &staticFake{fakeDocker: newFakeDocker(), code: 1, files: map[string]string{"/app/dist/index.html": "x"}}
+	bp := store.Blueprint{Name: "docs", Type: store.TypeStatic, Source: store.SourceRemote, BuildCmd: "npm run build", StaticDir: "dist", WorkingDir: dir}
+	if err := DeployApp(context.Background(), bp, "docs", t.TempDir(), nil, nil, f); err == nil {
+		t.Fatal("a failed build deployed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, siteDir)); !os.IsNotExist(err) {
+		t.Error("a failed build left a site directory")
+	}
+	if len(f.removed) != 1 {
+		t.Errorf("removed %v, want the build container", f.removed)
+	}
+}
+
+func TestDeployStatic_Image(t *testing.T) {
+	dir := t.TempDir()
+	f := &staticFake{fakeDocker: newFakeDocker(), files: map[string]string{
+		"/usr/share/site/index.html":     "home",
+		"/usr/share/site/css/site.css":   "body{}",
+		"/usr/share/nginx/nginx.conf":    "not served",
+		"/usr/share/site-old/index.html": "not served",
+	}}
+	bp := store.Blueprint{Name: "docs", Type: store.TypeStatic, Source: store.SourceImage, Image: "registry.local/apps:docs-1", StaticDir: "/usr/share/site", WorkingDir: dir}
+	if err := DeployApp(context.Background(), bp, "docs", t.TempDir(), nil, nil, f); err != nil {
+		t.Fatalf("DeployApp: %v", err)
+	}
+
+	if f.config.Image != bp.Image || len(f.pulled) != 0 {
+		t.Errorf("container of %q after pulling %v; want the deployment's image, already pulled", f.config.Image, f.pulled)
+	}
+	for name, want := range map[string]string{"index.html": "home", "css/site.css": "body{}"} {
+		if got, _ := os.ReadFile(filepath.Join(dir, siteDir, name)); string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, siteDir))
+	if len(entries) != 2 {
+		t.Errorf("site holds %d entries, want index.html and css", len(entries))
+	}
+	if len(f.removed) != 1 {
+		t.Errorf("removed %v, want the image container", f.removed)
+	}
+
+	bp.StaticDir = ""
+	if err := DeployApp(context.Background(), bp, "docs", t.TempDir(), nil, nil, f); err == nil {
+		t.Error("an image site without a static dir deployed")
+	}
+}
+
+func TestDeployStatic_NoBuildCmd(t *testing.T) {
+	dir := t.TempDir()
+	f := &staticFake{fakeDocker: newFakeDocker()}
+	bp := store.Blueprint{Name: "docs", Type: store.TypeStatic, Source: store.SourceRemote, WorkingDir: dir}
+	if err := DeployApp(context.Background(), bp, "docs", t.TempDir(), nil, nil, f); err != nil {
+		t.Fatalf("DeployApp: %v", err)
+	}
+	if len(f.created) != 0 {
+		t.Errorf("created %v; a site without a build is served as cloned", f.created)
+	}
+}
+
+func TestStaticRoot(t *testing.T) {
+	live := t.TempDir()
+
+	svc := &store.Service{Name: "docs", Source: store.SourceRemote, WorkingDir: "web", StaticDir: "public"}
+	if got, want := staticRoot(live, svc), filepath.Join(live, "web", "public"); got != want {
+		t.Errorf("unbuilt root = %q, want %q", got, want)
+	}
+
+	os.MkdirAll(filepath.Join(live, "web", siteDir), 0755)
+	if got, want := staticRoot(live, svc), filepath.Join(live, "web", siteDir); got != want {
+		t.Errorf("built root = %q, want %q", got, want)
+	}
+
+	svc = &store.Service{Name: "docs", Source: store.SourceImage, WorkingDir: "web", StaticDir: "/usr/share/site"}
+	os.MkdirAll(filepath.Join(live, siteDir), 0755)
+	if got, want := staticRoot(live, svc), filepath.Join(live, siteDir); got != want {
+		t.Errorf("image root = %q, want %q", got, want)
+	}
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 
-	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
 	"github.com/dployr-io/dployr/pkg/shared"
 	"github.com/dployr-io/dployr/pkg/store"
 
@@ -40,16 +39,25 @@ type deployDockerAPI interface {
 	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.CreateResponse, error)
 	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
 	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerRename(ctx context.Context, containerID, newContainerName string) error
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+	CopyToContainer(ctx context.Context, containerID, dstPath string, content io.Reader, options container.CopyToContainerOptions) error
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, container.PathStat, error)
 	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
 	ImageBuild(ctx context.Context, buildContext io.Reader, options dockertypes.ImageBuildOptions) (dockertypes.ImageBuildResponse, error)
 	ImagePush(ctx context.Context, image string, options image.PushOptions) (io.ReadCloser, error)
 	ImageRemove(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error)
+	ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error)
+	DistributionInspect(ctx context.Context, imageRef, encodedRegistryAuth string) (registry.DistributionInspect, error)
+	ContainerUpdate(ctx context.Context, containerID string, updateConfig container.UpdateConfig) (container.ContainerUpdateOKBody, error)
 }
 
-// imageRef constructs a uniqe docker image ref
-func imageRef(registryURL, name string) string {
+// imageRef constructs the docker image ref for a build of name whose inputs
+// hash to hash, so identical builds land on the same tag.
+func imageRef(registryURL, name, hash string) string {
 	slug := strings.ToLower(strings.ReplaceAll(name, "_", "-"))
-	tag := fmt.Sprintf("%s-%d", slug, time.Now().UnixMilli())
+	tag := fmt.Sprintf("%s-%s", slug, hash[:16])
 	return fmt.Sprintf("%s/apps:%s", strings.TrimRight(registryURL, "/"), tag)
 }
 
@@ -63,6 +71,7 @@ type BuildOpts struct {
 	Port         int
 	IsNextJS     bool
 	Env          map[string]string
+	ForceRebuild bool // skip the registry lookup and the local layer cache
 }
 
 // detectNextJS returns true if the directory looks like a Next.js project —
@@ -120,56 +129,58 @@ func writeLogEntry(f *os.File, level, msg string) {
 	f.Write(b) //nolint:errcheck
 }
 
-func BuildImage(name, srcDir string, cfg *shared.Config, opts BuildOpts, dockerCli deployDockerAPI, svcName, logDir string) (string, error) {
+// BuildImage builds srcDir and pushes the image, returning its ref. The tag
+// is a hash of the build inputs; when the registry already has it the build
+// is skipped and cached is true. Built images are kept as layer cache up to
+// BUILD_CACHE_SIZE. opts.ForceRebuild bypasses both.
+func BuildImage(ctx context.Context, name, srcDir string, cfg *shared.Config, opts BuildOpts, dockerCli deployDockerAPI, svcName, logDir string) (ref string, cached bool, err error) {
 	if cfg.RegistryURL == "" {
-		return "", fmt.Errorf("REGISTRY_URL is not configured on this build node")
+		return "", false, fmt.Errorf("REGISTRY_URL is not configured on this build node")
 	}
 
-	ref := imageRef(cfg.RegistryURL, name)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Minute)
 	defer cancel()
 
 	if err := ensureDockerfile(srcDir, opts); err != nil {
-		return "", fmt.Errorf("dockerfile setup failed: %w", err)
+		return "", false, fmt.Errorf("dockerfile setup failed: %w", err)
 	}
 
-	// Build tar context from srcDir
-	var excludes []string
-	for line := range strings.SplitSeq(DockerIgnoreContent, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") || line == "Dockerfile" || line == ".dockerignore" {
-			continue
+	excludes := buildExcludes()
+	hash, err := buildHash(srcDir, excludes, opts)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to hash build context: %w", err)
+	}
+	ref = imageRef(cfg.RegistryURL, name, hash)
+
+	var authStr string
+	if cfg.RegistryAuth != "" {
+		authStr, err = buildRegistryAuth(cfg.RegistryAuth, ref)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to build registry auth for %s: %w", ref, err)
 		}
-		excludes = append(excludes, line)
 	}
+
+	if !opts.ForceRebuild && imageInRegistry(ctx, ref, authStr, dockerCli) {
+		return ref, true, nil
+	}
+
 	buildCtx, err := archive.TarWithOptions(srcDir, &archive.TarOptions{ExcludePatterns: excludes})
 	if err != nil {
-		return "", fmt.Errorf("failed to create build context: %w", err)
+		return "", false, fmt.Errorf("failed to create build context: %w", err)
 	}
 
 	buildOpts := dockertypes.ImageBuildOptions{
 		Tags:       []string{ref},
 		Dockerfile: "Dockerfile",
 		Remove:     true,
-	}
-	for k, v := range opts.Env {
-		if strings.HasPrefix(k, "NEXT_PUBLIC_") {
-			if buildOpts.BuildArgs == nil {
-				buildOpts.BuildArgs = map[string]*string{}
-			}
-			val := v
-			buildOpts.BuildArgs[k] = &val
-		}
+		BuildArgs:  buildArgs(opts.Env),
+		NoCache:    opts.ForceRebuild,
+		PullParent: opts.ForceRebuild,
 	}
 	if cfg.BuildMemory > 0 {
 		buildOpts.Memory = int64(cfg.BuildMemory) * 1024 * 1024
 	}
-	if cfg.RegistryAuth != "" {
-		authStr, err := buildRegistryAuth(cfg.RegistryAuth, ref)
-		if err != nil {
-			return "", fmt.Errorf("failed to build registry auth for %s: %w", ref, err)
-		}
+	if authStr != "" {
 		registryHost := strings.SplitN(ref, "/", 2)[0]
 		buildOpts.AuthConfigs = map[string]registry.AuthConfig{
 			registryHost: parseAuthConfig(authStr),
@@ -179,9 +190,9 @@ func BuildImage(name, srcDir string, cfg *shared.Config, opts BuildOpts, dockerC
 	buildResp, err := dockerCli.ImageBuild(ctx, buildCtx, buildOpts)
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("docker build timed out after 20 minutes")
+			return "", false, fmt.Errorf("docker build timed out after 20 minutes")
 		}
-		return "", fmt.Errorf("docker build failed: %w", err)
+		return "", false, fmt.Errorf("docker build failed: %w", err)
 	}
 	defer buildResp.Body.Close()
 
@@ -208,29 +219,22 @@ func BuildImage(name, srcDir string, cfg *shared.Config, opts BuildOpts, dockerC
 			continue
 		}
 		if msg.Error != "" {
-			return "", fmt.Errorf("docker build: %s", strings.TrimSpace(msg.Error))
+			return "", false, fmt.Errorf("docker build: %s", strings.TrimSpace(msg.Error))
 		}
 		if line := normalizeDockerLine(msg.Stream); line != "" && logFile != nil {
 			writeLogEntry(logFile, "INFO", line)
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("docker build stream error: %w", err)
+		return "", false, fmt.Errorf("docker build stream error: %w", err)
 	}
 
-	var authStr string
-	if cfg.RegistryAuth != "" {
-		authStr, err = buildRegistryAuth(cfg.RegistryAuth, ref)
-		if err != nil {
-			return "", fmt.Errorf("failed to build registry auth for push %s: %w", ref, err)
-		}
-	}
 	pushRC, err := dockerCli.ImagePush(ctx, ref, image.PushOptions{RegistryAuth: authStr})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("docker push timed out")
+			return "", false, fmt.Errorf("docker push timed out")
 		}
-		return "", fmt.Errorf("docker push failed: %w", err)
+		return "", false, fmt.Errorf("docker push failed: %w", err)
 	}
 	defer pushRC.Close()
 	pushScanner := bufio.NewScanner(pushRC)
@@ -239,19 +243,19 @@ func BuildImage(name, srcDir string, cfg *shared.Config, opts BuildOpts, dockerC
 			Error string `json:"error"`
 		}
 		if json.Unmarshal(pushScanner.Bytes(), &msg) == nil && msg.Error != "" {
-			return "", fmt.Errorf("docker push failed: %s", strings.TrimSpace(msg.Error))
+			return "", false, fmt.Errorf("docker push failed: %s", strings.TrimSpace(msg.Error))
 		}
 	}
 	if err := pushScanner.Err(); err != nil {
-		return "", fmt.Errorf("docker push stream error: %w", err)
+		return "", false, fmt.Errorf("docker push stream error: %w", err)
 	}
 
-	if _, err := dockerCli.ImageRemove(ctx, ref, image.RemoveOptions{Force: true}); err != nil {
-		// Non-fatal: the image was pushed successfully; log and continue.
-		_ = err
+	if err := pruneBuildCache(ctx, cfg, ref, dockerCli); err != nil && logFile != nil {
+		// Non-fatal: the image was pushed successfully.
+		writeLogEntry(logFile, "WARN", err.Error())
 	}
 
-	return ref, nil
+	return ref, false, nil
 }
 
 // ensureDockerfile writes a generated Dockerfile unless the repo already ships one.
@@ -693,79 +697,18 @@ lerna-debug.log*
 .yarn/install-state.gz
 `
 
-// SetupDir creates a working directory for the deployment
-func SetupDir(name string) (string, error) {
-	dataDir := coreutils.GetDataDir()
-	workDir := filepath.Join(dataDir, ".dployr", "services", coreutils.FormatName(name))
-	err := os.MkdirAll(workDir, 0755)
-	if err != nil {
-		return "", err
-	}
-
-	return workDir, nil
-}
-
-// CloneRepo clones a git repository to the specified directory
-func CloneRepo(remote store.RemoteObj, destDir string, config *shared.Config) error {
-	authUrl, err := buildAuthUrl(remote.Url, remote.Token)
-	if err != nil {
-		return err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	// Always do a fresh clone into a clean directory. Re-using an existing
-	// working directory via git pull is fragile (detached HEAD, partial state
-	// from a failed previous build), so wipe and re-clone every time.
-	if err := os.RemoveAll(destDir); err != nil {
-		return fmt.Errorf("failed to clean destination directory: %s", err)
-	}
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create destination directory: %s", err)
-	}
-
-	cloneCmd := fmt.Sprintf(
-		"GIT_TERMINAL_PROMPT=0 git clone --depth 1 --branch %s %s .",
-		remote.Branch, authUrl,
-	)
-	if err := shared.Exec(ctx, cloneCmd, destDir); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("git clone timed out after 5 minutes")
-		}
-		return fmt.Errorf("git clone failed: %s", err)
-	}
-
-	if remote.CommitHash != "" {
-		checkoutCmd := fmt.Sprintf("git -C %s checkout %s", destDir, remote.CommitHash)
-		if err := shared.Exec(ctx, checkoutCmd, "."); err != nil {
-			if ctx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("git checkout timed out after 5 minutes")
-			}
-			return fmt.Errorf("git checkout failed: %s", err)
-		}
-	}
-
-	// Write .dockerignore to exclude build artifacts and dependencies
-	if err := writeDockerIgnore(destDir); err != nil {
-		return fmt.Errorf("failed to write .dockerignore: %s", err)
-	}
-
-	return nil
-}
-
 func writeDockerIgnore(destDir string) error {
 	dockerIgnorePath := filepath.Join(destDir, ".dockerignore")
 	return os.WriteFile(dockerIgnorePath, []byte(DockerIgnoreContent), 0644)
 }
 
 // PullImage pulls a docker image from a registry using the Docker SDK.
-func PullImage(imageRef string, config *shared.Config, dockerCli deployDockerAPI) error {
+func PullImage(ctx context.Context, imageRef string, config *shared.Config, dockerCli deployDockerAPI) error {
 	if !isValidDockerImageRef(imageRef) {
 		return fmt.Errorf("invalid docker image reference: %s", imageRef)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
 	var authStr string
@@ -793,15 +736,17 @@ func PullImage(imageRef string, config *shared.Config, dockerCli deployDockerAPI
 
 // DeployApp handles runtime setup, build, and service installation.
 // Jobs (TypeJob) use the systemd/vfox bash path; all other types use the Go
-// Docker path which avoids the bash script entirely.
-func DeployApp(bp store.Blueprint, name, logPath string, cfg *shared.Config, dockerCli deployDockerAPI) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+// Docker path which avoids the bash script entirely. hostPorts holds the
+// host port reserved for each replica; replicas without one use their
+// hashed port.
+func DeployApp(ctx context.Context, bp store.Blueprint, name, logPath string, hostPorts []int, cfg *shared.Config, dockerCli deployDockerAPI) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Minute)
 	defer cancel()
 
 	if bp.Type == store.TypeStatic {
-		// No container needed — the host Caddy proxy serves files directly.
+		// Nothing keeps running — the host Caddy proxy serves files directly.
 		// registerProxyRoute in the worker registers the static.tpl route.
-		return nil
+		return deployStatic(ctx, bp, name, logPath, cfg, dockerCli)
 	}
 
 	if bp.Type == store.TypeJob {
@@ -809,15 +754,15 @@ func DeployApp(bp store.Blueprint, name, logPath string, cfg *shared.Config, doc
 		if version == "" {
 			return fmt.Errorf("runtime version cannot be empty")
 		}
-		return runDeployScript(ctx, bp, name, logPath, cfg)
+		return runDeployScript(ctx, bp, name, logPath, replicaHostPort(hostPorts, name, 0), cfg)
 	}
 
-	return deployDocker(ctx, bp, name, logPath, cfg, dockerCli)
+	return deployDocker(ctx, bp, name, logPath, hostPorts, cfg, dockerCli)
 }
 
 // deployDocker uses the Docker SDK to deploy web, worker, and static service
 // types without spawning any shell processes.
-func deployDocker(ctx context.Context, bp store.Blueprint, name, logPath string, cfg *shared.Config, dockerCli deployDockerAPI) error {
+func deployDocker(ctx context.Context, bp store.Blueprint, name, logPath string, hostPorts []int, cfg *shared.Config, dockerCli deployDockerAPI) error {
 	port := bp.Port
 	if port == 0 {
 		port = 3000
@@ -825,52 +770,24 @@ func deployDocker(ctx context.Context, bp store.Blueprint, name, logPath string,
 
 	// Write .env to disk so the process can source it at runtime (TypeJob reads it;
 	// Docker containers also receive env vars inline via ContainerConfig.Env).
+	// Secrets are only ever passed inline.
 	if err := WriteEnvFile(bp.WorkingDir, bp, port); err != nil {
 		return fmt.Errorf("failed to write env file: %w", err)
 	}
 
 	if bp.Image != "" {
-		if err := PullImage(bp.Image, cfg, dockerCli); err != nil {
+		if err := PullImage(ctx, bp.Image, cfg, dockerCli); err != nil {
 			return fmt.Errorf("failed to pull image: %w", err)
 		}
 	}
 
-	cc := &ContainerConfig{
-		Name:        name,
-		Image:       bp.Image,
-		Port:        port,
-		HostPort:    coreutils.ComputeHostPort(name),
-		Env:         buildEnv(bp, port),
-		Description: bp.Desc,
-		Type:        bp.Type,
-		RunCmd:      bp.RunCmd,
-		ClusterID:   bp.ClusterID,
-	}
-	if cfg != nil {
-		cc.Memory = cfg.ContainerMemory
-		cc.CPU = cfg.ContainerCPU
-		cc.Storage = cfg.ContainerStorage
-	}
-
-	// Remove any pre-existing container with the same name (best-effort).
-	dockerCli.ContainerRemove(ctx, name, container.RemoveOptions{Force: true}) //nolint:errcheck
-
-	resp, err := dockerCli.ContainerCreate(ctx, ptr(cc.ContainerCfg()), ptr(cc.HostCfg()), nil, nil, name)
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("docker create timed out")
-		}
-		return fmt.Errorf("docker create failed: %w", err)
-	}
-
-	if err := dockerCli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("docker start timed out")
-		}
-		return fmt.Errorf("docker start failed: %w", err)
+	n := replicaCount(bp)
+	if err := startReplicas(ctx, bp, name, logPath, 0, n, port, hostPorts, cfg, dockerCli); err != nil {
+		return err
 	}
 
-	shared.LogInfoF(name, logPath, fmt.Sprintf("container started: %s", resp.ID))
+	// Replicas left over from a deployment that ran more of them.
+	removeReplicas(ctx, name, logPath, n, store.MaxReplicas, dockerCli)
 	return nil
 }
 
@@ -898,7 +815,7 @@ func buildEnv(bp store.Blueprint, port int) []string {
 
 func ptr[T any](v T) *T { return &v }
 
-func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath string, cfg *shared.Config) error {
+func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath string, hostPort int, cfg *shared.Config) error {
 	if runtime.GOOS == "windows" {
 		return fmt.Errorf("unified deployment script not yet supported on Windows")
 	}
@@ -911,6 +828,10 @@ func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath stri
 	if err := writeServiceConfig(bp); err != nil {
 		return fmt.Errorf("failed to write service config: %v", err)
 	}
+	secrets, err := secretsEnv(bp)
+	if err != nil {
+		return err
+	}
 
 	tmpFile, err := os.CreateTemp("", "deploy_app*.sh")
 	if err != nil {
@@ -935,11 +856,9 @@ func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath stri
 		port = "3000"
 	}
 
-	memory, cpu, storage, buildMemory := 0, 0, 0, 0
+	limits := effectiveResources(bp.Resources, cfg)
+	memory, cpu, storage, buildMemory := limits.MemoryMB, limits.CPU, limits.StorageGB, 0
 	if cfg != nil {
-		memory = cfg.ContainerMemory
-		cpu = cfg.ContainerCPU
-		storage = cfg.ContainerStorage
 		buildMemory = cfg.BuildMemory
 	}
 
@@ -956,7 +875,7 @@ func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath stri
 		desc,
 		buildCmd,
 		port,
-		strconv.Itoa(coreutils.ComputeHostPort(bp.Name)),
+		strconv.Itoa(hostPort),
 		bp.Image,
 		bp.StaticDir,
 		strconv.Itoa(memory),
@@ -964,10 +883,13 @@ func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath stri
 		strconv.Itoa(storage),
 		strconv.Itoa(buildMemory),
 		bp.ClusterID,
+		bp.Schedule,
 	}
 
 	cmd := exec.CommandContext(ctx, "bash", args...)
+	shared.KillGroupOnCancel(cmd)
 	cmd.Env = append(os.Environ(), fmt.Sprintf("HOME=%s", os.Getenv("HOME")))
+	cmd.Env = append(cmd.Env, secrets...)
 
 	// Capture stdout and stderr to deployment log
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -1027,35 +949,43 @@ func runDeployScript(ctx context.Context, bp store.Blueprint, name, logPath stri
 	return nil
 }
 
+// writeServiceConfig writes the env vars of bp to <workdir>/config.toml for
+// the deploy script. Secrets are left out; see secretsEnv.
 func writeServiceConfig(bp store.Blueprint) error {
 	if err := os.MkdirAll(bp.WorkingDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %v", err)
 	}
 
-	env := bp.EnvVars
-	if env == nil {
-		env = make(map[string]string)
-	}
-	secrets := bp.Secrets
-	if secrets == nil {
-		secrets = make(map[string]string)
-	}
-
 	var b strings.Builder
 
 	b.WriteString("[env]\n")
-	for k, v := range env {
-		fmt.Fprintf(&b, "%s = %q\n", k, v)
-	}
-
-	b.WriteString("\n[secrets]\n")
-	for k, v := range secrets {
+	for k, v := range bp.EnvVars {
 		fmt.Fprintf(&b, "%s = %q\n", k, v)
 	}
 
 	return os.WriteFile(filepath.Join(bp.WorkingDir, "config.toml"), []byte(b.String()), 0600)
 }
 
+// secretsEnv hands the secrets of bp to the deploy script in its environment,
+// each as DPLOYR_SECRET_<KEY>, so they never touch the workdir. The script
+// seals them into a systemd credential that is only decrypted when the unit
+// starts. A name bash cannot hold in a variable is rejected rather than
+// dropped.
+func secretsEnv(bp store.Blueprint) ([]string, error) {
+	env := make([]string, 0, len(bp.Secrets))
+	for k, v := range bp.Secrets {
+		if !envName.MatchString(k) {
+			return nil, fmt.Errorf("invalid secret name %q: use letters, digits and underscores", k)
+		}
+		env = append(env, secretEnvPrefix+k+"="+v)
+	}
+	return env, nil
+}
+
+const secretEnvPrefix = "DPLOYR_SECRET_"
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func buildAuthUrl(url, token string) (string, error) {
 	if strings.Contains(url, "@") {
 		return url, nil // credentials already embedded
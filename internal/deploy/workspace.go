@@ -0,0 +1,126 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/oklog/ulid/v2"
+
+	coreutils "github.com/dployr-io/dployr/pkg/core/utils"
+)
+
+// WorkspaceDir returns the path a service's live workspace is reached
+// through: a symlink to the workspace of the deployment now running.
+func WorkspaceDir(name string) string {
+	return filepath.Join(coreutils.GetDataDir(), ".dployr", "services", coreutils.FormatName(name))
+}
+
+// workspacesDir holds the workspaces of every deployment and build of a
+// service, one directory per run.
+func workspacesDir(name string) string {
+	return filepath.Join(coreutils.GetDataDir(), ".dployr", "workspaces", coreutils.FormatName(name))
+}
+
+// SetupDir creates a fresh workspace for one run of a deployment or build.
+// Each run gets its own, named by a new ULID rather than the deployment's
+// ID, which a service keeps across redeploys: a redeploy never clones into
+// the tree the live version is served from.
+func SetupDir(name string) (string, error) {
+	return setupDir(workspacesDir(name))
+}
+
+// setupDir is SetupDir with the service's workspaces under root.
+func setupDir(root string) (string, error) {
+	workDir := filepath.Join(root, ulid.Make().String())
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return "", err
+	}
+	return workDir, nil
+}
+
+// ActivateWorkspace makes dir the live workspace of a service and removes
+// the workspaces of older deployments.
+func ActivateWorkspace(name, dir string) error {
+	return activateWorkspace(WorkspaceDir(name), workspacesDir(name), dir)
+}
+
+// activateWorkspace is ActivateWorkspace with the live link at link and the
+// service's workspaces under root.
+func activateWorkspace(link, root, dir string) error {
+	if err := swapLink(link, dir); err != nil {
+		return fmt.Errorf("failed to activate workspace: %w", err)
+	}
+	return pruneWorkspaces(root, filepath.Base(dir))
+}
+
+// RemoveWorkspace removes dir, the workspace of a run that did not go live.
+// It refuses to remove the workspace the service is served from, in case
+// the run was stopped after activating it.
+func RemoveWorkspace(name, dir string) error {
+	return removeWorkspace(WorkspaceDir(name), dir)
+}
+
+// removeWorkspace is RemoveWorkspace with the live link at link.
+func removeWorkspace(link, dir string) error {
+	if live, err := os.Readlink(link); err == nil && filepath.Clean(live) == filepath.Clean(dir) {
+		return fmt.Errorf("%s is the live workspace", dir)
+	}
+	return os.RemoveAll(dir)
+}
+
+// swapLink points link at dir. The new symlink is renamed over the old one,
+// so a reader such as Caddy serving a static site sees either tree whole.
+// A plain directory at link, left from before workspaces were per build, is
+// moved aside first and removed.
+func swapLink(link, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return err
+	}
+	tmp := link + ".next"
+	os.Remove(tmp) //nolint:errcheck
+	if err := os.Symlink(dir, tmp); err != nil {
+		return err
+	}
+
+	var legacy string
+	if fi, err := os.Lstat(link); err == nil && fi.Mode()&os.ModeSymlink == 0 {
+		legacy = link + ".old"
+		if err := os.Rename(link, legacy); err != nil {
+			os.Remove(tmp) //nolint:errcheck
+			return err
+		}
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp) //nolint:errcheck
+		return err
+	}
+	if legacy != "" {
+		return os.RemoveAll(legacy)
+	}
+	return nil
+}
+
+// pruneWorkspaces removes the workspaces under root older than live.
+// Workspace names are ULIDs and sort by creation time; newer ones may belong
+// to builds still running and are left alone.
+func pruneWorkspaces(root, live string) error {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		if e.Name() >= live {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
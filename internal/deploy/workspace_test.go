@@ -0,0 +1,112 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSwapLink(t *testing.T) {
+	root := t.TempDir()
+	link := filepath.Join(root, "services", "site")
+	first := filepath.Join(root, "workspaces", "site", "01A")
+	second := filepath.Join(root, "workspaces", "site", "01B")
+	for _, dir := range []string{first, second} {
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "index.html"), []byte(filepath.Base(dir)), 0644)
+	}
+
+	// A live tree from before workspaces were per build.
+	os.MkdirAll(link, 0755)
+	os.WriteFile(filepath.Join(link, "index.html"), []byte("legacy"), 0644)
+
+	for _, dir := range []string{first, second} {
+		if err := swapLink(link, dir); err != nil {
+			t.Fatalf("swapLink(%s): %v", filepath.Base(dir), err)
+		}
+		got, err := os.ReadFile(filepath.Join(link, "index.html"))
+		if err != nil || string(got) != filepath.Base(dir) {
+			t.Errorf("served %q, %v; want %s", got, err, filepath.Base(dir))
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(link))
+	if len(entries) != 1 {
+		t.Errorf("services dir holds %d entries, want only the link", len(entries))
+	}
+}
+
+func TestPruneWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	for _, id := range []string{"01A", "01B", "01C", "01D"} {
+		os.MkdirAll(filepath.Join(root, id), 0755)
+	}
+
+	if err := pruneWorkspaces(root, "01C"); err != nil {
+		t.Fatalf("pruneWorkspaces: %v", err)
+	}
+
+	var left []string
+	entries, _ := os.ReadDir(root)
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	if len(left) != 2 || left[0] != "01C" || left[1] != "01D" {
+		t.Errorf("left %v, want the live workspace and the newer one", left)
+	}
+}
+
+func TestWorkspace_RedeploySameService(t *testing.T) {
+	root := t.TempDir()
+	link := filepath.Join(root, "services", "site")
+	workspaces := filepath.Join(root, "workspaces", "site")
+
+	deploy := func(body string) string {
+		t.Helper()
+		dir, err := setupDir(workspaces)
+		if err != nil {
+			t.Fatalf("setupDir: %v", err)
+		}
+		os.WriteFile(filepath.Join(dir, "index.html"), []byte(body), 0644)
+		return dir
+	}
+
+	first := deploy("v1")
+	if err := activateWorkspace(link, workspaces, first); err != nil {
+		t.Fatalf("activateWorkspace: %v", err)
+	}
+
+	// The redeploy clones beside the live tree, not into it.
+	second := deploy("v2")
+	if second == first {
+		t.Fatalf("redeploy reused the live workspace %s", first)
+	}
+	if got, _ := os.ReadFile(filepath.Join(link, "index.html")); string(got) != "v1" {
+		t.Errorf("served %q during the redeploy, want v1", got)
+	}
+	if err := removeWorkspace(link, first); err == nil {
+		t.Error("removed the live workspace")
+	}
+
+	if err := activateWorkspace(link, workspaces, second); err != nil {
+		t.Fatalf("activateWorkspace: %v", err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Error("the replaced workspace was not pruned")
+	}
+
+	// A cancelled third run takes only its own workspace with it.
+	third := deploy("v3")
+	if err := removeWorkspace(link, third); err != nil {
+		t.Fatalf("removeWorkspace: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(link, "index.html")); string(got) != "v2" {
+		t.Errorf("served %q after the cancel, want v2", got)
+	}
+	if _, err := os.Stat(third); !os.IsNotExist(err) {
+		t.Error("the cancelled workspace was left behind")
+	}
+}
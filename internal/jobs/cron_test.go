@@ -0,0 +1,75 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Matches(t *testing.T) {
+	// 2025-06-02 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.June, day, hour, minute, 30, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"* * * * *", at(2, 13, 7), true},
+		{"*/15 * * * *", at(2, 13, 45), true},
+		{"*/15 * * * *", at(2, 13, 46), false},
+		{"0 3 * * *", at(2, 3, 0), true},
+		{"0 3 * * *", at(2, 4, 0), false},
+		{"30 9 * * mon-fri", at(2, 9, 30), true},
+		{"30 9 * * mon-fri", at(1, 9, 30), false}, // Sunday
+		{"0 0 * * 7", at(1, 0, 0), true},          // 7 is Sunday
+		{"0 0 1,15 * *", at(15, 0, 0), true},
+		{"0 0 * jun *", at(2, 0, 0), true},
+		{"0 0 * jan-may *", at(2, 0, 0), false},
+		{"10-20/5 * * * *", at(2, 0, 15), true},
+		{"10-20/5 * * * *", at(2, 0, 25), false},
+		{"5/20 * * * *", at(2, 0, 45), true},
+		// Both day fields restricted: either may match.
+		{"0 0 13 * mon", at(2, 0, 0), true},
+		{"0 0 2 * fri", at(2, 0, 0), true},
+		{"0 0 3 * fri", at(2, 0, 0), false},
+		// A stepped wildcard still counts as unrestricted.
+		{"0 0 */2 * mon", at(3, 0, 0), false},
+		{"@hourly", at(2, 8, 0), true},
+		{"@daily", at(2, 0, 1), false},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error: %v", tt.expr, err)
+		}
+		if got := s.Matches(tt.t); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.expr, tt.t.Format("Mon 02 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestParseSchedule_Rejects(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"20-10 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jobs implements the daemon's scheduler for job services deployed
+// with a cron schedule.
+//
+// A scheduled job is installed as a one-shot systemd template unit,
+// <name>@.service, and is not started at deploy time. Each run is a fresh
+// instance <name>@<run-id> writing to its own log, so runs can overlap when
+// the job's concurrency policy allows it. The scheduler records every run in
+// the job_runs table with its trigger, exit code, duration and the tail of
+// its log.
+package jobs
@@ -0,0 +1,328 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/pkg/core/jobs"
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// outputTail bounds how much of a run's log is kept with the run record.
+const outputTail = 8 << 10
+
+// maxJobs bounds how many services one tick looks through for due jobs.
+const maxJobs = 1000
+
+// abortWait bounds how long Drain waits for runs to be recorded once their
+// units are stopped.
+const abortWait = 10 * time.Second
+
+// runner is the part of svc_runtime.ServiceManager the scheduler drives.
+// Start blocks until a one-shot unit exits.
+type runner interface {
+	Start(name string) error
+	Stop(name string) error
+	ExitCode(name string) (int, error)
+}
+
+type activeRun struct {
+	unit      string
+	cancelled bool // stopped by a newer run or at shutdown
+}
+
+// Scheduler launches runs of scheduled jobs. Every minute it looks for job
+// services whose schedule is due and starts an instance of the job's
+// template unit for each, one instance per run.
+type Scheduler struct {
+	logger   *shared.Logger
+	services store.ServiceStore
+	runs     store.JobRunStore
+	mgr      runner
+	logDir   string
+	now      func() time.Time
+
+	mu       sync.Mutex
+	active   map[string]map[string]*activeRun // job name -> run ID -> run
+	draining bool
+	wg       sync.WaitGroup // tracks execute goroutines
+}
+
+func NewScheduler(logger *shared.Logger, services store.ServiceStore, runs store.JobRunStore, mgr runner) *Scheduler {
+	return &Scheduler{
+		logger:   logger,
+		services: services,
+		runs:     runs,
+		mgr:      mgr,
+		logDir:   utils.GetServiceLogDir(),
+		now:      time.Now,
+		active:   make(map[string]map[string]*activeRun),
+	}
+}
+
+// Unit returns the systemd unit that runs one execution of a job.
+func Unit(name, runID string) string {
+	return utils.FormatName(name) + "@" + runID
+}
+
+// Start runs the scheduler until ctx is cancelled. Runs already started keep
+// going under systemd. Schedules are evaluated in the node's local time; a
+// minute missed while the daemon was down is not made up.
+func (s *Scheduler) Start(ctx context.Context) {
+	if n, err := s.runs.FailInterruptedRuns(ctx); err != nil {
+		s.logger.Error("failed to recover interrupted job runs", "error", err)
+	} else if n > 0 {
+		s.logger.Warn("marked interrupted job runs as failed", "count", n)
+	}
+
+	for {
+		now := s.now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+		s.tick(ctx, next)
+	}
+}
+
+// tick launches every job whose schedule matches t.
+func (s *Scheduler) tick(ctx context.Context, t time.Time) {
+	svcs, err := s.services.ListServices(ctx, maxJobs, 0)
+	if err != nil {
+		s.logger.Error("failed to list services for scheduling", "error", err)
+		return
+	}
+	for _, svc := range svcs {
+		if svc.Type != store.TypeJob || svc.Schedule == "" {
+			continue
+		}
+		sched, err := ParseSchedule(svc.Schedule)
+		if err != nil {
+			s.logger.Error("ignoring job with invalid schedule", "name", svc.Name, "schedule", svc.Schedule, "error", err)
+			continue
+		}
+		if !sched.Matches(t) {
+			continue
+		}
+		if _, err := s.launch(ctx, svc, store.TriggerSchedule); err != nil && !errors.Is(err, jobs.ErrRunInProgress) {
+			s.logger.Error("failed to launch scheduled job", "name", svc.Name, "error", err)
+		}
+	}
+}
+
+// Trigger starts a run of a scheduled job now.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*store.JobRun, error) {
+	svc, err := s.services.GetService(ctx, name)
+	if err != nil || svc == nil || svc.Type != store.TypeJob {
+		return nil, fmt.Errorf("%w: %s", jobs.ErrJobNotFound, name)
+	}
+	if svc.Schedule == "" {
+		return nil, fmt.Errorf("%w: redeploy %s with a schedule to trigger it", jobs.ErrNotScheduled, name)
+	}
+	return s.launch(ctx, svc, store.TriggerManual)
+}
+
+func (s *Scheduler) ListRuns(ctx context.Context, name string, limit int) ([]*store.JobRun, error) {
+	return s.runs.ListRuns(ctx, name, limit)
+}
+
+// launch applies the job's concurrency policy and starts a run. Under forbid
+// an overlapping run is recorded as skipped and ErrRunInProgress returned.
+func (s *Scheduler) launch(ctx context.Context, svc *store.Service, trigger store.JobTrigger) (*store.JobRun, error) {
+	run := &store.JobRun{
+		ID:        ulid.Make().String(),
+		Name:      svc.Name,
+		Trigger:   trigger,
+		Status:    store.RunRunning,
+		StartedAt: s.now(),
+	}
+
+	var replaced []string
+	s.mu.Lock()
+	if s.draining {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("not starting %s: the node is shutting down", svc.Name)
+	}
+	running := s.active[svc.Name]
+	if len(running) > 0 {
+		switch svc.Concurrency {
+		case store.ConcurrencyForbid:
+			s.mu.Unlock()
+			run.Status = store.RunSkipped
+			run.FinishedAt = &run.StartedAt
+			if err := s.runs.CreateRun(ctx, run); err != nil {
+				return nil, err
+			}
+			return run, jobs.ErrRunInProgress
+		case store.ConcurrencyReplace:
+			for _, a := range running {
+				a.cancelled = true
+				replaced = append(replaced, a.unit)
+			}
+		}
+	}
+	if running == nil {
+		running = make(map[string]*activeRun)
+		s.active[svc.Name] = running
+	}
+	a := &activeRun{unit: Unit(svc.Name, run.ID)}
+	running[run.ID] = a
+	// Counted before it is recorded, so Drain cannot miss it.
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	for _, unit := range replaced {
+		s.stop(unit)
+	}
+
+	if err := s.runs.CreateRun(ctx, run); err != nil {
+		s.release(svc.Name, run.ID)
+		s.wg.Done()
+		return nil, err
+	}
+
+	s.logger.Info("starting job run", "name", svc.Name, "run_id", run.ID, "trigger", trigger)
+	go s.execute(*run, a)
+	return run, nil
+}
+
+// execute runs one instance of the job's unit to completion and records how
+// it ended.
+func (s *Scheduler) execute(run store.JobRun, a *activeRun) {
+	defer s.wg.Done()
+	defer s.release(run.Name, run.ID)
+
+	startErr := s.mgr.Start(a.unit)
+	code, codeErr := s.mgr.ExitCode(a.unit)
+	finished := s.now()
+	// Releases a failed instance so systemd does not keep it around.
+	s.stop(a.unit)
+
+	s.mu.Lock()
+	cancelled := a.cancelled
+	s.mu.Unlock()
+
+	switch {
+	case cancelled:
+		run.Status = store.RunCancelled
+	case codeErr == nil && code == 0 && startErr == nil:
+		run.Status = store.RunSucceeded
+	default:
+		run.Status = store.RunFailed
+	}
+	if codeErr == nil {
+		run.ExitCode = &code
+	} else {
+		s.logger.Warn("could not read job exit code", "unit", a.unit, "error", codeErr)
+	}
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Output = readTail(filepath.Join(s.logDir, utils.FormatName(run.Name)+"-"+run.ID+".log"), outputTail)
+
+	if err := s.runs.FinishRun(context.Background(), &run); err != nil {
+		s.logger.Error("failed to record job run", "name", run.Name, "run_id", run.ID, "error", err)
+		return
+	}
+	s.logger.Info("job run finished", "name", run.Name, "run_id", run.ID, "status", run.Status, "duration_ms", run.DurationMs)
+}
+
+// Drain stops the scheduler launching runs and waits for the running ones to
+// finish. Runs still going when ctx ends have their units stopped and are
+// recorded as cancelled.
+func (s *Scheduler) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	var units []string
+	s.mu.Lock()
+	for _, running := range s.active {
+		for _, a := range running {
+			a.cancelled = true
+			units = append(units, a.unit)
+		}
+	}
+	s.mu.Unlock()
+	s.logger.Warn("grace period over, stopping job runs", "count", len(units))
+	for _, unit := range units {
+		s.stop(unit)
+	}
+	select {
+	case <-done:
+	case <-time.After(abortWait):
+		s.logger.Error("job runs did not stop", "count", len(units))
+	}
+	return ctx.Err()
+}
+
+func (s *Scheduler) stop(unit string) {
+	if err := s.mgr.Stop(unit); err != nil {
+		s.logger.Warn("failed to stop job unit", "unit", unit, "error", err)
+	}
+}
+
+func (s *Scheduler) release(name, runID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active[name], runID)
+	if len(s.active[name]) == 0 {
+		delete(s.active, name)
+	}
+}
+
+// readTail returns up to n bytes from the end of a file, starting at a line
+// boundary. A missing file reads as empty.
+func readTail(path string, n int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := max(info.Size()-n, 0)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return ""
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return ""
+	}
+	out := string(data)
+	if offset > 0 {
+		if i := strings.IndexByte(out, '\n'); i >= 0 {
+			out = out[i+1:]
+		}
+	}
+	return out
+}
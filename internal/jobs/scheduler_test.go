@@ -0,0 +1,327 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/jobs"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// fakeRunner stands in for systemd. Start blocks until the unit is stopped
+// or released, like `systemctl start` on a one-shot unit.
+type fakeRunner struct {
+	mu      sync.Mutex
+	code    int
+	started []string
+	stopped []string
+	running map[string]chan struct{}
+}
+
+func newFakeRunner(code int) *fakeRunner {
+	return &fakeRunner{code: code, running: make(map[string]chan struct{})}
+}
+
+func (f *fakeRunner) Start(name string) error {
+	done := make(chan struct{})
+	f.mu.Lock()
+	f.started = append(f.started, name)
+	f.running[name] = done
+	f.mu.Unlock()
+	<-done
+	return nil
+}
+
+func (f *fakeRunner) Stop(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = append(f.stopped, name)
+	if done, ok := f.running[name]; ok {
+		close(done)
+		delete(f.running, name)
+	}
+	return nil
+}
+
+func (f *fakeRunner) ExitCode(name string) (int, error) {
+	return f.code, nil
+}
+
+// finish lets every run in progress exit.
+func (f *fakeRunner) finish() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for name, done := range f.running {
+		close(done)
+		delete(f.running, name)
+	}
+}
+
+// waitStarted blocks until n units have been started.
+func (f *fakeRunner) waitStarted(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		f.mu.Lock()
+		got := len(f.started)
+		f.mu.Unlock()
+		if got >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d runs to start", n)
+}
+
+type memRunStore struct {
+	mu   sync.Mutex
+	runs map[string]store.JobRun
+}
+
+func (m *memRunStore) CreateRun(ctx context.Context, r *store.JobRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[r.ID] = *r
+	return nil
+}
+
+func (m *memRunStore) FinishRun(ctx context.Context, r *store.JobRun) error {
+	return m.CreateRun(ctx, r)
+}
+
+func (m *memRunStore) ListRuns(ctx context.Context, name string, limit int) ([]*store.JobRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*store.JobRun
+	for _, r := range m.runs {
+		if r.Name == name {
+			out = append(out, &r)
+		}
+	}
+	return out, nil
+}
+
+func (m *memRunStore) FailInterruptedRuns(ctx context.Context) (int, error) { return 0, nil }
+
+func (m *memRunStore) get(id string) store.JobRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs[id]
+}
+
+type memServiceStore struct {
+	services map[string]*store.Service
+}
+
+func (m *memServiceStore) GetService(ctx context.Context, name string) (*store.Service, error) {
+	return m.services[name], nil
+}
+
+func (m *memServiceStore) ListServices(ctx context.Context, limit, offset int) ([]*store.Service, error) {
+	var out []*store.Service
+	for _, s := range m.services {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (m *memServiceStore) UpsertService(ctx context.Context, svc *store.Service) (*store.Service, error) {
+	return svc, nil
+}
+
+func (m *memServiceStore) DeleteService(ctx context.Context, name string) error { return nil }
+
+func newTestScheduler(t *testing.T, mgr runner, svcs ...*store.Service) (*Scheduler, *memRunStore) {
+	t.Helper()
+	ss := &memServiceStore{services: map[string]*store.Service{}}
+	for _, svc := range svcs {
+		ss.services[svc.Name] = svc
+	}
+	runs := &memRunStore{runs: map[string]store.JobRun{}}
+	s := NewScheduler(shared.NewLogger(), ss, runs, mgr)
+	s.logDir = t.TempDir()
+	return s, runs
+}
+
+func job(name, schedule string, policy store.ConcurrencyPolicy) *store.Service {
+	return &store.Service{Name: name, Type: store.TypeJob, Schedule: schedule, Concurrency: policy}
+}
+
+func TestTrigger_RecordsRun(t *testing.T) {
+	mgr := newFakeRunner(3)
+	s, runs := newTestScheduler(t, mgr, job("report", "0 * * * *", store.ConcurrencyAllow))
+
+	run, err := s.Trigger(context.Background(), "report")
+	if err != nil {
+		t.Fatalf("Trigger() error: %v", err)
+	}
+	if run.Trigger != store.TriggerManual || run.Status != store.RunRunning {
+		t.Errorf("run = %+v, want a running manual run", run)
+	}
+	logFile := filepath.Join(s.logDir, "report-"+run.ID+".log")
+	if err := os.WriteFile(logFile, []byte("line 1\nexiting with 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr.waitStarted(t, 1)
+	if mgr.started[0] != "report@"+run.ID {
+		t.Errorf("started unit %q, want report@%s", mgr.started[0], run.ID)
+	}
+	mgr.finish()
+	s.wg.Wait()
+
+	got := runs.get(run.ID)
+	if got.Status != store.RunFailed {
+		t.Errorf("status = %s, want failed", got.Status)
+	}
+	if got.ExitCode == nil || *got.ExitCode != 3 {
+		t.Errorf("exit code = %v, want 3", got.ExitCode)
+	}
+	if got.FinishedAt == nil {
+		t.Error("finished run has no finish time")
+	}
+	if !strings.Contains(got.Output, "exiting with 3") {
+		t.Errorf("output = %q, want the run's log", got.Output)
+	}
+}
+
+func TestTrigger_ForbidSkipsOverlappingRun(t *testing.T) {
+	mgr := newFakeRunner(0)
+	s, runs := newTestScheduler(t, mgr, job("report", "* * * * *", store.ConcurrencyForbid))
+
+	first, err := s.Trigger(context.Background(), "report")
+	if err != nil {
+		t.Fatalf("Trigger() error: %v", err)
+	}
+	mgr.waitStarted(t, 1)
+
+	second, err := s.Trigger(context.Background(), "report")
+	if !errors.Is(err, jobs.ErrRunInProgress) {
+		t.Fatalf("second Trigger() error = %v, want ErrRunInProgress", err)
+	}
+	if runs.get(second.ID).Status != store.RunSkipped {
+		t.Errorf("overlapping run status = %s, want skipped", runs.get(second.ID).Status)
+	}
+
+	mgr.finish()
+	s.wg.Wait()
+	if runs.get(first.ID).Status != store.RunSucceeded {
+		t.Errorf("first run status = %s, want succeeded", runs.get(first.ID).Status)
+	}
+	if len(mgr.started) != 1 {
+		t.Errorf("started %d units, want 1", len(mgr.started))
+	}
+}
+
+func TestTrigger_ReplaceStopsRunInProgress(t *testing.T) {
+	mgr := newFakeRunner(0)
+	s, runs := newTestScheduler(t, mgr, job("report", "* * * * *", store.ConcurrencyReplace))
+
+	first, _ := s.Trigger(context.Background(), "report")
+	mgr.waitStarted(t, 1)
+	second, err := s.Trigger(context.Background(), "report")
+	if err != nil {
+		t.Fatalf("second Trigger() error: %v", err)
+	}
+	mgr.waitStarted(t, 2)
+
+	mgr.finish()
+	s.wg.Wait()
+	if got := runs.get(first.ID).Status; got != store.RunCancelled {
+		t.Errorf("replaced run status = %s, want cancelled", got)
+	}
+	if got := runs.get(second.ID).Status; got != store.RunSucceeded {
+		t.Errorf("new run status = %s, want succeeded", got)
+	}
+}
+
+func TestTrigger_AllowOverlaps(t *testing.T) {
+	mgr := newFakeRunner(0)
+	s, _ := newTestScheduler(t, mgr, job("report", "* * * * *", ""))
+
+	s.Trigger(context.Background(), "report")
+	s.Trigger(context.Background(), "report")
+	mgr.waitStarted(t, 2)
+	mgr.finish()
+	s.wg.Wait()
+
+	if len(mgr.stopped) != 2 {
+		t.Errorf("stopped = %v, want each finished unit released once", mgr.stopped)
+	}
+}
+
+func TestTrigger_Rejects(t *testing.T) {
+	s, _ := newTestScheduler(t, newFakeRunner(0),
+		job("once", "", ""),
+		&store.Service{Name: "api", Type: store.TypeWeb},
+	)
+
+	if _, err := s.Trigger(context.Background(), "once"); !errors.Is(err, jobs.ErrNotScheduled) {
+		t.Errorf("unscheduled job: error = %v, want ErrNotScheduled", err)
+	}
+	if _, err := s.Trigger(context.Background(), "api"); !errors.Is(err, jobs.ErrJobNotFound) {
+		t.Errorf("web service: error = %v, want ErrJobNotFound", err)
+	}
+	if _, err := s.Trigger(context.Background(), "missing"); !errors.Is(err, jobs.ErrJobNotFound) {
+		t.Errorf("missing job: error = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestTick_LaunchesDueJobsOnly(t *testing.T) {
+	mgr := newFakeRunner(0)
+	s, runs := newTestScheduler(t, mgr,
+		job("hourly", "0 * * * *", ""),
+		job("nightly", "0 3 * * *", ""),
+		job("once", "", ""),
+	)
+
+	s.tick(context.Background(), time.Date(2025, time.June, 2, 14, 0, 0, 0, time.Local))
+	mgr.waitStarted(t, 1)
+	mgr.finish()
+	s.wg.Wait()
+
+	if len(mgr.started) != 1 || !strings.HasPrefix(mgr.started[0], "hourly@") {
+		t.Fatalf("started = %v, want only hourly", mgr.started)
+	}
+	all, _ := runs.ListRuns(context.Background(), "hourly", 10)
+	if len(all) != 1 || all[0].Trigger != store.TriggerSchedule {
+		t.Errorf("hourly runs = %+v, want one scheduled run", all)
+	}
+}
+
+func TestDrain_StopsRunsAtEndOfGrace(t *testing.T) {
+	mgr := newFakeRunner(0)
+	s, runs := newTestScheduler(t, mgr, job("report", "* * * * *", store.ConcurrencyAllow))
+
+	run, err := s.Trigger(context.Background(), "report")
+	if err != nil {
+		t.Fatalf("Trigger() error: %v", err)
+	}
+	mgr.waitStarted(t, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() = %v, want the grace period to run out", err)
+	}
+	if got := runs.get(run.ID).Status; got != store.RunCancelled {
+		t.Errorf("run status = %s, want cancelled", got)
+	}
+	if len(mgr.stopped) == 0 || mgr.stopped[0] != Unit("report", run.ID) {
+		t.Errorf("stopped %v, want the run's unit", mgr.stopped)
+	}
+
+	if _, err := s.Trigger(context.Background(), "report"); err == nil {
+		t.Error("a drained scheduler started a run")
+	}
+}
@@ -0,0 +1,6 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lifecycle shuts the daemon down in order, giving in-flight work a
+// grace period to finish and reporting each step through system/mode.
+package lifecycle
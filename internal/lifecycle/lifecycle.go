@@ -0,0 +1,109 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// minStepTime is how long a step gets when the grace period is already
+// spent, so the steps that release resources still run.
+const minStepTime = 5 * time.Second
+
+// ReportFunc publishes shutdown progress. internal/system's ReportShutdown
+// satisfies it.
+type ReportFunc func(system.ShutdownStatus)
+
+type step struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager runs the daemon's shutdown steps one after another, in the order
+// they were registered, under a shared deadline.
+type Manager struct {
+	logger *shared.Logger
+	grace  time.Duration
+	report ReportFunc
+	now    func() time.Time
+
+	mu    sync.Mutex
+	steps []step
+	done  bool
+}
+
+func New(logger *shared.Logger, grace time.Duration, report ReportFunc) *Manager {
+	return &Manager{
+		logger: logger,
+		grace:  grace,
+		report: report,
+		now:    time.Now,
+	}
+}
+
+// OnShutdown adds a step to run on shutdown, after every step added before
+// it. fn should return once its work is done or ctx ends.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, step{name: name, fn: fn})
+}
+
+// Shutdown runs every step. The grace period is shared: a step that uses it
+// up leaves the rest minStepTime each. A failing step is logged and the
+// next one still runs; the errors are returned together. Only the first
+// call does anything.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if m.done {
+		m.mu.Unlock()
+		return nil
+	}
+	m.done = true
+	steps := m.steps
+	m.mu.Unlock()
+
+	deadline := m.now().Add(m.grace)
+	status := system.ShutdownStatus{Deadline: deadline}
+	var errs []error
+	for _, s := range steps {
+		status.Step = s.name
+		m.publish(status)
+
+		start := m.now()
+		m.logger.Info("shutdown step started", "step", s.name)
+		stepDeadline := deadline
+		if floor := start.Add(minStepTime); floor.After(stepDeadline) {
+			stepDeadline = floor
+		}
+		sctx, cancel := context.WithDeadline(ctx, stepDeadline)
+		err := s.fn(sctx)
+		cancel()
+
+		if err != nil {
+			m.logger.Warn("shutdown step failed", "step", s.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		} else {
+			m.logger.Info("shutdown step finished", "step", s.name, "duration_ms", m.now().Sub(start).Milliseconds())
+		}
+		status.Done = append(status.Done, s.name)
+	}
+
+	status.Step = ""
+	m.publish(status)
+	return errors.Join(errs...)
+}
+
+func (m *Manager) publish(st system.ShutdownStatus) {
+	if m.report != nil {
+		m.report(st)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+func TestShutdown_RunsStepsInOrderAndReports(t *testing.T) {
+	var reports []system.ShutdownStatus
+	m := New(shared.NewLogger(), time.Minute, func(st system.ShutdownStatus) {
+		st.Done = slices.Clone(st.Done)
+		reports = append(reports, st)
+	})
+
+	var ran []string
+	for _, name := range []string{"tasks", "worker", "http"} {
+		m.OnShutdown(name, func(ctx context.Context) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !slices.Equal(ran, []string{"tasks", "worker", "http"}) {
+		t.Errorf("ran = %v", ran)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("got %d reports, want one per step and a final one", len(reports))
+	}
+	if reports[1].Step != "worker" || !slices.Equal(reports[1].Done, []string{"tasks"}) {
+		t.Errorf("second report = %+v", reports[1])
+	}
+	last := reports[3]
+	if last.Step != "" || len(last.Done) != 3 {
+		t.Errorf("final report = %+v", last)
+	}
+}
+
+func TestShutdown_SharesGraceAndKeepsGoing(t *testing.T) {
+	m := New(shared.NewLogger(), 20*time.Millisecond, nil)
+
+	m.OnShutdown("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	var left time.Duration
+	m.OnShutdown("http", func(ctx context.Context) error {
+		d, _ := ctx.Deadline()
+		left = time.Until(d)
+		return nil
+	})
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the worker's deadline error", err)
+	}
+	if left < minStepTime-time.Second {
+		t.Errorf("step after the grace period got %s, want about %s", left, minStepTime)
+	}
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown = %v, want nil", err)
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// collectInterval is how often the collector looks for containers to follow.
+const collectInterval = 5 * time.Second
+
+// collectorDocker is the subset of the Docker client the collector uses.
+type collectorDocker interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]dockertypes.Container, error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+}
+
+// Collector copies the stdout and stderr of service containers into the
+// service log files read by Handler. Each line becomes a JSON entry carrying
+// the stream it came from and the short container ID.
+//
+// Containers are found by their service label. A container that stops is
+// picked up again once it is running, resuming after the last line seen, so
+// restarts and crash loops are followed without duplicating output.
+type Collector struct {
+	logger   *shared.Logger
+	docker   collectorDocker
+	dir      string
+	maxBytes int64
+	backups  int
+	interval time.Duration
+	started  time.Time
+
+	mu       sync.Mutex
+	attached map[string]bool          // container ID -> being followed
+	last     map[string]time.Time     // container ID -> time of last line written
+	files    map[string]*rotatingFile // service name -> log file
+}
+
+func NewCollector(logger *shared.Logger, cfg *shared.Config, docker collectorDocker) *Collector {
+	return &Collector{
+		logger:   logger,
+		docker:   docker,
+		dir:      utils.GetServiceLogDir(),
+		maxBytes: cfg.LogRotateBytes,
+		backups:  cfg.LogRotateBackups,
+		interval: collectInterval,
+		attached: make(map[string]bool),
+		last:     make(map[string]time.Time),
+		files:    make(map[string]*rotatingFile),
+	}
+}
+
+// Start follows service containers until ctx is cancelled. Containers that
+// were already running when the daemon started are collected from that point
+// on; their earlier output is left to `docker logs`.
+func (c *Collector) Start(ctx context.Context) {
+	c.started = time.Now()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			c.closeFiles()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile attaches to every running service container not yet followed and
+// forgets containers that no longer exist.
+func (c *Collector) reconcile(ctx context.Context) {
+	list, err := c.docker.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", utils.ServiceLabel)),
+	})
+	if err != nil {
+		c.logger.Warn("failed to list service containers", "error", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[string]bool, len(list))
+	services := make(map[string]bool)
+	for _, ctr := range list {
+		svc := ctr.Labels[utils.ServiceLabel]
+		if svc == "" {
+			continue
+		}
+		seen[ctr.ID] = true
+		services[svc] = true
+		if ctr.State != "running" || c.attached[ctr.ID] {
+			continue
+		}
+
+		since, ok := c.last[ctr.ID]
+		if ok {
+			since = since.Add(time.Nanosecond)
+		} else {
+			since = c.started
+			if created := time.Unix(ctr.Created, 0); created.After(since) {
+				since = created
+			}
+		}
+		c.attached[ctr.ID] = true
+		go c.follow(ctx, ctr.ID, svc, since)
+	}
+
+	for id := range c.last {
+		if !seen[id] && !c.attached[id] {
+			delete(c.last, id)
+		}
+	}
+	for svc, f := range c.files {
+		if !services[svc] {
+			f.close()
+			delete(c.files, svc)
+		}
+	}
+}
+
+// follow streams a container's output into its service log until the
+// container stops or ctx is cancelled.
+func (c *Collector) follow(ctx context.Context, id, svc string, since time.Time) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.attached, id)
+		c.mu.Unlock()
+	}()
+
+	rc, err := c.docker.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Since:      fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Warn("failed to attach to container logs", "service", svc, "container", shortID(id), "error", err)
+		}
+		return
+	}
+	defer rc.Close()
+
+	stdout := &lineWriter{c: c, svc: svc, id: id, stream: "stdout"}
+	stderr := &lineWriter{c: c, svc: svc, id: id, stream: "stderr"}
+	// dployr never allocates a TTY, so container output is always multiplexed.
+	if _, err := stdcopy.StdCopy(stdout, stderr, rc); err != nil && ctx.Err() == nil {
+		c.logger.Warn("container log stream ended", "service", svc, "container", shortID(id), "error", err)
+	}
+	stdout.flush()
+	stderr.flush()
+}
+
+// write appends one line of container output to the service's log.
+func (c *Collector) write(svc, id, stream, line string) {
+	ts := time.Now()
+	// Docker prefixes each line with its RFC 3339 timestamp when asked to.
+	if prefix, rest, ok := strings.Cut(line, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+			ts, line = t, rest
+		}
+	}
+
+	b, err := json.Marshal(map[string]any{
+		"time":         ts.UTC().Format(time.RFC3339Nano),
+		"level":        "INFO",
+		"msg":          strings.TrimRight(line, "\r"),
+		"stream":       stream,
+		"container_id": shortID(id),
+	})
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	// The file is written under the lock too, so reconcile cannot close it
+	// in between and leave the write to reopen a file nothing will close.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, ok := c.files[svc]
+	if !ok {
+		f = &rotatingFile{
+			path:     filepath.Join(c.dir, svc+".log"),
+			maxBytes: c.maxBytes,
+			backups:  c.backups,
+		}
+		c.files[svc] = f
+	}
+	if ts.After(c.last[id]) {
+		c.last[id] = ts
+	}
+
+	if err := f.write(b); err != nil {
+		c.logger.Warn("failed to write service log", "service", svc, "error", err)
+	}
+}
+
+func (c *Collector) closeFiles() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for svc, f := range c.files {
+		f.close()
+		delete(c.files, svc)
+	}
+}
+
+// lineWriter splits one stream of a container's output into lines.
+type lineWriter struct {
+	c      *Collector
+	svc    string
+	id     string
+	stream string
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.c.write(w.svc, w.id, w.stream, string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes out a trailing line that had no newline.
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.c.write(w.svc, w.id, w.stream, string(w.buf))
+		w.buf = nil
+	}
+}
+
+// rotatingFile is an append-only log file that is moved aside once it grows
+// past maxBytes. The live file is always recreated rather than truncated, so
+// readers detect the rotation by its new inode. It is guarded by the
+// collector's lock.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	backups  int
+	f        *os.File
+	size     int64
+}
+
+func (r *rotatingFile) write(b []byte) error {
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(b)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.f.Write(b)
+	r.size += int64(n)
+	return err
+}
+
+func (r *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts <path>.N up by one, dropping the oldest, moves the live file
+// to <path>.1 and opens a fresh one.
+func (r *rotatingFile) rotate() error {
+	r.f.Close()
+	r.f = nil
+
+	if r.backups > 0 {
+		for i := r.backups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1)) //nolint:errcheck
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) close() {
+	if r.f != nil {
+		r.f.Close()
+		r.f = nil
+	}
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+const testContainerID = "0123456789abcdef0123456789abcdef"
+
+type fakeLogsDocker struct {
+	mu         sync.Mutex
+	containers []dockertypes.Container
+	stdout     string
+	stderr     string
+	since      []string
+}
+
+func (f *fakeLogsDocker) ContainerList(ctx context.Context, _ container.ListOptions) ([]dockertypes.Container, error) {
+	return f.containers, nil
+}
+
+func (f *fakeLogsDocker) ContainerLogs(ctx context.Context, _ string, opts container.LogsOptions) (io.ReadCloser, error) {
+	f.mu.Lock()
+	f.since = append(f.since, opts.Since)
+	f.mu.Unlock()
+
+	var buf bytes.Buffer
+	stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(f.stdout))
+	stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(f.stderr))
+	return io.NopCloser(&buf), nil
+}
+
+func newTestCollector(t *testing.T, docker collectorDocker) *Collector {
+	t.Helper()
+	c := NewCollector(shared.NewLogger(), &shared.Config{LogRotateBytes: 1 << 20, LogRotateBackups: 2}, docker)
+	c.dir = t.TempDir()
+	c.started = time.Unix(1_700_000_000, 0)
+	return c
+}
+
+// collectOnce runs a reconcile pass and waits for the followers it started.
+func collectOnce(t *testing.T, c *Collector) {
+	t.Helper()
+	c.reconcile(context.Background())
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		c.mu.Lock()
+		n := len(c.attached)
+		c.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("container followers did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func runningContainer(svc string) dockertypes.Container {
+	return dockertypes.Container{
+		ID:      testContainerID,
+		State:   "running",
+		Created: 1_700_000_100,
+		Labels:  map[string]string{utils.ServiceLabel: svc},
+	}
+}
+
+func TestCollector_WritesTaggedEntries(t *testing.T) {
+	docker := &fakeLogsDocker{
+		containers: []dockertypes.Container{runningContainer("api")},
+		stdout:     "2026-01-02T03:04:05.000000001Z listening on :3000\n",
+		stderr:     "2026-01-02T03:04:06.5Z warning: cache cold",
+	}
+	c := newTestCollector(t, docker)
+	collectOnce(t, c)
+	c.closeFiles()
+
+	data, err := os.ReadFile(filepath.Join(c.dir, "api.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), data)
+	}
+
+	h := newTestHandler(t, t.TempDir())
+	want := []struct{ msg, stream, time string }{
+		{"listening on :3000", "stdout", "2026-01-02T03:04:05.000000001Z"},
+		{"warning: cache cold", "stderr", "2026-01-02T03:04:06.5Z"},
+	}
+	for i, w := range want {
+		e := h.parseLogLine(lines[i])
+		if e.Msg != w.msg || e.Time != w.time {
+			t.Errorf("entry %d = %q at %s, want %q at %s", i, e.Msg, e.Time, w.msg, w.time)
+		}
+		if e.Attrs["stream"] != w.stream || e.Attrs["container_id"] != testContainerID[:12] {
+			t.Errorf("entry %d attrs = %v, want stream %s and short container ID", i, e.Attrs, w.stream)
+		}
+	}
+
+	// First attach reads from when the container was created.
+	if docker.since[0] != "1700000100.000000000" {
+		t.Errorf("first Since = %q, want container creation time", docker.since[0])
+	}
+}
+
+func TestCollector_ResumesAfterLastLine(t *testing.T) {
+	docker := &fakeLogsDocker{
+		containers: []dockertypes.Container{runningContainer("api")},
+		stdout:     "2026-01-02T03:04:05Z started\n",
+	}
+	c := newTestCollector(t, docker)
+	collectOnce(t, c)
+
+	// The container restarted: the next attach starts just after the last line.
+	collectOnce(t, c)
+	if len(docker.since) != 2 || docker.since[1] != "1767323045.000000001" {
+		t.Errorf("Since on reattach = %v, want just after 2026-01-02T03:04:05Z", docker.since)
+	}
+
+	// A removed container is forgotten, along with its service's open file.
+	docker.containers = nil
+	collectOnce(t, c)
+	if len(c.last) != 0 || len(c.files) != 0 {
+		t.Errorf("state kept for removed container: last=%v files=%v", c.last, c.files)
+	}
+}
+
+func TestCollector_SkipsStoppedContainers(t *testing.T) {
+	ctr := runningContainer("api")
+	ctr.State = "exited"
+	docker := &fakeLogsDocker{containers: []dockertypes.Container{ctr}}
+	c := newTestCollector(t, docker)
+	collectOnce(t, c)
+	if len(docker.since) != 0 {
+		t.Errorf("attached to a stopped container")
+	}
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	r := &rotatingFile{path: path, maxBytes: 10, backups: 2}
+	defer r.close()
+
+	line := []byte("0123456789\n")
+	if err := r.write(line); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.Stat(path)
+	if err := r.write(line); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+	if os.SameFile(before, after) {
+		t.Error("live log kept its inode across rotation")
+	}
+
+	for range 2 {
+		if err := r.write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("missing backup %s", filepath.Base(name))
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("kept more backups than configured")
+	}
+}
@@ -2,5 +2,6 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package logs provides concrete implementations for log streaming functionality.
-// It handles tailing log files and streaming them via WebSocket to Base.
+// It handles tailing log files and streaming them via WebSocket to Base, and
+// collects the output of service containers into those files.
 package logs
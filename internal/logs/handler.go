@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/dployr-io/dployr/pkg/core/logs"
+	"github.com/dployr-io/dployr/pkg/core/utils"
 	"github.com/dployr-io/dployr/pkg/shared"
 )
 
@@ -339,12 +340,7 @@ func (h *Handler) getLogPath(path string) string {
 		if h.dataDir != "" {
 			return filepath.Join(h.dataDir, ".dployr", "logs", svcName)
 		}
-		switch runtime.GOOS {
-		case "windows":
-			return filepath.Join(os.Getenv("PROGRAMDATA"), "dployr", ".dployr", "logs", svcName)
-		default:
-			return filepath.Join("/home/dployrd/.dployr/logs", svcName)
-		}
+		return filepath.Join(utils.GetServiceLogDir(), svcName)
 	}
 
 	// Deployment logs
@@ -0,0 +1,187 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// The range service containers are bound in, the same one the hashed ports
+// of coreutils.ComputeHostPort fall in.
+const (
+	MinPort = 61000
+	MaxPort = 64999
+)
+
+// ErrExhausted is returned when every port in the range is taken.
+var ErrExhausted = errors.New("no free host port left")
+
+// Allocator reserves host ports for container slots. A slot is named after
+// the container that binds it: the service's container, a replica
+// ("<name>-r1") or the blue/green candidate ("<name>-next").
+type Allocator struct {
+	store store.PortStore
+	bound func(port int) bool
+
+	mu sync.Mutex // serialises reservations so two slots never race for a port
+}
+
+func NewAllocator(s store.PortStore) *Allocator {
+	return &Allocator{store: s, bound: portBound}
+}
+
+// Port returns the host port reserved for container, a slot of service,
+// reserving one the first time. The search starts at the slot's hashed
+// port and moves up the range, wrapping around, past ports that are
+// reserved or already bound on the host.
+func (a *Allocator) Port(ctx context.Context, service, container string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r, err := a.store.GetPortReservation(ctx, container)
+	if err != nil {
+		return 0, err
+	}
+	if r != nil {
+		return r.Port, nil
+	}
+
+	existing, err := a.store.ListPortReservations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	reserved := make(map[int]bool, len(existing))
+	for _, r := range existing {
+		reserved[r.Port] = true
+	}
+
+	start := utils.ComputeHostPort(container)
+	size := MaxPort - MinPort + 1
+	for i := range size {
+		port := MinPort + (start-MinPort+i)%size
+		if reserved[port] || a.bound(port) {
+			continue
+		}
+		err := a.store.ReservePort(ctx, &store.PortReservation{Port: port, Container: container, Service: service})
+		if errors.Is(err, store.ErrPortTaken) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return port, nil
+	}
+	return 0, ErrExhausted
+}
+
+// Replicas returns the ports of replicas [0, n) of the service whose
+// containers are named after name, reserving any that are missing.
+func (a *Allocator) Replicas(ctx context.Context, service, name string, n int) ([]int, error) {
+	ports := make([]int, max(n, 1))
+	for i := range ports {
+		port, err := a.Port(ctx, service, utils.ReplicaName(name, i))
+		if err != nil {
+			return nil, err
+		}
+		ports[i] = port
+	}
+	return ports, nil
+}
+
+// Trim releases the slots of the service whose containers are named after
+// name that it no longer uses once it runs n replicas, the first bound to
+// live: replicas from n up, and the "-next" slot unless live is its port.
+// Call it after the containers that held them are gone.
+func (a *Allocator) Trim(ctx context.Context, name string, n, live int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	slots := []string{name + "-next"}
+	if r, err := a.store.GetPortReservation(ctx, slots[0]); err != nil {
+		return err
+	} else if r != nil && r.Port == live {
+		slots = nil
+	}
+	for i := max(n, 1); i < store.MaxReplicas; i++ {
+		slots = append(slots, utils.ReplicaName(name, i))
+	}
+
+	var errs []error
+	for _, container := range slots {
+		if err := a.store.ReleasePort(ctx, container); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", container, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup returns the port reserved for container, or 0 when it has none.
+func (a *Allocator) Lookup(ctx context.Context, container string) int {
+	r, err := a.store.GetPortReservation(ctx, container)
+	if err != nil || r == nil {
+		return 0
+	}
+	return r.Port
+}
+
+// Migrate reserves the ports services deployed before reservations existed
+// are bound to: the hashed port of each replica, and the live port when a
+// blue/green redeploy left the service on its "-next" slot. It is safe to
+// run on every start; slots already reserved are left alone. A port two
+// services were hashed onto goes to the first; the other's slot gets a
+// fresh port on its next deploy.
+func (a *Allocator) Migrate(ctx context.Context, services []*store.Service) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	for _, svc := range services {
+		if svc.Type == store.TypeStatic || svc.Type == store.TypeJob {
+			continue
+		}
+		name := utils.FormatName(svc.Name)
+		slots := map[string]int{}
+		for i := range max(svc.Replicas, 1) {
+			rname := utils.ReplicaName(name, i)
+			slots[rname] = utils.ComputeHostPort(rname)
+		}
+		if svc.HostPort != 0 && svc.HostPort != slots[name] {
+			slots[name+"-next"] = svc.HostPort
+		}
+
+		for container, port := range slots {
+			if r, err := a.store.GetPortReservation(ctx, container); err != nil {
+				return err
+			} else if r != nil {
+				continue
+			}
+			err := a.store.ReservePort(ctx, &store.PortReservation{Port: port, Container: container, Service: svc.Name})
+			if errors.Is(err, store.ErrPortTaken) {
+				errs = append(errs, fmt.Errorf("%s: port %d is already reserved", container, port))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// portBound reports whether something on the host already listens on port.
+func portBound(port int) bool {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return true
+	}
+	l.Close()
+	return false
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package ports
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type memPortStore struct {
+	mu    sync.Mutex
+	byCtr map[string]store.PortReservation
+}
+
+func newMemPortStore() *memPortStore {
+	return &memPortStore{byCtr: map[string]store.PortReservation{}}
+}
+
+func (m *memPortStore) ReservePort(ctx context.Context, r *store.PortReservation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.byCtr[r.Container]; ok {
+		return store.ErrPortTaken
+	}
+	for _, o := range m.byCtr {
+		if o.Port == r.Port {
+			return store.ErrPortTaken
+		}
+	}
+	m.byCtr[r.Container] = *r
+	return nil
+}
+
+func (m *memPortStore) GetPortReservation(ctx context.Context, container string) (*store.PortReservation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.byCtr[container]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (m *memPortStore) ListPortReservations(ctx context.Context) ([]*store.PortReservation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*store.PortReservation
+	for _, r := range m.byCtr {
+		out = append(out, &r)
+	}
+	return out, nil
+}
+
+func (m *memPortStore) ReleasePort(ctx context.Context, container string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.byCtr, container)
+	return nil
+}
+
+func newTestAllocator(bound ...int) (*Allocator, *memPortStore) {
+	s := newMemPortStore()
+	a := NewAllocator(s)
+	a.bound = func(port int) bool {
+		for _, b := range bound {
+			if b == port {
+				return true
+			}
+		}
+		return false
+	}
+	return a, s
+}
+
+func TestPort_SkipsReservedAndBoundPorts(t *testing.T) {
+	hashed := utils.ComputeHostPort("api")
+	next := MinPort + (hashed-MinPort+1)%(MaxPort-MinPort+1)
+	a, s := newTestAllocator(next)
+	ctx := context.Background()
+
+	// Another service already holds api's hashed port.
+	s.ReservePort(ctx, &store.PortReservation{Port: hashed, Container: "web", Service: "web"})
+
+	port, err := a.Port(ctx, "api", "api")
+	if err != nil {
+		t.Fatalf("Port: %v", err)
+	}
+	if port == hashed || port == next {
+		t.Fatalf("Port = %d, want neither the reserved %d nor the bound %d", port, hashed, next)
+	}
+	if again, _ := a.Port(ctx, "api", "api"); again != port {
+		t.Errorf("second Port = %d, want the reserved %d", again, port)
+	}
+	if got := a.Lookup(ctx, "api"); got != port {
+		t.Errorf("Lookup = %d, want %d", got, port)
+	}
+
+	ports, err := a.Replicas(ctx, "api", "api", 3)
+	if err != nil {
+		t.Fatalf("Replicas: %v", err)
+	}
+	seen := map[int]bool{hashed: true}
+	for _, p := range ports {
+		if seen[p] {
+			t.Errorf("Replicas = %v, port %d handed out twice", ports, p)
+		}
+		seen[p] = true
+	}
+	if ports[0] != port {
+		t.Errorf("replica 0 = %d, want the service's own port %d", ports[0], port)
+	}
+}
+
+func TestPort_KeepsHashedPortWhenFree(t *testing.T) {
+	a, _ := newTestAllocator()
+	port, err := a.Port(context.Background(), "api", "api-r1")
+	if err != nil || port != utils.ComputeHostPort("api-r1") {
+		t.Errorf("Port = %d, %v; want the hashed port", port, err)
+	}
+}
+
+func TestMigrate_AdoptsLivePorts(t *testing.T) {
+	a, s := newTestAllocator()
+	ctx := context.Background()
+	services := []*store.Service{
+		{Name: "api", Type: store.TypeWeb, Replicas: 2, HostPort: 61234},
+		{Name: "site", Type: store.TypeStatic},
+		{Name: "clash", Type: store.TypeWorker, HostPort: 61234},
+	}
+
+	err := a.Migrate(ctx, services)
+	if err == nil || !strings.Contains(err.Error(), "clash-next") {
+		t.Errorf("Migrate err = %v, want the clash on port 61234 reported", err)
+	}
+
+	want := map[string]int{
+		"api":      utils.ComputeHostPort("api"),
+		"api-r1":   utils.ComputeHostPort("api-r1"),
+		"api-next": 61234,
+		"clash":    utils.ComputeHostPort("clash"),
+	}
+	for container, port := range want {
+		if got := a.Lookup(ctx, container); got != port {
+			t.Errorf("Lookup(%q) = %d, want %d", container, got, port)
+		}
+	}
+	if len(s.byCtr) != len(want) {
+		t.Errorf("reserved %d slots, want %d", len(s.byCtr), len(want))
+	}
+
+	if err := a.Migrate(ctx, services[:2]); err != nil {
+		t.Errorf("second Migrate = %v, want nothing left to do", err)
+	}
+}
+
+func TestTrim_ReleasesUnusedSlots(t *testing.T) {
+	a, s := newTestAllocator()
+	ctx := context.Background()
+	if _, err := a.Replicas(ctx, "api", "api", 3); err != nil {
+		t.Fatalf("Replicas: %v", err)
+	}
+	next, _ := a.Port(ctx, "api", "api-next")
+
+	// Live on the blue/green slot: it stays, the extra replicas go.
+	if err := a.Trim(ctx, "api", 1, next); err != nil {
+		t.Fatalf("Trim: %v", err)
+	}
+	if _, ok := s.byCtr["api-next"]; !ok || len(s.byCtr) != 2 {
+		t.Errorf("kept %v, want api and api-next", s.byCtr)
+	}
+
+	// Back on the primary slot.
+	if err := a.Trim(ctx, "api", 1, a.Lookup(ctx, "api")); err != nil {
+		t.Fatalf("Trim: %v", err)
+	}
+	if _, ok := s.byCtr["api"]; !ok || len(s.byCtr) != 1 {
+		t.Errorf("kept %v, want api only", s.byCtr)
+	}
+	if port, err := a.Port(ctx, "api", "api-r1"); err != nil || port != utils.ComputeHostPort("api-r1") {
+		t.Errorf("Port after release = %d, %v; want the freed hashed port", port, err)
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ports hands out the host ports service containers bind to. Each
+// container slot keeps its port in the daemon database for as long as its
+// service exists, so two services never share a port and a redeploy or a
+// restart lands on the same one.
+package ports
@@ -27,6 +27,9 @@ import (
 //go:embed templates/*.tpl
 var templateFS embed.FS
 
+//go:embed templates/maintenance.html
+var defaultMaintenancePage []byte
+
 type CaddyHandler struct {
 	Apps   map[string]proxy.App
 	logger *shared.Logger
@@ -39,7 +42,11 @@ func Init(a map[string]proxy.App, logger *shared.Logger) *CaddyHandler {
 	}
 }
 
-const stateFile = ".dployr/caddy/apps.json"
+const (
+	stateFile       = ".dployr/caddy/apps.json"
+	maintenanceFile = ".dployr/caddy/maintenance.json"
+	maintenanceDir  = ".dployr/caddy/maintenance" // holds the page Caddy serves
+)
 
 type TemplateData struct {
 	Apps    map[string]proxy.App
@@ -49,11 +56,13 @@ type TemplateData struct {
 }
 
 type AppTemplateData struct {
-	Domain  string
-	App     proxy.App
-	LogDir  string
-	LogFile string
-	HomeDir string
+	Domain          string
+	Address         string
+	App             proxy.App
+	LogDir          string
+	LogFile         string
+	HomeDir         string
+	MaintenanceRoot string // directory of the maintenance page
 }
 
 func (c *CaddyHandler) Setup(apps map[string]proxy.App) error {
@@ -90,33 +99,16 @@ func (c *CaddyHandler) Setup(apps map[string]proxy.App) error {
 	}
 	defer out.Close()
 
-	// generate content for each app
-	var contentBuilder strings.Builder
-	for domain, app := range apps {
-		logFile := filepath.Join(logDir, domain+".log")
-		appData := AppTemplateData{
-			Domain:  domain,
-			App:     app,
-			LogDir:  logDir,
-			LogFile: logFile,
-			HomeDir: dataDir,
-		}
-
-		templateName := fmt.Sprintf("%s.tpl", app.Template)
-		c.logger.Debug("processing app", "domain", domain, "template", templateName)
-
-		if err := tmpl.ExecuteTemplate(&contentBuilder, templateName, appData); err != nil {
-			c.logger.Error("template execution failed", "template", templateName, "domain", domain, "error", err)
-			return fmt.Errorf("unable to execute template %s for app %s: %w", templateName, domain, err)
-		}
-		contentBuilder.WriteString("\n")
+	content, err := c.renderSites(tmpl, apps, LoadMaintenance(), dataDir, logDir)
+	if err != nil {
+		return err
 	}
 
 	tmplData := TemplateData{
 		Apps:    apps,
 		LogDir:  logDir,
 		HomeDir: dataDir,
-		Content: contentBuilder.String(),
+		Content: content,
 	}
 
 	c.logger.Debug("executing caddyfile template", "app_count", len(tmplData.Apps), "content_length", len(tmplData.Content))
@@ -131,6 +123,47 @@ func (c *CaddyHandler) Setup(apps map[string]proxy.App) error {
 	return saveState(apps)
 }
 
+// renderSites renders the site block of each app. Domains under maintenance
+// are rendered with the maintenance template instead of their own, keeping
+// their address and TLS settings.
+func (c *CaddyHandler) renderSites(tmpl *template.Template, apps map[string]proxy.App, m *proxy.Maintenance, dataDir, logDir string) (string, error) {
+	var b strings.Builder
+	for domain, app := range apps {
+		appData := AppTemplateData{
+			Domain:  domain,
+			Address: siteAddress(domain, app),
+			App:     app,
+			LogDir:  logDir,
+			LogFile: filepath.Join(logDir, domain+".log"),
+			HomeDir: dataDir,
+		}
+
+		templateName := fmt.Sprintf("%s.tpl", app.Template)
+		if m.Covers(domain) {
+			templateName = fmt.Sprintf("%s.tpl", proxy.TemplateMaintenance)
+			appData.MaintenanceRoot = filepath.Join(dataDir, maintenanceDir)
+		}
+		c.logger.Debug("processing app", "domain", domain, "template", templateName)
+
+		if err := tmpl.ExecuteTemplate(&b, templateName, appData); err != nil {
+			c.logger.Error("template execution failed", "template", templateName, "domain", domain, "error", err)
+			return "", fmt.Errorf("unable to execute template %s for app %s: %w", templateName, domain, err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// siteAddress is the Caddyfile site address for domain. Routes without a TLS
+// mode are pinned to plain HTTP so Caddy never tries to obtain a certificate
+// for them; the rest are left bare and served over HTTPS.
+func siteAddress(domain string, app proxy.App) string {
+	if app.TLS == "" {
+		return "http://" + domain
+	}
+	return domain
+}
+
 func (c *CaddyHandler) Stop() error {
 	c.logger.Info("stopping caddy service")
 	conn, err := systemddbus.NewSystemConnectionContext(context.TODO())
@@ -261,6 +294,82 @@ func (c *CaddyHandler) Remove(domains []string) error {
 	return c.Restart()
 }
 
+// SetMaintenance puts the domains m covers behind the maintenance page, or
+// lifts maintenance when m is nil. The saved routes are not touched. If the
+// new Caddyfile cannot be loaded the previous maintenance is put back.
+func (c *CaddyHandler) SetMaintenance(m *proxy.Maintenance) error {
+	prev := LoadMaintenance()
+	if err := c.applyMaintenance(m); err != nil {
+		if rerr := c.applyMaintenance(prev); rerr != nil {
+			c.logger.Error("failed to restore proxy after maintenance change", "error", rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *CaddyHandler) applyMaintenance(m *proxy.Maintenance) error {
+	if err := saveMaintenance(m); err != nil {
+		return fmt.Errorf("failed to save maintenance state: %w", err)
+	}
+	if err := c.Setup(LoadState()); err != nil {
+		return err
+	}
+	return c.Restart()
+}
+
+func (c *CaddyHandler) Maintenance() *proxy.Maintenance {
+	return LoadMaintenance()
+}
+
+// LoadMaintenance reads the maintenance in effect, or nil if there is none.
+func LoadMaintenance() *proxy.Maintenance {
+	data, err := os.ReadFile(filepath.Join(utils.GetDataDir(), maintenanceFile))
+	if err != nil {
+		return nil
+	}
+	var m proxy.Maintenance
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return &m
+}
+
+// saveMaintenance persists m and writes out the page it serves; a nil m
+// removes both.
+func saveMaintenance(m *proxy.Maintenance) error {
+	dataDir := utils.GetDataDir()
+	statePath := filepath.Join(dataDir, maintenanceFile)
+	pageDir := filepath.Join(dataDir, maintenanceDir)
+	if m == nil {
+		if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.RemoveAll(pageDir)
+	}
+
+	page := defaultMaintenancePage
+	if m.Page != "" {
+		custom, err := os.ReadFile(m.Page)
+		if err != nil {
+			return fmt.Errorf("unable to read maintenance page: %w", err)
+		}
+		page = custom
+	}
+	if err := os.MkdirAll(pageDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(pageDir, "index.html"), page, 0644); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, data, 0644)
+}
+
 // LoadState reads app config from apps.json.
 // Returns an empty map if the file doesn't exist.
 func LoadState() map[string]proxy.App {
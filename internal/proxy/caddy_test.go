@@ -0,0 +1,162 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package proxy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/dployr-io/dployr/pkg/core/proxy"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+func renderApp(t *testing.T, domain string, app proxy.App) string {
+	t.Helper()
+	tmpl, err := template.ParseFS(templateFS, "templates/*.tpl")
+	if err != nil {
+		t.Fatalf("parse templates: %v", err)
+	}
+	var b strings.Builder
+	data := AppTemplateData{Domain: domain, Address: siteAddress(domain, app), App: app, LogFile: "/tmp/app.log"}
+	if err := tmpl.ExecuteTemplate(&b, string(app.Template)+".tpl", data); err != nil {
+		t.Fatalf("execute %s: %v", app.Template, err)
+	}
+	return b.String()
+}
+
+func TestSiteTemplates_TLSModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		app       proxy.App
+		wantFirst string
+		wantTLS   string
+		wantNoTLS bool
+	}{
+		{
+			name:      "default domain stays on http",
+			app:       proxy.App{Template: proxy.TemplateReverseProxy, Upstream: "localhost:62000"},
+			wantFirst: "http://app.example.com {",
+			wantNoTLS: true,
+		},
+		{
+			name:      "acme relies on automatic https",
+			app:       proxy.App{Template: proxy.TemplateReverseProxy, Upstream: "localhost:62000", TLS: store.TLSAcme},
+			wantFirst: "app.example.com {",
+			wantNoTLS: true,
+		},
+		{
+			name:      "internal ca",
+			app:       proxy.App{Template: proxy.TemplateStatic, Root: "/srv/app", TLS: store.TLSInternal},
+			wantFirst: "app.example.com {",
+			wantTLS:   "tls internal",
+		},
+		{
+			name:      "custom pair",
+			app:       proxy.App{Template: proxy.TemplatePHPFastCGI, Upstream: "unix//run/php.sock", TLS: store.TLSCustom, CertFile: "/etc/c.pem", KeyFile: "/etc/k.pem"},
+			wantFirst: "app.example.com {",
+			wantTLS:   "tls /etc/c.pem /etc/k.pem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderApp(t, "app.example.com", tt.app)
+			lines := strings.Split(out, "\n")
+			if lines[0] != tt.wantFirst {
+				t.Errorf("site address line = %q, want %q", lines[0], tt.wantFirst)
+			}
+			if tt.wantNoTLS && strings.Contains(out, "\ttls ") {
+				t.Errorf("unexpected tls directive:\n%s", out)
+			}
+			if tt.wantTLS != "" && strings.TrimSpace(lines[1]) != tt.wantTLS {
+				t.Errorf("line after address = %q, want %q\n%s", lines[1], tt.wantTLS, out)
+			}
+		})
+	}
+}
+
+func TestReverseProxyTemplate_Replicas(t *testing.T) {
+	single := renderApp(t, "app.example.com", proxy.App{Template: proxy.TemplateReverseProxy, Upstream: "localhost:62000"})
+	if !strings.Contains(single, "reverse_proxy localhost:62000 {") || strings.Contains(single, "lb_policy") {
+		t.Errorf("single upstream rendered unexpectedly:\n%s", single)
+	}
+
+	out := renderApp(t, "app.example.com", proxy.App{
+		Template:  proxy.TemplateReverseProxy,
+		Upstream:  "localhost:62000",
+		Upstreams: []string{"localhost:62000", "localhost:62001", "localhost:62002"},
+		HealthURI: "/healthz",
+	})
+	for _, want := range []string{
+		"reverse_proxy localhost:62000 localhost:62001 localhost:62002 {",
+		"lb_policy least_conn",
+		"health_uri /healthz",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	noProbe := renderApp(t, "app.example.com", proxy.App{
+		Template:  proxy.TemplateReverseProxy,
+		Upstreams: []string{"localhost:62000", "localhost:62001"},
+	})
+	if strings.Contains(noProbe, "health_uri") {
+		t.Errorf("health_uri rendered without a health check path:\n%s", noProbe)
+	}
+}
+
+func TestRenderSites_Maintenance(t *testing.T) {
+	tmpl, err := template.ParseFS(templateFS, "templates/*.tpl")
+	if err != nil {
+		t.Fatalf("parse templates: %v", err)
+	}
+	apps := map[string]proxy.App{
+		"api.example.com":  {Template: proxy.TemplateReverseProxy, Upstream: "localhost:62000", TLS: store.TLSInternal},
+		"docs.example.com": {Template: proxy.TemplateStatic, Root: "/srv/docs"},
+	}
+	c := Init(nil, shared.NewLogger())
+
+	tests := []struct {
+		name     string
+		m        *proxy.Maintenance
+		wantDown []string
+	}{
+		{name: "no maintenance", m: nil},
+		{name: "every domain", m: &proxy.Maintenance{}, wantDown: []string{"api.example.com", "docs.example.com"}},
+		{name: "selected domain", m: &proxy.Maintenance{Domains: []string{"api.example.com"}}, wantDown: []string{"api.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := c.renderSites(tmpl, apps, tt.m, "/var/lib/dployrd", "/var/log")
+			if err != nil {
+				t.Fatalf("renderSites: %v", err)
+			}
+			if got := strings.Count(out, "status 503"); got != len(tt.wantDown) {
+				t.Errorf("%d sites serve the maintenance page, want %d:\n%s", got, len(tt.wantDown), out)
+			}
+			for _, domain := range tt.wantDown {
+				if !tt.m.Covers(domain) {
+					t.Errorf("Covers(%s) = false", domain)
+				}
+			}
+			if !slices.Contains(tt.wantDown, "api.example.com") && !strings.Contains(out, "reverse_proxy localhost:62000") {
+				t.Errorf("route outside maintenance was not rendered:\n%s", out)
+			}
+			if slices.Contains(tt.wantDown, "api.example.com") {
+				if strings.Contains(out, "reverse_proxy") {
+					t.Errorf("route under maintenance still proxies:\n%s", out)
+				}
+				if !strings.Contains(out, "tls internal") {
+					t.Errorf("site under maintenance lost its tls settings:\n%s", out)
+				}
+			}
+			if !slices.Contains(tt.wantDown, "docs.example.com") && !strings.Contains(out, "root * /srv/docs") {
+				t.Errorf("static route outside maintenance was not rendered:\n%s", out)
+			}
+		})
+	}
+}
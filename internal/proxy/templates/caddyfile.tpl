@@ -3,7 +3,6 @@
 	admin localhost:2019
 	http_port 80
 	https_port 443
-	auto_https off
 }
 
 {{.Content}}
\ No newline at end of file
@@ -0,0 +1,15 @@
+{{.Address}} {
+{{- template "tls" .}}
+	root * {{.MaintenanceRoot}}
+	rewrite * /index.html
+	header Cache-Control "no-store"
+	header Retry-After 300
+	file_server {
+		status 503
+	}
+
+	log {
+		output file {{.LogFile}}
+		format json
+	}
+}
\ No newline at end of file
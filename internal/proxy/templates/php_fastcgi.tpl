@@ -1,4 +1,5 @@
-{{.Domain}} {
+{{.Address}} {
+{{- template "tls" .}}
 	{{if .App.Root}}
 	root * {{.App.Root}}
 	{{else}}
@@ -1,9 +1,20 @@
-http://{{.Domain}} {
-	reverse_proxy {{.App.Upstream}} {
+{{.Address}} {
+{{- template "tls" .}}
+	reverse_proxy {{if .App.Upstreams}}{{range $i, $u := .App.Upstreams}}{{if $i}} {{end}}{{$u}}{{end}}{{else}}{{.App.Upstream}}{{end}} {
 		header_up Host {upstream_hostport}
 		header_up X-Real-IP {remote_host}
 		header_up X-Forwarded-For {remote_host}
 		header_up X-Forwarded-Proto {scheme}
+{{- if .App.Upstreams}}
+
+		lb_policy least_conn
+		fail_duration 30s
+{{- if .App.HealthURI}}
+		health_uri {{.App.HealthURI}}
+		health_interval 10s
+		health_timeout 5s
+{{- end}}
+{{- end}}
 	}
 	
 	log {
@@ -1,4 +1,5 @@
-{{.Domain}} {
+{{.Address}} {
+{{- template "tls" .}}
     root * {{.App.Root}}
     file_server
     encode gzip
@@ -0,0 +1,5 @@
+{{define "tls"}}{{if eq .App.TLS "internal"}}
+	tls internal
+{{- else if eq .App.TLS "custom"}}
+	tls {{.App.CertFile}} {{.App.KeyFile}}
+{{- end}}{{end}}
\ No newline at end of file
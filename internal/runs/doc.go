@@ -0,0 +1,6 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package runs starts one-off commands against deployed services in
+// ephemeral containers, records how each ended and keeps its output.
+package runs
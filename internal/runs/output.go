@@ -0,0 +1,222 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package runs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dployr-io/dployr/internal/deploy"
+	corelogs "github.com/dployr-io/dployr/pkg/core/logs"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// sinkTimeout bounds how long one chunk may take to reach the base before
+// it is dropped; the run's log still has it.
+const sinkTimeout = 10 * time.Second
+
+// output writes a run's stdout and stderr to its log, one JSON entry per
+// line in the same form as collected service logs, and batches the entries
+// into log chunks when the run is streamed.
+type output struct {
+	r        *Runner
+	runID    string
+	path     string // log chunk path
+	streamID string
+	sink     LogSink
+	file     *os.File // nil when the log could not be opened
+
+	mu      sync.Mutex
+	writers []*lineWriter
+	batch   []corelogs.LogEntry
+	offset  int64 // bytes written to the log
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// openOutput opens a run's log. It always returns a usable output, so a run
+// whose log cannot be written still runs and is recorded.
+func (r *Runner) openOutput(run store.ServiceRun, streamID string) (*output, error) {
+	o := &output{
+		r:        r,
+		runID:    run.ID,
+		path:     "service:" + deploy.RunName(run.Service, run.ID),
+		streamID: streamID,
+	}
+	if streamID != "" {
+		o.sink = r.getSink()
+	}
+	if o.sink != nil {
+		o.stop = make(chan struct{})
+		o.stopped = make(chan struct{})
+		go o.flushLoop()
+	}
+
+	var err error
+	if err = os.MkdirAll(r.logDir, 0755); err == nil {
+		o.file, err = os.OpenFile(r.logPath(run.Service, run.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	}
+	return o, err
+}
+
+// stream returns the writer for one of the run's output streams.
+func (o *output) stream(name string) io.Writer {
+	w := &lineWriter{o: o, stream: name}
+	o.mu.Lock()
+	o.writers = append(o.writers, w)
+	o.mu.Unlock()
+	return w
+}
+
+// write records one line of output.
+func (o *output) write(stream, line string, attrs map[string]any) {
+	if attrs == nil {
+		attrs = map[string]any{}
+	}
+	attrs["run_id"] = o.runID
+	if stream != "" {
+		attrs["stream"] = stream
+	}
+	e := corelogs.LogEntry{
+		Time:  o.r.now().UTC().Format(time.RFC3339Nano),
+		Level: "INFO",
+		Msg:   strings.TrimRight(line, "\r"),
+		Attrs: attrs,
+	}
+
+	o.mu.Lock()
+	if o.file != nil {
+		if b, err := json.Marshal(e); err == nil {
+			n, _ := o.file.Write(append(b, '\n'))
+			o.offset += int64(n)
+		}
+	}
+	full := false
+	if o.sink != nil {
+		o.batch = append(o.batch, e)
+		full = len(o.batch) >= o.batchSize()
+	}
+	o.mu.Unlock()
+
+	if full {
+		o.flush(false)
+	}
+}
+
+func (o *output) batchSize() int {
+	if n := o.r.cfg.LogBatchSize; n > 0 {
+		return n
+	}
+	return 50
+}
+
+func (o *output) flushLoop() {
+	defer close(o.stopped)
+	interval := o.r.cfg.LogBatchTimeout
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			o.flush(false)
+		}
+	}
+}
+
+// flush sends the pending entries as one chunk. The last chunk of a run is
+// sent with eof set, even when empty, so the base knows the stream is over.
+func (o *output) flush(eof bool) {
+	o.mu.Lock()
+	entries := o.batch
+	o.batch = nil
+	offset := o.offset
+	o.mu.Unlock()
+	if len(entries) == 0 && !eof {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+	defer cancel()
+	err := o.sink(ctx, corelogs.LogChunk{
+		StreamID: o.streamID,
+		Path:     o.path,
+		Entries:  entries,
+		EOF:      eof,
+		Offset:   offset,
+	})
+	if err != nil {
+		o.r.logger.Debug("failed to stream run output", "run_id", o.runID, "stream_id", o.streamID, "error", err)
+	}
+}
+
+// finish writes out partial lines and a closing entry with the run's
+// outcome, sends the last chunk and closes the log.
+func (o *output) finish(run store.ServiceRun) {
+	o.mu.Lock()
+	writers := o.writers
+	o.mu.Unlock()
+	for _, w := range writers {
+		w.flush()
+	}
+
+	attrs := map[string]any{"status": run.Status}
+	msg := fmt.Sprintf("run %s", run.Status)
+	if run.ExitCode != nil {
+		attrs["exit_code"] = *run.ExitCode
+		msg = fmt.Sprintf("run exited with code %d", *run.ExitCode)
+	}
+	if run.Error != "" {
+		msg += ": " + run.Error
+	}
+	o.write("", msg, attrs)
+
+	if o.sink != nil {
+		close(o.stop)
+		<-o.stopped
+		o.flush(true)
+	}
+	if o.file != nil {
+		o.file.Close()
+	}
+}
+
+// lineWriter splits one output stream into lines.
+type lineWriter struct {
+	o      *output
+	stream string
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.o.write(w.stream, string(w.buf[:i]), nil)
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes out a trailing line that had no newline.
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.o.write(w.stream, string(w.buf), nil)
+		w.buf = nil
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package runs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/internal/deploy"
+	corelogs "github.com/dployr-io/dployr/pkg/core/logs"
+	"github.com/dployr-io/dployr/pkg/core/runs"
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// maxOutputRead bounds how much of a run's log one Output call returns.
+const maxOutputRead = 1 << 20
+
+// abortWait bounds how long Drain waits for runs to unwind once they are
+// killed.
+const abortWait = 10 * time.Second
+
+// runDocker is the subset of the Docker client the runner uses: what a
+// one-off run needs, and listing containers to clean up after a crash.
+type runDocker interface {
+	deploy.RunDockerAPI
+	ContainerList(ctx context.Context, options container.ListOptions) ([]dockertypes.Container, error)
+}
+
+// LogSink delivers a chunk of run output to the base. The executor's
+// SendLogChunk satisfies it.
+type LogSink func(ctx context.Context, chunk corelogs.LogChunk) error
+
+// Runner runs one-off commands against deployed services. Each run gets a
+// fresh container from the service's current image and blueprint, so it sees
+// the same environment, secrets, limits and cluster slice as the service.
+// Output goes to a per-run log in the service log directory, from where it
+// can be read back by offset, and to the base as log chunks when the run was
+// started with a stream ID.
+type Runner struct {
+	logger   *shared.Logger
+	cfg      *shared.Config
+	services store.ServiceStore
+	deps     store.DeploymentStore
+	runs     store.ServiceRunStore
+	docker   runDocker
+	logDir   string
+	now      func() time.Time
+
+	base  context.Context // parent of every run's context
+	abort context.CancelFunc
+
+	mu       sync.Mutex
+	sink     LogSink
+	draining bool
+	wg       sync.WaitGroup // tracks execute goroutines
+}
+
+func NewRunner(logger *shared.Logger, cfg *shared.Config, services store.ServiceStore, deps store.DeploymentStore, runs store.ServiceRunStore, docker runDocker) *Runner {
+	base, abort := context.WithCancel(context.Background())
+	return &Runner{
+		base:     base,
+		abort:    abort,
+		logger:   logger,
+		cfg:      cfg,
+		services: services,
+		deps:     deps,
+		runs:     runs,
+		docker:   docker,
+		logDir:   utils.GetServiceLogDir(),
+		now:      time.Now,
+	}
+}
+
+// SetLogSink streams the output of runs started with a stream ID.
+func (r *Runner) SetLogSink(fn LogSink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sink = fn
+}
+
+func (r *Runner) getSink() LogSink {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sink
+}
+
+// Recover fails runs a previous daemon left running and removes their
+// containers. It must run before any new run is started.
+func (r *Runner) Recover(ctx context.Context) {
+	if n, err := r.runs.FailInterruptedServiceRuns(ctx); err != nil {
+		r.logger.Error("failed to recover interrupted runs", "error", err)
+	} else if n > 0 {
+		r.logger.Warn("marked interrupted runs as failed", "count", n)
+	}
+
+	leftover, err := r.docker.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", utils.RunLabel)),
+	})
+	if err != nil {
+		r.logger.Warn("failed to list leftover run containers", "error", err)
+		return
+	}
+	for _, c := range leftover {
+		if err := r.docker.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err != nil {
+			r.logger.Warn("failed to remove leftover run container", "container", c.ID, "error", err)
+		}
+	}
+}
+
+// Run validates req, records the run and starts its container in the
+// background.
+func (r *Runner) Run(ctx context.Context, req runs.RunRequest) (*store.ServiceRun, error) {
+	if len(req.Command) == 0 || strings.TrimSpace(req.Command[0]) == "" {
+		return nil, fmt.Errorf("%w: command is empty", runs.ErrInvalidRun)
+	}
+	timeout := r.cfg.RunTimeout
+	if req.Timeout != "" {
+		t, err := time.ParseDuration(req.Timeout)
+		if err != nil || t <= 0 {
+			return nil, fmt.Errorf("%w: timeout %q is not a positive duration", runs.ErrInvalidRun, req.Timeout)
+		}
+		timeout = t
+	}
+	if r.cfg.RunMaxTimeout > 0 && timeout > r.cfg.RunMaxTimeout {
+		return nil, fmt.Errorf("%w: timeout %s is longer than the node's limit of %s", runs.ErrInvalidRun, timeout, r.cfg.RunMaxTimeout)
+	}
+
+	svc, err := r.services.GetService(ctx, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if svc == nil {
+		return nil, fmt.Errorf("%w: %s", runs.ErrServiceNotFound, req.Name)
+	}
+	if svc.Type != store.TypeWeb && svc.Type != store.TypeWorker {
+		return nil, fmt.Errorf("%w: %s services do not run a container", runs.ErrNotRunnable, svc.Type)
+	}
+	d, err := r.deps.GetDeployment(ctx, svc.DeploymentId)
+	if err != nil || d == nil {
+		return nil, fmt.Errorf("failed to get deployment for service %s: %v", req.Name, err)
+	}
+	if d.Status == store.StatusPending || d.Status == store.StatusInProgress {
+		return nil, fmt.Errorf("%w: %s has a deployment in progress", runs.ErrNotRunnable, req.Name)
+	}
+	if d.Blueprint.Image == "" {
+		return nil, fmt.Errorf("%w: %s has no image", runs.ErrNotRunnable, req.Name)
+	}
+
+	bp := d.Blueprint
+	if bp.Secrets, err = r.deps.OpenSecrets(bp.Secrets); err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets: %w", err)
+	}
+
+	run := &store.ServiceRun{
+		ID:        ulid.Make().String(),
+		Service:   svc.Name,
+		Command:   req.Command,
+		Image:     bp.Image,
+		Status:    store.ServiceRunRunning,
+		TimeoutMs: timeout.Milliseconds(),
+		StartedAt: r.now(),
+	}
+	if user, err := shared.UserFromContext(ctx); err == nil {
+		run.Subject = user.ID
+	}
+
+	// Counted before it is recorded, so Drain cannot miss it.
+	r.mu.Lock()
+	if r.draining {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: the node is shutting down", runs.ErrNotRunnable)
+	}
+	r.wg.Add(1)
+	r.mu.Unlock()
+	if err := r.runs.CreateServiceRun(ctx, run); err != nil {
+		r.wg.Done()
+		return nil, err
+	}
+
+	r.logger.Info("starting run", "service", svc.Name, "run_id", run.ID, "timeout", timeout)
+	go r.execute(*run, bp, timeout, req.StreamID)
+	return run, nil
+}
+
+// execute runs one command to completion and records how it ended.
+func (r *Runner) execute(run store.ServiceRun, bp store.Blueprint, timeout time.Duration, streamID string) {
+	defer r.wg.Done()
+
+	ctx, cancel := context.WithTimeout(r.base, timeout)
+	defer cancel()
+
+	out, err := r.openOutput(run, streamID)
+	if err != nil {
+		r.logger.Error("failed to open run log", "run_id", run.ID, "error", err)
+	}
+
+	cc := deploy.RunConfig(bp, run.Service, run.ID, run.Command, r.cfg)
+	code, runErr := deploy.RunOneOff(ctx, cc, out.stream("stdout"), out.stream("stderr"), r.docker)
+	finished := r.now()
+
+	switch {
+	case runErr == nil:
+		run.ExitCode = &code
+		run.Status = store.ServiceRunSucceeded
+		if code != 0 {
+			run.Status = store.ServiceRunFailed
+		}
+	case errors.Is(runErr, context.DeadlineExceeded):
+		run.Status = store.ServiceRunTimedOut
+		run.Error = fmt.Sprintf("killed after %s", timeout)
+	case errors.Is(runErr, context.Canceled):
+		run.Status = store.ServiceRunFailed
+		run.Error = "killed when the daemon shut down"
+	default:
+		run.Status = store.ServiceRunFailed
+		run.Error = runErr.Error()
+	}
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	out.finish(run)
+
+	if err := r.runs.FinishServiceRun(context.Background(), &run); err != nil {
+		r.logger.Error("failed to record run", "service", run.Service, "run_id", run.ID, "error", err)
+		return
+	}
+	r.logger.Info("run finished", "service", run.Service, "run_id", run.ID, "status", run.Status, "duration_ms", run.DurationMs)
+}
+
+// Wait blocks until every run in progress has finished.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// Drain stops the runner starting new runs and waits for the running ones to
+// finish. Runs still going when ctx ends are killed, their containers
+// removed, and recorded as failed.
+func (r *Runner) Drain(ctx context.Context) error {
+	r.mu.Lock()
+	r.draining = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	r.logger.Warn("grace period over, killing running runs")
+	r.abort()
+	select {
+	case <-done:
+	case <-time.After(abortWait):
+		r.logger.Error("runs did not stop after being killed")
+	}
+	return ctx.Err()
+}
+
+// Output returns a run with the entries its log holds past offset. Only
+// whole lines are returned, so an entry still being written is left for the
+// next call.
+func (r *Runner) Output(ctx context.Context, id string, offset int64) (*runs.RunOutput, error) {
+	run, err := r.runs.GetServiceRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("%w: %s", runs.ErrRunNotFound, id)
+	}
+
+	out := &runs.RunOutput{Run: run, Entries: []corelogs.LogEntry{}, Offset: offset}
+	f, err := os.Open(r.logPath(run.Service, run.ID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return out, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, maxOutputRead)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	buf = buf[:n]
+	if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i+1]
+	} else {
+		buf = nil
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(buf))
+	sc.Buffer(make([]byte, 64*1024), maxOutputRead)
+	for sc.Scan() {
+		var e corelogs.LogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		out.Entries = append(out.Entries, e)
+	}
+	out.Offset = offset + int64(len(buf))
+	return out, nil
+}
+
+func (r *Runner) ListRuns(ctx context.Context, name string, limit int) ([]*store.ServiceRun, error) {
+	return r.runs.ListServiceRuns(ctx, name, limit)
+}
+
+// logPath is where a run's output is kept. It is named after the run's
+// container, which is also the path of its log chunks, so a "service:" log
+// stream of that name replays it.
+func (r *Runner) logPath(service, runID string) string {
+	return filepath.Join(r.logDir, deploy.RunName(service, runID)+".log")
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package runs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	corelogs "github.com/dployr-io/dployr/pkg/core/logs"
+	"github.com/dployr-io/dployr/pkg/core/runs"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// fakeDocker plays a run container that prints stdout and exits with code,
+// or runs until it is killed when hang is set.
+type fakeDocker struct {
+	stdout string
+	code   int64
+	hang   bool
+}
+
+func (f *fakeDocker) ContainerCreate(context.Context, *container.Config, *container.HostConfig, *network.NetworkingConfig, *specs.Platform, string) (container.CreateResponse, error) {
+	return container.CreateResponse{ID: "run-container"}, nil
+}
+
+func (f *fakeDocker) ContainerStart(context.Context, string, container.StartOptions) error {
+	return nil
+}
+
+func (f *fakeDocker) ContainerWait(ctx context.Context, _ string, _ container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	res := make(chan container.WaitResponse, 1)
+	errs := make(chan error, 1)
+	if f.hang {
+		go func() {
+			<-ctx.Done()
+			errs <- ctx.Err()
+		}()
+	} else {
+		res <- container.WaitResponse{StatusCode: f.code}
+	}
+	return res, errs
+}
+
+func (f *fakeDocker) ContainerLogs(ctx context.Context, _ string, _ container.LogsOptions) (io.ReadCloser, error) {
+	var buf bytes.Buffer
+	stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(f.stdout))
+	return io.NopCloser(&buf), nil
+}
+
+func (f *fakeDocker) ContainerRemove(context.Context, string, container.RemoveOptions) error {
+	return nil
+}
+
+func (f *fakeDocker) ContainerList(context.Context, container.ListOptions) ([]dockertypes.Container, error) {
+	return nil, nil
+}
+
+type memRunStore struct {
+	mu   sync.Mutex
+	runs map[string]store.ServiceRun
+}
+
+func (m *memRunStore) CreateServiceRun(ctx context.Context, r *store.ServiceRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[r.ID] = *r
+	return nil
+}
+
+func (m *memRunStore) FinishServiceRun(ctx context.Context, r *store.ServiceRun) error {
+	return m.CreateServiceRun(ctx, r)
+}
+
+func (m *memRunStore) GetServiceRun(ctx context.Context, id string) (*store.ServiceRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.runs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (m *memRunStore) ListServiceRuns(ctx context.Context, service string, limit int) ([]*store.ServiceRun, error) {
+	return nil, nil
+}
+
+func (m *memRunStore) FailInterruptedServiceRuns(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+type memServices struct {
+	store.ServiceStore
+	services map[string]*store.Service
+}
+
+func (m *memServices) GetService(ctx context.Context, name string) (*store.Service, error) {
+	return m.services[name], nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	deployments map[string]*store.Deployment
+}
+
+func (m *memDeployments) GetDeployment(ctx context.Context, id string) (*store.Deployment, error) {
+	return m.deployments[id], nil
+}
+
+func (m *memDeployments) OpenSecrets(secrets map[string]string) (map[string]string, error) {
+	return secrets, nil
+}
+
+func newTestRunner(t *testing.T, docker *fakeDocker) (*Runner, *memRunStore) {
+	t.Helper()
+	services := &memServices{services: map[string]*store.Service{
+		"api":  {Name: "api", Type: store.TypeWeb, DeploymentId: "d1"},
+		"site": {Name: "site", Type: store.TypeStatic, DeploymentId: "d2"},
+	}}
+	deployments := &memDeployments{deployments: map[string]*store.Deployment{
+		"d1": {ID: "d1", Status: store.StatusCompleted, Blueprint: store.Blueprint{Name: "api", Image: "apps:api-1", Type: store.TypeWeb}},
+		"d2": {ID: "d2", Status: store.StatusCompleted, Blueprint: store.Blueprint{Name: "site", Type: store.TypeStatic}},
+	}}
+	rs := &memRunStore{runs: map[string]store.ServiceRun{}}
+	cfg := &shared.Config{RunTimeout: time.Minute, RunMaxTimeout: time.Hour, LogBatchSize: 50, LogBatchTimeout: time.Millisecond}
+	r := NewRunner(shared.NewLogger(), cfg, services, deployments, rs, docker)
+	r.logDir = t.TempDir()
+	return r, rs
+}
+
+func TestRun_RecordsExitCodeAndOutput(t *testing.T) {
+	r, rs := newTestRunner(t, &fakeDocker{stdout: "migrating\ndone\n", code: 2})
+
+	run, err := r.Run(context.Background(), runs.RunRequest{Name: "api", Command: []string{"migrate"}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	r.Wait()
+
+	got, _ := rs.GetServiceRun(context.Background(), run.ID)
+	if got.Status != store.ServiceRunFailed || got.ExitCode == nil || *got.ExitCode != 2 {
+		t.Fatalf("run = %+v, want failed with exit code 2", got)
+	}
+
+	out, err := r.Output(context.Background(), run.ID, 0)
+	if err != nil {
+		t.Fatalf("Output: %v", err)
+	}
+	var msgs []string
+	for _, e := range out.Entries {
+		msgs = append(msgs, e.Msg)
+	}
+	want := []string{"migrating", "done", "run exited with code 2"}
+	if len(msgs) != len(want) {
+		t.Fatalf("entries = %q, want %q", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, msgs[i], want[i])
+		}
+	}
+	if out.Entries[0].Attrs["stream"] != "stdout" {
+		t.Errorf("stream = %v, want stdout", out.Entries[0].Attrs["stream"])
+	}
+
+	more, err := r.Output(context.Background(), run.ID, out.Offset)
+	if err != nil || len(more.Entries) != 0 || more.Offset != out.Offset {
+		t.Errorf("read past the end = %+v, %v; want no entries", more, err)
+	}
+}
+
+func TestRun_StreamsChunks(t *testing.T) {
+	r, _ := newTestRunner(t, &fakeDocker{stdout: "hello\n"})
+	var mu sync.Mutex
+	var chunks []corelogs.LogChunk
+	r.SetLogSink(func(ctx context.Context, c corelogs.LogChunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, c)
+		return nil
+	})
+
+	run, err := r.Run(context.Background(), runs.RunRequest{Name: "api", Command: []string{"echo", "hello"}, StreamID: "s1"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	r.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chunks) == 0 || !chunks[len(chunks)-1].EOF {
+		t.Fatalf("chunks = %+v, want a final eof chunk", chunks)
+	}
+	var entries []corelogs.LogEntry
+	for _, c := range chunks {
+		if c.StreamID != "s1" || c.Path != "service:api-run-"+strings.ToLower(run.ID) {
+			t.Errorf("chunk stream = %q path = %q", c.StreamID, c.Path)
+		}
+		entries = append(entries, c.Entries...)
+	}
+	if len(entries) != 2 || entries[0].Msg != "hello" || entries[1].Attrs["exit_code"] != 0 {
+		t.Errorf("entries = %+v, want the output then the exit code", entries)
+	}
+}
+
+func TestRun_TimesOut(t *testing.T) {
+	r, rs := newTestRunner(t, &fakeDocker{hang: true})
+
+	run, err := r.Run(context.Background(), runs.RunRequest{Name: "api", Command: []string{"sleep", "infinity"}, Timeout: "20ms"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	r.Wait()
+
+	got, _ := rs.GetServiceRun(context.Background(), run.ID)
+	if got.Status != store.ServiceRunTimedOut || got.ExitCode != nil {
+		t.Errorf("run = %+v, want timed out without an exit code", got)
+	}
+}
+
+func TestDrain_KillsRunsAtEndOfGrace(t *testing.T) {
+	r, rs := newTestRunner(t, &fakeDocker{hang: true})
+
+	run, err := r.Run(context.Background(), runs.RunRequest{Name: "api", Command: []string{"sleep", "infinity"}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain = %v, want the grace period to run out", err)
+	}
+	got, _ := rs.GetServiceRun(context.Background(), run.ID)
+	if got.Status != store.ServiceRunFailed || got.FinishedAt == nil {
+		t.Errorf("run = %+v, want failed and finished", got)
+	}
+
+	if _, err := r.Run(context.Background(), runs.RunRequest{Name: "api", Command: []string{"ls"}}); !errors.Is(err, runs.ErrNotRunnable) {
+		t.Errorf("Run after Drain = %v, want ErrNotRunnable", err)
+	}
+}
+
+func TestRun_Refused(t *testing.T) {
+	r, _ := newTestRunner(t, &fakeDocker{})
+	tests := []struct {
+		name string
+		req  runs.RunRequest
+		want error
+	}{
+		{"unknown service", runs.RunRequest{Name: "nope", Command: []string{"ls"}}, runs.ErrServiceNotFound},
+		{"static service", runs.RunRequest{Name: "site", Command: []string{"ls"}}, runs.ErrNotRunnable},
+		{"empty command", runs.RunRequest{Name: "api", Command: []string{""}}, runs.ErrInvalidRun},
+		{"bad timeout", runs.RunRequest{Name: "api", Command: []string{"ls"}, Timeout: "soon"}, runs.ErrInvalidRun},
+		{"timeout over limit", runs.RunRequest{Name: "api", Command: []string{"ls"}, Timeout: "2h"}, runs.ErrInvalidRun},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.Run(context.Background(), tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
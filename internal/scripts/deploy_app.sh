@@ -5,8 +5,9 @@
 
 # deploy_app.sh — unified deployment script
 # Handles runtime setup, build, and service installation in one go
-# Usage: deploy_app.sh <action> <service_name> <source> <type> <runtime> <version> <workdir> <run_cmd> <description> <build_cmd> <port> <host_port> [image] [static_dir] [memory_mb] [cpu_millicores] [storage_gb] [build_memory_mb] [cluster_id]
+# Usage: deploy_app.sh <action> <service_name> <source> <type> <runtime> <version> <workdir> <run_cmd> <description> <build_cmd> <port> <host_port> [image] [static_dir] [memory_mb] [cpu_millicores] [storage_gb] [build_memory_mb] [cluster_id] [schedule]
 # Env vars and health_check are read from config.toml in the workdir; resource limits are positional args
+# Secrets arrive in the environment as DPLOYR_SECRET_<KEY> and are never written to the workdir
 
 set -euo pipefail
 
@@ -30,6 +31,7 @@ CPU="${16:-0}"
 STORAGE="${17:-0}"
 BUILD_MEMORY="${18:-0}"
 CLUSTER_ID="${19:-}"
+SCHEDULE="${20:-}"
 
 # --- logging ---
 log() { echo "[INFO] $*" >&2; }
@@ -239,12 +241,7 @@ create_env_file() {
                 
                 echo "${key}=${value}" >> "$env_file"
                 written_keys[$key]=1
-                
-                if [ "$current_section" = "secrets" ]; then
-                    log "Adding secret: $key=***"
-                else
-                    log "Adding env var: $key=$value"
-                fi
+                log "Adding env var: $key=$value"
             fi
         done < "$config_file"
     else
@@ -254,6 +251,36 @@ create_env_file() {
     log "Environment variables written to .env file"
 }
 
+# Prints the secrets passed in as DPLOYR_SECRET_<KEY>, one KEY=value per line
+# with the value shell-quoted for sourcing.
+secret_lines() {
+    local var
+    for var in "${!DPLOYR_SECRET_@}"; do
+        printf '%s=%q\n' "${var#DPLOYR_SECRET_}" "${!var}"
+    done
+}
+
+# Seals the secrets into an encrypted systemd credential bound to this host,
+# which systemd decrypts into the unit's private credentials directory when
+# it starts. Prints the LoadCredentialEncrypted= line for the unit, or nothing
+# when the service has no secrets.
+seal_secrets() {
+    local service_name="$1"
+    local cred_file="${HOME}/.dployr/creds/${service_name}.cred"
+
+    if [ -z "$(secret_lines)" ]; then
+        sudo rm -f "$cred_file"
+        return 0
+    fi
+    command -v systemd-creds >/dev/null 2>&1 || abort "secrets for jobs need systemd-creds (systemd 250 or later)"
+
+    mkdir -p "$(dirname "$cred_file")"
+    secret_lines | sudo systemd-creds encrypt --name=secrets - "$cred_file" >/dev/null \
+        || abort "failed to seal secrets for $service_name"
+    log "Sealed secrets for $service_name"
+    echo "LoadCredentialEncrypted=secrets:${cred_file}"
+}
+
 create_service_exe() {
     local service_name="$1"
     local runtime="$2"
@@ -280,6 +307,13 @@ else
     exit 1
 fi
 
+# Secrets decrypted by systemd for this run only
+if [ -n "\${CREDENTIALS_DIRECTORY:-}" ] && [ -f "\${CREDENTIALS_DIRECTORY}/secrets" ]; then
+    set -a
+    source "\${CREDENTIALS_DIRECTORY}/secrets"
+    set +a
+fi
+
 cd "${workdir}" || exit 1
 
 exec ${run_cmd}
@@ -309,6 +343,14 @@ systemd_install() {
     cluster_slice=$(ensure_cluster_slice)
     local slice_line=""
     [ -n "$cluster_slice" ] && slice_line="Slice=${cluster_slice}"
+    local creds_line
+    creds_line=$(seal_secrets "$service_name")
+
+    if [ -n "$SCHEDULE" ]; then
+        systemd_install_scheduled "$service_name" "$description" "$exe_script" "$workdir" "$slice_line" "$creds_line"
+        return
+    fi
+    sudo rm -f "/etc/systemd/system/${service_name}@.service"
 
     sudo tee "/etc/systemd/system/${service_name}.service" > /dev/null <<EOF
 [Unit]
@@ -323,6 +365,7 @@ ExecStart=${exe_script}
 StandardOutput=append:${log_file}
 StandardError=append:${log_file}
 ${slice_line}
+${creds_line}
 Restart=always
 RestartSec=10
 
@@ -335,6 +378,46 @@ EOF
     log "Service $service_name installed and enabled"
 }
 
+# Scheduled jobs are installed as a one-shot template unit that dployrd starts
+# as <name>@<run-id> for every run, each run logging to its own file. Nothing
+# is started here.
+systemd_install_scheduled() {
+    local service_name="$1"
+    local description="$2"
+    local exe_script="$3"
+    local workdir="$4"
+    local slice_line="$5"
+    local creds_line="$6"
+
+    local log_dir="${HOME}/.dployr/logs"
+
+    # Replace the long-running unit if the job was deployed without a schedule before.
+    if [ -f "/etc/systemd/system/${service_name}.service" ]; then
+        sudo systemctl stop "$service_name" 2>/dev/null || true
+        sudo systemctl disable "$service_name" 2>/dev/null || true
+        sudo rm -f "/etc/systemd/system/${service_name}.service"
+    fi
+
+    sudo tee "/etc/systemd/system/${service_name}@.service" > /dev/null <<EOF
+[Unit]
+Description=${description} (run %i)
+After=network.target
+
+[Service]
+Type=oneshot
+User=dployrd
+WorkingDirectory=${workdir}
+ExecStart=${exe_script}
+StandardOutput=append:${log_dir}/${service_name}-%i.log
+StandardError=append:${log_dir}/${service_name}-%i.log
+${slice_line}
+${creds_line}
+EOF
+
+    sudo systemctl daemon-reload
+    log "Scheduled job $service_name installed (schedule: $SCHEDULE)"
+}
+
 systemd_start() {
     local service_name="$1"
     local log_file="${HOME}/.dployr/logs/${service_name}.log"
@@ -402,8 +485,9 @@ systemd_remove() {
     log "Removing service: $service_name"
     sudo systemctl stop "$service_name" 2>/dev/null || true
     sudo systemctl disable "$service_name" 2>/dev/null || true
-    sudo rm -f "/etc/systemd/system/${service_name}.service"
+    sudo rm -f "/etc/systemd/system/${service_name}.service" "/etc/systemd/system/${service_name}@.service"
     rm -f "${HOME}/.dployr/scripts/${service_name}.sh"
+    sudo rm -f "${HOME}/.dployr/creds/${service_name}.cred"
     sudo systemctl daemon-reload
     log "Service $service_name removed"
 }
@@ -411,6 +495,16 @@ systemd_remove() {
 systemd_status() {
     local service_name="$1"
     
+    if [ -f "/etc/systemd/system/${service_name}@.service" ]; then
+        # A scheduled job is only running while one of its runs is.
+        if sudo systemctl is-active --quiet "${service_name}@*" 2>/dev/null; then
+            echo "running"
+        else
+            echo "stopped"
+        fi
+        return
+    fi
+
     if [ ! -f "/etc/systemd/system/${service_name}.service" ]; then
         echo "stopped"
         exit 1
@@ -760,6 +854,11 @@ docker_create_container() {
     if [ -f "$env_file" ]; then
         create_cmd+=(--env-file "$env_file")
     fi
+    # Secrets are passed by name; docker reads the values from its environment.
+    local var
+    for var in "${!DPLOYR_SECRET_@}"; do
+        create_cmd+=(-e "${var#DPLOYR_SECRET_}")
+    done
     
     if [ -n "$description" ]; then
         create_cmd+=(--label "description=$description")
@@ -803,7 +902,12 @@ docker_create_container() {
         create_cmd+=("$image")
     fi
     
-    "${create_cmd[@]}" || abort "Failed to create container"
+    (
+        for var in "${!DPLOYR_SECRET_@}"; do
+            export "${var#DPLOYR_SECRET_}=${!var}"
+        done
+        "${create_cmd[@]}"
+    ) || abort "Failed to create container"
     
     log "Container $name created successfully"
 }
@@ -876,7 +980,10 @@ deploy_systemd() {
     
     systemd_install "$SERVICE_NAME" "$DESCRIPTION" "$exe_script" "$WORKDIR" "$RUNTIME" "$VERSION"
     
-    systemd_start "$SERVICE_NAME"
+    # Scheduled jobs are started by dployrd's scheduler, not at deploy time.
+    if [ -z "$SCHEDULE" ]; then
+        systemd_start "$SERVICE_NAME"
+    fi
     
     log "Deployment completed for: $SERVICE_NAME"
 }
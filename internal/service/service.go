@@ -20,6 +20,13 @@ import (
 // Injected at startup so the service layer stays decoupled from deploy internals.
 type RedeployFunc func(name string) error
 
+// ScaleFunc changes how many replicas of a deployed service run. Injected at
+// startup for the same reason as RedeployFunc.
+type ScaleFunc func(ctx context.Context, name string, replicas int) error
+
+// ResizeFunc changes the resource limits of a running service in place.
+type ResizeFunc func(ctx context.Context, name string, r store.Resources) error
+
 type Servicer struct {
 	cfg      *shared.Config
 	logger   *shared.Logger
@@ -27,9 +34,11 @@ type Servicer struct {
 	proxyAPI proxy.HandleProxy
 	svcMgr   svc_runtime.ServiceManager
 	redeploy RedeployFunc // nil = redeploy not available
+	scale    ScaleFunc    // nil = scaling not available
+	resize   ResizeFunc   // nil = resizing not available
 }
 
-func Init(cfg *shared.Config, logger *shared.Logger, store store.ServiceStore, proxyAPI proxy.HandleProxy, redeploy RedeployFunc) *Servicer {
+func Init(cfg *shared.Config, logger *shared.Logger, store store.ServiceStore, proxyAPI proxy.HandleProxy, redeploy RedeployFunc, scale ScaleFunc, resize ResizeFunc) *Servicer {
 	svcMgr, err := svc_runtime.SvcRuntime()
 	if err != nil {
 		logger.Error("failed to initialize service manager", "error", err)
@@ -42,6 +51,8 @@ func Init(cfg *shared.Config, logger *shared.Logger, store store.ServiceStore, p
 		proxyAPI: proxyAPI,
 		svcMgr:   svcMgr,
 		redeploy: redeploy,
+		scale:    scale,
+		resize:   resize,
 	}
 }
 
@@ -59,8 +70,10 @@ func (s *Servicer) SleepService(name string) error {
 	}
 	svcName := utils.FormatName(name)
 	s.logger.Info("sleeping service", "service", svcName)
-	if err := s.svcMgr.Stop(svcName); err != nil {
-		return fmt.Errorf("failed to stop service %s: %w", svcName, err)
+	for _, c := range s.containers(name) {
+		if err := s.svcMgr.Stop(c); err != nil {
+			return fmt.Errorf("failed to stop service %s: %w", c, err)
+		}
 	}
 	return nil
 }
@@ -71,11 +84,14 @@ func (s *Servicer) WakeService(name string) error {
 	}
 	svcName := utils.FormatName(name)
 	s.logger.Info("waking service", "service", svcName)
-	if err := s.svcMgr.Start(svcName); err != nil {
-		if errdefs.IsNotFound(err) {
-			return s.redeployService(svcName)
+	for _, c := range s.containers(name) {
+		if err := s.svcMgr.Start(c); err != nil {
+			if errdefs.IsNotFound(err) {
+				// Redeploying recreates every replica, not just this one.
+				return s.redeployService(svcName)
+			}
+			return fmt.Errorf("failed to start service %s: %w", c, err)
 		}
-		return fmt.Errorf("failed to start service %s: %w", svcName, err)
 	}
 	return nil
 }
@@ -97,12 +113,49 @@ func (s *Servicer) IceService(name string) error {
 	}
 	svcName := utils.FormatName(name)
 	s.logger.Info("icing service", "service", svcName)
-	if err := s.svcMgr.Ice(svcName); err != nil {
-		return fmt.Errorf("failed to ice service %s: %w", svcName, err)
+	for _, c := range s.containers(name) {
+		if err := s.svcMgr.Ice(c); err != nil {
+			return fmt.Errorf("failed to ice service %s: %w", c, err)
+		}
 	}
 	return nil
 }
 
+// ScaleService runs replicas copies of a deployed service.
+func (s *Servicer) ScaleService(ctx context.Context, name string, replicas int) error {
+	if s.scale == nil {
+		return fmt.Errorf("scaling is not available")
+	}
+	s.logger.Info("scaling service", "service", name, "replicas", replicas)
+	return s.scale(ctx, name, replicas)
+}
+
+// ResizeService changes the resource limits of a service without a rebuild.
+func (s *Servicer) ResizeService(ctx context.Context, name string, r store.Resources) error {
+	if s.resize == nil {
+		return fmt.Errorf("resizing is not available")
+	}
+	s.logger.Info("resizing service", "service", name, "resources", r)
+	return s.resize(ctx, name, r)
+}
+
+// containers returns the container names of every replica of a service. The
+// replica count comes from the store; without one only the primary is used.
+func (s *Servicer) containers(name string) []string {
+	svcName := utils.FormatName(name)
+	n := 1
+	if s.store != nil {
+		if svc, err := s.store.GetService(context.Background(), name); err == nil && svc != nil {
+			n = max(svc.Replicas, 1)
+		}
+	}
+	names := make([]string, n)
+	for i := range names {
+		names[i] = utils.ReplicaName(svcName, i)
+	}
+	return names
+}
+
 func (s *Servicer) DeleteService(ctx context.Context, name string) error {
 	svc, err := s.store.GetService(ctx, name)
 	if err != nil {
@@ -115,14 +168,17 @@ func (s *Servicer) DeleteService(ctx context.Context, name string) error {
 	service_name := utils.FormatName(svc.Name)
 
 	if s.svcMgr != nil {
-		s.logger.Info("stopping systemd service", "service", service_name)
-		if err := s.svcMgr.Stop(service_name); err != nil {
-			s.logger.Warn("failed to stop service (may not exist)", "service", service_name, "error", err)
-		}
+		for i := range max(svc.Replicas, 1) {
+			c := utils.ReplicaName(service_name, i)
+			s.logger.Info("stopping systemd service", "service", c)
+			if err := s.svcMgr.Stop(c); err != nil {
+				s.logger.Warn("failed to stop service (may not exist)", "service", c, "error", err)
+			}
 
-		s.logger.Info("removing systemd service", "service", service_name)
-		if err := s.svcMgr.Remove(service_name); err != nil {
-			s.logger.Warn("failed to remove service (may not exist)", "service", service_name, "error", err)
+			s.logger.Info("removing systemd service", "service", c)
+			if err := s.svcMgr.Remove(c); err != nil {
+				s.logger.Warn("failed to remove service (may not exist)", "service", c, "error", err)
+			}
 		}
 	}
 
@@ -131,6 +187,14 @@ func (s *Servicer) DeleteService(ctx context.Context, name string) error {
 		var domainsToRemove []string
 
 		for _, app := range apps {
+			if app.Service != "" {
+				if app.Service == svc.Name {
+					domainsToRemove = append(domainsToRemove, app.Domain)
+				}
+				continue
+			}
+			// Routes written before they recorded their owner are matched
+			// by upstream port.
 			if app.Upstream != "" && (app.Upstream == fmt.Sprintf("localhost:%d", svc.Port) ||
 				app.Upstream == fmt.Sprintf("127.0.0.1:%d", svc.Port)) {
 				domainsToRemove = append(domainsToRemove, app.Domain)
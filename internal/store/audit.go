@@ -0,0 +1,102 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// AuditStore implements store.AuditStore using SQLite.
+type AuditStore struct {
+	db *sql.DB
+	mu sync.Mutex // serialises appends so each links to the true previous entry
+}
+
+func NewAuditStore(db *sql.DB) *AuditStore {
+	return &AuditStore{db: db}
+}
+
+func (s *AuditStore) AppendAudit(ctx context.Context, e *store.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lastSeq int64
+	var lastHash string
+	err = tx.QueryRowContext(ctx, `SELECT seq, hash FROM audit_log ORDER BY seq DESC LIMIT 1`).Scan(&lastSeq, &lastHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = time.UnixMicro(e.Time.UnixMicro())
+	e.Seq = lastSeq + 1
+	e.PrevHash = lastHash
+	e.Hash = e.ComputeHash()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_log (seq, time, subject, role, task_id, method, route, status, outcome, args_digest, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Seq, e.Time.UnixMicro(), e.Subject, e.Role, e.TaskID, e.Method, e.Route, e.Status, e.Outcome, e.ArgsDigest, e.PrevHash, e.Hash)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *AuditStore) ListAudit(ctx context.Context, since time.Time, limit int) ([]*store.AuditEntry, error) {
+	return s.query(ctx, `
+		SELECT seq, time, subject, role, task_id, method, route, status, outcome, args_digest, prev_hash, hash
+		FROM audit_log
+		WHERE time >= ?
+		ORDER BY seq
+		LIMIT ?`, since.UnixMicro(), limit)
+}
+
+func (s *AuditStore) VerifyAudit(ctx context.Context) (int64, error) {
+	entries, err := s.query(ctx, `
+		SELECT seq, time, subject, role, task_id, method, route, status, outcome, args_digest, prev_hash, hash
+		FROM audit_log
+		ORDER BY seq`)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) > 0 && (entries[0].Seq != 1 || entries[0].PrevHash != "") {
+		return entries[0].Seq, nil
+	}
+	return store.VerifyAuditChain(entries), nil
+}
+
+func (s *AuditStore) query(ctx context.Context, query string, args ...any) ([]*store.AuditEntry, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*store.AuditEntry
+	for rows.Next() {
+		var e store.AuditEntry
+		var micros int64
+		if err := rows.Scan(&e.Seq, &micros, &e.Subject, &e.Role, &e.TaskID, &e.Method, &e.Route, &e.Status, &e.Outcome, &e.ArgsDigest, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		e.Time = time.UnixMicro(micros)
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -7,20 +7,40 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/dployr-io/dployr/pkg/store"
 )
 
 type DeploymentStore struct {
-	db *sql.DB
+	db     *sql.DB
+	sealer *Sealer
 }
 
-func NewDeploymentStore(db *sql.DB) *DeploymentStore {
-	return &DeploymentStore{db: db}
+// NewDeploymentStore returns a store that encrypts blueprint secrets with
+// sealer before they are written. A nil sealer stores them as given.
+func NewDeploymentStore(db *sql.DB, sealer *Sealer) *DeploymentStore {
+	return &DeploymentStore{db: db, sealer: sealer}
 }
 
-func (ds DeploymentStore) getDeploymentByName(ctx context.Context, name string) (*store.Deployment, error) {
+// OpenSecrets decrypts secrets read back from the store. Callers should do
+// so only when handing them to the workload.
+func (ds DeploymentStore) OpenSecrets(secrets map[string]string) (map[string]string, error) {
+	return ds.sealer.Open(secrets)
+}
+
+// marshalBlueprint encodes bp for the config column with its secrets sealed.
+func (ds DeploymentStore) marshalBlueprint(bp store.Blueprint) ([]byte, error) {
+	sealed, err := ds.sealer.Seal(bp.Secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+	bp.Secrets = sealed
+	return json.Marshal(bp)
+}
+
+func (ds DeploymentStore) GetDeploymentByName(ctx context.Context, name string) (*store.Deployment, error) {
 	stmt, err := ds.db.PrepareContext(ctx, `
 		SELECT id, name, user_id, config, status, metadata, created_at, updated_at
 		FROM deployments
@@ -53,12 +73,12 @@ func (ds DeploymentStore) getDeploymentByName(ctx context.Context, name string)
 
 // UpsertDeployment creates a new deployment or updates an existing one with the same name.
 func (ds DeploymentStore) UpsertDeployment(ctx context.Context, deployment *store.Deployment) error {
-	existing, err := ds.getDeploymentByName(ctx, deployment.Blueprint.Name)
+	existing, err := ds.GetDeploymentByName(ctx, deployment.Blueprint.Name)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
 
-	configJSON, err := json.Marshal(deployment.Blueprint)
+	configJSON, err := ds.marshalBlueprint(deployment.Blueprint)
 	if err != nil {
 		return err
 	}
@@ -182,3 +202,82 @@ func (ds DeploymentStore) UpdateDeploymentStatus(ctx context.Context, id, status
 	_, err = stmt.ExecContext(ctx, status, time.Now().Unix(), id)
 	return err
 }
+
+func (ds DeploymentStore) UpdateDeploymentReplicas(ctx context.Context, id string, replicas int) error {
+	_, err := ds.db.ExecContext(ctx, `
+		UPDATE deployments SET config = json_set(config, '$.replicas', ?), updated_at = ? WHERE id = ?`,
+		replicas, time.Now().Unix(), id)
+	return err
+}
+
+func (ds DeploymentStore) UpdateDeploymentResources(ctx context.Context, id string, r store.Resources) error {
+	limits, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = ds.db.ExecContext(ctx, `
+		UPDATE deployments SET config = json_set(config, '$.resources', json(?)), updated_at = ? WHERE id = ?`,
+		string(limits), time.Now().Unix(), id)
+	return err
+}
+
+// SealStoredSecrets encrypts secrets that were written in plaintext before
+// the store had a key, in both live deployments and release history. Only
+// the secrets of a config are rewritten, which is all the append-only
+// releases table lets change.
+func (ds DeploymentStore) SealStoredSecrets(ctx context.Context) error {
+	if ds.sealer == nil {
+		return nil
+	}
+
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, table := range []string{"deployments", "releases"} {
+		rows, err := tx.QueryContext(ctx, `SELECT id, config FROM `+table+` WHERE config LIKE '%"secrets"%'`)
+		if err != nil {
+			return err
+		}
+		pending := map[string][]byte{}
+		for rows.Next() {
+			var id string
+			var configJSON []byte
+			if err := rows.Scan(&id, &configJSON); err != nil {
+				rows.Close()
+				return err
+			}
+			var bp store.Blueprint
+			if err := json.Unmarshal(configJSON, &bp); err != nil {
+				rows.Close()
+				return fmt.Errorf("%s %s: %w", table, id, err)
+			}
+			if !hasPlainSecrets(bp.Secrets) {
+				continue
+			}
+			sealed, err := ds.sealer.Seal(bp.Secrets)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to encrypt secrets: %w", err)
+			}
+			if pending[id], err = json.Marshal(sealed); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, secretsJSON := range pending {
+			if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET config = json_set(config, '$.secrets', json(?)) WHERE id = ?`, string(secretsJSON), id); err != nil {
+				return fmt.Errorf("%s %s: %w", table, id, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dployr-io/dployr/internal/db"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+func TestSealStoredSecrets_PlaintextRelease(t *testing.T) {
+	conn := openTestDB(t)
+
+	// Rows written before the store had a key.
+	legacy := `{"name":"shop","image":"reg/shop:1","env_vars":{"MODE":"prod"},"secrets":{"DB_PASS":"hunter2"}}`
+	if _, err := conn.Exec(`INSERT INTO deployments (id, name, config, status) VALUES ('d1', 'shop', ?, 'completed')`, legacy); err != nil {
+		t.Fatalf("seed deployment: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO releases (id, name, version, deployment_id, config, image, status) VALUES ('r1', 'shop', 1, 'd1', ?, 'reg/shop:1', 'completed')`, legacy); err != nil {
+		t.Fatalf("seed release: %v", err)
+	}
+
+	sealer, err := NewSealer(filepath.Join(t.TempDir(), "secret.key"))
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+	ds := NewDeploymentStore(conn, sealer)
+	if err := ds.SealStoredSecrets(context.Background()); err != nil {
+		t.Fatalf("SealStoredSecrets: %v", err)
+	}
+
+	for _, table := range []string{"deployments", "releases"} {
+		var config string
+		if err := conn.QueryRow(`SELECT config FROM ` + table).Scan(&config); err != nil {
+			t.Fatalf("read %s: %v", table, err)
+		}
+		if strings.Contains(config, "hunter2") || !strings.Contains(config, sealedPrefix) {
+			t.Errorf("%s config = %s, want the secret sealed", table, config)
+		}
+	}
+
+	releases, err := ds.ListReleases(context.Background(), "shop", 10)
+	if err != nil || len(releases) != 1 {
+		t.Fatalf("ListReleases = %v, %v", releases, err)
+	}
+	bp := releases[0].Blueprint
+	if bp.EnvVars["MODE"] != "prod" || bp.Image != "reg/shop:1" {
+		t.Errorf("re-seal changed the rest of the release: %+v", bp)
+	}
+	if opened, err := ds.OpenSecrets(bp.Secrets); err != nil || opened["DB_PASS"] != "hunter2" {
+		t.Errorf("OpenSecrets = %v, %v; want hunter2", opened, err)
+	}
+
+	// Releases stay append-only otherwise.
+	for _, stmt := range []string{
+		`UPDATE releases SET image = 'reg/shop:2'`,
+		`UPDATE releases SET config = json_set(config, '$.image', 'reg/shop:2')`,
+		`UPDATE releases SET config = json_set(config, '$.secrets.DB_PASS', 'plain')`,
+	} {
+		if _, err := conn.Exec(stmt); err == nil {
+			t.Errorf("%s was allowed", stmt)
+		}
+	}
+}
+
+func TestUpdateDeploymentReplicas_KeepsRestOfRow(t *testing.T) {
+	conn := openTestDB(t)
+	ds := NewDeploymentStore(conn, nil)
+	ctx := context.Background()
+
+	if err := ds.UpsertDeployment(ctx, &store.Deployment{
+		ID:        "d1",
+		Blueprint: store.Blueprint{Name: "shop", Image: "reg/shop:1", Replicas: 1},
+		Status:    store.StatusCompleted,
+	}); err != nil {
+		t.Fatalf("UpsertDeployment: %v", err)
+	}
+	// Changed by someone else while the replicas were starting.
+	if err := ds.UpdateDeploymentStatus(ctx, "d1", string(store.StatusFailed)); err != nil {
+		t.Fatalf("UpdateDeploymentStatus: %v", err)
+	}
+
+	if err := ds.UpdateDeploymentReplicas(ctx, "d1", 3); err != nil {
+		t.Fatalf("UpdateDeploymentReplicas: %v", err)
+	}
+	d, err := ds.GetDeployment(ctx, "d1")
+	if err != nil {
+		t.Fatalf("GetDeployment: %v", err)
+	}
+	if d.Blueprint.Replicas != 3 || d.Status != store.StatusFailed || d.Blueprint.Image != "reg/shop:1" {
+		t.Errorf("deployment = %+v, want 3 replicas and the rest untouched", d)
+	}
+}
+
+func TestUpdateDeploymentResources_KeepsRestOfRow(t *testing.T) {
+	conn := openTestDB(t)
+	ds := NewDeploymentStore(conn, nil)
+	ctx := context.Background()
+
+	if err := ds.UpsertDeployment(ctx, &store.Deployment{
+		ID:        "d1",
+		Blueprint: store.Blueprint{Name: "shop", Replicas: 2, Resources: store.Resources{MemoryMB: 256, StorageGB: 5}},
+		Status:    store.StatusCompleted,
+	}); err != nil {
+		t.Fatalf("UpsertDeployment: %v", err)
+	}
+	if err := ds.UpdateDeploymentReplicas(ctx, "d1", 4); err != nil {
+		t.Fatalf("UpdateDeploymentReplicas: %v", err)
+	}
+
+	want := store.Resources{MemoryMB: 512, CPU: 500, StorageGB: 5}
+	if err := ds.UpdateDeploymentResources(ctx, "d1", want); err != nil {
+		t.Fatalf("UpdateDeploymentResources: %v", err)
+	}
+	d, err := ds.GetDeployment(ctx, "d1")
+	if err != nil {
+		t.Fatalf("GetDeployment: %v", err)
+	}
+	if d.Blueprint.Resources != want || d.Blueprint.Replicas != 4 {
+		t.Errorf("deployment = %+v, want new limits and 4 replicas", d.Blueprint)
+	}
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := db.OpenFile(filepath.Join(t.TempDir(), "data.db"))
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// JobRunStore implements store.JobRunStore using SQLite.
+type JobRunStore struct {
+	db *sql.DB
+}
+
+func NewJobRunStore(db *sql.DB) *JobRunStore {
+	return &JobRunStore{db: db}
+}
+
+func (s *JobRunStore) CreateRun(ctx context.Context, r *store.JobRun) error {
+	if r.ID == "" {
+		r.ID = ulid.Make().String()
+	}
+	if r.StartedAt.IsZero() {
+		r.StartedAt = time.Now()
+	}
+
+	var finishedAt sql.NullInt64
+	if r.FinishedAt != nil {
+		finishedAt = sql.NullInt64{Int64: r.FinishedAt.Unix(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO job_runs (id, name, trigger, status, exit_code, output, started_at, finished_at, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Name, r.Trigger, r.Status, exitCode(r.ExitCode), r.Output, r.StartedAt.Unix(), finishedAt, r.DurationMs)
+	return err
+}
+
+func (s *JobRunStore) FinishRun(ctx context.Context, r *store.JobRun) error {
+	finishedAt := time.Now()
+	if r.FinishedAt != nil {
+		finishedAt = *r.FinishedAt
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE job_runs
+		SET status = ?, exit_code = ?, output = ?, finished_at = ?, duration_ms = ?
+		WHERE id = ?`,
+		r.Status, exitCode(r.ExitCode), r.Output, finishedAt.Unix(), r.DurationMs, r.ID)
+	return err
+}
+
+func (s *JobRunStore) ListRuns(ctx context.Context, name string, limit int) ([]*store.JobRun, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, trigger, status, exit_code, output, started_at, finished_at, duration_ms
+		FROM job_runs
+		WHERE name = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?`, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*store.JobRun
+	for rows.Next() {
+		var r store.JobRun
+		var code, finishedAtUnix sql.NullInt64
+		var startedAtUnix int64
+		if err := rows.Scan(&r.ID, &r.Name, &r.Trigger, &r.Status, &code, &r.Output, &startedAtUnix, &finishedAtUnix, &r.DurationMs); err != nil {
+			return nil, err
+		}
+		if code.Valid {
+			c := int(code.Int64)
+			r.ExitCode = &c
+		}
+		r.StartedAt = time.Unix(startedAtUnix, 0)
+		if finishedAtUnix.Valid {
+			t := time.Unix(finishedAtUnix.Int64, 0)
+			r.FinishedAt = &t
+		}
+		runs = append(runs, &r)
+	}
+	return runs, rows.Err()
+}
+
+// FailInterruptedRuns marks runs still recorded as running as failed. The
+// daemon that started them stopped watching before they ended, so their
+// outcome is unknown. It must only run before the scheduler starts.
+func (s *JobRunStore) FailInterruptedRuns(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE job_runs SET status = ?, finished_at = ? WHERE status = ?`,
+		store.RunFailed, time.Now().Unix(), store.RunRunning)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func exitCode(code *int) sql.NullInt64 {
+	if code == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*code), Valid: true}
+}
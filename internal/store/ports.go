@@ -0,0 +1,89 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// PortStore implements store.PortStore using SQLite.
+type PortStore struct {
+	db *sql.DB
+}
+
+func NewPortStore(db *sql.DB) *PortStore {
+	return &PortStore{db: db}
+}
+
+func (s *PortStore) ReservePort(ctx context.Context, r *store.PortReservation) error {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO port_reservations (port, container, service, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		r.Port, r.Container, r.Service, r.CreatedAt.Unix())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return store.ErrPortTaken
+	}
+	return nil
+}
+
+func (s *PortStore) GetPortReservation(ctx context.Context, container string) (*store.PortReservation, error) {
+	r, err := scanPortReservation(s.db.QueryRowContext(ctx, `
+		SELECT port, container, service, created_at
+		FROM port_reservations
+		WHERE container = ?`, container))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return r, err
+}
+
+func (s *PortStore) ListPortReservations(ctx context.Context) ([]*store.PortReservation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT port, container, service, created_at
+		FROM port_reservations
+		ORDER BY port`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*store.PortReservation
+	for rows.Next() {
+		r, err := scanPortReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *PortStore) ReleasePort(ctx context.Context, container string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM port_reservations WHERE container = ?`, container)
+	return err
+}
+
+func scanPortReservation(row rowScanner) (*store.PortReservation, error) {
+	var r store.PortReservation
+	var createdAtUnix int64
+	if err := row.Scan(&r.Port, &r.Container, &r.Service, &createdAtUnix); err != nil {
+		return nil, err
+	}
+	r.CreatedAt = time.Unix(createdAtUnix, 0)
+	return &r, nil
+}
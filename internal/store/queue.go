@@ -0,0 +1,128 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// EnqueueDeployment persists a deployment in the worker queue. Enqueueing an
+// already queued deployment is a no-op that keeps its original position and
+// reports false.
+func (ds DeploymentStore) EnqueueDeployment(ctx context.Context, id string) (bool, error) {
+	stmt, err := ds.db.PrepareContext(ctx, `
+		INSERT INTO deployment_queue (deployment_id, enqueued_at) VALUES (?, ?)
+		ON CONFLICT(deployment_id) DO NOTHING`)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (ds DeploymentStore) DequeueDeployment(ctx context.Context, id string) error {
+	stmt, err := ds.db.PrepareContext(ctx, `
+		DELETE FROM deployment_queue WHERE deployment_id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, id)
+	return err
+}
+
+func (ds DeploymentStore) ListQueuedDeployments(ctx context.Context) ([]string, error) {
+	stmt, err := ds.db.PrepareContext(ctx, `
+		SELECT q.deployment_id
+		FROM deployment_queue q
+		JOIN deployments d ON d.id = q.deployment_id
+		WHERE d.status = ?
+		ORDER BY q.enqueued_at, q.rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, store.StatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// FailInterruptedDeployments marks every in_progress deployment and release as
+// failed and drops the deployments from the queue. It must only run before the
+// worker starts, when nothing can legitimately be in progress.
+func (ds DeploymentStore) FailInterruptedDeployments(ctx context.Context) ([]string, error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM deployments WHERE status = ?`, store.StatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE deployments SET status = ?, updated_at = ? WHERE status = ?`,
+		store.StatusFailed, now, store.StatusInProgress); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE releases SET status = ?, updated_at = ? WHERE status = ?`,
+		store.StatusFailed, now, store.StatusInProgress); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM deployment_queue WHERE deployment_id = ?`, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// RecordRelease appends a release to the service's history. The version is
+// allocated in the same statement as the insert so concurrent deployments of
+// one service can never share a number.
+func (ds DeploymentStore) RecordRelease(ctx context.Context, r *store.Release) error {
+	configJSON, err := ds.marshalBlueprint(r.Blueprint)
+	if err != nil {
+		return err
+	}
+
+	if r.ID == "" {
+		r.ID = ulid.Make().String()
+	}
+	createdAt := r.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	stmt, err := ds.db.PrepareContext(ctx, `
+		INSERT INTO releases (id, name, version, deployment_id, config, image, commit_hash, status, created_at, updated_at)
+		SELECT ?, ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ?, ?, ?
+		FROM releases WHERE name = ?
+		RETURNING version`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, r.ID, r.Name, r.DeploymentID, configJSON, r.Image, r.CommitHash, r.Status,
+		createdAt.Unix(), createdAt.Unix(), r.Name).Scan(&r.Version)
+	if err != nil {
+		return err
+	}
+
+	r.CreatedAt = time.Unix(createdAt.Unix(), 0)
+	r.UpdatedAt = r.CreatedAt
+	return nil
+}
+
+func (ds DeploymentStore) UpdateReleaseStatus(ctx context.Context, id, status string) error {
+	stmt, err := ds.db.PrepareContext(ctx, `
+		UPDATE releases SET status = ?, updated_at = ? WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, status, time.Now().Unix(), id)
+	return err
+}
+
+func (ds DeploymentStore) ListReleases(ctx context.Context, name string, limit int) ([]*store.Release, error) {
+	stmt, err := ds.db.PrepareContext(ctx, `
+		SELECT id, name, version, deployment_id, config, image, commit_hash, status, created_at, updated_at
+		FROM releases
+		WHERE name = ?
+		ORDER BY version DESC
+		LIMIT ?`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []*store.Release
+	for rows.Next() {
+		var r store.Release
+		var configJSON []byte
+		var image, commitHash sql.NullString
+		var createdAtUnix, updatedAtUnix int64
+		err := rows.Scan(&r.ID, &r.Name, &r.Version, &r.DeploymentID, &configJSON, &image, &commitHash, &r.Status, &createdAtUnix, &updatedAtUnix)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(configJSON, &r.Blueprint); err != nil {
+			return nil, err
+		}
+		r.Image = image.String
+		r.CommitHash = commitHash.String
+		r.CreatedAt = time.Unix(createdAtUnix, 0)
+		r.UpdatedAt = time.Unix(updatedAtUnix, 0)
+		releases = append(releases, &r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dployr-io/dployr/pkg/core/utils"
+)
+
+// sealedPrefix marks a secret value encrypted by a Sealer. Values without it
+// were written before encryption was introduced and are read as plaintext.
+const sealedPrefix = "enc:v1:"
+
+const secretKeySize = 32
+
+// Sealer encrypts secret values with a node-local AES-256-GCM key.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// SecretKeyPath returns where the node's secret key is kept, next to the
+// database it protects.
+func SecretKeyPath() string {
+	return filepath.Join(utils.GetDataDir(), "secret.key")
+}
+
+// NewSealer loads the key at path, generating one readable only by the
+// daemon user on first start.
+func NewSealer(path string) (*Sealer, error) {
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key, err = createSecretKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret key: %w", err)
+	}
+	if len(key) != secretKeySize {
+		return nil, fmt.Errorf("secret key %s must be %d bytes, got %d", path, secretKeySize, len(key))
+	}
+	return newSealer(key)
+}
+
+func newSealer(key []byte) (*Sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+func createSecretKey(path string) ([]byte, error) {
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// O_EXCL so two daemons racing on first start cannot each write a key.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		os.Remove(path) //nolint:errcheck
+		return nil, err
+	}
+	return key, f.Close()
+}
+
+func hasPlainSecrets(secrets map[string]string) bool {
+	for _, v := range secrets {
+		if !strings.HasPrefix(v, sealedPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Seal encrypts every value in secrets. Values that are already sealed are
+// kept as they are, so a blueprint read back from the store can be saved
+// again without double encryption.
+func (s *Sealer) Seal(secrets map[string]string) (map[string]string, error) {
+	if s == nil || len(secrets) == 0 {
+		return secrets, nil
+	}
+	sealed := make(map[string]string, len(secrets))
+	for k, v := range secrets {
+		if strings.HasPrefix(v, sealedPrefix) {
+			sealed[k] = v
+			continue
+		}
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		ct := s.aead.Seal(nonce, nonce, []byte(v), []byte(k))
+		sealed[k] = sealedPrefix + base64.StdEncoding.EncodeToString(ct)
+	}
+	return sealed, nil
+}
+
+// Open decrypts sealed values. The key is bound to each value as associated
+// data, so a ciphertext copied under another name fails to open.
+func (s *Sealer) Open(secrets map[string]string) (map[string]string, error) {
+	if len(secrets) == 0 {
+		return secrets, nil
+	}
+	opened := make(map[string]string, len(secrets))
+	for k, v := range secrets {
+		enc, ok := strings.CutPrefix(v, sealedPrefix)
+		if !ok {
+			opened[k] = v
+			continue
+		}
+		if s == nil {
+			return nil, fmt.Errorf("secret %s is encrypted but no key is loaded", k)
+		}
+		ct, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil || len(ct) < s.aead.NonceSize() {
+			return nil, fmt.Errorf("secret %s is malformed", k)
+		}
+		n := s.aead.NonceSize()
+		pt, err := s.aead.Open(nil, ct[:n], ct[n:], []byte(k))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret %s: %w", k, err)
+		}
+		opened[k] = string(pt)
+	}
+	return opened, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSealer_RoundTrip(t *testing.T) {
+	s, err := NewSealer(filepath.Join(t.TempDir(), "secret.key"))
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+
+	sealed, err := s.Seal(map[string]string{"DB_PASS": "hunter2"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if v := sealed["DB_PASS"]; !strings.HasPrefix(v, sealedPrefix) || strings.Contains(v, "hunter2") {
+		t.Fatalf("sealed value = %q, want ciphertext", v)
+	}
+
+	resealed, err := s.Seal(sealed)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if resealed["DB_PASS"] != sealed["DB_PASS"] {
+		t.Error("sealing an already sealed value changed it")
+	}
+
+	opened, err := s.Open(resealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if opened["DB_PASS"] != "hunter2" {
+		t.Errorf("opened DB_PASS = %q, want hunter2", opened["DB_PASS"])
+	}
+}
+
+func TestSealer_OpenRejects(t *testing.T) {
+	s, err := NewSealer(filepath.Join(t.TempDir(), "secret.key"))
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+	other, err := NewSealer(filepath.Join(t.TempDir(), "secret.key"))
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+	sealed, err := s.Seal(map[string]string{"API_KEY": "abc"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		sealer  *Sealer
+		secrets map[string]string
+	}{
+		{"other key", other, sealed},
+		{"renamed key", s, map[string]string{"OTHER": sealed["API_KEY"]}},
+		{"no key loaded", nil, sealed},
+		{"malformed", s, map[string]string{"API_KEY": sealedPrefix + "!!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.sealer.Open(tt.secrets); err == nil {
+				t.Error("Open succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestSealer_OpenPassesLegacyPlaintext(t *testing.T) {
+	var s *Sealer
+	opened, err := s.Open(map[string]string{"DB_PASS": "hunter2"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if opened["DB_PASS"] != "hunter2" {
+		t.Errorf("opened DB_PASS = %q, want hunter2", opened["DB_PASS"])
+	}
+}
+
+func TestNewSealer_ReusesKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.key")
+	first, err := NewSealer(path)
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+	sealed, err := first.Seal(map[string]string{"K": "v"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	second, err := NewSealer(path)
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+	if _, err := second.Open(sealed); err != nil {
+		t.Errorf("Open with reloaded key: %v", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat key: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file mode = %o, want 600", perm)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	got := redactSecrets(map[string]string{"DB_PASS": "hunter2"})
+	if got["DB_PASS"] == "hunter2" {
+		t.Error("secret value was not redacted")
+	}
+	if _, ok := got["DB_PASS"]; !ok {
+		t.Error("secret key was dropped")
+	}
+}
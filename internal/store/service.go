@@ -28,8 +28,8 @@ func (s ServiceStore) createService(ctx context.Context, svc *store.Service) (*s
 	stmt, err := s.db.PrepareContext(ctx, `
 		INSERT INTO services
 		(id, name, description, source, type, runtime, runtime_version, run_cmd, build_cmd, working_dir,
-		static_dir, image, remote_url, remote_branch, remote_commit_hash, deployment_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		static_dir, image, remote_url, remote_branch, remote_commit_hash, deployment_id, host_port, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +45,7 @@ func (s ServiceStore) createService(ctx context.Context, svc *store.Service) (*s
 	}
 
 	_, err = stmt.ExecContext(ctx, svc.ID, svc.Name, svc.Description, svc.Source, svc.Type, svc.Runtime, svc.RuntimeVersion, svc.RunCmd, svc.BuildCmd,
-		svc.WorkingDir, svc.StaticDir, svc.Image, svc.Remote, svc.Branch, svc.CommitHash, svc.DeploymentId, createdAt.Unix(), updatedAt.Unix())
+		svc.WorkingDir, svc.StaticDir, svc.Image, svc.Remote, svc.Branch, svc.CommitHash, svc.DeploymentId, svc.HostPort, createdAt.Unix(), updatedAt.Unix())
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +55,7 @@ func (s ServiceStore) createService(ctx context.Context, svc *store.Service) (*s
 func (s ServiceStore) GetService(ctx context.Context, name string) (*store.Service, error) {
 	stmt, err := s.db.PrepareContext(ctx, `
 		SELECT id, name, description, source, type, runtime, runtime_version, run_cmd, build_cmd, working_dir,
-		       static_dir, image, remote_url, remote_branch, remote_commit_hash, deployment_id, created_at, updated_at
+		       static_dir, image, remote_url, remote_branch, remote_commit_hash, deployment_id, host_port, created_at, updated_at
 		FROM services WHERE name = ?`)
 	if err != nil {
 		return nil, err
@@ -66,11 +66,12 @@ func (s ServiceStore) GetService(ctx context.Context, name string) (*store.Servi
 
 	var svc store.Service
 	var createdAtUnix, updatedAtUnix int64
+	var hostPort sql.NullInt64
 	var description, runCmd, buildCmd, staticDir, image, remoteURL, remoteBranch, remoteCommitHash, deploymentID sql.NullString
 	err = row.Scan(
 		&svc.ID, &svc.Name, &description, &svc.Source, &svc.Type, &svc.Runtime, &svc.RuntimeVersion, &runCmd, &buildCmd,
 		&svc.WorkingDir, &staticDir, &image, &remoteURL, &remoteBranch,
-		&remoteCommitHash, &deploymentID, &createdAtUnix, &updatedAtUnix,
+		&remoteCommitHash, &deploymentID, &hostPort, &createdAtUnix, &updatedAtUnix,
 	)
 	if err != nil {
 		return nil, err
@@ -84,6 +85,7 @@ func (s ServiceStore) GetService(ctx context.Context, name string) (*store.Servi
 	svc.Branch = remoteBranch.String
 	svc.CommitHash = remoteCommitHash.String
 	svc.DeploymentId = deploymentID.String
+	svc.HostPort = int(hostPort.Int64)
 	svc.CreatedAt = time.Unix(createdAtUnix, 0)
 	svc.UpdatedAt = time.Unix(updatedAtUnix, 0)
 
@@ -94,10 +96,7 @@ func (s ServiceStore) GetService(ctx context.Context, name string) (*store.Servi
 	if svc.DeploymentId != "" {
 		deployment, err := s.ds.GetDeployment(ctx, svc.DeploymentId)
 		if err == nil {
-			svc.Blueprint = &deployment.Blueprint
-			svc.Port = deployment.Blueprint.Port
-			svc.EnvVars = deployment.Blueprint.EnvVars
-			svc.Secrets = deployment.Blueprint.Secrets
+			applyDeployment(&svc, deployment)
 		}
 	}
 
@@ -107,7 +106,7 @@ func (s ServiceStore) GetService(ctx context.Context, name string) (*store.Servi
 func (s ServiceStore) ListServices(ctx context.Context, limit, offset int) ([]*store.Service, error) {
 	stmt, err := s.db.PrepareContext(ctx, `
 		SELECT id, name, description, source, type, runtime, runtime_version, run_cmd, build_cmd, working_dir,
-		       static_dir, image, remote_url, remote_branch, remote_commit_hash, deployment_id, created_at, updated_at
+		       static_dir, image, remote_url, remote_branch, remote_commit_hash, deployment_id, host_port, created_at, updated_at
 		FROM services
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?`)
@@ -126,11 +125,12 @@ func (s ServiceStore) ListServices(ctx context.Context, limit, offset int) ([]*s
 	for rows.Next() {
 		var svc store.Service
 		var createdAtUnix, updatedAtUnix int64
+		var hostPort sql.NullInt64
 		var description, runCmd, buildCmd, staticDir, image, remoteURL, remoteBranch, remoteCommitHash, deploymentID sql.NullString
 		err := rows.Scan(
 			&svc.ID, &svc.Name, &description, &svc.Source, &svc.Type, &svc.Runtime, &svc.RuntimeVersion, &runCmd, &buildCmd,
 			&svc.WorkingDir, &staticDir, &image, &remoteURL, &remoteBranch,
-			&remoteCommitHash, &deploymentID, &createdAtUnix, &updatedAtUnix,
+			&remoteCommitHash, &deploymentID, &hostPort, &createdAtUnix, &updatedAtUnix,
 		)
 		if err != nil {
 			return nil, err
@@ -144,6 +144,7 @@ func (s ServiceStore) ListServices(ctx context.Context, limit, offset int) ([]*s
 		svc.Branch = remoteBranch.String
 		svc.CommitHash = remoteCommitHash.String
 		svc.DeploymentId = deploymentID.String
+		svc.HostPort = int(hostPort.Int64)
 		svc.CreatedAt = time.Unix(createdAtUnix, 0)
 		svc.UpdatedAt = time.Unix(updatedAtUnix, 0)
 
@@ -162,10 +163,7 @@ func (s ServiceStore) ListServices(ctx context.Context, limit, offset int) ([]*s
 		if svc.DeploymentId != "" {
 			deployment, err := s.ds.GetDeployment(ctx, svc.DeploymentId)
 			if err == nil {
-				svc.Blueprint = &deployment.Blueprint
-				svc.Port = deployment.Blueprint.Port
-				svc.EnvVars = deployment.Blueprint.EnvVars
-				svc.Secrets = deployment.Blueprint.Secrets
+				applyDeployment(svc, deployment)
 			}
 		}
 	}
@@ -173,12 +171,40 @@ func (s ServiceStore) ListServices(ctx context.Context, limit, offset int) ([]*s
 	return services, nil
 }
 
+// applyDeployment fills in the fields a service takes from its deployment.
+// Secret values never leave the store through a service; only their keys do.
+func applyDeployment(svc *store.Service, d *store.Deployment) {
+	bp := d.Blueprint
+	bp.Secrets = redactSecrets(bp.Secrets)
+	svc.Blueprint = &bp
+	svc.Port = bp.Port
+	svc.EnvVars = bp.EnvVars
+	svc.Secrets = bp.Secrets
+	svc.Domains = bp.Domains
+	svc.Replicas = bp.Replicas
+	svc.Resources = bp.Resources
+	svc.HealthCheck = bp.HealthCheck
+	svc.Schedule = bp.Schedule
+	svc.Concurrency = bp.Concurrency
+}
+
+func redactSecrets(secrets map[string]string) map[string]string {
+	if len(secrets) == 0 {
+		return secrets
+	}
+	redacted := make(map[string]string, len(secrets))
+	for k := range secrets {
+		redacted[k] = store.RedactedSecret
+	}
+	return redacted
+}
+
 func (s ServiceStore) updateService(ctx context.Context, svc *store.Service) error {
 	stmt, err := s.db.PrepareContext(ctx, `
 		UPDATE services
 		SET name = ?, description = ?, source = ?, type = ?, runtime = ?, runtime_version = ?, run_cmd = ?, build_cmd = ?,
 		    working_dir = ?, static_dir = ?, image = ?, remote_url = ?, remote_branch = ?,
-			remote_commit_hash = ?, deployment_id = ?, updated_at = ?
+			remote_commit_hash = ?, deployment_id = ?, host_port = ?, updated_at = ?
 		WHERE id = ?`)
 	if err != nil {
 		return err
@@ -187,7 +213,7 @@ func (s ServiceStore) updateService(ctx context.Context, svc *store.Service) err
 
 	_, err = stmt.ExecContext(ctx, svc.Name, svc.Description, svc.Source, svc.Type, svc.Runtime, svc.RuntimeVersion, svc.RunCmd, svc.BuildCmd,
 		svc.WorkingDir, svc.StaticDir, svc.Image, svc.Remote, svc.Branch, svc.CommitHash, svc.DeploymentId,
-		svc.UpdatedAt.Unix(), svc.ID)
+		svc.HostPort, svc.UpdatedAt.Unix(), svc.ID)
 	return err
 }
 
@@ -206,14 +232,19 @@ func (s ServiceStore) UpsertService(ctx context.Context, svc *store.Service) (*s
 	return s.createService(ctx, svc)
 }
 
-// DeleteService removes a service by name.
+// DeleteService removes a service by name, releasing its host ports.
 func (s ServiceStore) DeleteService(ctx context.Context, name string) error {
-	stmt, err := s.db.PrepareContext(ctx, `DELETE FROM services WHERE name = ?`)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	defer tx.Rollback() //nolint:errcheck
 
-	_, err = stmt.ExecContext(ctx, name)
-	return err
+	if _, err := tx.ExecContext(ctx, `DELETE FROM services WHERE name = ?`, name); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM port_reservations WHERE service = ?`, name); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,140 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// ServiceRunStore implements store.ServiceRunStore using SQLite.
+type ServiceRunStore struct {
+	db *sql.DB
+}
+
+func NewServiceRunStore(db *sql.DB) *ServiceRunStore {
+	return &ServiceRunStore{db: db}
+}
+
+const serviceRunColumns = `id, service, command, image, subject, status, exit_code, error, timeout_ms, started_at, finished_at, duration_ms`
+
+func (s *ServiceRunStore) CreateServiceRun(ctx context.Context, r *store.ServiceRun) error {
+	if r.ID == "" {
+		r.ID = ulid.Make().String()
+	}
+	if r.StartedAt.IsZero() {
+		r.StartedAt = time.Now()
+	}
+
+	command, err := json.Marshal(r.Command)
+	if err != nil {
+		return err
+	}
+	var finishedAt sql.NullInt64
+	if r.FinishedAt != nil {
+		finishedAt = sql.NullInt64{Int64: r.FinishedAt.Unix(), Valid: true}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO service_runs (`+serviceRunColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Service, string(command), r.Image, r.Subject, r.Status, exitCode(r.ExitCode), r.Error, r.TimeoutMs, r.StartedAt.Unix(), finishedAt, r.DurationMs)
+	return err
+}
+
+func (s *ServiceRunStore) FinishServiceRun(ctx context.Context, r *store.ServiceRun) error {
+	finishedAt := time.Now()
+	if r.FinishedAt != nil {
+		finishedAt = *r.FinishedAt
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE service_runs
+		SET status = ?, exit_code = ?, error = ?, finished_at = ?, duration_ms = ?
+		WHERE id = ?`,
+		r.Status, exitCode(r.ExitCode), r.Error, finishedAt.Unix(), r.DurationMs, r.ID)
+	return err
+}
+
+func (s *ServiceRunStore) GetServiceRun(ctx context.Context, id string) (*store.ServiceRun, error) {
+	r, err := scanServiceRun(s.db.QueryRowContext(ctx, `
+		SELECT `+serviceRunColumns+`
+		FROM service_runs
+		WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return r, err
+}
+
+func (s *ServiceRunStore) ListServiceRuns(ctx context.Context, service string, limit int) ([]*store.ServiceRun, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+serviceRunColumns+`
+		FROM service_runs
+		WHERE service = ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?`, service, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*store.ServiceRun
+	for rows.Next() {
+		r, err := scanServiceRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// FailInterruptedServiceRuns marks runs still recorded as running as failed.
+// The daemon that started them stopped waiting on their containers before
+// they exited, so their outcome is unknown. It must only run at startup.
+func (s *ServiceRunStore) FailInterruptedServiceRuns(ctx context.Context) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE service_runs SET status = ?, error = ?, finished_at = ? WHERE status = ?`,
+		store.ServiceRunFailed, "interrupted by a daemon restart", time.Now().Unix(), store.ServiceRunRunning)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanServiceRun(row rowScanner) (*store.ServiceRun, error) {
+	var r store.ServiceRun
+	var command string
+	var code, finishedAtUnix sql.NullInt64
+	var startedAtUnix int64
+	if err := row.Scan(&r.ID, &r.Service, &command, &r.Image, &r.Subject, &r.Status, &code, &r.Error, &r.TimeoutMs, &startedAtUnix, &finishedAtUnix, &r.DurationMs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(command), &r.Command); err != nil {
+		return nil, err
+	}
+	if code.Valid {
+		c := int(code.Int64)
+		r.ExitCode = &c
+	}
+	r.StartedAt = time.Unix(startedAtUnix, 0)
+	if finishedAtUnix.Valid {
+		t := time.Unix(finishedAtUnix.Int64, 0)
+		r.FinishedAt = &t
+	}
+	return &r, nil
+}
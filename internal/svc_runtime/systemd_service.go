@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/dployr-io/dployr/pkg/core/utils"
@@ -27,6 +28,63 @@ func (s *SystemdManager) Status(name string) (string, error) {
 	return s.systemdCheck(name)
 }
 
+// Start starts a container, or a systemd unit when one defines name. A
+// one-shot unit, such as a run of a scheduled job, is waited on until its
+// process exits.
+func (s *SystemdManager) Start(name string) error {
+	if !isUnit(name) {
+		return s.DockerService.Start(name)
+	}
+	if out, err := systemctl("start", name); err != nil {
+		return fmt.Errorf("systemctl start %s: %v\n%s", name, err, out)
+	}
+	return nil
+}
+
+func (s *SystemdManager) Stop(name string) error {
+	if !isUnit(name) {
+		return s.DockerService.Stop(name)
+	}
+	if out, err := systemctl("stop", name); err != nil {
+		return fmt.Errorf("systemctl stop %s: %v\n%s", name, err, out)
+	}
+	// A failed unit stays loaded until its failure is reset.
+	systemctl("reset-failed", name) //nolint:errcheck
+	return nil
+}
+
+// ExitCode returns the exit status of a unit's main process, or of a
+// container's. systemd unloads a one-shot instance as soon as it succeeds and
+// then reports 0; a failed one keeps its status until it is stopped.
+func (s *SystemdManager) ExitCode(name string) (int, error) {
+	if !isUnit(name) {
+		return s.DockerService.ExitCode(name)
+	}
+	out, err := exec.Command("systemctl", "show", "--property=ExecMainStatus", "--value", name).Output()
+	if err != nil {
+		return -1, fmt.Errorf("systemctl show %s: %v", name, err)
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return -1, fmt.Errorf("unexpected exit status %q for %s", strings.TrimSpace(string(out)), name)
+	}
+	return code, nil
+}
+
+// isUnit reports whether a systemd unit file defines name. An instance of a
+// template unit, "job@run", is defined by "job@.service".
+func isUnit(name string) bool {
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name = name[:i+1]
+	}
+	_, err := os.Stat("/etc/systemd/system/" + name + ".service")
+	return err == nil
+}
+
+func systemctl(args ...string) ([]byte, error) {
+	return exec.Command("sudo", append([]string{"systemctl"}, args...)...).CombinedOutput()
+}
+
 func (s *SystemdManager) systemdCheck(name string) (string, error) {
 	name = utils.FormatName(name)
 
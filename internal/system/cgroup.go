@@ -19,6 +19,7 @@ import (
 	cgroup2 "github.com/containerd/cgroups/v3/cgroup2"
 	systemddbus "github.com/coreos/go-systemd/v22/dbus"
 	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/store"
 	godbus "github.com/godbus/dbus/v5"
 )
 
@@ -155,6 +156,24 @@ func ReadClusterResources() map[string]*system.ClusterResourcesInfo {
 	return result
 }
 
+// ClusterLimits returns the memory and CPU limits of a cluster's slice on
+// this node. Reports false when the slice has not been set up here.
+func ClusterLimits(clusterID string) (store.Resources, bool) {
+	slicePath := filepath.Join(cgroupRoot, "dployr.slice", "dployr-cluster.slice", "dployr-cluster-"+clusterID+".slice")
+	raw, err := os.ReadFile(filepath.Join(slicePath, "memory.max"))
+	if err != nil {
+		return store.Resources{}, false
+	}
+	var limits store.Resources
+	if v := strings.TrimSpace(string(raw)); v != "max" {
+		if b, err := strconv.ParseInt(v, 10, 64); err == nil {
+			limits.MemoryMB = int(b / (1024 * 1024))
+		}
+	}
+	limits.CPU = int(readCPUMaxMillicores(slicePath))
+	return limits, true
+}
+
 // computeCPUPercent returns the CPU usage as a percentage of one full core since
 // the last call for this cluster ID. Returns 0 on the first call (no prior sample).
 func computeCPUPercent(clusterID string, usageUsec uint64) float64 {
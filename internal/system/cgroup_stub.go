@@ -5,7 +5,10 @@
 
 package system
 
-import "github.com/dployr-io/dployr/pkg/core/system"
+import (
+	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/store"
+)
 
 func EnsureClusterSlice(clusterID string, memoryMB int, cpuMillicores int) error {
 	return nil
@@ -14,3 +17,7 @@ func EnsureClusterSlice(clusterID string, memoryMB int, cpuMillicores int) error
 func ReadClusterResources() map[string]*system.ClusterResourcesInfo {
 	return nil
 }
+
+func ClusterLimits(clusterID string) (store.Resources, bool) {
+	return store.Resources{}, false
+}
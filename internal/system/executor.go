@@ -24,6 +24,7 @@ import (
 	pkgAuth "github.com/dployr-io/dployr/pkg/auth"
 	corelogs "github.com/dployr-io/dployr/pkg/core/logs"
 	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
 	"github.com/dployr-io/dployr/pkg/tasks"
 )
 
@@ -32,7 +33,7 @@ type AccessTokenProvider interface {
 }
 
 type TerminalHandler interface {
-	HandleRelaySession(ctx context.Context, conn *websocket.Conn, sessionID string, cols, rows uint16) error
+	HandleRelaySession(ctx context.Context, conn *websocket.Conn, sessionID, service string, cols, rows uint16) error
 }
 
 type Executor struct {
@@ -45,6 +46,7 @@ type Executor struct {
 	wsConnMu        sync.RWMutex
 	terminalHandler TerminalHandler
 	terminalMu      sync.RWMutex
+	audit           pkgAuth.AuditRecorder
 	activeStreams   sync.Map // streamID → struct{}, guards against duplicate log stream goroutines
 	streamCancelsMu sync.Mutex
 	streamCancels   map[string]streamOwner // path → owner, ensures only one active stream per log path
@@ -93,20 +95,34 @@ func (e *Executor) SetWSConn(conn *websocket.Conn) {
 	e.wsConn = conn
 }
 
+// conn returns the current websocket connection to base, or nil.
+func (e *Executor) conn() *websocket.Conn {
+	e.wsConnMu.RLock()
+	defer e.wsConnMu.RUnlock()
+	return e.wsConn
+}
+
 func (e *Executor) SetTerminalHandler(h TerminalHandler) {
 	e.terminalMu.Lock()
 	defer e.terminalMu.Unlock()
 	e.terminalHandler = h
 }
 
+// SetAuditLog records terminal sessions, which bypass the mux and its audit
+// middleware, to the audit log.
+func (e *Executor) SetAuditLog(rec pkgAuth.AuditRecorder) {
+	e.audit = rec
+}
+
 func (e *Executor) getTerminalHandler() TerminalHandler {
 	e.terminalMu.RLock()
 	defer e.terminalMu.RUnlock()
 	return e.terminalHandler
 }
 
-// sendLogChunkToBase sends a log chunk to the base via WebSocket.
-func (e *Executor) sendLogChunkToBase(ctx context.Context, chunk corelogs.LogChunk) error {
+// SendLogChunk sends a log chunk to the base via WebSocket. Besides log
+// streams, it carries the output of one-off service runs.
+func (e *Executor) SendLogChunk(ctx context.Context, chunk corelogs.LogChunk) error {
 	e.wsConnMu.RLock()
 	conn := e.wsConn
 	e.wsConnMu.RUnlock()
@@ -167,7 +183,8 @@ func (e *Executor) handleLogStream(ctx context.Context, task *tasks.Task) *tasks
 		}
 	}
 	if e.auth != nil {
-		if _, err := e.auth.ValidateToken(ctx, strings.TrimSpace(payload.Token)); err != nil {
+		claims, err := e.auth.ValidateToken(ctx, strings.TrimSpace(payload.Token))
+		if err != nil {
 			e.logger.Error("log streaming token validation failed", "error", err)
 			return &tasks.Result{
 				ID:     task.ID,
@@ -175,6 +192,13 @@ func (e *Executor) handleLogStream(ctx context.Context, task *tasks.Task) *tasks
 				Error:  "invalid token",
 			}
 		}
+		if !claims.HasScope(pkgAuth.ScopeLogsRead) {
+			return &tasks.Result{
+				ID:     task.ID,
+				Status: "failed",
+				Error:  fmt.Sprintf("token lacks scope %s", pkgAuth.ScopeLogsRead),
+			}
+		}
 	}
 
 	if _, loaded := e.activeStreams.LoadOrStore(payload.StreamID, struct{}{}); loaded {
@@ -216,7 +240,7 @@ func (e *Executor) handleLogStream(ctx context.Context, task *tasks.Task) *tasks
 		}
 
 		err := logHandler.StreamLogs(streamCtx, opts, func(chunk corelogs.LogChunk) error {
-			return e.sendLogChunkToBase(streamCtx, chunk)
+			return e.SendLogChunk(streamCtx, chunk)
 		})
 
 		if err != nil && !errors.Is(err, context.Canceled) {
@@ -238,11 +262,13 @@ func (e *Executor) handleTerminalOpen(ctx context.Context, task *tasks.Task) *ta
 	var payload struct {
 		Token     string `json:"token"`
 		SessionID string `json:"sessionId"`
+		Service   string `json:"service"` // run inside this service's container instead of on the host
 		Cols      uint16 `json:"cols"`
 		Rows      uint16 `json:"rows"`
 	}
 
 	if err := json.Unmarshal(task.Payload, &payload); err != nil {
+		e.auditTerminal(ctx, nil, http.StatusBadRequest, task.Payload)
 		return &tasks.Result{
 			ID:     task.ID,
 			Status: "failed",
@@ -250,7 +276,18 @@ func (e *Executor) handleTerminalOpen(ctx context.Context, task *tasks.Task) *ta
 		}
 	}
 
+	// The token is left out of the digest; the subject already says who asked.
+	args, _ := json.Marshal(map[string]any{"sessionId": payload.SessionID, "service": payload.Service, "cols": payload.Cols, "rows": payload.Rows})
+
+	// A host shell runs as the daemon's user, so it takes more than a shell
+	// confined to one service's container.
+	scope, role := pkgAuth.ScopeTerminalOpen, string(store.RoleAdmin)
+	if payload.Service != "" {
+		scope, role = pkgAuth.ScopeTerminalExec, string(store.RoleDeveloper)
+	}
+
 	if strings.TrimSpace(payload.Token) == "" {
+		e.auditTerminal(ctx, nil, http.StatusUnauthorized, args)
 		return &tasks.Result{
 			ID:     task.ID,
 			Status: "failed",
@@ -258,18 +295,43 @@ func (e *Executor) handleTerminalOpen(ctx context.Context, task *tasks.Task) *ta
 		}
 	}
 
+	var claims *pkgAuth.Claims
 	if e.auth != nil {
-		if _, err := e.auth.ValidateToken(ctx, strings.TrimSpace(payload.Token)); err != nil {
+		var err error
+		claims, err = e.auth.ValidateToken(ctx, strings.TrimSpace(payload.Token))
+		if err != nil {
 			e.logger.Error("terminal token validation failed", "error", err)
+			e.auditTerminal(ctx, nil, http.StatusUnauthorized, args)
 			return &tasks.Result{
 				ID:     task.ID,
 				Status: "failed",
 				Error:  "invalid token",
 			}
 		}
+		if !claims.HasScope(scope) {
+			e.auditTerminal(ctx, claims, http.StatusForbidden, args)
+			return &tasks.Result{
+				ID:     task.ID,
+				Status: "failed",
+				Error:  fmt.Sprintf("token lacks scope %s", scope),
+			}
+		}
+		if !pkgAuth.IsPermitted(claims.Perm, role) {
+			e.auditTerminal(ctx, claims, http.StatusForbidden, args)
+			return &tasks.Result{
+				ID:     task.ID,
+				Status: "failed",
+				Error:  fmt.Sprintf("role %s required", role),
+			}
+		}
+	}
+	e.auditTerminal(ctx, claims, http.StatusOK, args)
+	if claims != nil {
+		// The terminal handler attributes its recording to this subject.
+		ctx = shared.WithUser(ctx, claims.UserID())
 	}
 
-	e.logger.Info("starting terminal session", "session_id", payload.SessionID, "cols", payload.Cols, "rows", payload.Rows)
+	e.logger.Info("starting terminal session", "session_id", payload.SessionID, "service", payload.Service, "cols", payload.Cols, "rows", payload.Rows)
 
 	go func() {
 		wsURL := strings.Replace(e.cfg.BaseURL, "https://", "wss://", 1)
@@ -293,7 +355,7 @@ func (e *Executor) handleTerminalOpen(ctx context.Context, task *tasks.Task) *ta
 			return
 		}
 
-		if err := terminalHandler.HandleRelaySession(ctx, conn, payload.SessionID, payload.Cols, payload.Rows); err != nil {
+		if err := terminalHandler.HandleRelaySession(ctx, conn, payload.SessionID, payload.Service, payload.Cols, payload.Rows); err != nil {
 			e.logger.Error("terminal relay session failed", "error", err, "session_id", payload.SessionID)
 		}
 	}()
@@ -308,8 +370,34 @@ func (e *Executor) handleTerminalOpen(ctx context.Context, task *tasks.Task) *ta
 	}
 }
 
+// auditTerminal records a terminal/open task, which is authorised here rather
+// than by the mux, under the outcome its status maps to.
+func (e *Executor) auditTerminal(ctx context.Context, claims *pkgAuth.Claims, status int, args []byte) {
+	if e.audit == nil {
+		return
+	}
+	entry := &store.AuditEntry{
+		TaskID:     shared.TaskID(ctx),
+		Method:     http.MethodPost,
+		Route:      "terminal/open",
+		Status:     status,
+		Outcome:    pkgAuth.AuditOutcome(status),
+		ArgsDigest: pkgAuth.DigestArgs("", args),
+	}
+	if claims != nil {
+		entry.Subject = claims.UserID()
+		entry.Role = claims.Perm
+	}
+	if err := e.audit.AppendAudit(context.WithoutCancel(ctx), entry); err != nil {
+		e.logger.Error("failed to record audit entry", "route", entry.Route, "error", err)
+	}
+}
+
 // Execute runs a task by converting it to an HTTP request and routing it internally.
+// A routed task is held to the scope of the route its address maps to; the
+// log stream and terminal tasks, which bypass the mux, check theirs here.
 func (e *Executor) Execute(ctx context.Context, task *tasks.Task) *tasks.Result {
+	ctx = shared.WithTask(ctx, task.ID)
 	start := time.Now()
 	atomic.AddInt64(&pendingTasks, 1)
 	defer atomic.AddInt64(&pendingTasks, -1)
@@ -358,7 +446,7 @@ func (e *Executor) Execute(ctx context.Context, task *tasks.Task) *tasks.Result
 		}
 	}
 
-	logger.Info("raw task payload", "payload", string(task.Payload))
+	logger.Debug("task payload", "payload", string(shared.RedactJSON(task.Payload)))
 
 	req.Header.Set("Content-Type", "application/json")
 	// Authorization: extract token from payload;
@@ -368,14 +456,13 @@ func (e *Executor) Execute(ctx context.Context, task *tasks.Task) *tasks.Result
 		if err := json.Unmarshal(task.Payload, &p); err != nil {
 			logger.Error("failed to unmarshal payload for auth", "error", err)
 		} else {
-			logger.Info("parsed payload map", "payload_map", p)
 			if t, ok := p["token"].(string); ok && strings.TrimSpace(t) != "" {
 				bearer = strings.TrimSpace(t)
 			}
 		}
 	}
 	if bearer != "" {
-		logger.Info("setting Authorization header", "bearer", bearer)
+		logger.Debug("setting Authorization header from payload token")
 		req.Header.Set("Authorization", "Bearer "+bearer)
 	} else {
 		logger.Info("no bearer token found in payload")
@@ -420,11 +507,12 @@ func (e *Executor) Execute(ctx context.Context, task *tasks.Task) *tasks.Result
 		return res
 	}
 
-	logger.Error("task failed", "status_code", resp.StatusCode, "duration_ms", duration.Milliseconds(), "response_bytes", len(respBody), "response", string(respBody))
+	redactedBody := shared.RedactString(string(respBody))
+	logger.Error("task failed", "status_code", resp.StatusCode, "duration_ms", duration.Milliseconds(), "response_bytes", len(respBody), "response", redactedBody)
 	res := &tasks.Result{
 		ID:     task.ID,
 		Status: "failed",
-		Error:  fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody)),
+		Error:  fmt.Sprintf("HTTP %d: %s", resp.StatusCode, redactedBody),
 	}
 	lastExec.Store(lastExecInfo{ID: task.ID, Status: "failed", DurMs: duration.Milliseconds(), At: time.Now()})
 	atomic.AddUint64(&taskExecutedFailedTotal, 1)
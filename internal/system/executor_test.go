@@ -0,0 +1,170 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	pkgAuth "github.com/dployr-io/dployr/pkg/auth"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+	"github.com/dployr-io/dployr/pkg/tasks"
+)
+
+// secretValues appear in the test payload and must never reach the log.
+var secretValues = []string{"tok-live-1", "sk_live_2", "hunter3", "cmVnOnNlYy00"}
+
+const secretPayload = `{
+	"token": "tok-live-1",
+	"name": "api",
+	"secrets": {"STRIPE_KEY": "sk_live_2"},
+	"env_vars": {"PORT": "3000", "DB_PASSWORD": "hunter3"},
+	"registry_auth": "cmVnOnNlYy00"
+}`
+
+func TestExecute_KeepsSecretsOutOfLogs(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusBadRequest} {
+		var logs bytes.Buffer
+		var gotAuth string
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Authorization")
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if status != http.StatusOK {
+				shared.WriteError(w, status, "bad_request", "rejected", body)
+				return
+			}
+			shared.WriteJSON(w, status, map[string]any{"name": body["name"]})
+		})
+
+		e := NewExecutor(shared.NewWriterLogger(&logs), &shared.Config{}, handler, nil, nil)
+		res := e.Execute(context.Background(), &tasks.Task{
+			ID:      "t1",
+			Type:    "deployments:post",
+			Payload: json.RawMessage(secretPayload),
+		})
+
+		if gotAuth != "Bearer tok-live-1" {
+			t.Errorf("Authorization = %q, want the payload token", gotAuth)
+		}
+		for _, v := range secretValues {
+			if strings.Contains(logs.String(), v) {
+				t.Errorf("status %d: log contains %q:\n%s", status, v, logs.String())
+			}
+			if strings.Contains(res.Error, v) {
+				t.Errorf("status %d: task error contains %q: %s", status, v, res.Error)
+			}
+		}
+	}
+}
+
+type stubAuthenticator struct {
+	claims *pkgAuth.Claims
+}
+
+func (s stubAuthenticator) ValidateToken(ctx context.Context, token string) (*pkgAuth.Claims, error) {
+	return s.claims, nil
+}
+
+type memAudit struct {
+	entries []*store.AuditEntry
+}
+
+func (m *memAudit) AppendAudit(ctx context.Context, e *store.AuditEntry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func TestExecute_AuditsRefusedTerminal(t *testing.T) {
+	rec := &memAudit{}
+	claims := &pkgAuth.Claims{Subject: "alice", Perm: "viewer", Scopes: []string{"services:read"}}
+	e := NewExecutor(shared.NewLogger(), &shared.Config{}, http.NotFoundHandler(), nil, stubAuthenticator{claims})
+	e.SetAuditLog(rec)
+
+	res := e.Execute(context.Background(), &tasks.Task{
+		ID:      "t2",
+		Type:    "terminal/open:post",
+		Payload: json.RawMessage(`{"token":"tok-live-1","sessionId":"s1","cols":80,"rows":24}`),
+	})
+	if res.Status != "failed" {
+		t.Fatalf("status = %s, want failed for a token without terminal:open", res.Status)
+	}
+	if len(rec.entries) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(rec.entries))
+	}
+	got := rec.entries[0]
+	if got.Subject != "alice" || got.TaskID != "t2" || got.Route != "terminal/open" || got.Outcome != store.AuditDenied {
+		t.Errorf("entry = %+v", got)
+	}
+}
+
+func TestExecute_TerminalRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		perm    string
+		scopes  []string
+		service string
+		want    store.AuditOutcome
+	}{
+		{"developer in service", "developer", []string{"terminal:exec"}, "api", store.AuditSucceeded},
+		{"developer on host", "developer", nil, "", store.AuditDenied},
+		{"admin on host", "admin", []string{"terminal:open"}, "", store.AuditSucceeded},
+		{"host scope does not cover service", "admin", []string{"terminal:open"}, "api", store.AuditDenied},
+		{"viewer in service", "viewer", []string{"terminal:exec"}, "api", store.AuditDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &memAudit{}
+			claims := &pkgAuth.Claims{Subject: "alice", Perm: tt.perm, Scopes: tt.scopes}
+			e := NewExecutor(shared.NewLogger(), &shared.Config{}, http.NotFoundHandler(), nil, stubAuthenticator{claims})
+			e.SetAuditLog(rec)
+
+			payload, _ := json.Marshal(map[string]any{"token": "tok", "sessionId": "s1", "service": tt.service})
+			e.Execute(context.Background(), &tasks.Task{ID: "t3", Type: "terminal/open:post", Payload: payload})
+
+			if len(rec.entries) != 1 || rec.entries[0].Outcome != tt.want {
+				t.Errorf("entries = %+v, want one %s", rec.entries, tt.want)
+			}
+		})
+	}
+}
+
+type workloadDeployStore struct {
+	store.DeploymentStore
+	deps []*store.Deployment
+}
+
+func (s workloadDeployStore) ListDeployments(ctx context.Context, limit, offset int) ([]*store.Deployment, error) {
+	return s.deps, nil
+}
+
+func TestBuildWorkloads_RedactsEnv(t *testing.T) {
+	ds := workloadDeployStore{deps: []*store.Deployment{{
+		ID: "d1",
+		Blueprint: store.Blueprint{
+			Name:    "api",
+			EnvVars: map[string]string{"PORT": "3000", "DB_PASSWORD": "hunter3"},
+			Secrets: map[string]string{"STRIPE_KEY": "sk_live_2"},
+		},
+	}}}
+
+	w, err := buildWorkloads(context.Background(), ds, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(w)
+	for _, v := range secretValues {
+		if strings.Contains(string(b), v) {
+			t.Errorf("workload snapshot contains %q: %s", v, b)
+		}
+	}
+	if env := w.Deployments[0].EnvVars; env["PORT"] != "3000" || env["DB_PASSWORD"] != shared.Redacted {
+		t.Errorf("env_vars = %v", env)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -38,6 +39,7 @@ type FileSystem struct {
 	maxDepth    int
 	maxChildren int
 	roots       []string
+	denied      []string // resolved paths unreachable through the API
 
 	// Filesystem watcher
 	watcher      *fsnotify.Watcher
@@ -72,6 +74,48 @@ func NewFS() *FileSystem {
 	return fs
 }
 
+// Deny makes paths, and everything below them, unreachable through the
+// filesystem API: they are left out of listings and cannot be read, written,
+// deleted or watched. Call it before serving requests.
+func (c *FileSystem) Deny(paths ...string) {
+	for _, p := range paths {
+		c.denied = append(c.denied, resolvePath(p))
+	}
+}
+
+// isDenied reports whether path resolves to a denied path or one below it.
+// Symlinks are followed, so a link to a denied file is denied too.
+func (c *FileSystem) isDenied(path string) bool {
+	resolved := resolvePath(path)
+	for _, d := range c.denied {
+		if resolved == d || strings.HasPrefix(resolved, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path with symlinks resolved. For a path
+// that does not exist yet the deepest existing parent is resolved instead.
+func resolvePath(path string) string {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	var rest []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...)
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
 // GetSnapshot returns the current snapshot, triggering refresh if stale
 func (c *FileSystem) GetSnapshot() *system.FSSnapshot {
 	c.mu.RLock()
@@ -119,6 +163,9 @@ func (c *FileSystem) buildSnapshot() *system.FSSnapshot {
 }
 
 func (c *FileSystem) walkDir(path string, depth int) *system.FSNode {
+	if c.isDenied(path) {
+		return nil
+	}
 	info, err := os.Lstat(path)
 	if err != nil {
 		return nil
@@ -199,6 +246,9 @@ func (c *FileSystem) ListDir(path string, depth, limit int, cursor string) (*sys
 	if limit > 500 {
 		limit = 500
 	}
+	if c.isDenied(path) {
+		return nil, system.ErrPathDenied
+	}
 
 	info, err := os.Lstat(path)
 	if err != nil {
@@ -259,6 +309,9 @@ func (c *FileSystem) ListDir(path string, depth, limit int, cursor string) (*sys
 
 // ReadFile reads file contents
 func (c *FileSystem) ReadFile(path string, offset, limit int64) (*system.FSReadResponse, error) {
+	if c.isDenied(path) {
+		return nil, system.ErrPathDenied
+	}
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("file not found: %w", err)
@@ -316,6 +369,14 @@ func (c *FileSystem) ReadFile(path string, offset, limit int64) (*system.FSReadR
 
 // WriteFile writes content to a file
 func (c *FileSystem) WriteFile(req *system.FSWriteRequest) (*system.FSOpResponse, error) {
+	if c.isDenied(req.Path) {
+		return &system.FSOpResponse{
+			Success: false,
+			Path:    req.Path,
+			Error:   system.ErrPathDenied.Error(),
+		}, nil
+	}
+
 	// Check parent directory is writable
 	parentDir := filepath.Dir(req.Path)
 	if !canWriteDir(parentDir) {
@@ -367,6 +428,14 @@ func (c *FileSystem) WriteFile(req *system.FSWriteRequest) (*system.FSOpResponse
 
 // CreateFile creates a new file or directory
 func (c *FileSystem) CreateFile(req *system.FSCreateRequest) (*system.FSOpResponse, error) {
+	if c.isDenied(req.Path) {
+		return &system.FSOpResponse{
+			Success: false,
+			Path:    req.Path,
+			Error:   system.ErrPathDenied.Error(),
+		}, nil
+	}
+
 	// Check parent directory is writable
 	parentDir := filepath.Dir(req.Path)
 	if !canWriteDir(parentDir) {
@@ -429,6 +498,14 @@ func (c *FileSystem) CreateFile(req *system.FSCreateRequest) (*system.FSOpRespon
 
 // DeleteFile deletes a file or directory
 func (c *FileSystem) DeleteFile(req *system.FSDeleteRequest) (*system.FSOpResponse, error) {
+	if c.isDenied(req.Path) {
+		return &system.FSOpResponse{
+			Success: false,
+			Path:    req.Path,
+			Error:   system.ErrPathDenied.Error(),
+		}, nil
+	}
+
 	// Check parent directory is writable
 	parentDir := filepath.Dir(req.Path)
 	if !canWriteDir(parentDir) {
@@ -587,7 +664,7 @@ func (c *FileSystem) handleWatchEvent(event fsnotify.Event) {
 	broadcaster := c.broadcaster
 	c.watchMu.RUnlock()
 
-	if broadcaster == nil {
+	if broadcaster == nil || c.isDenied(event.Name) {
 		return
 	}
 
@@ -626,6 +703,9 @@ func (c *FileSystem) Watch(path string, recursive bool) error {
 	if c.watcher == nil {
 		return fmt.Errorf("filesystem watcher not available")
 	}
+	if c.isDenied(path) {
+		return system.ErrPathDenied
+	}
 
 	c.watchMu.Lock()
 	defer c.watchMu.Unlock()
@@ -647,6 +727,9 @@ func (c *FileSystem) Watch(path string, recursive bool) error {
 			if err != nil {
 				return nil // skip errors
 			}
+			if info.IsDir() && c.isDenied(subpath) {
+				return filepath.SkipDir
+			}
 			if info.IsDir() && subpath != path {
 				if !c.watchedPaths[subpath] {
 					if err := c.watcher.Add(subpath); err == nil {
@@ -0,0 +1,84 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+func TestFS_DeniedPathsAreRefused(t *testing.T) {
+	dataDir := t.TempDir()
+	key := filepath.Join(dataDir, "secret.key")
+	keys := filepath.Join(dataDir, ".dployr", "keys")
+	os.WriteFile(key, []byte("topsecret"), 0600)
+	os.MkdirAll(filepath.Join(keys, "api"), 0700)
+	os.WriteFile(filepath.Join(keys, "api", "id_ed25519"), []byte("topsecret"), 0600)
+	os.WriteFile(filepath.Join(dataDir, "notes.txt"), []byte("hello"), 0644)
+	link := filepath.Join(t.TempDir(), "key")
+	os.Symlink(key, link)
+
+	fs := NewFS()
+	t.Cleanup(func() { fs.Close() })
+	fs.Deny(key, keys)
+	h := system.NewFSHandler(fs, shared.NewLogger())
+
+	read := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.HandleRead(rec, httptest.NewRequest(http.MethodGet, "/system/fs/read?path="+url.QueryEscape(path), nil))
+		return rec
+	}
+	for _, path := range []string{
+		key,
+		filepath.Join(dataDir, ".dployr", "..", "secret.key"),
+		link,
+		filepath.Join(keys, "api", "id_ed25519"),
+	} {
+		rec := read(path)
+		if rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "topsecret") {
+			t.Errorf("read %s = %d %s, want it refused", path, rec.Code, rec.Body)
+		}
+	}
+	if rec := read(filepath.Join(dataDir, "notes.txt")); rec.Code != http.StatusOK {
+		t.Errorf("read of an unprotected file = %d %s", rec.Code, rec.Body)
+	}
+
+	list, err := fs.ListDir(dataDir, 2, 0, "")
+	if err != nil {
+		t.Fatalf("ListDir: %v", err)
+	}
+	var check func(n system.FSNode)
+	check = func(n system.FSNode) {
+		if n.Path == key || strings.HasPrefix(n.Path, keys) {
+			t.Errorf("listing exposes %s", n.Path)
+		}
+		for _, c := range n.Children {
+			check(c)
+		}
+	}
+	check(list.Node)
+	if _, err := fs.ListDir(keys, 1, 0, ""); err == nil {
+		t.Error("listed the deploy keys")
+	}
+
+	resp, _ := fs.WriteFile(&system.FSWriteRequest{Path: link, Content: "replaced"})
+	if resp.Success {
+		t.Error("overwrote the key through a symlink")
+	}
+	resp, _ = fs.CreateFile(&system.FSCreateRequest{Path: filepath.Join(keys, "api", "new"), Type: "file"})
+	if resp.Success {
+		t.Error("created a file among the deploy keys")
+	}
+	if got, _ := os.ReadFile(key); string(got) != "topsecret" {
+		t.Errorf("key = %q after the refused writes", got)
+	}
+}
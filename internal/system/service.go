@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -32,20 +33,54 @@ import (
 var startTime = time.Now()
 
 var (
-	currentModeMu sync.RWMutex
-	currentMode   = system.ModeReady
+	currentModeMu     sync.RWMutex
+	currentMode       = system.ModeReady
+	maintenanceStatus *system.MaintenanceStatus // set in maintenance mode
+	shutdownStatus    *system.ShutdownStatus    // set once the daemon starts draining
+
+	// modeChangeMu serialises SetMode, so maintenance is applied and lifted
+	// in the order requested without holding currentModeMu meanwhile.
+	modeChangeMu sync.Mutex
 )
 
+// inMode reports whether the daemon is in any of modes.
+func inMode(modes ...system.Mode) bool {
+	currentModeMu.RLock()
+	defer currentModeMu.RUnlock()
+	return slices.Contains(modes, currentMode)
+}
+
+// ReportShutdown puts the daemon in draining mode and records the progress
+// of its shutdown for system/mode. Draining is never left; the process exits.
+func ReportShutdown(st system.ShutdownStatus) {
+	st.Done = slices.Clone(st.Done)
+	currentModeMu.Lock()
+	defer currentModeMu.Unlock()
+	currentMode = system.ModeDraining
+	shutdownStatus = &st
+}
+
+// MaintenanceFunc applies maintenance when on is set, covering domains or
+// every domain when there are none, and lifts it otherwise.
+type MaintenanceFunc func(on bool, domains []string) error
+
 type DefaultService struct {
-	cfg     *shared.Config
-	store   store.InstanceStore
-	results store.TaskResultStore
+	cfg         *shared.Config
+	store       store.InstanceStore
+	results     store.TaskResultStore
+	maintenance MaintenanceFunc
 }
 
 func NewDefaultService(cfg *shared.Config, store store.InstanceStore, results store.TaskResultStore) *DefaultService {
 	return &DefaultService{cfg: cfg, store: store, results: results}
 }
 
+// SetMaintenanceHandler sets what entering and leaving maintenance mode does
+// beyond changing the reported mode.
+func (s *DefaultService) SetMaintenanceHandler(fn MaintenanceFunc) {
+	s.maintenance = fn
+}
+
 func (s *DefaultService) GetInfo(ctx context.Context) (utils.SystemInfo, error) {
 	return utils.GetSystemInfo()
 }
@@ -285,25 +320,78 @@ func (s *DefaultService) GetTasks(ctx context.Context, status string, excludeSys
 func (s *DefaultService) GetMode(ctx context.Context) (system.ModeStatus, error) {
 	currentModeMu.RLock()
 	defer currentModeMu.RUnlock()
-	return system.ModeStatus{Mode: currentMode}, nil
+	st := system.ModeStatus{Mode: currentMode}
+	if currentMode == system.ModeMaintenance && maintenanceStatus != nil {
+		ms := *maintenanceStatus
+		ms.Domains = slices.Clone(ms.Domains)
+		st.Maintenance = &ms
+	}
+	if shutdownStatus != nil {
+		sd := *shutdownStatus
+		st.Shutdown = &sd
+	}
+	return st, nil
 }
 
-// SetMode updates the daemon mode.
+// SetMode updates the daemon mode. Entering maintenance applies it through
+// the maintenance handler before the mode changes, and leaving it lifts it
+// first, so a failure leaves the daemon in the mode it was in.
 func (s *DefaultService) SetMode(ctx context.Context, req system.SetModeRequest) (system.ModeStatus, error) {
 	mode := req.Mode
 	if mode == "" {
 		mode = system.ModeReady
 	}
 
-	if mode != system.ModeReady && mode != system.ModeUpdating {
+	if mode != system.ModeReady && mode != system.ModeUpdating && mode != system.ModeMaintenance {
 		return system.ModeStatus{}, fmt.Errorf("unsupported mode %q", mode)
 	}
+	if mode != system.ModeMaintenance && len(req.Domains) > 0 {
+		return system.ModeStatus{}, fmt.Errorf("domains only apply to maintenance mode")
+	}
+
+	modeChangeMu.Lock()
+	defer modeChangeMu.Unlock()
+
+	currentModeMu.RLock()
+	prev := currentMode
+	since := time.Now()
+	if maintenanceStatus != nil {
+		since = maintenanceStatus.Since
+	}
+	currentModeMu.RUnlock()
+	if prev == system.ModeDraining {
+		return system.ModeStatus{}, fmt.Errorf("daemon is shutting down")
+	}
+
+	if s.maintenance != nil {
+		switch {
+		case mode == system.ModeMaintenance:
+			if err := s.maintenance(true, req.Domains); err != nil {
+				return system.ModeStatus{}, fmt.Errorf("failed to enter maintenance: %w", err)
+			}
+		case prev == system.ModeMaintenance:
+			if err := s.maintenance(false, nil); err != nil {
+				return system.ModeStatus{}, fmt.Errorf("failed to leave maintenance: %w", err)
+			}
+		}
+	}
 
 	currentModeMu.Lock()
+	if currentMode == system.ModeDraining {
+		currentModeMu.Unlock()
+		return system.ModeStatus{}, fmt.Errorf("daemon is shutting down")
+	}
 	currentMode = mode
+	maintenanceStatus = nil
+	if mode == system.ModeMaintenance {
+		if prev != system.ModeMaintenance {
+			since = time.Now()
+		}
+		maintenanceStatus = &system.MaintenanceStatus{Domains: slices.Clone(req.Domains), Since: since}
+	}
 	currentModeMu.Unlock()
 
-	return system.ModeStatus{Mode: mode}, nil
+	return s.GetMode(ctx)
 }
 
 func (s *DefaultService) UpdateBootstrapToken(ctx context.Context, req system.UpdateBootstrapTokenRequest) error {
@@ -0,0 +1,70 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package system
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dployr-io/dployr/pkg/core/system"
+)
+
+func TestSetMode_Maintenance(t *testing.T) {
+	t.Cleanup(func() {
+		currentMode, maintenanceStatus = system.ModeReady, nil
+	})
+
+	type call struct {
+		on      bool
+		domains []string
+	}
+	var calls []call
+	fail := false
+	s := &DefaultService{}
+	s.SetMaintenanceHandler(func(on bool, domains []string) error {
+		if fail {
+			return errors.New("caddy restart failed")
+		}
+		calls = append(calls, call{on, domains})
+		return nil
+	})
+	ctx := context.Background()
+
+	st, err := s.SetMode(ctx, system.SetModeRequest{Mode: system.ModeMaintenance, Domains: []string{"shop.example.com"}})
+	if err != nil {
+		t.Fatalf("entering maintenance: %v", err)
+	}
+	if st.Mode != system.ModeMaintenance || st.Maintenance == nil || !slices.Equal(st.Maintenance.Domains, []string{"shop.example.com"}) {
+		t.Fatalf("status = %+v, want maintenance for shop.example.com", st)
+	}
+	if !inMode(system.ModeMaintenance) {
+		t.Error("daemon did not report maintenance mode")
+	}
+
+	fail = true
+	if _, err := s.SetMode(ctx, system.SetModeRequest{Mode: system.ModeReady}); err == nil {
+		t.Fatal("leaving maintenance succeeded although the handler failed")
+	}
+	if !inMode(system.ModeMaintenance) {
+		t.Error("a failed change must leave the daemon in maintenance")
+	}
+
+	fail = false
+	st, err = s.SetMode(ctx, system.SetModeRequest{Mode: system.ModeReady})
+	if err != nil {
+		t.Fatalf("leaving maintenance: %v", err)
+	}
+	if st.Mode != system.ModeReady || st.Maintenance != nil {
+		t.Errorf("status = %+v, want ready", st)
+	}
+	if len(calls) != 2 || !calls[0].on || calls[1].on {
+		t.Errorf("handler calls = %+v, want on then off", calls)
+	}
+
+	if _, err := s.SetMode(ctx, system.SetModeRequest{Mode: system.ModeReady, Domains: []string{"x.example.com"}}); err == nil {
+		t.Error("domains outside maintenance mode must be refused")
+	}
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/dployr-io/dployr/pkg/store"
 	"github.com/dployr-io/dployr/pkg/tasks"
 	"github.com/dployr-io/dployr/version"
+
+	"github.com/dployr-io/dployr/internal/ports"
 )
 
 var wsConnected int32
@@ -102,16 +104,22 @@ type Syncer struct {
 	fs                  *FileSystem
 	topCollector        *TopCollector
 	watchDog            *WatchDog
+	ports               *ports.Allocator
 	executor            *Executor
 	nodeTokenBackoff    time.Duration
 	workerMaxConcurrent int
 	workerActiveJobs    func() int
+	buildQueue          func() *system.BuildsDiag
 	epoch               string
 	fullSyncRequests    chan struct{}
 
 	dedupeMu sync.Mutex
 	dedupe   map[string]time.Time
 
+	// batchMu is held for reading while a batch of tasks runs; Flush takes
+	// it for writing to wait the batch out.
+	batchMu sync.RWMutex
+
 	lastFullSyncAt atomic.Value // time.Time
 }
 
@@ -119,6 +127,25 @@ func (s *Syncer) Executor() *Executor {
 	return s.executor
 }
 
+// SetPortAllocator sets where the watchdog finds the host port of a
+// service recorded without one.
+func (s *Syncer) SetPortAllocator(a *ports.Allocator) {
+	s.ports = a
+}
+
+// SetBuildQueue sets where status updates read the build queue of a build
+// node from.
+func (s *Syncer) SetBuildQueue(fn func() *system.BuildsDiag) {
+	s.buildQueue = fn
+}
+
+func (s *Syncer) builds() *system.BuildsDiag {
+	if s.buildQueue == nil {
+		return nil
+	}
+	return s.buildQueue()
+}
+
 func (s *Syncer) obtainNodeTokenWithBackoff(ctx context.Context, bootstrapToken string) (string, error) {
 	return pkgAuth.ObtainNodeTokenWithBackoff(ctx, s.cfg.BaseURL, bootstrapToken, &s.nodeTokenBackoff, s.logger)
 }
@@ -190,9 +217,17 @@ func (s *Syncer) Start(ctx context.Context) {
 				healthPath = svc.Blueprint.HealthCheck
 			}
 
+			hostPort := svc.HostPort
+			if hostPort == 0 && s.ports != nil {
+				hostPort = s.ports.Lookup(ctx, utils.FormatName(svc.Name))
+			}
+			if hostPort == 0 {
+				hostPort = utils.ComputeHostPort(svc.Name)
+			}
+
 			targets = append(targets, WatchTarget{
 				Name:     svc.Name,
-				HostPort: utils.ComputeHostPort(svc.Name),
+				HostPort: hostPort,
 				Path:     healthPath,
 			})
 		}
@@ -364,16 +399,8 @@ ws_connected:
 		_ = ws.Send(connCtx, conn, ws.Message{ID: ulid.Make().String(), TS: time.Now(), Kind: "hello", Hello: h})
 	}
 
-	pending, err := s.resultStore.ListUnsent(ctx)
-	if err == nil && len(pending) > 0 {
-		ids := make([]string, 0, len(pending))
-		for _, r := range pending {
-			ids = append(ids, r.ID)
-		}
-		logger.Debug("syncer: sending pending acks", "count", len(ids))
-		if err := ws.Send(ctx, conn, ws.Message{ID: ulid.Make().String(), TS: time.Now(), Kind: "ack", IDs: ids}); err == nil {
-			s.resultStore.MarkSynced(ctx, ids)
-		}
+	if err := s.sendPendingAcks(ctx, conn); err != nil {
+		logger.Debug("syncer: failed to send pending acks", "error", err)
 	}
 
 	logger.Debug("syncer: sending initial pull")
@@ -402,6 +429,7 @@ ws_connected:
 		s.watchDog,
 		s.workerMaxConcurrent,
 		activeJobs,
+		s.builds(),
 		logger,
 	)
 	if err != nil {
@@ -466,6 +494,7 @@ ws_connected:
 					s.watchDog,
 					s.workerMaxConcurrent,
 					activeJobs,
+					s.builds(),
 					s.logger,
 				)
 				if err != nil {
@@ -569,6 +598,7 @@ ws_connected:
 				s.watchDog,
 				s.workerMaxConcurrent,
 				activeJobs,
+				s.builds(),
 				s.logger,
 			)
 			if err != nil {
@@ -593,11 +623,13 @@ ws_connected:
 }
 
 func (s *Syncer) handleTasks(ctx context.Context, conn *websocket.Conn, items []ws.Task, logger *shared.Logger) {
-	currentModeMu.RLock()
-	mode := currentMode
-	currentModeMu.RUnlock()
-	if mode == system.ModeUpdating {
-		logger.Info("skipping tasks while daemon is in updating mode")
+	s.batchMu.RLock()
+	defer s.batchMu.RUnlock()
+
+	// Maintenance mode still takes tasks: deployments wait in the worker's
+	// queue, and the task that ends maintenance must get through.
+	if inMode(system.ModeUpdating, system.ModeDraining) {
+		logger.Info("skipping tasks while daemon is updating or shutting down")
 		return
 	}
 
@@ -656,6 +688,7 @@ func (s *Syncer) handleTasks(ctx context.Context, conn *websocket.Conn, items []
 				s.watchDog,
 				s.workerMaxConcurrent,
 				activeJobs,
+				s.builds(),
 				s.logger,
 			)
 			if err != nil {
@@ -704,6 +737,53 @@ func (s *Syncer) handleTasks(ctx context.Context, conn *websocket.Conn, items []
 	}
 }
 
+// sendPendingAcks acknowledges every persisted result the base has not yet
+// been told about and marks them synced.
+func (s *Syncer) sendPendingAcks(ctx context.Context, conn *websocket.Conn) error {
+	pending, err := s.resultStore.ListUnsent(ctx)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+	ids := make([]string, 0, len(pending))
+	for _, r := range pending {
+		ids = append(ids, r.ID)
+	}
+	s.logger.Debug("syncer: sending pending acks", "count", len(ids))
+	if err := ws.Send(ctx, conn, ws.Message{ID: ulid.Make().String(), TS: time.Now(), Kind: "ack", IDs: ids}); err != nil {
+		return err
+	}
+	return s.resultStore.MarkSynced(ctx, ids)
+}
+
+// Flush waits for the batch of tasks in progress to finish and sends the
+// base any results it has not acknowledged. The daemon must already be out
+// of ready mode so no new batch starts. Results that cannot be sent stay in
+// the store and go out on the next connection.
+func (s *Syncer) Flush(ctx context.Context) error {
+	locked := make(chan struct{})
+	go func() {
+		s.batchMu.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		defer s.batchMu.Unlock()
+	case <-ctx.Done():
+		// Release the lock once the batch does finish.
+		go func() {
+			<-locked
+			s.batchMu.Unlock()
+		}()
+		return fmt.Errorf("task batch still running: %w", ctx.Err())
+	}
+
+	conn := s.executor.conn()
+	if conn == nil {
+		return nil
+	}
+	return s.sendPendingAcks(ctx, conn)
+}
+
 // sendTaskResponse sends a task_response message back to the server for realtime requests
 func (s *Syncer) sendTaskResponse(ctx context.Context, conn *websocket.Conn, taskID string, result *tasks.Result) error {
 	success := result.Status == "done"
@@ -755,6 +835,7 @@ func (s *Syncer) sendFullSync(ctx context.Context, conn *websocket.Conn) error {
 		s.watchDog,
 		s.workerMaxConcurrent,
 		activeJobs,
+		s.builds(),
 		s.logger,
 	)
 	if err != nil {
@@ -72,6 +72,7 @@ func BuildUpdateV1_1(
 	watchDog *WatchDog,
 	workerMaxConcurrent int,
 	workerActiveJobs int,
+	builds *system.BuildsDiag,
 	l *shared.Logger,
 ) (*system.UpdateV1_1, error) {
 	now := time.Now()
@@ -91,7 +92,7 @@ func BuildUpdateV1_1(
 	update.ClusterResources = ReadClusterResources()
 	update.Proxy = buildProxy(proxyHandler, isFullSync)
 	update.Processes = buildProcesses(ctx, topCollector, isFullSync)
-	update.Diagnostics = buildDiagnostics(ctx, instStore, isFullSync, workerMaxConcurrent, workerActiveJobs)
+	update.Diagnostics = buildDiagnostics(ctx, instStore, isFullSync, workerMaxConcurrent, workerActiveJobs, builds)
 	workloads, err := buildWorkloads(ctx, deployStore, svcStore, watchDog)
 	if err != nil {
 		l.Error("error retrieving workloads", "error", err)
@@ -113,7 +114,7 @@ func buildStatus() system.StatusInfo {
 	uptime := int64(time.Since(startTime).Seconds())
 
 	state := "healthy"
-	if mode == system.ModeUpdating {
+	if mode == system.ModeUpdating || mode == system.ModeDraining {
 		state = "degraded"
 	}
 
@@ -332,11 +333,12 @@ func mapProcessState(state string) string {
 	}
 }
 
-func buildDiagnostics(ctx context.Context, instStore store.InstanceStore, isFull bool, maxConcurrent, activeJobs int) system.DiagnosticsInfo {
+func buildDiagnostics(ctx context.Context, instStore store.InstanceStore, isFull bool, maxConcurrent, activeJobs int, builds *system.BuildsDiag) system.DiagnosticsInfo {
 	diag := system.DiagnosticsInfo{
 		Websocket: buildWebsocketDiag(),
 		Tasks:     buildTasksDiag(),
 		Auth:      buildAuthDiag(ctx, instStore),
+		Builds:    builds,
 	}
 
 	if isFull {
@@ -436,6 +438,8 @@ func buildNode() *system.NodeInfo {
 	}
 }
 
+// buildWorkloads snapshots deployments and services for sync. Environment
+// values that look like credentials are redacted; secrets go out as keys only.
 func buildWorkloads(ctx context.Context, deployStore store.DeploymentStore, svcStore store.ServiceStore, watchDog *WatchDog) (*system.WorkloadsInfo, error) {
 	workloads := &system.WorkloadsInfo{
 		Deployments: []system.DeploymentV1_1{},
@@ -449,6 +453,9 @@ func buildWorkloads(ctx context.Context, deployStore store.DeploymentStore, svcS
 			return nil, fmt.Errorf("failed to list deployments: %w", err)
 		}
 		if converted := system.FromStoreDeployments(deps); converted != nil {
+			for i := range converted {
+				converted[i].EnvVars = shared.RedactEnv(converted[i].EnvVars)
+			}
 			workloads.Deployments = converted
 		}
 	}
@@ -462,6 +469,7 @@ func buildWorkloads(ctx context.Context, deployStore store.DeploymentStore, svcS
 		if converted := system.FromStoreServices(svcs); converted != nil {
 			svcMgr, svcMgrErr := svc_runtime.SvcRuntime()
 			for i := range converted {
+				converted[i].EnvVars = shared.RedactEnv(converted[i].EnvVars)
 				if store.ServiceType(svcs[i].Type) == store.TypeStatic {
 					// Caddy serves files directly — no container to inspect.
 					converted[i].Status = "running"
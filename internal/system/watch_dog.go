@@ -7,9 +7,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/system"
+	"github.com/dployr-io/dployr/pkg/core/utils"
 )
 
 const (
@@ -81,26 +83,10 @@ type WatchTarget struct {
 	Path     string // optional; defaults to "/". Must be an absolute path (e.g. "/healthz"), not a full URL.
 }
 
-// normalisePath returns a canonical absolute path from raw:
-//   - ""           → "/"
-//   - "foo/bar"    → "/foo/bar"   (A: missing leading slash — added)
-//   - "/foo/bar/"  → "/foo/bar"   (C: trailing slash — stripped, except bare "/")
-//
-// Returns an error if raw looks like a full URL (contains "://").
+// normalisePath returns a canonical absolute health check path; see
+// utils.NormaliseHealthPath.
 func normalisePath(raw string) (string, error) {
-	if strings.Contains(raw, "://") {
-		return "", fmt.Errorf("health check path must be a path, not a full URL: %q", raw)
-	}
-	if raw == "" {
-		return "/", nil
-	}
-	if !strings.HasPrefix(raw, "/") {
-		raw = "/" + raw
-	}
-	if len(raw) > 1 {
-		raw = strings.TrimRight(raw, "/")
-	}
-	return raw, nil
+	return utils.NormaliseHealthPath(raw)
 }
 
 func (p *WatchDog) probe(target WatchTarget) {
@@ -132,6 +118,13 @@ func (p *WatchDog) probe(target WatchTarget) {
 		return
 	}
 
+	// Services are expected to be unreachable during maintenance, so
+	// failures then are not held against them.
+	if inMode(system.ModeMaintenance) {
+		p.failures[target.Name] = 0
+		return
+	}
+
 	p.failures[target.Name]++
 	if p.failures[target.Name] >= pollThreshold {
 		p.results[target.Name] = "degraded"
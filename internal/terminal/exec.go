@@ -0,0 +1,126 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/dployr-io/dployr/pkg/core/terminal"
+	"github.com/dployr-io/dployr/pkg/core/utils"
+)
+
+// ErrServiceNotRunning is returned when a service terminal is requested for a
+// service with no running container.
+var ErrServiceNotRunning = errors.New("service has no running container")
+
+// execShell prefers bash but falls back to sh, which every image but the
+// most minimal has.
+var execShell = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// execDocker is the subset of the Docker client used for service terminals.
+type execDocker interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]dockertypes.Container, error)
+	ContainerExecCreate(ctx context.Context, container string, options container.ExecOptions) (dockertypes.IDResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, config container.ExecAttachOptions) (dockertypes.HijackedResponse, error)
+	ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error
+}
+
+// SetDocker enables terminals inside service containers.
+func (h *Handler) SetDocker(d execDocker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.docker = d
+}
+
+// createExecSession starts a shell in the first running container of service
+// through the Docker exec API, with a TTY so the session behaves like the
+// host one.
+func (h *Handler) createExecSession(ctx context.Context, sessionID, service string, cols, rows uint16) (*terminal.Session, error) {
+	h.mu.Lock()
+	docker := h.docker
+	h.mu.Unlock()
+	if docker == nil {
+		return nil, fmt.Errorf("service terminals need Docker, which is not available")
+	}
+
+	// Containers carry the formatted name, not the one the service was given.
+	containers, err := docker.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", utils.ServiceLabel+"="+utils.FormatName(service)),
+			filters.Arg("status", "running"),
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotRunning, service)
+	}
+	ctr := containers[0]
+
+	size := &[2]uint{uint(rows), uint(cols)}
+	created, err := docker.ContainerExecCreate(ctx, ctr.ID, container.ExecOptions{
+		Tty:          true,
+		ConsoleSize:  size,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env: []string{
+			"TERM=xterm-256color",
+			fmt.Sprintf("COLUMNS=%d", cols),
+			fmt.Sprintf("LINES=%d", rows),
+		},
+		Cmd: execShell,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{Tty: true, ConsoleSize: size})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach exec: %w", err)
+	}
+
+	session := &terminal.Session{
+		ID:           sessionID,
+		Service:      service,
+		Shell:        "sh",
+		Exec:         &execStream{ctx: ctx, docker: docker, id: created.ID, resp: resp},
+		Cols:         cols,
+		Rows:         rows,
+		CreatedAt:    time.Now(),
+		LastActivity: time.Now(),
+	}
+	h.sessions.Store(sessionID, session)
+	return session, nil
+}
+
+// execStream is a TTY exec session. With a TTY Docker sends output as a
+// single raw stream, so it is read as is rather than demultiplexed.
+type execStream struct {
+	ctx    context.Context
+	docker execDocker
+	id     string
+	resp   dockertypes.HijackedResponse
+}
+
+func (s *execStream) Read(p []byte) (int, error) { return s.resp.Reader.Read(p) }
+
+func (s *execStream) Write(p []byte) (int, error) { return s.resp.Conn.Write(p) }
+
+func (s *execStream) Resize(cols, rows uint16) error {
+	return s.docker.ContainerExecResize(s.ctx, s.id, container.ResizeOptions{Height: uint(rows), Width: uint(cols)})
+}
+
+func (s *execStream) Close() error {
+	s.resp.Close()
+	return nil
+}
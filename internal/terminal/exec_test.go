@@ -0,0 +1,97 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/dployr-io/dployr/pkg/core/utils"
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+type fakeExecDocker struct {
+	containers []dockertypes.Container
+	filter     string
+	execOpts   container.ExecOptions
+	execOn     string
+	resized    []container.ResizeOptions
+	remote     net.Conn // the container's end of the exec connection
+}
+
+func (f *fakeExecDocker) ContainerList(ctx context.Context, options container.ListOptions) ([]dockertypes.Container, error) {
+	f.filter = options.Filters.Get("label")[0]
+	return f.containers, nil
+}
+
+func (f *fakeExecDocker) ContainerExecCreate(ctx context.Context, ctr string, options container.ExecOptions) (dockertypes.IDResponse, error) {
+	f.execOn, f.execOpts = ctr, options
+	return dockertypes.IDResponse{ID: "exec-1"}, nil
+}
+
+func (f *fakeExecDocker) ContainerExecAttach(ctx context.Context, execID string, config container.ExecAttachOptions) (dockertypes.HijackedResponse, error) {
+	local, remote := net.Pipe()
+	f.remote = remote
+	return dockertypes.NewHijackedResponse(local, ""), nil
+}
+
+func (f *fakeExecDocker) ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error {
+	f.resized = append(f.resized, options)
+	return nil
+}
+
+func TestCreateExecSession(t *testing.T) {
+	docker := &fakeExecDocker{containers: []dockertypes.Container{{ID: "ctr-api-1"}}}
+	h := NewHandler(shared.NewLogger())
+	h.SetDocker(docker)
+
+	session, err := h.createExecSession(context.Background(), "s1", "API", 100, 30)
+	if err != nil {
+		t.Fatalf("createExecSession: %v", err)
+	}
+	defer session.Cleanup()
+
+	if docker.filter != utils.ServiceLabel+"=api" || docker.execOn != "ctr-api-1" {
+		t.Errorf("exec on %s found by %s, want ctr-api-1 by the service label", docker.execOn, docker.filter)
+	}
+	if !docker.execOpts.Tty || !docker.execOpts.AttachStdin || *docker.execOpts.ConsoleSize != [2]uint{30, 100} {
+		t.Errorf("exec options = %+v, want an interactive 30x100 TTY", docker.execOpts)
+	}
+
+	go docker.remote.Write([]byte("$ "))
+	buf := make([]byte, 8)
+	if n, err := session.Read(buf); err != nil || string(buf[:n]) != "$ " {
+		t.Errorf("Read = %q, %v; want the container's output", buf[:n], err)
+	}
+
+	go session.Write([]byte("ls\r"))
+	r := bufio.NewReader(docker.remote)
+	in := make([]byte, 3)
+	if _, err := io.ReadFull(r, in); err != nil || string(in) != "ls\r" {
+		t.Errorf("container received %q, %v; want the typed input", in, err)
+	}
+
+	if err := session.Resize(120, 40); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	if len(docker.resized) != 1 || docker.resized[0].Width != 120 || docker.resized[0].Height != 40 {
+		t.Errorf("exec resized to %+v, want 120x40", docker.resized)
+	}
+}
+
+func TestCreateExecSession_NotRunning(t *testing.T) {
+	h := NewHandler(shared.NewLogger())
+	h.SetDocker(&fakeExecDocker{})
+
+	if _, err := h.createExecSession(context.Background(), "s1", "api", 80, 24); !errors.Is(err, ErrServiceNotRunning) {
+		t.Errorf("error = %v, want ErrServiceNotRunning", err)
+	}
+}
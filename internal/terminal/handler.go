@@ -32,6 +32,8 @@ type Handler struct {
 	sessions     sync.Map
 	sessionCount int32
 	mu           sync.Mutex
+	recorder     *Recorder
+	docker       execDocker
 }
 
 func NewHandler(logger *shared.Logger) *Handler {
@@ -40,11 +42,26 @@ func NewHandler(logger *shared.Logger) *Handler {
 	}
 }
 
-func (h *Handler) HandleRelaySession(ctx context.Context, conn *websocket.Conn, sessionID string, cols, rows uint16) error {
+// SetRecorder records every session from now on. A session whose recording
+// cannot be started is refused rather than run unrecorded.
+func (h *Handler) SetRecorder(r *Recorder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.recorder = r
+}
+
+// HandleRelaySession runs a terminal session relayed through conn until
+// either side closes it. With an empty service the session is a shell on the
+// host; otherwise it runs inside that service's container.
+func (h *Handler) HandleRelaySession(ctx context.Context, conn *websocket.Conn, sessionID, service string, cols, rows uint16) error {
 	logger := h.logger.With("session_id", sessionID)
+	if service != "" {
+		logger = logger.With("service", service)
+	}
 
 	h.mu.Lock()
 	count := h.sessionCount
+	recorder := h.recorder
 	h.mu.Unlock()
 
 	if count >= maxSessions {
@@ -63,13 +80,39 @@ func (h *Handler) HandleRelaySession(ctx context.Context, conn *websocket.Conn,
 
 	logger.Info("spawning terminal", "cols", cols, "rows", rows)
 
-	session, err := h.createSession(ctx, sessionID, cols, rows, logger)
+	var session *terminal.Session
+	var err error
+	if service != "" {
+		session, err = h.createExecSession(ctx, sessionID, service, cols, rows)
+	} else {
+		session, err = h.createSession(ctx, sessionID, cols, rows, logger)
+	}
 	if err != nil {
 		h.sendError(ctx, conn, fmt.Sprintf("failed to create session: %v", err))
 		return err
 	}
 	defer h.removeSession(sessionID)
 
+	var rec *recording
+	if recorder != nil {
+		subject := ""
+		if user, err := shared.UserFromContext(ctx); err == nil {
+			subject = user.ID
+		}
+		rec, err = recorder.Start(sessionID, subject, service, session.Shell, cols, rows)
+		if err != nil {
+			logger.Error("failed to start terminal recording", "error", err)
+			h.sendError(ctx, conn, "session recording unavailable")
+			return err
+		}
+		defer func() {
+			if err := rec.Close(); err != nil {
+				logger.Error("failed to finish terminal recording", "error", err)
+			}
+		}()
+		logger.Info("recording terminal session", "subject", subject)
+	}
+
 	h.mu.Lock()
 	h.sessionCount++
 	h.mu.Unlock()
@@ -88,8 +131,8 @@ func (h *Handler) HandleRelaySession(ctx context.Context, conn *websocket.Conn,
 
 	errChan := make(chan error, 2)
 
-	go h.ptyToWebSocket(ctx, session, conn, logger, errChan)
-	go h.webSocketToPTY(ctx, session, conn, logger, errChan, idleTimer)
+	go h.ptyToWebSocket(ctx, session, conn, rec, logger, errChan)
+	go h.webSocketToPTY(ctx, session, conn, rec, logger, errChan, idleTimer)
 
 	select {
 	case err := <-errChan:
@@ -143,6 +186,7 @@ func (h *Handler) createSession(ctx context.Context, sessionID string, cols, row
 
 	session := &terminal.Session{
 		ID:           sessionID,
+		Shell:        shell,
 		PTY:          ptmx,
 		Process:      cmd.Process,
 		Cols:         cols,
@@ -165,7 +209,7 @@ func (h *Handler) createSession(ctx context.Context, sessionID string, cols, row
 	return session, nil
 }
 
-func (h *Handler) ptyToWebSocket(ctx context.Context, session *terminal.Session, conn *websocket.Conn, logger *shared.Logger, errChan chan error) {
+func (h *Handler) ptyToWebSocket(ctx context.Context, session *terminal.Session, conn *websocket.Conn, rec *recording, logger *shared.Logger, errChan chan error) {
 	buf := make([]byte, ptyBufferSize)
 
 	for {
@@ -174,7 +218,7 @@ func (h *Handler) ptyToWebSocket(ctx context.Context, session *terminal.Session,
 			errChan <- ctx.Err()
 			return
 		default:
-			n, err := session.PTY.Read(buf)
+			n, err := session.Read(buf)
 			if err != nil {
 				if err == io.EOF {
 					logger.Debug("pty read EOF")
@@ -187,6 +231,9 @@ func (h *Handler) ptyToWebSocket(ctx context.Context, session *terminal.Session,
 			}
 
 			if n > 0 {
+				if rec != nil {
+					rec.Output(buf[:n])
+				}
 				msg := terminal.Message{
 					Action: terminal.ActionOutput,
 					Data:   string(buf[:n]),
@@ -202,7 +249,7 @@ func (h *Handler) ptyToWebSocket(ctx context.Context, session *terminal.Session,
 	}
 }
 
-func (h *Handler) webSocketToPTY(ctx context.Context, session *terminal.Session, conn *websocket.Conn, logger *shared.Logger, errChan chan error, idleTimer *time.Timer) {
+func (h *Handler) webSocketToPTY(ctx context.Context, session *terminal.Session, conn *websocket.Conn, rec *recording, logger *shared.Logger, errChan chan error, idleTimer *time.Timer) {
 	for {
 		var msg terminal.Message
 		if err := h.readMessage(ctx, conn, &msg); err != nil {
@@ -221,7 +268,10 @@ func (h *Handler) webSocketToPTY(ctx context.Context, session *terminal.Session,
 
 		switch msg.Action {
 		case terminal.ActionInput:
-			if _, err := session.PTY.Write([]byte(msg.Data)); err != nil {
+			if rec != nil {
+				rec.Input(msg.Data)
+			}
+			if _, err := session.Write([]byte(msg.Data)); err != nil {
 				logger.Error("failed to write to pty", "error", err)
 				h.sendError(ctx, conn, "failed to write input")
 			}
@@ -232,6 +282,9 @@ func (h *Handler) webSocketToPTY(ctx context.Context, session *terminal.Session,
 					logger.Error("failed to resize pty", "error", err, "cols", msg.Cols, "rows", msg.Rows)
 				} else {
 					logger.Debug("terminal resized", "cols", msg.Cols, "rows", msg.Rows)
+					if rec != nil {
+						rec.Resize(msg.Cols, msg.Rows)
+					}
 				}
 			}
 
@@ -270,6 +323,18 @@ func (h *Handler) sendError(ctx context.Context, conn *websocket.Conn, errMsg st
 	h.sendMessage(ctx, conn, msg)
 }
 
+// CloseAll ends every open session and reports how many there were. Each
+// relay sees its session close and returns, finishing its recording.
+func (h *Handler) CloseAll() int {
+	n := 0
+	h.sessions.Range(func(key, _ any) bool {
+		h.removeSession(key.(string))
+		n++
+		return true
+	})
+	return n
+}
+
 func (h *Handler) removeSession(sessionID string) {
 	if val, ok := h.sessions.LoadAndDelete(sessionID); ok {
 		if session, ok := val.(*terminal.Session); ok {
@@ -0,0 +1,334 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dployr-io/dployr/pkg/core/terminal"
+)
+
+// maxRecordingBytes bounds a single recording so it can still be fetched
+// through a task. Output past it is dropped and the recording marked truncated.
+const maxRecordingBytes = 16 << 20
+
+// recordingID restricts session IDs to names that are safe as file names.
+var recordingID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// Recorder stores terminal sessions as asciicast v2 files, each with a JSON
+// metadata file beside it, and prunes them by age and total size. Only
+// output is recorded unless SetRecordInput turns on keystroke capture.
+type Recorder struct {
+	dir       string
+	retention time.Duration
+	maxBytes  int64
+	input     bool
+
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+// NewRecorder records into dir. Finished recordings older than retention
+// are removed, then the oldest until the rest fit in maxBytes. A zero limit
+// disables that check.
+func NewRecorder(dir string, retention time.Duration, maxBytes int64) *Recorder {
+	return &Recorder{
+		dir:       dir,
+		retention: retention,
+		maxBytes:  maxBytes,
+		active:    make(map[string]bool),
+	}
+}
+
+// SetRecordInput records what clients type in sessions started from now on.
+// Keystrokes include passwords typed at prompts that do not echo them, so
+// this is off by default.
+func (r *Recorder) SetRecordInput(on bool) {
+	r.input = on
+}
+
+func (r *Recorder) castPath(id string) string { return filepath.Join(r.dir, id+".cast") }
+func (r *Recorder) metaPath(id string) string { return filepath.Join(r.dir, id+".json") }
+
+// Start opens a recording for a session and writes the asciicast header.
+// An empty service means a host shell.
+func (r *Recorder) Start(sessionID, subject, service, shell string, cols, rows uint16) (*recording, error) {
+	if !recordingID.MatchString(sessionID) {
+		return nil, fmt.Errorf("invalid session id %q", sessionID)
+	}
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(r.castPath(sessionID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	header, _ := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": start.Unix(),
+		"title":     "dployr session " + sessionID,
+		"env":       map[string]string{"TERM": "xterm-256color", "SHELL": shell},
+	})
+	rec := &recording{
+		r:     r,
+		file:  f,
+		start: start,
+		input: r.input,
+		meta: terminal.Recording{
+			ID:        sessionID,
+			Subject:   subject,
+			Service:   service,
+			Cols:      cols,
+			Rows:      rows,
+			StartedAt: start.UTC(),
+		},
+	}
+	if err := rec.write(append(header, '\n')); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := r.writeMeta(rec.meta); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.active[sessionID] = true
+	r.mu.Unlock()
+	return rec, nil
+}
+
+func (r *Recorder) writeMeta(m terminal.Recording) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := r.metaPath(m.ID) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.metaPath(m.ID))
+}
+
+func (r *Recorder) readMeta(id string) (terminal.Recording, error) {
+	var m terminal.Recording
+	b, err := os.ReadFile(r.metaPath(id))
+	if err != nil {
+		return m, err
+	}
+	return m, json.Unmarshal(b, &m)
+}
+
+func (r *Recorder) ListRecordings(ctx context.Context) ([]terminal.Recording, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var recordings []terminal.Recording
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !recordingID.MatchString(id) {
+			continue
+		}
+		m, err := r.readMeta(id)
+		if err != nil {
+			continue
+		}
+		recordings = append(recordings, m)
+	}
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].StartedAt.After(recordings[j].StartedAt)
+	})
+	return recordings, nil
+}
+
+func (r *Recorder) GetRecording(ctx context.Context, id string) (*terminal.RecordingCast, error) {
+	if !recordingID.MatchString(id) {
+		return nil, fmt.Errorf("%w: %s", terminal.ErrRecordingNotFound, id)
+	}
+	m, err := r.readMeta(id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", terminal.ErrRecordingNotFound, id)
+		}
+		return nil, err
+	}
+	cast, err := os.ReadFile(r.castPath(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", terminal.ErrRecordingNotFound, id)
+		}
+		return nil, err
+	}
+	return &terminal.RecordingCast{Recording: m, Cast: string(cast)}, nil
+}
+
+// Prune applies the retention limits. Open recordings are never removed; a
+// recording left without an end time by a crash counts as ended when its
+// cast file was last written.
+func (r *Recorder) Prune() error {
+	recordings, err := r.ListRecordings(context.Background())
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Oldest first, so the size limit removes the oldest recordings.
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].StartedAt.Before(recordings[j].StartedAt)
+	})
+
+	var total int64
+	var kept []terminal.Recording
+	for _, m := range recordings {
+		if r.active[m.ID] {
+			total += m.Bytes
+			continue
+		}
+		ended := m.EndedAt
+		if ended == nil {
+			if fi, err := os.Stat(r.castPath(m.ID)); err == nil {
+				t := fi.ModTime()
+				ended = &t
+			}
+		}
+		if r.retention > 0 && ended != nil && time.Since(*ended) > r.retention {
+			r.remove(m.ID)
+			continue
+		}
+		total += m.Bytes
+		kept = append(kept, m)
+	}
+
+	for _, m := range kept {
+		if r.maxBytes <= 0 || total <= r.maxBytes {
+			break
+		}
+		r.remove(m.ID)
+		total -= m.Bytes
+	}
+	return nil
+}
+
+func (r *Recorder) remove(id string) {
+	os.Remove(r.castPath(id))
+	os.Remove(r.metaPath(id))
+}
+
+// recording is one session being written. Output, Input and Resize may be
+// called from different goroutines.
+type recording struct {
+	r     *Recorder
+	file  *os.File // written unbuffered so a crash loses nothing already shown
+	start time.Time
+	input bool // keystrokes are recorded
+
+	mu      sync.Mutex
+	meta    terminal.Recording
+	pending []byte // trailing bytes of an incomplete UTF-8 sequence in the output
+	closed  bool
+}
+
+// Output records data the shell wrote to the terminal.
+func (rec *recording) Output(data []byte) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	// A PTY read can end part way through a multi-byte character; hold the
+	// partial character back so it is not mangled into a replacement rune.
+	buf := append(rec.pending, data...)
+	cut := len(buf)
+	for i := len(buf) - 1; i >= 0 && i >= len(buf)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if !utf8.FullRune(buf[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	rec.pending = append([]byte(nil), buf[cut:]...)
+	rec.event("o", string(buf[:cut]))
+}
+
+// Input records keystrokes the client sent, if the recorder captures them.
+func (rec *recording) Input(data string) {
+	if !rec.input {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.event("i", data)
+}
+
+// Resize records a change of terminal size.
+func (rec *recording) Resize(cols, rows uint16) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+func (rec *recording) event(kind, data string) {
+	if rec.closed || rec.meta.Truncated || data == "" {
+		return
+	}
+	elapsed := time.Since(rec.start).Seconds()
+	line, _ := json.Marshal([]any{float64(int64(elapsed*1e6)) / 1e6, kind, data})
+	if rec.meta.Bytes+int64(len(line))+1 > maxRecordingBytes {
+		rec.meta.Truncated = true
+		return
+	}
+	rec.write(append(line, '\n'))
+}
+
+func (rec *recording) write(b []byte) error {
+	n, err := rec.file.Write(b)
+	rec.meta.Bytes += int64(n)
+	return err
+}
+
+// Close finishes the recording, stamps its end time and applies retention.
+func (rec *recording) Close() error {
+	rec.mu.Lock()
+	if rec.closed {
+		rec.mu.Unlock()
+		return nil
+	}
+	if len(rec.pending) > 0 {
+		rec.event("o", string(rec.pending))
+	}
+	rec.closed = true
+	end := time.Now().UTC()
+	rec.meta.EndedAt = &end
+	err := errors.Join(rec.file.Close(), rec.r.writeMeta(rec.meta))
+	id := rec.meta.ID
+	rec.mu.Unlock()
+
+	rec.r.mu.Lock()
+	delete(rec.r.active, id)
+	rec.r.mu.Unlock()
+
+	return errors.Join(err, rec.r.Prune())
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/terminal"
+)
+
+func TestRecorder_WritesAsciicast(t *testing.T) {
+	r := NewRecorder(t.TempDir(), 0, 0)
+	rec, err := r.Start("sess-1", "alice", "", "/bin/bash", 80, 24)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	euro := []byte("€") // three bytes, split across two PTY reads
+	rec.Output(append([]byte("price "), euro[:2]...))
+	rec.Output(euro[2:])
+	rec.Input("ls\r")
+	rec.Resize(120, 40)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got, err := r.GetRecording(context.Background(), "sess-1")
+	if err != nil {
+		t.Fatalf("GetRecording: %v", err)
+	}
+	if got.Subject != "alice" || got.Cols != 80 || got.EndedAt == nil || got.Bytes != int64(len(got.Cast)) {
+		t.Errorf("metadata = %+v, want alice 80x24, ended, bytes matching the cast", got.Recording)
+	}
+
+	lines := strings.Split(strings.TrimSpace(got.Cast), "\n")
+	var header map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil || header["version"] != float64(2) || header["width"] != float64(80) {
+		t.Fatalf("header = %s, want asciicast v2 80 wide", lines[0])
+	}
+
+	var kinds, data []string
+	for _, l := range lines[1:] {
+		var ev []any
+		if err := json.Unmarshal([]byte(l), &ev); err != nil {
+			t.Fatalf("event %s: %v", l, err)
+		}
+		kinds = append(kinds, ev[1].(string))
+		data = append(data, ev[2].(string))
+	}
+	if strings.Join(kinds, ",") != "o,o,r" {
+		t.Errorf("event kinds = %v, want o,o,r with input left out", kinds)
+	}
+	if data[0]+data[1] != "price €" {
+		t.Errorf("output = %q, want the split character rejoined", data[0]+data[1])
+	}
+	if data[2] != "120x40" {
+		t.Errorf("resize = %q, want 120x40", data[2])
+	}
+}
+
+func TestRecorder_RecordsInputWhenEnabled(t *testing.T) {
+	r := NewRecorder(t.TempDir(), 0, 0)
+	r.SetRecordInput(true)
+	rec, err := r.Start("sess-1", "alice", "", "/bin/bash", 80, 24)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	rec.Output([]byte("$ "))
+	rec.Input("ls\r")
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got, err := r.GetRecording(context.Background(), "sess-1")
+	if err != nil {
+		t.Fatalf("GetRecording: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(got.Cast), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], `"i","ls\r"`) {
+		t.Errorf("cast = %s, want the keystrokes recorded", got.Cast)
+	}
+}
+
+func TestRecorder_RejectsUnsafeIDs(t *testing.T) {
+	r := NewRecorder(t.TempDir(), 0, 0)
+	if _, err := r.Start("../escape", "alice", "", "sh", 80, 24); err == nil {
+		t.Error("Start accepted a session id with a path")
+	}
+	if _, err := r.GetRecording(context.Background(), "../escape"); !errors.Is(err, terminal.ErrRecordingNotFound) {
+		t.Errorf("GetRecording error = %v, want ErrRecordingNotFound", err)
+	}
+}
+
+func TestRecorder_Prune(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, time.Hour, 0)
+
+	record := func(id string) {
+		rec, err := r.Start(id, "alice", "", "sh", 80, 24)
+		if err != nil {
+			t.Fatalf("Start %s: %v", id, err)
+		}
+		rec.Output([]byte(strings.Repeat("x", 1000)))
+		if err := rec.Close(); err != nil {
+			t.Fatalf("Close %s: %v", id, err)
+		}
+	}
+	record("old")
+	record("mid")
+	record("new")
+
+	// Backdate "old" past the retention window.
+	m, _ := r.readMeta("old")
+	ended := time.Now().Add(-2 * time.Hour)
+	m.EndedAt = &ended
+	r.writeMeta(m)
+
+	open, err := r.Start("open", "bob", "api", "sh", 80, 24)
+	if err != nil {
+		t.Fatalf("Start open: %v", err)
+	}
+	defer open.Close()
+
+	// Leave room for one finished recording besides the open one.
+	r.maxBytes = 2 * m.Bytes
+	if err := r.Prune(); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	list, _ := r.ListRecordings(context.Background())
+	var ids []string
+	for _, rec := range list {
+		ids = append(ids, rec.ID)
+	}
+	if strings.Join(ids, ",") != "open,new" {
+		t.Errorf("kept %v, want open,new", ids)
+	}
+	if _, err := os.Stat(r.castPath("old")); !os.IsNotExist(err) {
+		t.Errorf("expired cast still on disk: %v", err)
+	}
+}
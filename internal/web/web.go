@@ -4,7 +4,6 @@
 package web
 
 import (
-	"log"
 	"net/http"
 	"strconv"
 
@@ -23,6 +22,11 @@ type WebHandler struct {
 	BuildH   BuildHandler
 	StorageH StorageHandler
 	ClusterH ClusterHandler
+	JobsH    JobsHandler
+	RunsH    RunsHandler
+	AuditH   AuditHandler
+	TermH    TerminalHandler
+	HooksH   WebhooksHandler
 	AuthM    *auth.Middleware
 	MetricsH http.Handler
 }
@@ -30,6 +34,9 @@ type WebHandler struct {
 type DeploymentHandler interface {
 	ListDeployments(w http.ResponseWriter, r *http.Request)
 	CreateDeployment(w http.ResponseWriter, r *http.Request)
+	RollbackDeployment(w http.ResponseWriter, r *http.Request)
+	ListReleases(w http.ResponseWriter, r *http.Request)
+	CancelDeployment(w http.ResponseWriter, r *http.Request)
 }
 
 type ServiceHandler interface {
@@ -39,6 +46,8 @@ type ServiceHandler interface {
 	SleepService(w http.ResponseWriter, r *http.Request)
 	WakeService(w http.ResponseWriter, r *http.Request)
 	IceService(w http.ResponseWriter, r *http.Request)
+	ScaleService(w http.ResponseWriter, r *http.Request)
+	UpdateService(w http.ResponseWriter, r *http.Request)
 }
 
 type ProxyHandler interface {
@@ -73,6 +82,30 @@ type ClusterHandler interface {
 	SetupCluster(w http.ResponseWriter, r *http.Request)
 }
 
+type JobsHandler interface {
+	ListRuns(w http.ResponseWriter, r *http.Request)
+	Trigger(w http.ResponseWriter, r *http.Request)
+}
+
+type RunsHandler interface {
+	Run(w http.ResponseWriter, r *http.Request)
+	GetRun(w http.ResponseWriter, r *http.Request)
+	ListRuns(w http.ResponseWriter, r *http.Request)
+}
+
+type TerminalHandler interface {
+	ListRecordings(w http.ResponseWriter, r *http.Request)
+	GetRecording(w http.ResponseWriter, r *http.Request)
+}
+
+type WebhooksHandler interface {
+	HandlePush(w http.ResponseWriter, r *http.Request)
+}
+
+type AuditHandler interface {
+	ListAudit(w http.ResponseWriter, r *http.Request)
+}
+
 type FSHandler interface {
 	HandleList(w http.ResponseWriter, r *http.Request)
 	HandleRead(w http.ResponseWriter, r *http.Request)
@@ -88,9 +121,14 @@ type TopHandler interface {
 type BuildHandler interface {
 	HandleBuild(w http.ResponseWriter, r *http.Request)
 	HandlePublish(w http.ResponseWriter, r *http.Request)
+	HandleDeployKey(w http.ResponseWriter, r *http.Request)
 }
 
-// BuildMux creates and returns the configured HTTP multiplexer.
+// BuildMux creates and returns the configured HTTP multiplexer. Every
+// authenticated route names the token scope it requires; routes that act
+// differently per method check the scope inside their method switch.
+// Privileged routes pass through AuthM.Audit right after authentication, so
+// their writes are recorded whether or not the checks that follow allow them.
 func (w *WebHandler) BuildMux(cfg *shared.Config) *http.ServeMux {
 	mux := http.NewServeMux()
 
@@ -113,15 +151,16 @@ func (w *WebHandler) BuildMux(cfg *shared.Config) *http.ServeMux {
 	depsH := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		switch req.Method {
 		case http.MethodGet:
-			w.DepsH.ListDeployments(rw, req)
+			w.AuthM.RequireScope(auth.ScopeDeploymentsRead)(http.HandlerFunc(w.DepsH.ListDeployments)).ServeHTTP(rw, req)
 		case http.MethodPost:
-			w.DepsH.CreateDeployment(rw, req)
+			w.AuthM.RequireScope(auth.ScopeDeploymentsWrite)(http.HandlerFunc(w.DepsH.CreateDeployment)).ServeHTTP(rw, req)
 		default:
 			e := shared.Errors.Request.MethodNotAllowed
 			shared.WriteError(rw, e.HTTPStatus, string(e.Code), e.Message, nil)
 		}
 	})
-	mux.Handle("/deployments", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(depsH)))))
+	mux.Handle("/deployments", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(depsH))))))
+	mux.Handle("/deployments/cancel", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeDeploymentsWrite)(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(http.HandlerFunc(w.DepsH.CancelDeployment))))))))
 
 	svcListH := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		if req.URL.Path != "/services" {
@@ -137,12 +176,12 @@ func (w *WebHandler) BuildMux(cfg *shared.Config) *http.ServeMux {
 			shared.WriteError(rw, e.HTTPStatus, string(e.Code), e.Message, nil)
 		}
 	})
-	mux.Handle("/services", corsMiddleware(w.AuthM.Auth(w.AuthM.Trace(svcListH))))
+	mux.Handle("/services", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeServicesRead)(w.AuthM.Trace(svcListH)))))
 
 	svcH := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		path := req.URL.Path
 		if len(path) <= len("/services/") {
-			w.SvcH.ListServices(rw, req)
+			w.AuthM.RequireScope(auth.ScopeServicesRead)(http.HandlerFunc(w.SvcH.ListServices)).ServeHTTP(rw, req)
 			return
 		}
 
@@ -159,80 +198,120 @@ func (w *WebHandler) BuildMux(cfg *shared.Config) *http.ServeMux {
 
 		switch req.Method {
 		case http.MethodGet:
-			w.SvcH.GetService(rw, req)
+			w.AuthM.RequireScope(auth.ScopeServicesRead)(http.HandlerFunc(w.SvcH.GetService)).ServeHTTP(rw, req)
 		case http.MethodDelete:
-			w.SvcH.DeleteService(rw, req)
+			w.AuthM.RequireScope(auth.ScopeServicesWrite)(http.HandlerFunc(w.SvcH.DeleteService)).ServeHTTP(rw, req)
+		case http.MethodPatch:
+			w.AuthM.RequireScope(auth.ScopeServicesWrite)(http.HandlerFunc(w.SvcH.UpdateService)).ServeHTTP(rw, req)
 		default:
 			e := shared.Errors.Request.MethodNotAllowed
 			shared.WriteError(rw, e.HTTPStatus, string(e.Code), e.Message, nil)
 		}
 	})
-	mux.Handle("/services/", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(w.AuthM.Trace(svcH)))))
-	mux.Handle("/services/sleep", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.SvcH.SleepService)))))
-	mux.Handle("/services/wake", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.SvcH.WakeService)))))
-	mux.Handle("/services/ice", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.SvcH.IceService)))))
-	mux.Handle("/proxy/status", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.GetStatus)))))
-	mux.Handle("/proxy/restart", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.HandleRestart)))))
-	mux.Handle("/proxy/add", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.HandleAdd)))))
-	mux.Handle("/proxy/remove", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.HandleRemove)))))
-
-	mux.Handle("/system/info", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleDeveloper))(http.HandlerFunc(w.SystemH.GetInfo)))))
-	mux.Handle("/system/status", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.SystemH.SystemStatus)))))
-	mux.Handle("/system/tasks", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.SystemH.Tasks)))))
-	mux.Handle("/system/doctor", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleDeveloper))(http.HandlerFunc(w.SystemH.RunDoctor)))))
-	mux.Handle("/system/install", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.SystemH.Install)))))
-	mux.Handle("/system/restart", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleDeveloper))(http.HandlerFunc(w.SystemH.Restart)))))
-	mux.Handle("/system/reboot", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.SystemH.Reboot)))))
+	mux.Handle("/services/", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireRole(string(store.RoleAdmin))(w.AuthM.Trace(svcH))))))
+	mux.Handle("/services/sleep", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeServicesWrite)(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.SvcH.SleepService)))))))
+	mux.Handle("/services/wake", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeServicesWrite)(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.SvcH.WakeService)))))))
+	mux.Handle("/services/ice", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeServicesWrite)(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.SvcH.IceService)))))))
+	mux.Handle("/services/scale", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeServicesWrite)(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(http.HandlerFunc(w.SvcH.ScaleService))))))))
+	mux.Handle("/services/rollback", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeDeploymentsWrite)(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(http.HandlerFunc(w.DepsH.RollbackDeployment))))))))
+	mux.Handle("/services/releases", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeDeploymentsRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.DepsH.ListReleases))))))
+	mux.Handle("/proxy/status", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeProxyRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.GetStatus))))))
+	mux.Handle("/proxy/restart", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeProxyWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.HandleRestart)))))))
+	mux.Handle("/proxy/add", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeProxyWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.HandleAdd)))))))
+	mux.Handle("/proxy/remove", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeProxyWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ProxyH.HandleRemove)))))))
+
+	mux.Handle("/system/info", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleDeveloper))(http.HandlerFunc(w.SystemH.GetInfo))))))
+	mux.Handle("/system/status", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.SystemH.SystemStatus))))))
+	mux.Handle("/system/tasks", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.SystemH.Tasks))))))
+	mux.Handle("/system/doctor", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleDeveloper))(http.HandlerFunc(w.SystemH.RunDoctor))))))
+	mux.Handle("/system/install", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.SystemH.Install)))))))
+	mux.Handle("/system/restart", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(store.RoleDeveloper))(http.HandlerFunc(w.SystemH.Restart)))))))
+	mux.Handle("/system/reboot", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.SystemH.Reboot)))))))
 	mux.Handle("/system/register", corsMiddleware(http.HandlerFunc(w.SystemH.RegisterInstance)))
 	mux.Handle("/system/domain", corsMiddleware(http.HandlerFunc(w.SystemH.RequestDomain)))
 	mux.Handle("/system/token/rotate", corsMiddleware(http.HandlerFunc(w.SystemH.UpdateBootstrapToken)))
 	mux.Handle("/system/registered", corsMiddleware(http.HandlerFunc(w.SystemH.Registered)))
 
-	mux.Handle("/system/fs", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleList)))))
-	mux.Handle("/system/fs/read", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleRead)))))
-	mux.Handle("/system/fs/write", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleWrite)))))
-	mux.Handle("/system/fs/create", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleCreate)))))
-	mux.Handle("/system/fs/delete", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleDelete)))))
+	mux.Handle("/system/fs", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeFSRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleList))))))
+	mux.Handle("/system/fs/read", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeFSRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleRead))))))
+	mux.Handle("/system/fs/write", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeFSWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleWrite)))))))
+	mux.Handle("/system/fs/create", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeFSWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleCreate)))))))
+	mux.Handle("/system/fs/delete", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeFSWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.FSH.HandleDelete)))))))
 
-	mux.Handle("/system/top", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.TopH.HandleTop)))))
-	mux.Handle("/system/mode", corsMiddleware(w.AuthM.Auth(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+	mux.Handle("/system/top", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.TopH.HandleTop))))))
+	mux.Handle("/system/mode", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		switch req.Method {
 		case http.MethodGet:
-			w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.SystemH.GetMode)).ServeHTTP(rw, req)
+			w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.SystemH.GetMode))).ServeHTTP(rw, req)
 		case http.MethodPost:
-			w.AuthM.RequireRole(string(auth.RoleNode))(http.HandlerFunc(w.SystemH.SetMode)).ServeHTTP(rw, req)
+			w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(auth.RoleNode))(http.HandlerFunc(w.SystemH.SetMode))).ServeHTTP(rw, req)
 		default:
 			e := shared.Errors.Request.MethodNotAllowed
 			shared.WriteError(rw, e.HTTPStatus, string(e.Code), e.Message, nil)
 		}
-	}))))
+	})))))
 
-	mux.Handle("/system/docker-prune", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.SystemH.DockerPrune)))))
+	mux.Handle("/system/docker-prune", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.SystemH.DockerPrune)))))))
 
 	if w.ClusterH != nil {
-		mux.Handle("/clusters/setup", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ClusterH.SetupCluster)))))
+		mux.Handle("/clusters/setup", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.ClusterH.SetupCluster)))))))
 	}
 
 	if w.StorageH != nil {
-		mux.Handle("/storage/mount", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.StorageH.HandleMount)))))
+		mux.Handle("/storage/mount", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeSystemWrite)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.StorageH.HandleMount)))))))
+	}
+
+	if w.JobsH != nil {
+		mux.Handle("/jobs/runs", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeServicesRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.JobsH.ListRuns))))))
+		mux.Handle("/jobs/trigger", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeServicesWrite)(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(http.HandlerFunc(w.JobsH.Trigger))))))))
+	}
+
+	if w.RunsH != nil {
+		mux.Handle("/services/run", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodGet:
+				w.AuthM.RequireScope(auth.ScopeServicesRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.RunsH.GetRun))).ServeHTTP(rw, req)
+			case http.MethodPost:
+				w.AuthM.RequireScope(auth.ScopeServicesWrite)(w.AuthM.RequireAnyRole(string(store.RoleDeveloper), string(auth.RoleNode))(w.AuthM.Trace(http.HandlerFunc(w.RunsH.Run)))).ServeHTTP(rw, req)
+			default:
+				e := shared.Errors.Request.MethodNotAllowed
+				shared.WriteError(rw, e.HTTPStatus, string(e.Code), e.Message, nil)
+			}
+		})))))
+		mux.Handle("/services/runs", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeServicesRead)(w.AuthM.RequireRole(string(store.RoleViewer))(http.HandlerFunc(w.RunsH.ListRuns))))))
 	}
 
 	if w.BuildH != nil {
-		mux.Handle("/builds", corsMiddleware(w.AuthM.Auth(http.HandlerFunc(w.BuildH.HandleBuild))))
-		mux.Handle("/builds/publish", corsMiddleware(w.AuthM.Auth(http.HandlerFunc(w.BuildH.HandlePublish))))
+		mux.Handle("/builds", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeBuildsWrite)(http.HandlerFunc(w.BuildH.HandleBuild))))))
+		mux.Handle("/builds/publish", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeBuildsWrite)(http.HandlerFunc(w.BuildH.HandlePublish))))))
+		mux.Handle("/builds/deploy-key", corsMiddleware(w.AuthM.Auth(w.AuthM.Audit(w.AuthM.RequireScope(auth.ScopeBuildsWrite)(w.AuthM.RequireAnyRole(string(store.RoleAdmin), string(auth.RoleNode))(http.HandlerFunc(w.BuildH.HandleDeployKey)))))))
+	}
+
+	if w.AuditH != nil {
+		mux.Handle("/audit", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.AuditH.ListAudit))))))
+	}
+
+	if w.TermH != nil {
+		mux.Handle("/terminal/recordings", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeTerminalRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.TermH.ListRecordings))))))
+		mux.Handle("/terminal/recording", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeTerminalRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(http.HandlerFunc(w.TermH.GetRecording))))))
+	}
+
+	// Git hosts cannot log in, so webhooks skip Auth; the handler checks
+	// each delivery's signature instead.
+	if w.HooksH != nil {
+		mux.Handle("/webhooks/git", http.HandlerFunc(w.HooksH.HandlePush))
 	}
 
 	if w.MetricsH != nil {
-		mux.Handle("/metrics", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireRole(string(store.RoleAdmin))(w.MetricsH))))
+		mux.Handle("/metrics", corsMiddleware(w.AuthM.Auth(w.AuthM.RequireScope(auth.ScopeSystemRead)(w.AuthM.RequireRole(string(store.RoleAdmin))(w.MetricsH)))))
 	}
 
 	return mux
 }
 
-// NewServer starts the HTTP server.
-func (w *WebHandler) NewServer(cfg *shared.Config) error {
-	mux := w.BuildMux(cfg)
+// NewServer returns the daemon's HTTP server, not yet listening. The caller
+// runs ListenAndServe and stops it with Shutdown.
+func (w *WebHandler) NewServer(cfg *shared.Config) *http.Server {
 	addr := ":" + strconv.Itoa(cfg.Port)
-	log.Printf("Listening on port %s", addr)
-	return http.ListenAndServe(addr, mux)
+	return &http.Server{Addr: addr, Handler: w.BuildMux(cfg)}
 }
@@ -0,0 +1,6 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhooks turns verified git push webhooks into builds of the
+// pushed commit for the services that track the branch and opted in.
+package webhooks
@@ -0,0 +1,156 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/dployr-io/dployr/pkg/core/webhooks"
+)
+
+// detect names the provider and event of a delivery from its headers.
+// Gitea sends GitHub's headers too, so it is checked first.
+func detect(h http.Header) (webhooks.Provider, string) {
+	switch {
+	case h.Get("X-Gitea-Event") != "":
+		return webhooks.ProviderGitea, h.Get("X-Gitea-Event")
+	case h.Get("X-Gitlab-Event") != "":
+		return webhooks.ProviderGitLab, h.Get("X-Gitlab-Event")
+	case h.Get("X-GitHub-Event") != "":
+		return webhooks.ProviderGitHub, h.Get("X-GitHub-Event")
+	}
+	return "", ""
+}
+
+// verify checks a delivery against secret. GitHub and Gitea sign the body
+// with HMAC-SHA256; GitLab sends the secret itself as a token.
+func verify(p webhooks.Provider, h http.Header, body []byte, secret string) error {
+	switch p {
+	case webhooks.ProviderGitHub:
+		sig, ok := strings.CutPrefix(h.Get("X-Hub-Signature-256"), "sha256=")
+		if !ok {
+			return webhooks.ErrInvalidSignature
+		}
+		return checkHMAC(body, secret, sig)
+	case webhooks.ProviderGitea:
+		return checkHMAC(body, secret, h.Get("X-Gitea-Signature"))
+	case webhooks.ProviderGitLab:
+		if subtle.ConstantTimeCompare([]byte(h.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return webhooks.ErrInvalidSignature
+		}
+		return nil
+	}
+	return webhooks.ErrUnknownProvider
+}
+
+func checkHMAC(body []byte, secret, sig string) error {
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return webhooks.ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return webhooks.ErrInvalidSignature
+	}
+	return nil
+}
+
+func isPush(p webhooks.Provider, event string) bool {
+	if p == webhooks.ProviderGitLab {
+		return event == "Push Hook"
+	}
+	return event == "push"
+}
+
+// githubPush covers GitHub's push payload, which Gitea mirrors.
+type githubPush struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+type gitlabPush struct {
+	Ref         string `json:"ref"`
+	After       string `json:"after"`
+	CheckoutSHA string `json:"checkout_sha"`
+	Project     struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+// parsePush reads a push event. Branch is empty for tag pushes.
+func parsePush(p webhooks.Provider, body []byte) (*webhooks.Push, error) {
+	var ref, after string
+	var push webhooks.Push
+	switch p {
+	case webhooks.ProviderGitLab:
+		var e gitlabPush
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, err
+		}
+		ref, after = e.Ref, e.After
+		if e.CheckoutSHA != "" {
+			after = e.CheckoutSHA
+		}
+		push.Repo = []string{e.Project.GitHTTPURL, e.Project.GitSSHURL, e.Project.WebURL}
+	default:
+		var e githubPush
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, err
+		}
+		ref, after, push.Deleted = e.Ref, e.After, e.Deleted
+		push.Repo = []string{e.Repository.CloneURL, e.Repository.SSHURL, e.Repository.HTMLURL}
+	}
+
+	push.Branch, _ = strings.CutPrefix(ref, "refs/heads/")
+	if push.Branch == ref {
+		push.Branch = ""
+	}
+	push.Commit = after
+	// A deleted branch is reported as a push to the all-zero commit.
+	if strings.Trim(after, "0") == "" {
+		push.Deleted = true
+	}
+	return &push, nil
+}
+
+// repoKey reduces a repository URL to host/path, so the HTTPS, SSH and web
+// URLs of one repository compare equal. Hosts compare case-insensitively
+// and so do paths, as GitHub and GitLab treat them.
+func repoKey(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	if u == "" {
+		return ""
+	}
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	}
+	host, path, _ := strings.Cut(u, "/")
+	if _, h, ok := strings.Cut(host, "@"); ok {
+		host = h
+	}
+	if h, after, ok := strings.Cut(host, ":"); ok {
+		host = h
+		// scp-like git@host:owner/repo rather than a port.
+		if strings.Trim(after, "0123456789") != "" {
+			path = after + "/" + path
+		}
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return host + "/" + path
+}
@@ -0,0 +1,198 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/core/webhooks"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// servicePageSize is how many services are read at a time when matching a
+// push against them.
+const servicePageSize = 100
+
+// DeployFunc queues a build of commit for the named service.
+type DeployFunc func(ctx context.Context, name, commit string) error
+
+// Receiver verifies push webhooks and queues builds for the services they
+// concern. Pushes are debounced per service: a build is queued once no
+// further push has arrived for WEBHOOK_DEBOUNCE, and builds the last commit.
+type Receiver struct {
+	cfg      *shared.Config
+	logger   *shared.Logger
+	services store.ServiceStore
+	deps     store.DeploymentStore
+	deploy   DeployFunc
+
+	mu      sync.Mutex
+	pending map[string]*pendingPush // service name → push waiting out the debounce
+}
+
+type pendingPush struct {
+	commit string
+	timer  *time.Timer
+}
+
+func NewReceiver(cfg *shared.Config, logger *shared.Logger, services store.ServiceStore, deps store.DeploymentStore, deploy DeployFunc) *Receiver {
+	return &Receiver{
+		cfg:      cfg,
+		logger:   logger,
+		services: services,
+		deps:     deps,
+		deploy:   deploy,
+		pending:  make(map[string]*pendingPush),
+	}
+}
+
+func (r *Receiver) Receive(ctx context.Context, header http.Header, body []byte) (*webhooks.Delivery, error) {
+	if r.cfg.Role != store.NodeRoleBuild || r.cfg.WebhookSecret == "" {
+		return nil, webhooks.ErrDisabled
+	}
+	provider, event := detect(header)
+	if provider == "" {
+		return nil, webhooks.ErrUnknownProvider
+	}
+	if err := verify(provider, header, body, r.cfg.WebhookSecret); err != nil {
+		return nil, err
+	}
+
+	d := &webhooks.Delivery{Provider: provider, Event: event, Services: []string{}}
+	if !isPush(provider, event) {
+		d.Ignored = "not a push event"
+		return d, nil
+	}
+	push, err := parsePush(provider, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", webhooks.ErrInvalidPayload, err)
+	}
+	d.Branch, d.Commit = push.Branch, push.Commit
+	switch {
+	case push.Branch == "":
+		d.Ignored = "not a branch push"
+		return d, nil
+	case push.Deleted:
+		d.Ignored = "branch deleted"
+		return d, nil
+	}
+
+	names, err := r.match(ctx, push)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		d.Ignored = "no auto-deploy service tracks this branch"
+		return d, nil
+	}
+	for _, name := range names {
+		r.schedule(name, push.Commit)
+	}
+	d.Services = names
+	r.logger.Info("push received", "provider", provider, "branch", push.Branch, "commit", push.Commit, "services", names)
+	return d, nil
+}
+
+// match returns the services built from push's repository and branch that
+// opted into auto-deploys.
+func (r *Receiver) match(ctx context.Context, push *webhooks.Push) ([]string, error) {
+	var keys []string
+	for _, u := range push.Repo {
+		if k := repoKey(u); k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	var names []string
+	for offset := 0; ; offset += servicePageSize {
+		services, err := r.services.ListServices(ctx, servicePageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for _, svc := range services {
+			if svc.Source != store.SourceRemote || svc.Branch != push.Branch || !slices.Contains(keys, repoKey(svc.Remote)) {
+				continue
+			}
+			dep, err := r.deps.GetDeployment(ctx, svc.DeploymentId)
+			if err != nil || dep == nil {
+				r.logger.Warn("failed to load deployment for pushed service", "service", svc.Name, "error", err)
+				continue
+			}
+			if dep.Blueprint.AutoDeploy {
+				names = append(names, svc.Name)
+			}
+		}
+		if len(services) < servicePageSize {
+			return names, nil
+		}
+	}
+}
+
+// schedule queues commit for name once the debounce passes, replacing a
+// commit still waiting for it.
+func (r *Receiver) schedule(name, commit string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.pending[name]; ok {
+		p.commit = commit
+		p.timer.Reset(r.cfg.WebhookDebounce)
+		return
+	}
+	p := &pendingPush{commit: commit}
+	p.timer = time.AfterFunc(r.cfg.WebhookDebounce, func() { r.fire(name, p) })
+	r.pending[name] = p
+}
+
+func (r *Receiver) fire(name string, p *pendingPush) {
+	r.mu.Lock()
+	// A timer reset while it was firing fires again; the first run took
+	// the push already.
+	if r.pending[name] != p {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.pending, name)
+	commit := p.commit
+	r.mu.Unlock()
+
+	r.build(name, commit)
+}
+
+// Flush queues every debounced push now. On shutdown the worker keeps what
+// was queued for the next start, so no push is lost.
+func (r *Receiver) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[string]*pendingPush)
+	r.mu.Unlock()
+
+	for name, p := range pending {
+		p.timer.Stop()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.build(name, p.commit)
+	}
+	return nil
+}
+
+func (r *Receiver) build(name, commit string) {
+	ctx := context.Background()
+	// Providers redeliver webhooks; the commit already live or on its way
+	// needs no second build.
+	if live, err := r.deps.GetDeploymentByName(ctx, name); err == nil && live.Blueprint.Remote.CommitHash == commit &&
+		live.Status != store.StatusFailed && live.Status != store.StatusCancelled {
+		r.logger.Info("pushed commit already deployed, skipping", "service", name, "commit", commit)
+		return
+	}
+	if err := r.deploy(ctx, name, commit); err != nil {
+		r.logger.Error("failed to queue build for push", "service", name, "commit", commit, "error", err)
+	}
+}
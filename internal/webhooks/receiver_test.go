@@ -0,0 +1,431 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/internal/deploy"
+	"github.com/dployr-io/dployr/internal/worker"
+	"github.com/dployr-io/dployr/pkg/core/webhooks"
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// gitServer is a stand-in git host: one repository served over smart HTTP
+// by git-http-backend.
+type gitServer struct {
+	t    *testing.T
+	work string // checkout commits are made in
+	URL  string // clone URL of the repository
+}
+
+func newGitServer(t *testing.T) *gitServer {
+	t.Helper()
+	out, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	backend := filepath.Join(strings.TrimSpace(string(out)), "git-http-backend")
+	if _, err := os.Stat(backend); err != nil {
+		t.Skipf("git-http-backend not available: %v", err)
+	}
+
+	root := t.TempDir()
+	g := &gitServer{t: t, work: filepath.Join(root, "work")}
+	g.git(root, "init", "--bare", "--initial-branch=main", "shop.git")
+	g.git(root, "init", "--initial-branch=main", "work")
+	g.git(g.work, "remote", "add", "origin", filepath.Join(root, "shop.git"))
+
+	srv := httptest.NewServer(&cgi.Handler{
+		Path: backend,
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(srv.Close)
+	g.URL = srv.URL + "/shop.git"
+	return g
+}
+
+func (g *gitServer) git(dir string, args ...string) string {
+	g.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		g.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// push commits version to main and returns the commit hash.
+func (g *gitServer) push(version string) string {
+	g.t.Helper()
+	if err := os.WriteFile(filepath.Join(g.work, "VERSION"), []byte(version), 0644); err != nil {
+		g.t.Fatal(err)
+	}
+	g.git(g.work, "add", "VERSION")
+	g.git(g.work, "commit", "-m", version)
+	g.git(g.work, "push", "origin", "main")
+	return g.git(g.work, "rev-parse", "HEAD")
+}
+
+// pushEvent is a GitHub push event for commit, signed with testSecret.
+func (g *gitServer) pushEvent(commit string) (http.Header, []byte) {
+	body := fmt.Appendf(nil, `{"ref":"refs/heads/main","after":%q,"repository":{"clone_url":%q}}`, commit, g.URL)
+	return deliveryHeader(webhooks.ProviderGitHub, "push", body, testSecret), body
+}
+
+type memServices struct {
+	store.ServiceStore
+	services []*store.Service
+}
+
+func (m *memServices) ListServices(ctx context.Context, limit, offset int) ([]*store.Service, error) {
+	if offset >= len(m.services) {
+		return nil, nil
+	}
+	return m.services[offset:min(offset+limit, len(m.services))], nil
+}
+
+type memDeployments struct {
+	store.DeploymentStore
+	deployments map[string]*store.Deployment
+}
+
+func (m *memDeployments) GetDeployment(ctx context.Context, id string) (*store.Deployment, error) {
+	return m.deployments[id], nil
+}
+
+func (m *memDeployments) GetDeploymentByName(ctx context.Context, name string) (*store.Deployment, error) {
+	for _, d := range m.deployments {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// newTestReceiver tracks g's main branch with service "shop", which opted
+// into auto-deploys, and "docs", which did not. Every queued build clones
+// the pushed commit from g and reports the VERSION it checked out.
+func newTestReceiver(t *testing.T, g *gitServer) (*Receiver, <-chan string) {
+	t.Helper()
+	services := &memServices{services: []*store.Service{
+		{Name: "shop", Source: store.SourceRemote, Remote: g.URL, Branch: "main", DeploymentId: "d1"},
+		{Name: "docs", Source: store.SourceRemote, Remote: g.URL, Branch: "main", DeploymentId: "d2"},
+	}}
+	deps := &memDeployments{deployments: map[string]*store.Deployment{
+		"d1": {ID: "d1", Name: "shop", Status: store.StatusCompleted, Blueprint: store.Blueprint{Name: "shop", Source: store.SourceRemote, AutoDeploy: true}},
+		"d2": {ID: "d2", Name: "docs", Status: store.StatusCompleted, Blueprint: store.Blueprint{Name: "docs", Source: store.SourceRemote}},
+	}}
+
+	built := make(chan string, 4)
+	var mu sync.Mutex
+	build := func(ctx context.Context, name, commit string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		dir := t.TempDir()
+		if err := deploy.CloneRepo(ctx, name, store.RemoteObj{Url: g.URL, Branch: "main", CommitHash: commit}, dir, nil); err != nil {
+			t.Errorf("clone of pushed commit: %v", err)
+			return err
+		}
+		v, _ := os.ReadFile(filepath.Join(dir, "VERSION"))
+		built <- name + "@" + string(v)
+		return nil
+	}
+
+	cfg := &shared.Config{Role: store.NodeRoleBuild, WebhookSecret: testSecret, WebhookDebounce: 100 * time.Millisecond}
+	return NewReceiver(cfg, shared.NewLogger(), services, deps, build), built
+}
+
+func TestReceive_DebouncesPushesIntoOneBuild(t *testing.T) {
+	g := newGitServer(t)
+	r, built := newTestReceiver(t, g)
+
+	for _, v := range []string{"v1", "v2", "v3"} {
+		h, body := g.pushEvent(g.push(v))
+		d, err := r.Receive(context.Background(), h, body)
+		if err != nil {
+			t.Fatalf("Receive(%s) error = %v", v, err)
+		}
+		if len(d.Services) != 1 || d.Services[0] != "shop" {
+			t.Fatalf("delivery = %+v, want a build scheduled for shop only", d)
+		}
+	}
+
+	select {
+	case got := <-built:
+		if got != "shop@v3" {
+			t.Errorf("built %s, want shop@v3", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no build was queued after the debounce")
+	}
+	select {
+	case got := <-built:
+		t.Errorf("unexpected second build %s", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestReceive_FlushQueuesPendingPushes(t *testing.T) {
+	g := newGitServer(t)
+	r, built := newTestReceiver(t, g)
+	r.cfg.WebhookDebounce = time.Hour
+
+	h, body := g.pushEvent(g.push("v1"))
+	if _, err := r.Receive(context.Background(), h, body); err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	select {
+	case got := <-built:
+		if got != "shop@v1" {
+			t.Errorf("built %s, want shop@v1", got)
+		}
+	default:
+		t.Fatal("Flush() did not queue the pending push")
+	}
+}
+
+func TestReceive_Refused(t *testing.T) {
+	r, _ := newTestReceiver(t, &gitServer{URL: "https://git.example.com/shop.git"})
+	body := []byte(`{"ref":"refs/heads/main","after":"abc"}`)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		cfg    func(*shared.Config)
+		want   error
+	}{
+		{"bad signature", deliveryHeader(webhooks.ProviderGitHub, "push", body, "guess"), nil, webhooks.ErrInvalidSignature},
+		{"unknown provider", http.Header{"X-Event": {"push"}}, nil, webhooks.ErrUnknownProvider},
+		{"no secret", deliveryHeader(webhooks.ProviderGitHub, "push", body, ""), func(c *shared.Config) { c.WebhookSecret = "" }, webhooks.ErrDisabled},
+		{"instance node", deliveryHeader(webhooks.ProviderGitHub, "push", body, testSecret), func(c *shared.Config) { c.Role = store.NodeRoleInstance }, webhooks.ErrDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := *r.cfg
+			defer func() { *r.cfg = saved }()
+			if tt.cfg != nil {
+				tt.cfg(r.cfg)
+			}
+			if _, err := r.Receive(context.Background(), tt.header, body); !errors.Is(err, tt.want) {
+				t.Errorf("Receive() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	h := deliveryHeader(webhooks.ProviderGitHub, "ping", []byte(`{}`), testSecret)
+	d, err := r.Receive(context.Background(), h, []byte(`{}`))
+	if err != nil || d.Ignored == "" {
+		t.Errorf("ping = %+v, %v; want it acknowledged and ignored", d, err)
+	}
+}
+
+// runningDeployments is a deployment store for a real worker, keeping one
+// row per service name as the SQLite store does. OpenSecrets holds the first
+// run at gate, and fails every run once it opens so nothing is installed.
+type runningDeployments struct {
+	store.DeploymentStore
+	gate    chan struct{}
+	started chan struct{}
+
+	mu       sync.Mutex
+	rows     map[string]*store.Deployment
+	queued   []string
+	enqueues int
+	commits  []string // commit of each run, in order
+}
+
+func (m *runningDeployments) get(id string) *store.Deployment {
+	for _, d := range m.rows {
+		if d.ID == id {
+			c := *d
+			return &c
+		}
+	}
+	return nil
+}
+
+func (m *runningDeployments) GetDeployment(ctx context.Context, id string) (*store.Deployment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.get(id), nil
+}
+
+func (m *runningDeployments) GetDeploymentByName(ctx context.Context, name string) (*store.Deployment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.rows[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	c := *d
+	return &c, nil
+}
+
+func (m *runningDeployments) UpsertDeployment(ctx context.Context, d *store.Deployment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.rows[d.Blueprint.Name]; ok {
+		d.ID = existing.ID
+	}
+	c := *d
+	m.rows[d.Blueprint.Name] = &c
+	return nil
+}
+
+func (m *runningDeployments) UpdateDeploymentStatus(ctx context.Context, id, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.rows {
+		if d.ID == id {
+			d.Status = store.Status(status)
+		}
+	}
+	return nil
+}
+
+func (m *runningDeployments) EnqueueDeployment(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enqueues++
+	if slices.Contains(m.queued, id) {
+		return false, nil
+	}
+	m.queued = append(m.queued, id)
+	return true, nil
+}
+
+func (m *runningDeployments) DequeueDeployment(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queued = slices.DeleteFunc(m.queued, func(q string) bool { return q == id })
+	return nil
+}
+
+func (m *runningDeployments) ListQueuedDeployments(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *runningDeployments) FailInterruptedDeployments(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *runningDeployments) RecordRelease(ctx context.Context, r *store.Release) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commits = append(m.commits, r.CommitHash)
+	return nil
+}
+
+func (m *runningDeployments) UpdateReleaseStatus(ctx context.Context, id, status string) error {
+	return nil
+}
+
+func (m *runningDeployments) OpenSecrets(map[string]string) (map[string]string, error) {
+	select {
+	case m.started <- struct{}{}:
+		<-m.gate
+	default:
+	}
+	return nil, errors.New("sealed")
+}
+
+func (m *runningDeployments) snapshot() (enqueues int, commits []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.enqueues, slices.Clone(m.commits)
+}
+
+type noInstance struct{ store.InstanceStore }
+
+func (noInstance) GetAccessToken(context.Context) (string, error) {
+	return "", errors.New("not registered")
+}
+
+func TestReceive_PushDuringRunningDeploy(t *testing.T) {
+	g := newGitServer(t)
+	first := g.push("v1")
+	deps := &runningDeployments{
+		gate:    make(chan struct{}),
+		started: make(chan struct{}),
+		rows: map[string]*store.Deployment{"shop": {ID: "d1", Name: "shop", Status: store.StatusCompleted, Blueprint: store.Blueprint{
+			Name: "shop", Source: store.SourceRemote, AutoDeploy: true,
+			Remote: store.RemoteObj{Url: g.URL, Branch: "main"},
+		}}},
+	}
+	services := &memServices{services: []*store.Service{
+		{Name: "shop", Source: store.SourceRemote, Remote: g.URL, Branch: "main", DeploymentId: "d1"},
+	}}
+
+	cfg := &shared.Config{Role: store.NodeRoleBuild, WebhookSecret: testSecret, WebhookDebounce: 10 * time.Millisecond}
+	logger := shared.NewLogger()
+	w := worker.New(1, cfg, logger, deps, services, noInstance{}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Start(ctx)
+
+	d := deploy.Init(cfg, logger, deps, w, nil)
+	r := NewReceiver(cfg, logger, services, deps, func(ctx context.Context, name, commit string) error {
+		_, err := d.DeployCommit(ctx, name, commit)
+		return err
+	})
+
+	h, body := g.pushEvent(first)
+	if _, err := r.Receive(ctx, h, body); err != nil {
+		t.Fatalf("Receive(v1) error = %v", err)
+	}
+	select {
+	case <-deps.started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the first push was never deployed")
+	}
+
+	// The next push lands while the first deployment is still running.
+	second := g.push("v2")
+	h, body = g.pushEvent(second)
+	if _, err := r.Receive(ctx, h, body); err != nil {
+		t.Fatalf("Receive(v2) error = %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if n, _ := deps.snapshot(); n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the second push was never submitted")
+		}
+	}
+	close(deps.gate)
+
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		_, commits := deps.snapshot()
+		if len(commits) == 2 {
+			if commits[0] != first || commits[1] != second {
+				t.Errorf("deployed %v, want %s then %s", commits, first, second)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deployed %v, want the second push deployed after the first", commits)
+		}
+	}
+}
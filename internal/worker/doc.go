@@ -5,4 +5,8 @@
 // and concurrency management. It coordinates deployment tasks, tracks active jobs,
 // and integrates with service orchestration and store layers. All deployment job
 // execution logic and internal worker state are encapsulated in this package.
+//
+// The queue is persisted in SQLite alongside the deployments table. On start the
+// worker fails deployments interrupted mid-run and re-enqueues pending ones, so
+// a restart never leaves a deployment stuck.
 package worker
@@ -16,6 +16,7 @@ import (
 	"sync"
 	"time"
 
+	coredeploy "github.com/dployr-io/dployr/pkg/core/deploy"
 	"github.com/dployr-io/dployr/pkg/core/proxy"
 	"github.com/dployr-io/dployr/pkg/core/service"
 	"github.com/dployr-io/dployr/pkg/core/utils"
@@ -25,9 +26,18 @@ import (
 	dockerclient "github.com/docker/docker/client"
 
 	"github.com/dployr-io/dployr/internal/deploy"
+	"github.com/dployr-io/dployr/internal/ports"
 	"github.com/dployr-io/dployr/internal/svc_runtime"
 )
 
+// queueCapacity bounds how many deployments may wait for a worker slot.
+// Submissions beyond it are rejected rather than blocking the caller.
+const queueCapacity = 100
+
+// abortWait bounds how long Drain waits for deployments to unwind once their
+// context is cancelled.
+const abortWait = 10 * time.Second
+
 type Worker struct {
 	maxConcurrent int
 	logger        *shared.Logger
@@ -39,9 +49,23 @@ type Worker struct {
 	dockerCli     *dockerclient.Client
 	semaphore     *shared.Semaphore
 	activeJobs    map[string]bool
+	rerun         map[string]bool               // running deployments submitted again; guarded by jobsMux
+	cancels       map[string]context.CancelFunc // running deployment → cancel
 	jobsMux       sync.RWMutex
 	queue         chan string
 	onComplete    func(id string)
+	sliceLimits   deploy.SliceLimitsFunc
+	ports         *ports.Allocator // nil falls back to hashed host ports
+
+	draining bool               // guarded by jobsMux
+	abort    context.CancelFunc // cancels running deployments; guarded by jobsMux
+	stop     chan struct{}      // closed to stop the dispatch loop
+	stopOnce sync.Once
+	running  sync.WaitGroup // execute goroutines
+
+	held    bool          // guarded by jobsMux
+	heldIDs []string      // submitted while held, in order; guarded by jobsMux
+	resume  chan struct{} // wakes the dispatch loop when the hold changes
 }
 
 // New creates a new Worker instance
@@ -64,14 +88,34 @@ func New(m int, c *shared.Config, l *shared.Logger, d store.DeploymentStore, s s
 		dockerCli:     dockerCli,
 		semaphore:     shared.NewSemaphore(m),
 		activeJobs:    make(map[string]bool),
-		queue:         make(chan string, 100),
+		rerun:         make(map[string]bool),
+		cancels:       make(map[string]context.CancelFunc),
+		queue:         make(chan string, queueCapacity),
+		stop:          make(chan struct{}),
+		resume:        make(chan struct{}, 1),
 	}
 }
 
 func (w *Worker) Start(ctx context.Context) {
+	ctx, abort := context.WithCancel(ctx)
+	w.jobsMux.Lock()
+	w.abort = abort
+	w.jobsMux.Unlock()
+
+	w.recover(ctx)
+
 	for {
+		// A nil channel never receives, so a held worker leaves the queue be.
+		queue := w.queue
+		if w.isHeld() {
+			queue = nil
+		}
+
 		select {
-		case id := <-w.queue:
+		case id := <-queue:
+			if w.holdBack(id) {
+				continue
+			}
 			if w.isRunning(id) {
 				w.logger.Info("deployment with " + id + " already running, skipping")
 				continue
@@ -81,9 +125,17 @@ func (w *Worker) Start(ctx context.Context) {
 				w.logger.Warn("failed to acquire semaphore slot", "error", err)
 				continue
 			}
-			w.markActive(id)
+			if !w.dispatch(ctx, id) {
+				w.semaphore.Release()
+				w.logger.Info("worker draining, leaving deployment queued", "deployment_id", id)
+				return
+			}
+
+		case <-w.resume:
 
-			go w.execute(ctx, id)
+		case <-w.stop:
+			w.logger.Info("Worker stopped taking deployments")
+			return
 
 		case <-ctx.Done():
 			w.logger.Info("Worker shutting down")
@@ -92,37 +144,308 @@ func (w *Worker) Start(ctx context.Context) {
 	}
 }
 
-func (w *Worker) Submit(id string) {
-	w.queue <- id
+// dispatch starts deployment id unless the worker is draining. Checking and
+// registering under jobsMux means Drain either sees the job or the job sees
+// the drain.
+func (w *Worker) dispatch(ctx context.Context, id string) bool {
+	w.jobsMux.Lock()
+	defer w.jobsMux.Unlock()
+	if w.draining {
+		return false
+	}
+	w.activeJobs[id] = true
+	w.running.Add(1)
+	go func() {
+		defer w.running.Done()
+		w.execute(ctx, id)
+	}()
+	return true
+}
+
+// Drain stops the worker taking deployments off its queue and waits for the
+// running ones to finish. Deployments still running when ctx ends are
+// cancelled and checkpointed back to pending, so they start over after the
+// next startup; queued ones simply stay queued.
+func (w *Worker) Drain(ctx context.Context) error {
+	w.jobsMux.Lock()
+	w.draining = true
+	abort := w.abort
+	w.jobsMux.Unlock()
+	w.stopOnce.Do(func() { close(w.stop) })
+
+	done := make(chan struct{})
+	go func() {
+		w.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	w.logger.Warn("grace period over, checkpointing running deployments", "count", w.ActiveJobs())
+	if abort != nil {
+		abort()
+	}
+	select {
+	case <-done:
+	case <-time.After(abortWait):
+		w.logger.Error("deployments did not stop after cancellation", "count", w.ActiveJobs())
+	}
+	return ctx.Err()
+}
+
+func (w *Worker) isDraining() bool {
+	w.jobsMux.RLock()
+	defer w.jobsMux.RUnlock()
+	return w.draining
+}
+
+// Hold stops the worker starting deployments while on is set, as in
+// maintenance mode. Running deployments finish; new ones wait in the queue
+// and start in order once the hold is lifted.
+func (w *Worker) Hold(on bool) {
+	w.jobsMux.Lock()
+	w.held = on
+	var release []string
+	if !on {
+		release, w.heldIDs = w.heldIDs, nil
+	}
+	w.jobsMux.Unlock()
+
+	select {
+	case w.resume <- struct{}{}:
+	default:
+	}
+	if len(release) == 0 {
+		return
+	}
+	w.logger.Info("releasing held deployments", "count", len(release))
+	go func() {
+		for _, id := range release {
+			select {
+			case w.queue <- id:
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (w *Worker) isHeld() bool {
+	w.jobsMux.RLock()
+	defer w.jobsMux.RUnlock()
+	return w.held
+}
+
+// holdBack sets id aside until the hold is lifted, and reports whether it
+// did. It catches deployments taken off the queue just as the hold began.
+func (w *Worker) holdBack(id string) bool {
+	w.jobsMux.Lock()
+	defer w.jobsMux.Unlock()
+	if !w.held {
+		return false
+	}
+	w.heldIDs = append(w.heldIDs, id)
+	return true
+}
+
+// Submit persists the deployment in the queue and wakes the worker. It never
+// blocks: when the queue is saturated it returns deploy.ErrQueueFull. While
+// draining the deployment is only persisted, and runs after the next startup;
+// while held it runs once the hold is lifted. A deployment submitted while it
+// runs goes again once the run finishes.
+func (w *Worker) Submit(id string) error {
+	ctx := context.Background()
+	added, err := w.depsStore.EnqueueDeployment(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to persist queued deployment %s: %w", id, err)
+	}
+	if !added {
+		// Already queued, by an earlier submit or by recovery, which owns
+		// its place in the queue and reads the new config when it starts.
+		// A run already under way read the old one.
+		w.markRerun(id)
+		return nil
+	}
+	if w.isDraining() || w.holdBack(id) {
+		return nil
+	}
+
+	select {
+	case w.queue <- id:
+		return nil
+	default:
+		// Only the row this call added is dropped.
+		if err := w.depsStore.DequeueDeployment(ctx, id); err != nil {
+			w.logger.Warn("failed to drop rejected deployment from queue", "deployment_id", id, "error", err)
+		}
+		return coredeploy.ErrQueueFull
+	}
+}
+
+// recover restores the queue after a restart. Deployments that were running
+// when the daemon stopped cannot be resumed part way, so they are failed and
+// left for the user to redeploy; pending ones are queued again in order.
+func (w *Worker) recover(ctx context.Context) {
+	interrupted, err := w.depsStore.FailInterruptedDeployments(ctx)
+	if err != nil {
+		w.logger.Error("failed to reconcile interrupted deployments", "error", err)
+	}
+	for _, id := range interrupted {
+		w.logger.Warn("deployment interrupted by restart, marked as failed", "deployment_id", id)
+		w.notifyComplete(id)
+	}
+
+	pending, err := w.depsStore.ListQueuedDeployments(ctx)
+	if err != nil {
+		w.logger.Error("failed to load queued deployments", "error", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	w.logger.Info("re-enqueueing pending deployments", "count", len(pending))
+	// The backlog may exceed the channel, so feed it while the loop drains.
+	go func() {
+		for _, id := range pending {
+			select {
+			case w.queue <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 }
 
 func (w *Worker) SetCompletionHandler(fn func(id string)) {
 	w.onComplete = fn
 }
 
+// SetSliceLimits sets how cluster slice limits are looked up when a running
+// service is scaled or resized.
+func (w *Worker) SetSliceLimits(fn deploy.SliceLimitsFunc) {
+	w.sliceLimits = fn
+}
+
+// SetPortAllocator sets where service containers get their host ports.
+func (w *Worker) SetPortAllocator(a *ports.Allocator) {
+	w.ports = a
+}
+
 func (w *Worker) execute(ctx context.Context, id string) {
+	jobCtx, skip := w.trackJob(ctx, id)
+	requeue := false
 	defer func() {
-		w.markInactive(id)
+		w.untrackJob(id)
+		if !requeue {
+			if err := w.depsStore.DequeueDeployment(ctx, id); err != nil {
+				w.logger.Warn("failed to remove deployment from queue", "deployment_id", id, "error", err)
+			}
+		}
+		// Dequeued first: a submit that lands after it queues the deployment
+		// itself, one before it is seen here.
+		rerun := w.markInactive(id)
 		w.semaphore.Release()
+		if rerun && !requeue {
+			w.resubmit(ctx, id)
+		}
 	}()
 
+	if skip {
+		w.logger.Info("deployment cancelled before it started", "deployment_id", id)
+		return
+	}
+
 	w.depsStore.UpdateDeploymentStatus(ctx, id, string(store.StatusInProgress))
 	logPath := filepath.Join(utils.GetDataDir(), ".dployr", "logs") + "/"
+	releaseID := w.recordRelease(ctx, id)
 
-	name, err := w.runDeployment(ctx, id)
+	name, err := w.runDeployment(jobCtx, id)
+	// A cancelled job context with a live parent means a user cancelled it;
+	// on shutdown both are done and the deployment is checkpointed instead.
+	if err != nil && ctx.Err() != nil {
+		requeue = true
+		w.checkpoint(id, name, logPath, releaseID)
+		return
+	}
+	if err != nil && jobCtx.Err() == context.Canceled {
+		// Containers are discarded by the deploy package as it unwinds and
+		// the run's workspace by runDeployment.
+		if name != "" {
+			shared.LogWarnF(name, logPath, "deployment cancelled")
+		}
+		w.depsStore.UpdateDeploymentStatus(ctx, id, string(store.StatusCancelled))
+		w.finishRelease(ctx, releaseID, store.StatusCancelled)
+		w.notifyComplete(id)
+		go w.submitDeploymentLogs(ctx, id, name, logPath)
+		return
+	}
 	if err != nil {
 		w.logger.Error("deployment failed", "error", err)
 		w.depsStore.UpdateDeploymentStatus(ctx, id, string(store.StatusFailed))
+		w.finishRelease(ctx, releaseID, store.StatusFailed)
 		w.notifyComplete(id)
 		go w.submitDeploymentLogs(ctx, id, name, logPath)
 		return
 	}
 
 	w.depsStore.UpdateDeploymentStatus(ctx, id, string(store.StatusCompleted))
+	w.finishRelease(ctx, releaseID, store.StatusCompleted)
 	w.notifyComplete(id)
 	go w.submitDeploymentLogs(ctx, id, name, logPath)
 }
 
+// checkpoint returns a deployment interrupted by shutdown to pending and
+// leaves it queued, so startup recovery runs it again from the start. The
+// worker's context is already done, so the store is written without it.
+func (w *Worker) checkpoint(id, name, logPath, releaseID string) {
+	ctx := context.Background()
+	if name != "" {
+		shared.LogWarnF(name, logPath, "deployment interrupted by shutdown, it will restart when dployrd is back")
+	}
+	if err := w.depsStore.UpdateDeploymentStatus(ctx, id, string(store.StatusPending)); err != nil {
+		w.logger.Error("failed to checkpoint deployment", "deployment_id", id, "error", err)
+	}
+	w.finishRelease(ctx, releaseID, store.StatusCancelled)
+	w.logger.Warn("deployment checkpointed for restart", "deployment_id", id)
+}
+
+// recordRelease appends the deployment's blueprint to the service's release
+// history and returns the release ID, or "" if it could not be recorded.
+// History is best-effort: a failure here must not block the deployment.
+func (w *Worker) recordRelease(ctx context.Context, id string) string {
+	d, err := w.depsStore.GetDeployment(ctx, id)
+	if err != nil || d == nil {
+		return ""
+	}
+
+	r := &store.Release{
+		Name:         d.Blueprint.Name,
+		DeploymentID: d.ID,
+		Blueprint:    d.Blueprint,
+		Image:        d.Blueprint.Image,
+		CommitHash:   d.Blueprint.Remote.CommitHash,
+		Status:       store.StatusInProgress,
+	}
+	if err := w.depsStore.RecordRelease(ctx, r); err != nil {
+		w.logger.Warn("failed to record release", "deployment_id", id, "error", err)
+		return ""
+	}
+	return r.ID
+}
+
+func (w *Worker) finishRelease(ctx context.Context, releaseID string, status store.Status) {
+	if releaseID == "" {
+		return
+	}
+	if err := w.depsStore.UpdateReleaseStatus(ctx, releaseID, string(status)); err != nil {
+		w.logger.Warn("failed to update release status", "release_id", releaseID, "error", err)
+	}
+}
+
 func (w *Worker) notifyComplete(id string) {
 	if w.onComplete != nil {
 		w.onComplete(id)
@@ -157,11 +480,21 @@ func (w *Worker) runDeployment(ctx context.Context, id string) (string, error) {
 		shared.LogErrF(svcName, logPath, err)
 		return svcName, err
 	}
+	// A cancelled run leaves a partial clone behind. Only this run's own
+	// workspace goes; the live one is never touched.
+	defer func() {
+		if ctx.Err() == nil {
+			return
+		}
+		if err := deploy.RemoveWorkspace(d.Blueprint.Name, workingDir); err != nil {
+			w.logger.Warn("failed to remove cancelled workspace", "deployment_id", id, "error", err)
+		}
+	}()
 
 	switch d.Blueprint.Source {
 	case store.SourceImage:
 		shared.LogInfoF(svcName, logPath, "pulling image")
-		err = deploy.PullImage(d.Blueprint.Image, w.cfg, w.dockerCli)
+		err = deploy.PullImage(ctx, d.Blueprint.Image, w.cfg, w.dockerCli)
 		if err != nil {
 			err = fmt.Errorf("failed to pull image: %s", err)
 			shared.LogErrF(svcName, logPath, err)
@@ -170,7 +503,7 @@ func (w *Worker) runDeployment(ctx context.Context, id string) (string, error) {
 		dir = workingDir
 	case store.SourceRemote:
 		shared.LogInfoF(svcName, logPath, "cloning repository")
-		err = deploy.CloneRepo(d.Blueprint.Remote, workingDir, w.cfg)
+		err = deploy.CloneRepo(ctx, d.Blueprint.Name, d.Blueprint.Remote, workingDir, w.cfg)
 		if err != nil {
 			err = fmt.Errorf("failed to clone repository: %s", err)
 			shared.LogErrF(svcName, logPath, err)
@@ -186,6 +519,15 @@ func (w *Worker) runDeployment(ctx context.Context, id string) (string, error) {
 		return svcName, err
 	}
 
+	// Secrets stay sealed in the store; they are decrypted only to hand to
+	// the workload, and before the previous version is touched.
+	secrets, err := w.depsStore.OpenSecrets(d.Blueprint.Secrets)
+	if err != nil {
+		err = fmt.Errorf("failed to decrypt secrets: %w", err)
+		shared.LogErrF(svcName, logPath, err)
+		return svcName, err
+	}
+
 	shared.LogInfoF(svcName, logPath, "checking for existing service")
 	s, err := svc_runtime.SvcRuntime()
 	if err != nil {
@@ -195,7 +537,18 @@ func (w *Worker) runDeployment(ctx context.Context, id string) (string, error) {
 	}
 
 	status, err := s.Status(svcName)
-	if err == nil {
+	// A running web service is replaced blue/green: the old container keeps
+	// serving until the new one passes its health check.
+	// With several replicas they are replaced one at a time instead, so the
+	// rest keep serving while each new one comes up.
+	running := err == nil && status == string(service.SvcRunning) && d.Blueprint.Type == store.TypeWeb
+	cutover := running && d.Blueprint.Replicas <= 1
+	rolling := running && d.Blueprint.Replicas > 1
+	if cutover {
+		shared.LogInfoF(svcName, logPath, fmt.Sprintf("previous version of %s is running, performing blue/green cutover", svcName))
+	} else if rolling {
+		shared.LogInfoF(svcName, logPath, fmt.Sprintf("previous version of %s is running, performing rolling update of %d replicas", svcName, d.Blueprint.Replicas))
+	} else if err == nil {
 		// Service exists, remove it first
 		shared.LogWarnF(svcName, logPath, fmt.Sprintf("previous version of %s exists", svcName))
 		shared.LogInfoF(svcName, logPath, "uninstalling previous version...")
@@ -223,22 +576,69 @@ func (w *Worker) runDeployment(ctx context.Context, id string) (string, error) {
 		StaticDir:   d.Blueprint.StaticDir,
 		Image:       d.Blueprint.Image,
 		EnvVars:     d.Blueprint.EnvVars,
-		Secrets:     d.Blueprint.Secrets,
+		Secrets:     secrets,
 		Status:      d.Blueprint.Status,
 		ProjectID:   d.Blueprint.ProjectID,
 		HealthCheck: d.Blueprint.HealthCheck,
 		ClusterID:   d.Blueprint.ClusterID,
+		Replicas:    d.Blueprint.Replicas,
+		Resources:   d.Blueprint.Resources,
+		Schedule:    d.Blueprint.Schedule,
+		Concurrency: d.Blueprint.Concurrency,
+	}
+
+	req := buildServiceRecord(d, svcName)
+	var hostPorts []int
+	if bp.Type != store.TypeStatic && bp.Type != store.TypeJob {
+		if hostPorts, err = w.hostPorts(ctx, d.Blueprint.Name, svcName, bp.Replicas); err != nil {
+			err = fmt.Errorf("failed to reserve host ports: %w", err)
+			shared.LogErrF(svcName, logPath, err)
+			return svcName, err
+		}
+		req.HostPort = utils.ComputeHostPort(svcName)
+		if len(hostPorts) > 0 {
+			req.HostPort = hostPorts[0]
+		}
 	}
 
 	shared.LogInfoF(svcName, logPath, "deploying application")
-	err = deploy.DeployApp(bp, svcName, logPath, w.cfg, w.dockerCli)
+	if cutover {
+		c := deploy.Cutover{
+			PrevHostPort: w.liveHostPort(ctx, d.Blueprint.Name, svcName),
+			Switch: func(hostPort int) error {
+				next := *req
+				next.HostPort = hostPort
+				return w.registerProxyRoute(&next)
+			},
+		}
+		if w.ports != nil {
+			// The replacement takes whichever of the service's two slots
+			// the live container is not on.
+			c.HostPort = req.HostPort
+			if c.PrevHostPort == req.HostPort {
+				if c.HostPort, err = w.ports.Port(ctx, d.Blueprint.Name, svcName+"-next"); err != nil {
+					err = fmt.Errorf("failed to reserve host port: %w", err)
+					shared.LogErrF(svcName, logPath, err)
+					return svcName, err
+				}
+			}
+		}
+		req.HostPort, err = deploy.CutoverApp(ctx, bp, svcName, logPath, w.cfg, w.dockerCli, c)
+	} else {
+		err = deploy.DeployApp(ctx, bp, svcName, logPath, hostPorts, w.cfg, w.dockerCli)
+	}
 	if err != nil {
 		err = fmt.Errorf("deployment failed: %s", err)
 		shared.LogErrF(svcName, logPath, err)
 		return svcName, err
 	}
 
-	req := buildServiceRecord(d, svcName)
+	// Static sites are served straight from the live workspace, so it only
+	// moves once the deployment is in place.
+	if err := deploy.ActivateWorkspace(d.Blueprint.Name, workingDir); err != nil {
+		shared.LogErrF(svcName, logPath, err)
+		return svcName, err
+	}
 
 	w.logger.Info("saving service", "source", req.Source, "type", req.Type)
 
@@ -251,13 +651,61 @@ func (w *Worker) runDeployment(ctx context.Context, id string) (string, error) {
 
 	shared.LogInfoF(svcName, logPath, fmt.Sprintf("successfully deployed %s", d.Blueprint.Name))
 
-	if err := w.registerProxyRoute(req); err != nil {
-		return svcName, fmt.Errorf("failed to register proxy route for %s: %w", req.Name, err)
+	if hostPorts != nil {
+		w.trimPorts(ctx, svcName, bp.Replicas, req.HostPort)
+	}
+
+	if !cutover {
+		if err := w.registerProxyRoute(req); err != nil {
+			return svcName, fmt.Errorf("failed to register proxy route for %s: %w", req.Name, err)
+		}
 	}
 
 	return svcName, nil
 }
 
+// liveHostPort returns the host port the currently deployed container of a
+// service is bound to, falling back to its reserved port, then the hashed
+// default, for services recorded before host ports were tracked.
+func (w *Worker) liveHostPort(ctx context.Context, name, svcName string) int {
+	if prev, err := w.svcStore.GetService(ctx, name); err == nil && prev != nil && prev.HostPort > 0 {
+		return prev.HostPort
+	}
+	return w.replicaPort(ctx, svcName, 0)
+}
+
+// hostPorts reserves the host ports of a service's replicas. Without an
+// allocator it returns nil and the replicas bind their hashed ports.
+func (w *Worker) hostPorts(ctx context.Context, name, svcName string, replicas int) ([]int, error) {
+	if w.ports == nil {
+		return nil, nil
+	}
+	return w.ports.Replicas(ctx, name, svcName, replicas)
+}
+
+// trimPorts releases the host ports a service left unused once it runs
+// replicas containers with the first bound to live. A failure only leaves
+// the ports reserved, so it is logged rather than failing the caller.
+func (w *Worker) trimPorts(ctx context.Context, svcName string, replicas, live int) {
+	if w.ports == nil {
+		return
+	}
+	if err := w.ports.Trim(ctx, svcName, replicas, live); err != nil {
+		w.logger.Warn("failed to release host ports", "service", svcName, "error", err)
+	}
+}
+
+// replicaPort returns the host port replica i of a service is bound to.
+func (w *Worker) replicaPort(ctx context.Context, svcName string, i int) int {
+	rname := utils.ReplicaName(svcName, i)
+	if w.ports != nil {
+		if port := w.ports.Lookup(ctx, rname); port != 0 {
+			return port
+		}
+	}
+	return utils.ComputeHostPort(rname)
+}
+
 // buildServiceRecord constructs the store.Service record from a completed deployment.
 // WorkingDir is always the relative path from the blueprint — never the absolute
 // runtime dir — so the UI and workload sync never expose internal host paths.
@@ -282,6 +730,12 @@ func buildServiceRecord(d *store.Deployment, svcName string) *store.Service {
 		Branch:         d.Blueprint.Remote.Branch,
 		CommitHash:     d.Blueprint.Remote.CommitHash,
 		DeploymentId:   d.ID,
+		Domains:        d.Blueprint.Domains,
+		Replicas:       d.Blueprint.Replicas,
+		Resources:      d.Blueprint.Resources,
+		HealthCheck:    d.Blueprint.HealthCheck,
+		Schedule:       d.Blueprint.Schedule,
+		Concurrency:    d.Blueprint.Concurrency,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -297,11 +751,7 @@ func (w *Worker) registerProxyRoute(svc *store.Service) error {
 
 	var app proxy.App
 	if store.ServiceType(svc.Type) == store.TypeStatic {
-		absWorkDir := filepath.Join(utils.GetDataDir(), ".dployr", "services", serviceName)
-		if svc.WorkingDir != "" {
-			absWorkDir = filepath.Join(absWorkDir, svc.WorkingDir)
-		}
-		root := deploy.ResolveStaticDir(absWorkDir, svc.StaticDir)
+		root := deploy.StaticRoot(svc)
 		app = proxy.App{
 			Domain:   serviceDomain,
 			Root:     root,
@@ -309,7 +759,10 @@ func (w *Worker) registerProxyRoute(svc *store.Service) error {
 		}
 		w.logger.Info("registering static proxy route", "domain", serviceDomain, "root", root)
 	} else {
-		port := svc.Port
+		port := svc.HostPort
+		if port == 0 {
+			port = svc.Port
+		}
 		if port == 0 {
 			port = 3000
 		}
@@ -318,16 +771,256 @@ func (w *Worker) registerProxyRoute(svc *store.Service) error {
 			Upstream: fmt.Sprintf("localhost:%d", port),
 			Template: proxy.TemplateReverseProxy,
 		}
+		if svc.Replicas > 1 {
+			app.Upstreams = []string{app.Upstream}
+			for i := 1; i < svc.Replicas; i++ {
+				app.Upstreams = append(app.Upstreams, fmt.Sprintf("localhost:%d", w.replicaPort(context.Background(), serviceName, i)))
+			}
+			if svc.HealthCheck != "" {
+				if path, err := utils.NormaliseHealthPath(svc.HealthCheck); err == nil {
+					app.HealthURI = path
+				}
+			}
+		}
 		w.logger.Info("registering proxy route", "domain", serviceDomain, "upstream", app.Upstream)
 	}
 
-	if err := w.proxyAPI.Add(map[string]proxy.App{serviceDomain: app}); err != nil {
+	app.Service = svc.Name
+	apps := map[string]proxy.App{serviceDomain: app}
+	for _, d := range svc.Domains {
+		custom := app
+		custom.Domain = d.Host
+		custom.TLS = d.TLS
+		custom.CertFile = d.CertFile
+		custom.KeyFile = d.KeyFile
+		apps[d.Host] = custom
+	}
+
+	// Routes this service registered before but no longer declares.
+	var stale []string
+	for _, existing := range w.proxyAPI.GetApps() {
+		_, wanted := apps[existing.Domain]
+		if wanted && existing.Service != "" && existing.Service != svc.Name {
+			return fmt.Errorf("domain %s is already routed to service %s", existing.Domain, existing.Service)
+		}
+		if !wanted && existing.Service == svc.Name {
+			stale = append(stale, existing.Domain)
+		}
+	}
+
+	if err := w.proxyAPI.Add(apps); err != nil {
 		return fmt.Errorf("failed to add proxy route: %w", err)
 	}
 
+	if len(stale) > 0 {
+		w.logger.Info("removing dropped domains", "service", svc.Name, "domains", stale)
+		if err := w.proxyAPI.Remove(stale); err != nil {
+			return fmt.Errorf("failed to remove dropped domains: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Scale changes how many replicas of a deployed service are running, reusing
+// its current image and blueprint. The proxy is repointed after new replicas
+// come up and before surplus ones are removed, so no request is routed to a
+// container that is not there.
+func (w *Worker) Scale(ctx context.Context, name string, replicas int) error {
+	svc, d, err := w.liveDeployment(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := deploy.ValidateReplicas(svc.Type, replicas); err != nil {
+		return err
+	}
+	if svc.Type != store.TypeWeb && svc.Type != store.TypeWorker {
+		return fmt.Errorf("%w: %s services cannot be scaled", coredeploy.ErrInvalidReplicas, svc.Type)
+	}
+	if err := deploy.ValidateResources(d.Blueprint.Resources, replicas, d.Blueprint.ClusterID, w.sliceLimits); err != nil {
+		return err
+	}
+
+	secrets, err := w.depsStore.OpenSecrets(d.Blueprint.Secrets)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt secrets: %w", err)
+	}
+	bp := d.Blueprint
+	bp.Secrets = secrets
+
+	svcName := utils.FormatName(svc.Name)
+	logPath := filepath.Join(utils.GetDataDir(), ".dployr", "logs") + "/"
+	from := max(svc.Replicas, 1)
+	to := max(replicas, 1)
+
+	next := *svc
+	next.Replicas = replicas
+	if to >= from {
+		hostPorts, err := w.hostPorts(ctx, svc.Name, svcName, to)
+		if err != nil {
+			return fmt.Errorf("failed to reserve host ports: %w", err)
+		}
+		if err := deploy.ScaleApp(ctx, bp, svcName, logPath, from, to, hostPorts, w.cfg, w.dockerCli); err != nil {
+			return err
+		}
+		if err := w.registerProxyRoute(&next); err != nil {
+			return fmt.Errorf("failed to update proxy route: %w", err)
+		}
+	} else {
+		if err := w.registerProxyRoute(&next); err != nil {
+			return fmt.Errorf("failed to update proxy route: %w", err)
+		}
+		if err := deploy.ScaleApp(ctx, bp, svcName, logPath, from, to, nil, w.cfg, w.dockerCli); err != nil {
+			return err
+		}
+		w.trimPorts(ctx, svcName, to, svc.HostPort)
+	}
+
+	// Later redeploys and rollbacks keep the new size. Only the count is
+	// written: the row may have changed while the replicas started.
+	if err := w.depsStore.UpdateDeploymentReplicas(ctx, d.ID, replicas); err != nil {
+		return fmt.Errorf("failed to save replica count: %w", err)
+	}
+	w.logger.Info("scaled service", "service", svc.Name, "from", from, "to", to)
+	return nil
+}
+
+// Resize changes the resource limits of a running service in place. Fields
+// left zero in r keep their current value. Storage is fixed when a container
+// is created, so changing it needs a redeploy.
+func (w *Worker) Resize(ctx context.Context, name string, r store.Resources) error {
+	svc, d, err := w.liveDeployment(ctx, name)
+	if err != nil {
+		return err
+	}
+	if svc.Type == store.TypeStatic {
+		return fmt.Errorf("%w: static services do not run a container", coredeploy.ErrInvalidResources)
+	}
+
+	cur := d.Blueprint.Resources
+	if r.StorageGB != 0 && r.StorageGB != cur.StorageGB {
+		return fmt.Errorf("%w: storage can only change with a redeploy", coredeploy.ErrInvalidResources)
+	}
+	next := cur
+	if r.MemoryMB != 0 {
+		next.MemoryMB = r.MemoryMB
+	}
+	if r.CPU != 0 {
+		next.CPU = r.CPU
+	}
+	if r.Pids != 0 {
+		next.Pids = r.Pids
+	}
+	if err := deploy.ValidateResources(next, d.Blueprint.Replicas, d.Blueprint.ClusterID, w.sliceLimits); err != nil {
+		return err
+	}
+
+	bp := d.Blueprint
+	bp.Resources = next
+	logPath := filepath.Join(utils.GetDataDir(), ".dployr", "logs") + "/"
+	if err := deploy.UpdateResources(ctx, bp, utils.FormatName(svc.Name), logPath, w.cfg, w.dockerCli); err != nil {
+		return err
+	}
+
+	// Later redeploys and rollbacks keep the new limits. Only the limits
+	// are written: the row may have changed while the containers updated.
+	if err := w.depsStore.UpdateDeploymentResources(ctx, d.ID, next); err != nil {
+		return fmt.Errorf("failed to save resource limits: %w", err)
+	}
+	w.logger.Info("resized service", "service", svc.Name, "memory_mb", next.MemoryMB, "cpu_millicores", next.CPU, "pids", next.Pids)
 	return nil
 }
 
+// liveDeployment returns a service and the deployment it runs, refusing
+// while that deployment is still queued or running.
+func (w *Worker) liveDeployment(ctx context.Context, name string) (*store.Service, *store.Deployment, error) {
+	svc, err := w.svcStore.GetService(ctx, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if svc == nil {
+		return nil, nil, fmt.Errorf("service %s not found", name)
+	}
+	d, err := w.depsStore.GetDeployment(ctx, svc.DeploymentId)
+	if err != nil || d == nil {
+		return nil, nil, fmt.Errorf("failed to get deployment for service %s: %v", name, err)
+	}
+	if d.Status == store.StatusPending || d.Status == store.StatusInProgress {
+		return nil, nil, fmt.Errorf("service %s has a deployment in progress", name)
+	}
+	return svc, d, nil
+}
+
+// Cancel stops deployment id. A running deployment has its context cancelled
+// and is cleaned up by execute; a queued one is marked cancelled so execute
+// skips it when it is dequeued. Reports false if there was nothing to stop.
+func (w *Worker) Cancel(id string) bool {
+	if w.cancelRunning(id) {
+		return true
+	}
+
+	ctx := context.Background()
+	d, err := w.depsStore.GetDeployment(ctx, id)
+	if err != nil || d == nil || d.Status != store.StatusPending {
+		return false
+	}
+	if err := w.depsStore.UpdateDeploymentStatus(ctx, id, string(store.StatusCancelled)); err != nil {
+		w.logger.Warn("failed to cancel queued deployment", "deployment_id", id, "error", err)
+		return false
+	}
+	// The deployment may have started between the check and the status
+	// write; trackJob only skips it once the status reads cancelled.
+	if w.cancelRunning(id) {
+		return true
+	}
+	if err := w.depsStore.DequeueDeployment(ctx, id); err != nil {
+		w.logger.Warn("failed to remove cancelled deployment from queue", "deployment_id", id, "error", err)
+	}
+	w.notifyComplete(id)
+	return true
+}
+
+// cancelRunning cancels the context of deployment id and drops a pending
+// rerun if it is running. Reports whether it was.
+func (w *Worker) cancelRunning(id string) bool {
+	w.jobsMux.Lock()
+	defer w.jobsMux.Unlock()
+
+	cancel, ok := w.cancels[id]
+	if !ok {
+		return false
+	}
+	delete(w.rerun, id)
+	cancel()
+	return true
+}
+
+// trackJob registers a cancellable context for deployment id and reports
+// whether it was cancelled while still queued. Both happen under jobsMux so a
+// concurrent Cancel either sees the registration or the job sees its status.
+func (w *Worker) trackJob(ctx context.Context, id string) (context.Context, bool) {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	w.jobsMux.Lock()
+	defer w.jobsMux.Unlock()
+
+	if d, err := w.depsStore.GetDeployment(ctx, id); err == nil && d != nil && d.Status == store.StatusCancelled {
+		cancel()
+		return jobCtx, true
+	}
+	w.cancels[id] = cancel
+	return jobCtx, false
+}
+
+func (w *Worker) untrackJob(id string) {
+	w.jobsMux.Lock()
+	defer w.jobsMux.Unlock()
+	if cancel, ok := w.cancels[id]; ok {
+		cancel()
+		delete(w.cancels, id)
+	}
+}
+
 func (w *Worker) isRunning(id string) bool {
 	w.jobsMux.RLock()
 	defer w.jobsMux.RUnlock()
@@ -340,10 +1033,48 @@ func (w *Worker) markActive(id string) {
 	w.activeJobs[id] = true
 }
 
-func (w *Worker) markInactive(id string) {
+// markInactive unregisters a finished deployment and reports whether it was
+// submitted again while it ran.
+func (w *Worker) markInactive(id string) bool {
 	w.jobsMux.Lock()
 	defer w.jobsMux.Unlock()
 	delete(w.activeJobs, id)
+	rerun := w.rerun[id]
+	delete(w.rerun, id)
+	return rerun
+}
+
+// markRerun flags id to run again if it is running.
+func (w *Worker) markRerun(id string) {
+	w.jobsMux.Lock()
+	defer w.jobsMux.Unlock()
+	if w.activeJobs[id] {
+		w.rerun[id] = true
+	}
+}
+
+// resubmit queues a deployment again after a run it was submitted during.
+// The run took its row off the queue, so it is persisted again first; the
+// worker's context may be ending, so the store is written without it.
+func (w *Worker) resubmit(ctx context.Context, id string) {
+	w.logger.Info("deployment submitted while running, queueing it again", "deployment_id", id)
+	if _, err := w.depsStore.EnqueueDeployment(context.Background(), id); err != nil {
+		w.logger.Error("failed to requeue deployment", "deployment_id", id, "error", err)
+		return
+	}
+	if err := w.depsStore.UpdateDeploymentStatus(context.Background(), id, string(store.StatusPending)); err != nil {
+		w.logger.Warn("failed to mark requeued deployment pending", "deployment_id", id, "error", err)
+	}
+	if w.isDraining() || w.holdBack(id) {
+		return
+	}
+	// The queue may be full; the row keeps its place either way.
+	go func() {
+		select {
+		case w.queue <- id:
+		case <-ctx.Done():
+		}
+	}()
 }
 
 // ActiveJobs returns the current count of active jobs
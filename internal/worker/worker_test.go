@@ -6,12 +6,15 @@ package worker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/dployr-io/dployr/internal/deploy"
+	coredeploy "github.com/dployr-io/dployr/pkg/core/deploy"
 	"github.com/dployr-io/dployr/pkg/core/proxy"
 	"github.com/dployr-io/dployr/pkg/core/utils"
 	"github.com/dployr-io/dployr/pkg/shared"
@@ -20,16 +23,25 @@ import (
 
 // mockProxyAPI implements proxy.HandleProxy for testing.
 type mockProxyAPI struct {
-	mu     sync.Mutex
-	added  []map[string]proxy.App
-	addErr error
+	mu      sync.Mutex
+	apps    []proxy.App
+	added   []map[string]proxy.App
+	removed []string
+	addErr  error
 }
 
-func (m *mockProxyAPI) Setup(apps map[string]proxy.App) error { return nil }
-func (m *mockProxyAPI) Status() proxy.ProxyStatus             { return proxy.ProxyStatus{} }
-func (m *mockProxyAPI) GetApps() []proxy.App                  { return nil }
-func (m *mockProxyAPI) Restart() error                        { return nil }
-func (m *mockProxyAPI) Remove(domains []string) error         { return nil }
+func (m *mockProxyAPI) Setup(apps map[string]proxy.App) error   { return nil }
+func (m *mockProxyAPI) Status() proxy.ProxyStatus               { return proxy.ProxyStatus{} }
+func (m *mockProxyAPI) GetApps() []proxy.App                    { return m.apps }
+func (m *mockProxyAPI) Restart() error                          { return nil }
+func (m *mockProxyAPI) SetMaintenance(*proxy.Maintenance) error { return nil }
+func (m *mockProxyAPI) Maintenance() *proxy.Maintenance         { return nil }
+func (m *mockProxyAPI) Remove(domains []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removed = append(m.removed, domains...)
+	return nil
+}
 func (m *mockProxyAPI) Add(apps map[string]proxy.App) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -49,6 +61,8 @@ type mockDeploymentStore struct {
 	mu          sync.Mutex
 	deployments map[string]*store.Deployment
 	statusCalls []string
+	releases    []*store.Release
+	queued      []string
 }
 
 func (m *mockDeploymentStore) UpsertDeployment(ctx context.Context, d *store.Deployment) error {
@@ -67,6 +81,17 @@ func (m *mockDeploymentStore) GetDeployment(ctx context.Context, id string) (*st
 	return nil, nil
 }
 
+func (m *mockDeploymentStore) GetDeploymentByName(ctx context.Context, name string) (*store.Deployment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.deployments {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
 func (m *mockDeploymentStore) ListDeployments(ctx context.Context, limit, offset int) ([]*store.Deployment, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -77,6 +102,24 @@ func (m *mockDeploymentStore) ListDeployments(ctx context.Context, limit, offset
 	return result, nil
 }
 
+func (m *mockDeploymentStore) UpdateDeploymentReplicas(ctx context.Context, id string, replicas int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deployments[id]; ok {
+		d.Blueprint.Replicas = replicas
+	}
+	return nil
+}
+
+func (m *mockDeploymentStore) UpdateDeploymentResources(ctx context.Context, id string, r store.Resources) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deployments[id]; ok {
+		d.Blueprint.Resources = r
+	}
+	return nil
+}
+
 func (m *mockDeploymentStore) UpdateDeploymentStatus(ctx context.Context, id, status string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -87,6 +130,91 @@ func (m *mockDeploymentStore) UpdateDeploymentStatus(ctx context.Context, id, st
 	return nil
 }
 
+func (m *mockDeploymentStore) RecordRelease(ctx context.Context, r *store.Release) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r.ID = fmt.Sprintf("rel-%d", len(m.releases)+1)
+	r.Version = len(m.releases) + 1
+	m.releases = append(m.releases, r)
+	return nil
+}
+
+func (m *mockDeploymentStore) UpdateReleaseStatus(ctx context.Context, id, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.releases {
+		if r.ID == id {
+			r.Status = store.Status(status)
+		}
+	}
+	return nil
+}
+
+func (m *mockDeploymentStore) ListReleases(ctx context.Context, name string, limit int) ([]*store.Release, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*store.Release
+	for i := len(m.releases) - 1; i >= 0; i-- {
+		if m.releases[i].Name == name {
+			out = append(out, m.releases[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *mockDeploymentStore) EnqueueDeployment(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if slices.Contains(m.queued, id) {
+		return false, nil
+	}
+	m.queued = append(m.queued, id)
+	return true, nil
+}
+
+func (m *mockDeploymentStore) DequeueDeployment(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queued = slices.DeleteFunc(m.queued, func(q string) bool { return q == id })
+	return nil
+}
+
+func (m *mockDeploymentStore) ListQueuedDeployments(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, id := range m.queued {
+		if d, ok := m.deployments[id]; ok && d.Status == store.StatusPending {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockDeploymentStore) OpenSecrets(secrets map[string]string) (map[string]string, error) {
+	return secrets, nil
+}
+
+func (m *mockDeploymentStore) FailInterruptedDeployments(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []string
+	for id, d := range m.deployments {
+		if d.Status == store.StatusInProgress {
+			d.Status = store.StatusFailed
+			ids = append(ids, id)
+		}
+	}
+	m.queued = slices.DeleteFunc(m.queued, func(q string) bool { return slices.Contains(ids, q) })
+	return ids, nil
+}
+
+func (m *mockDeploymentStore) queuedSnapshot() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.queued)
+}
+
 func (m *mockDeploymentStore) statusCallsSnapshot() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -214,6 +342,235 @@ func TestWorker_Submit(t *testing.T) {
 	})
 }
 
+func TestWorker_SubmitFailsFastWhenFull(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: make(map[string]*store.Deployment)}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+
+	for i := range queueCapacity {
+		if err := worker.Submit(fmt.Sprintf("job-%d", i)); err != nil {
+			t.Fatalf("Submit(job-%d) error: %v", i, err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- worker.Submit("overflow") }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, coredeploy.ErrQueueFull) {
+			t.Errorf("Submit() error = %v, want ErrQueueFull", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Submit() blocked on a saturated queue")
+	}
+
+	if slices.Contains(deployStore.queuedSnapshot(), "overflow") {
+		t.Error("rejected deployment must not stay in the persistent queue")
+	}
+
+	// A deployment accepted before the queue filled up keeps its place when
+	// it is submitted again.
+	if err := worker.Submit("job-0"); err != nil {
+		t.Errorf("resubmitting a queued deployment: %v", err)
+	}
+	if !slices.Contains(deployStore.queuedSnapshot(), "job-0") {
+		t.Error("resubmit dropped a row owned by an accepted submit")
+	}
+}
+
+func TestWorker_SubmitIsIdempotent(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: make(map[string]*store.Deployment)}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+
+	for range 3 {
+		if err := worker.Submit("dep-1"); err != nil {
+			t.Fatalf("Submit() error: %v", err)
+		}
+	}
+	if len(worker.queue) != 1 {
+		t.Errorf("queue holds %d entries, want the deployment once", len(worker.queue))
+	}
+}
+
+// TestWorker_SubmitDuringRun redeploys a service while its last deployment,
+// which shares the ID, is still running: it must run again afterwards.
+func TestWorker_SubmitDuringRun(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: map[string]*store.Deployment{
+		"dep-1": {ID: "dep-1", Status: store.StatusPending, Blueprint: store.Blueprint{
+			Name: "my-app", Source: store.SourceImage, Image: "registry.example.com/my-app:1",
+		}},
+	}}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{Role: store.NodeRoleInstance}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := worker.Submit("dep-1"); err != nil {
+		t.Fatalf("Submit() error: %v", err)
+	}
+	<-worker.queue
+	// As the dispatch loop does before starting the run.
+	worker.semaphore.Acquire(ctx)
+	worker.markActive("dep-1")
+
+	// The redeploy's config is already saved; its submit finds the row queued.
+	if err := worker.Submit("dep-1"); err != nil {
+		t.Fatalf("Submit() during the run error: %v", err)
+	}
+	if len(worker.queue) != 0 {
+		t.Fatal("the running deployment was queued twice")
+	}
+
+	worker.execute(ctx, "dep-1")
+
+	select {
+	case id := <-worker.queue:
+		if id != "dep-1" {
+			t.Errorf("queued %q, want dep-1", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the redeploy was dropped when the run finished")
+	}
+	if !slices.Contains(deployStore.queuedSnapshot(), "dep-1") {
+		t.Error("the redeploy was not persisted in the queue")
+	}
+	if got := deployStore.deployments["dep-1"].Status; got != store.StatusPending {
+		t.Errorf("status = %q, want pending for the redeploy", got)
+	}
+	if worker.isRunning("dep-1") {
+		t.Error("the finished run is still registered")
+	}
+}
+
+// TestWorker_RecoverAfterRestart simulates a daemon restart with one deployment
+// waiting and one that was mid-flight when the process died.
+func TestWorker_RecoverAfterRestart(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: map[string]*store.Deployment{
+		"dep-pending":     {ID: "dep-pending", Status: store.StatusPending},
+		"dep-interrupted": {ID: "dep-interrupted", Status: store.StatusInProgress},
+	}}
+	deployStore.queued = []string{"dep-interrupted", "dep-pending"}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+
+	var completed []string
+	worker.SetCompletionHandler(func(id string) { completed = append(completed, id) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker.recover(ctx)
+
+	select {
+	case id := <-worker.queue:
+		if id != "dep-pending" {
+			t.Errorf("re-enqueued %q, want dep-pending", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending deployment was not re-enqueued")
+	}
+
+	if got := deployStore.deployments["dep-interrupted"].Status; got != store.StatusFailed {
+		t.Errorf("interrupted deployment status = %q, want %q", got, store.StatusFailed)
+	}
+	if slices.Contains(deployStore.queuedSnapshot(), "dep-interrupted") {
+		t.Error("interrupted deployment must leave the queue")
+	}
+	if !slices.Contains(completed, "dep-interrupted") {
+		t.Error("completion handler not notified for interrupted deployment")
+	}
+}
+
+func TestWorker_CancelQueued(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: map[string]*store.Deployment{
+		"dep-1": {ID: "dep-1", Status: store.StatusPending},
+	}}
+	deployStore.queued = []string{"dep-1"}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+
+	if !worker.Cancel("dep-1") {
+		t.Fatal("Cancel() = false for a queued deployment")
+	}
+	if got := deployStore.deployments["dep-1"].Status; got != store.StatusCancelled {
+		t.Errorf("status = %q, want %q", got, store.StatusCancelled)
+	}
+	if len(deployStore.queuedSnapshot()) != 0 {
+		t.Errorf("cancelled deployment still queued: %v", deployStore.queuedSnapshot())
+	}
+
+	// The ID is still in the channel; the worker must skip it.
+	worker.semaphore.Acquire(context.Background())
+	worker.execute(context.Background(), "dep-1")
+	if calls := deployStore.statusCallsSnapshot(); slices.Contains(calls, string(store.StatusInProgress)) {
+		t.Errorf("cancelled deployment was started, status calls = %v", calls)
+	}
+}
+
+// The completion handler runs after Cancel has let go of the worker's lock,
+// so it may call back into the worker.
+func TestWorker_CancelQueuedNotifiesUnlocked(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: map[string]*store.Deployment{
+		"dep-1": {ID: "dep-1", Status: store.StatusPending},
+	}}
+	deployStore.queued = []string{"dep-1"}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+	worker.SetCompletionHandler(func(id string) {
+		worker.isRunning(id)
+	})
+
+	done := make(chan bool)
+	go func() { done <- worker.Cancel("dep-1") }()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("Cancel() = false for a queued deployment")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancel() still holds the worker lock while notifying")
+	}
+}
+
+func TestWorker_CancelRunning(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: map[string]*store.Deployment{
+		"dep-1": {ID: "dep-1", Status: store.StatusInProgress},
+	}}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+
+	jobCtx, skip := worker.trackJob(context.Background(), "dep-1")
+	if skip {
+		t.Fatal("running deployment must not be skipped")
+	}
+	defer worker.untrackJob("dep-1")
+
+	if !worker.Cancel("dep-1") {
+		t.Fatal("Cancel() = false for a running deployment")
+	}
+	if jobCtx.Err() != context.Canceled {
+		t.Errorf("job context err = %v, want context.Canceled", jobCtx.Err())
+	}
+	if worker.Cancel("dep-2") {
+		t.Error("Cancel() = true for an unknown deployment")
+	}
+}
+
 func TestWorker_ActiveJobs(t *testing.T) {
 	cfg := &shared.Config{}
 	logger := shared.NewLogger()
@@ -592,6 +949,44 @@ func TestRegisterProxyRoute_WebDefaultPort(t *testing.T) {
 	}
 }
 
+// After a blue/green cutover the route must target the container's host port,
+// not the port the app listens on inside the container.
+func TestRegisterProxyRoute_WebHostPort(t *testing.T) {
+	mock := &mockProxyAPI{}
+	w := newWorkerWithProxy(mock)
+
+	w.registerProxyRoute(&store.Service{Name: "api", Type: store.TypeWeb, Port: 8080, HostPort: 62123})
+
+	calls := mock.snapshot()
+	app := calls[0]["api.dployr.run"]
+	if app.Upstream != "localhost:62123" {
+		t.Errorf("upstream = %q, want localhost:62123", app.Upstream)
+	}
+}
+
+func TestRegisterProxyRoute_Replicas(t *testing.T) {
+	mock := &mockProxyAPI{}
+	w := newWorkerWithProxy(mock)
+
+	w.registerProxyRoute(&store.Service{Name: "api", Type: store.TypeWeb, Port: 8080, HostPort: 62123, Replicas: 3, HealthCheck: "healthz"})
+
+	app := mock.snapshot()[0]["api.dployr.run"]
+	want := []string{
+		"localhost:62123",
+		fmt.Sprintf("localhost:%d", utils.ComputeHostPort("api-r1")),
+		fmt.Sprintf("localhost:%d", utils.ComputeHostPort("api-r2")),
+	}
+	if !slices.Equal(app.Upstreams, want) {
+		t.Errorf("upstreams = %v, want %v", app.Upstreams, want)
+	}
+	if app.Upstream != want[0] {
+		t.Errorf("upstream = %q, want first replica %q", app.Upstream, want[0])
+	}
+	if app.HealthURI != "/healthz" {
+		t.Errorf("health uri = %q, want /healthz", app.HealthURI)
+	}
+}
+
 func TestRegisterProxyRoute_Static(t *testing.T) {
 	mock := &mockProxyAPI{}
 	w := newWorkerWithProxy(mock)
@@ -641,6 +1036,75 @@ func TestRegisterProxyRoute_StaticNoSubDir(t *testing.T) {
 	}
 }
 
+func TestRegisterProxyRoute_CustomDomains(t *testing.T) {
+	mock := &mockProxyAPI{}
+	w := newWorkerWithProxy(mock)
+
+	err := w.registerProxyRoute(&store.Service{
+		Name:     "shop",
+		Type:     store.TypeWeb,
+		HostPort: 62001,
+		Domains: []store.Domain{
+			{Host: "shop.example.com", TLS: store.TLSAcme},
+			{Host: "shop.internal", TLS: store.TLSCustom, CertFile: "/etc/certs/shop.crt", KeyFile: "/etc/certs/shop.key"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	apps := mock.snapshot()[0]
+	if len(apps) != 3 {
+		t.Fatalf("registered %d domains, want 3: %v", len(apps), apps)
+	}
+	if app := apps["shop.dployr.run"]; app.TLS != "" || app.Service != "shop" {
+		t.Errorf("default domain = %+v, want plain HTTP owned by shop", app)
+	}
+	acme := apps["shop.example.com"]
+	if acme.TLS != store.TLSAcme || acme.Upstream != "localhost:62001" || acme.Service != "shop" {
+		t.Errorf("acme domain = %+v", acme)
+	}
+	custom := apps["shop.internal"]
+	if custom.TLS != store.TLSCustom || custom.CertFile != "/etc/certs/shop.crt" || custom.KeyFile != "/etc/certs/shop.key" {
+		t.Errorf("custom domain = %+v", custom)
+	}
+}
+
+func TestRegisterProxyRoute_RemovesDroppedDomains(t *testing.T) {
+	mock := &mockProxyAPI{apps: []proxy.App{
+		{Domain: "shop.dployr.run", Service: "shop"},
+		{Domain: "old.example.com", Service: "shop"},
+		{Domain: "blog.example.com", Service: "blog"},
+		{Domain: "manual.example.com"},
+	}}
+	w := newWorkerWithProxy(mock)
+
+	if err := w.registerProxyRoute(&store.Service{Name: "shop", Type: store.TypeWeb, Port: 3000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.removed) != 1 || mock.removed[0] != "old.example.com" {
+		t.Errorf("removed = %v, want [old.example.com]", mock.removed)
+	}
+}
+
+func TestRegisterProxyRoute_DomainOwnedByOtherService(t *testing.T) {
+	mock := &mockProxyAPI{apps: []proxy.App{{Domain: "example.com", Service: "blog"}}}
+	w := newWorkerWithProxy(mock)
+
+	err := w.registerProxyRoute(&store.Service{
+		Name:    "shop",
+		Type:    store.TypeWeb,
+		Domains: []store.Domain{{Host: "example.com", TLS: store.TLSAcme}},
+	})
+	if err == nil || !containsStr(err.Error(), "blog") {
+		t.Fatalf("err = %v, want conflict with service blog", err)
+	}
+	if len(mock.snapshot()) != 0 {
+		t.Error("routes were added despite the conflict")
+	}
+}
+
 func TestRegisterProxyRoute_Failure(t *testing.T) {
 	mock := &mockProxyAPI{addErr: errors.New("caddy config invalid")}
 	w := newWorkerWithProxy(mock)
@@ -799,6 +1263,44 @@ func TestWorker_ExecuteFailed_SetsStatusFailed(t *testing.T) {
 	}
 }
 
+// TestWorker_ExecuteRecordsRelease verifies that every execution appends a
+// release carrying the deployment's image and that its status follows the
+// deployment's terminal status.
+func TestWorker_ExecuteRecordsRelease(t *testing.T) {
+	cfg := &shared.Config{Role: store.NodeRoleInstance}
+	logger := shared.NewLogger()
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+	deployStore := &mockDeploymentStore{deployments: make(map[string]*store.Deployment)}
+
+	dep := &store.Deployment{
+		ID:     "release-dep-01",
+		Status: store.StatusPending,
+		Blueprint: store.Blueprint{
+			Name:   "release-app",
+			Source: store.SourceImage,
+			Image:  "registry.example.com/release-app:1700000000000",
+			Remote: store.RemoteObj{CommitHash: "abc123"},
+		},
+	}
+	deployStore.UpsertDeployment(context.Background(), dep)
+
+	w := New(1, cfg, logger, deployStore, svcStore, instStore, nil)
+	w.execute(context.Background(), "release-dep-01")
+
+	releases, _ := deployStore.ListReleases(context.Background(), "release-app", 10)
+	if len(releases) != 1 {
+		t.Fatalf("expected 1 release, got %d", len(releases))
+	}
+	r := releases[0]
+	if r.Image != dep.Blueprint.Image || r.CommitHash != "abc123" || r.DeploymentID != dep.ID {
+		t.Errorf("release = %+v, want image/commit/deployment from blueprint", r)
+	}
+	if r.Status != dep.Status {
+		t.Errorf("release status = %q, want deployment status %q", r.Status, dep.Status)
+	}
+}
+
 func TestWorker_DuplicateJobPrevention(t *testing.T) {
 	cfg := &shared.Config{}
 	logger := shared.NewLogger()
@@ -824,3 +1326,94 @@ func TestWorker_DuplicateJobPrevention(t *testing.T) {
 		}
 	})
 }
+
+func TestWorker_DrainKeepsSubmissionsQueued(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: make(map[string]*store.Deployment)}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		worker.Start(context.Background())
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := worker.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v with nothing running", err)
+	}
+
+	if err := worker.Submit("late"); err != nil {
+		t.Fatalf("Submit() while draining error = %v", err)
+	}
+	if len(worker.queue) != 0 {
+		t.Error("a deployment submitted while draining must not be dispatched")
+	}
+	if !slices.Contains(deployStore.queuedSnapshot(), "late") {
+		t.Error("a deployment submitted while draining must stay in the persistent queue")
+	}
+	if worker.dispatch(context.Background(), "late") {
+		t.Error("dispatch() started a deployment while draining")
+	}
+}
+
+func TestWorker_HoldDefersDeployments(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: make(map[string]*store.Deployment)}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+	worker.Hold(true)
+
+	for _, id := range []string{"first", "second"} {
+		if err := worker.Submit(id); err != nil {
+			t.Fatalf("Submit(%s) while held error = %v", id, err)
+		}
+	}
+	if len(worker.queue) != 0 {
+		t.Error("a deployment submitted while held must not be dispatched")
+	}
+	if !slices.Equal(deployStore.queuedSnapshot(), []string{"first", "second"}) {
+		t.Errorf("persistent queue = %v, want both held deployments", deployStore.queuedSnapshot())
+	}
+
+	worker.Hold(false)
+	for _, want := range []string{"first", "second"} {
+		select {
+		case got := <-worker.queue:
+			if got != want {
+				t.Errorf("released %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not released when the hold was lifted", want)
+		}
+	}
+}
+
+func TestWorker_CheckpointsOnShutdown(t *testing.T) {
+	deployStore := &mockDeploymentStore{deployments: map[string]*store.Deployment{
+		"dep-1": {ID: "dep-1", Status: store.StatusPending, Blueprint: store.Blueprint{Name: "app", Source: store.SourceImage, Image: "app:1"}},
+	}}
+	deployStore.queued = []string{"dep-1"}
+	svcStore := &mockServiceStore{services: make(map[string]*store.Service)}
+	instStore := &mockInstanceStore{accessToken: "test-token"}
+
+	worker := New(1, &shared.Config{}, shared.NewLogger(), deployStore, svcStore, instStore, nil)
+
+	// The worker's context is gone: the daemon is shutting down.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	worker.semaphore.Acquire(context.Background())
+	worker.execute(ctx, "dep-1")
+
+	calls := deployStore.statusCallsSnapshot()
+	if len(calls) == 0 || calls[len(calls)-1] != string(store.StatusPending) {
+		t.Errorf("status calls = %v, want the deployment back to pending", calls)
+	}
+	if !slices.Contains(deployStore.queuedSnapshot(), "dep-1") {
+		t.Error("checkpointed deployment must stay queued for the next startup")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// maxAuditBody bounds how much of a request body is read to digest it.
+const maxAuditBody = 10 << 20
+
+// AuditRecorder stores audit entries. store.AuditStore satisfies it.
+type AuditRecorder interface {
+	AppendAudit(ctx context.Context, e *store.AuditEntry) error
+}
+
+// SetAuditLog enables Audit. Until it is called Audit passes requests
+// through unrecorded.
+func (m *Middleware) SetAuditLog(rec AuditRecorder) {
+	m.audit = rec
+}
+
+// Audit records every state-changing request to the audit log once it has
+// been handled: who made it, under which task, what it targeted, how it
+// ended and a digest of its arguments. Place it directly inside Auth so that
+// requests refused by role and scope checks are recorded too. Reads pass
+// through unrecorded.
+func (m *Middleware) Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.audit == nil || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, maxAuditBody))
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		ctx := r.Context()
+		entry := &store.AuditEntry{
+			TaskID:     shared.TaskID(ctx),
+			Method:     r.Method,
+			Route:      r.URL.Path,
+			Status:     rec.status,
+			Outcome:    AuditOutcome(rec.status),
+			ArgsDigest: DigestArgs(r.URL.RawQuery, body),
+		}
+		if claims, ok := ctx.Value(claimsCtxKey).(*Claims); ok {
+			entry.Subject = claims.UserID()
+			entry.Role = claims.Perm
+		}
+		// Recorded even if the caller has gone away.
+		if err := m.audit.AppendAudit(context.WithoutCancel(ctx), entry); err != nil {
+			shared.LogWithContext(ctx).Error("failed to record audit entry", "route", entry.Route, "error", err)
+		}
+	})
+}
+
+// AuditOutcome classifies a response status for the audit log.
+func AuditOutcome(status int) store.AuditOutcome {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return store.AuditDenied
+	case status >= 400:
+		return store.AuditFailed
+	default:
+		return store.AuditSucceeded
+	}
+}
+
+// DigestArgs returns the hex sha256 of a request's query and body. The log
+// keeps only the digest so arguments such as file contents and secrets are
+// never stored, yet a known request can still be matched against it.
+func DigestArgs(query string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(query))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
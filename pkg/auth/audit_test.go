@@ -0,0 +1,103 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type memAudit struct {
+	entries []*store.AuditEntry
+}
+
+func (m *memAudit) AppendAudit(ctx context.Context, e *store.AuditEntry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func TestAudit_RecordsWrites(t *testing.T) {
+	rec := &memAudit{}
+	m := NewMiddleware(nil)
+	m.SetAuditLog(rec)
+
+	var gotBody string
+	h := m.Audit(m.RequireScope(ScopeSystemWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	})))
+
+	serve := func(method string, claims *Claims, body string) int {
+		req := httptest.NewRequest(method, "/system/reboot", strings.NewReader(body))
+		ctx := context.WithValue(req.Context(), claimsCtxKey, claims)
+		req = req.WithContext(shared.WithTask(ctx, "task-1"))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := serve(http.MethodPost, &Claims{Subject: "alice", Perm: "admin", Scopes: []string{"system:write"}}, `{"force":true}`); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", code, http.StatusAccepted)
+	}
+	if gotBody != `{"force":true}` {
+		t.Errorf("handler read body %q, want it intact after digesting", gotBody)
+	}
+	serve(http.MethodPost, &Claims{Subject: "bob", Perm: "viewer", Scopes: []string{"system:read"}}, `{}`)
+	serve(http.MethodGet, &Claims{Subject: "carol", Scopes: []string{"system:write"}}, "")
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("recorded %d entries, want 2 (reads are not audited)", len(rec.entries))
+	}
+	ok, denied := rec.entries[0], rec.entries[1]
+	if ok.Subject != "alice" || ok.Role != "admin" || ok.TaskID != "task-1" || ok.Route != "/system/reboot" || ok.Outcome != store.AuditSucceeded {
+		t.Errorf("allowed entry = %+v", ok)
+	}
+	if ok.ArgsDigest != DigestArgs("", []byte(`{"force":true}`)) {
+		t.Errorf("args digest = %s, want digest of the request body", ok.ArgsDigest)
+	}
+	if denied.Subject != "bob" || denied.Status != http.StatusForbidden || denied.Outcome != store.AuditDenied {
+		t.Errorf("denied entry = %+v", denied)
+	}
+}
+
+func TestClaims_UserID(t *testing.T) {
+	for _, tc := range []struct {
+		claims Claims
+		want   string
+	}{
+		{Claims{Subject: "alice", RegisteredClaims: jwt.RegisteredClaims{Subject: "ignored"}}, "alice"},
+		{Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "bob"}}, "bob"},
+		{Claims{}, ""},
+	} {
+		if got := tc.claims.UserID(); got != tc.want {
+			t.Errorf("UserID of %+v = %q, want %q", tc.claims, got, tc.want)
+		}
+	}
+}
+
+func TestAuditOutcome(t *testing.T) {
+	tests := map[int]store.AuditOutcome{
+		http.StatusOK:                  store.AuditSucceeded,
+		http.StatusAccepted:            store.AuditSucceeded,
+		http.StatusUnauthorized:        store.AuditDenied,
+		http.StatusForbidden:           store.AuditDenied,
+		http.StatusBadRequest:          store.AuditFailed,
+		http.StatusInternalServerError: store.AuditFailed,
+	}
+	for status, want := range tests {
+		if got := AuditOutcome(status); got != want {
+			t.Errorf("AuditOutcome(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
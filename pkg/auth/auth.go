@@ -20,6 +20,15 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// UserID returns who the token was issued to: the sub claim, or the
+// registered subject when only that is set.
+func (c *Claims) UserID() string {
+	if c.Subject != "" {
+		return c.Subject
+	}
+	return c.RegisteredClaims.Subject
+}
+
 type Authenticator interface {
 	ValidateToken(ctx context.Context, inputToken string) (*Claims, error)
 }
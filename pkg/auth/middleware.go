@@ -25,7 +25,8 @@ const (
 )
 
 type Middleware struct {
-	auth Authenticator
+	auth  Authenticator
+	audit AuditRecorder
 }
 
 func NewMiddleware(auth Authenticator) *Middleware {
@@ -61,11 +62,7 @@ func (m *Middleware) Auth(next http.Handler) http.Handler {
 			return
 		}
 
-		subject := claims.Subject
-		if subject == "" {
-			subject = claims.RegisteredClaims.Subject
-		}
-		ctx = context.WithValue(ctx, shared.CtxUserIDKey, subject)
+		ctx = context.WithValue(ctx, shared.CtxUserIDKey, claims.UserID())
 		ctx = context.WithValue(ctx, claimsCtxKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,92 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// Scope names an API capability a token may be limited to. Scopes narrow
+// what a role allows; they never grant beyond it.
+type Scope string
+
+const (
+	ScopeDeploymentsRead  Scope = "deployments:read"
+	ScopeDeploymentsWrite Scope = "deployments:write"
+	ScopeServicesRead     Scope = "services:read"
+	ScopeServicesWrite    Scope = "services:write"
+	ScopeBuildsWrite      Scope = "builds:write"
+	ScopeProxyRead        Scope = "proxy:read"
+	ScopeProxyWrite       Scope = "proxy:write"
+	ScopeSystemRead       Scope = "system:read"
+	ScopeSystemWrite      Scope = "system:write"
+	ScopeFSRead           Scope = "fs:read"
+	ScopeFSWrite          Scope = "fs:write"
+	ScopeLogsRead         Scope = "logs:read"
+	ScopeTerminalOpen     Scope = "terminal:open" // host shell
+	ScopeTerminalExec     Scope = "terminal:exec" // shell in a service container
+	ScopeTerminalRead     Scope = "terminal:read" // recorded sessions
+)
+
+// Scopes is the catalogue of scopes the daemon enforces.
+var Scopes = []Scope{
+	ScopeDeploymentsRead, ScopeDeploymentsWrite,
+	ScopeServicesRead, ScopeServicesWrite,
+	ScopeBuildsWrite,
+	ScopeProxyRead, ScopeProxyWrite,
+	ScopeSystemRead, ScopeSystemWrite,
+	ScopeFSRead, ScopeFSWrite,
+	ScopeLogsRead,
+	ScopeTerminalOpen, ScopeTerminalExec, ScopeTerminalRead,
+}
+
+// HasScope reports whether the token may be used for required. A token
+// without scopes is unrestricted. Otherwise one of its scopes must match
+// exactly, be "*" or "<resource>:*", or be the write scope of a read.
+func (c *Claims) HasScope(required Scope) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+	resource, action, _ := strings.Cut(string(required), ":")
+	for _, s := range c.Scopes {
+		switch s {
+		case string(required), "*", resource + ":*":
+			return true
+		case resource + ":write":
+			if action == "read" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequireScope rejects tokens whose scopes do not cover required.
+func (m *Middleware) RequireScope(required Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logger := shared.LogWithContext(ctx)
+			claims, ok := ctx.Value(claimsCtxKey).(*Claims)
+			if !ok {
+				e := shared.Errors.Runtime.InternalServer
+				logger.Error("missing claims in context during scope check")
+				shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+				return
+			}
+
+			if !claims.HasScope(required) {
+				e := shared.Errors.Auth.Forbidden
+				logger.Warn("scope denied", "required", required, "actual", claims.Scopes)
+				shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"scope": required})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClaims_HasScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		required Scope
+		want     bool
+	}{
+		{"unscoped token", nil, ScopeFSWrite, true},
+		{"exact match", []string{"deployments:write"}, ScopeDeploymentsWrite, true},
+		{"write covers read", []string{"services:write"}, ScopeServicesRead, true},
+		{"read does not cover write", []string{"services:read"}, ScopeServicesWrite, false},
+		{"resource wildcard", []string{"fs:*"}, ScopeFSWrite, true},
+		{"global wildcard", []string{"*"}, ScopeTerminalOpen, true},
+		{"other resource", []string{"deployments:write"}, ScopeFSRead, false},
+		{"unrelated scope only", []string{"oidc:bind"}, ScopeDeploymentsRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Claims{Scopes: tt.scopes}
+			if got := c.HasScope(tt.required); got != tt.want {
+				t.Errorf("HasScope(%s) with %v = %v, want %v", tt.required, tt.scopes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	m := NewMiddleware(nil)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := m.RequireScope(ScopeDeploymentsWrite)(ok)
+
+	serve := func(claims *Claims) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/deployments", nil)
+		if claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), claimsCtxKey, claims))
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := serve(&Claims{Scopes: []string{"deployments:write"}}); rr.Code != http.StatusNoContent {
+		t.Errorf("scoped token: status = %d, want %d", rr.Code, http.StatusNoContent)
+	}
+
+	rr := serve(&Claims{Scopes: []string{"deployments:read"}})
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("read-only token: status = %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	if !strings.Contains(rr.Body.String(), "deployments:write") {
+		t.Errorf("forbidden body does not name the missing scope: %s", rr.Body.String())
+	}
+
+	if rr := serve(nil); rr.Code != http.StatusInternalServerError {
+		t.Errorf("no claims: status = %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type HandleAudit interface {
+	// ListAudit returns entries recorded at or after since, oldest first.
+	ListAudit(ctx context.Context, since time.Time, limit int) ([]*store.AuditEntry, error)
+	// VerifyAudit returns the Seq of the first entry that breaks the hash
+	// chain, or 0 when it is intact.
+	VerifyAudit(ctx context.Context) (int64, error)
+}
+
+// AuditLog is the response to an audit query.
+type AuditLog struct {
+	Entries []*store.AuditEntry `json:"entries"`
+	// Intact is false when the chain no longer verifies; BrokenAt then names
+	// the first entry that was altered, removed or inserted out of order.
+	Intact   bool  `json:"intact"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
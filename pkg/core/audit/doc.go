@@ -0,0 +1,7 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package audit exposes the node's audit log of privileged actions over HTTP.
+// Entries are written by auth.Middleware.Audit and stored hash-chained, so a
+// read also reports whether the chain still verifies.
+package audit
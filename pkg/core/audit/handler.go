@@ -0,0 +1,85 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+const defaultSince = 24 * time.Hour
+
+type Handler struct {
+	api    HandleAudit
+	logger *shared.Logger
+}
+
+func NewHandler(api HandleAudit, logger *shared.Logger) *Handler {
+	return &Handler{api: api, logger: logger}
+}
+
+// ListAudit returns the audit entries since ?since=, which takes a duration
+// back from now (24h, 90m) or an RFC 3339 time, and defaults to 24h.
+func (h *Handler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("audit.list request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	since, ok := parseSince(r.URL.Query().Get("since"), time.Now())
+	if !ok {
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), "since must be a duration such as 24h or an RFC 3339 time", map[string]any{"param": "since"})
+		return
+	}
+
+	limit := 500
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, 5000)
+	}
+
+	entries, err := h.api.ListAudit(ctx, since, limit)
+	if err != nil {
+		h.logger.Error("failed to list audit entries", "error", err)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+	brokenAt, err := h.api.VerifyAudit(ctx)
+	if err != nil {
+		h.logger.Error("failed to verify audit chain", "error", err)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+	if entries == nil {
+		entries = []*store.AuditEntry{}
+	}
+	if brokenAt != 0 {
+		h.logger.Warn("audit chain verification failed", "broken_at", brokenAt)
+	}
+
+	shared.WriteJSON(w, http.StatusOK, AuditLog{Entries: entries, Intact: brokenAt == 0, BrokenAt: brokenAt})
+}
+
+func parseSince(v string, now time.Time) (time.Time, bool) {
+	if v == "" {
+		return now.Add(-defaultSince), true
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return now.Add(-d), true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
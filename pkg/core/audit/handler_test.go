@@ -0,0 +1,79 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type stubAudit struct {
+	since    time.Time
+	limit    int
+	brokenAt int64
+}
+
+func (s *stubAudit) ListAudit(ctx context.Context, since time.Time, limit int) ([]*store.AuditEntry, error) {
+	s.since, s.limit = since, limit
+	return []*store.AuditEntry{{Seq: 7, Subject: "alice", Route: "/system/reboot", Outcome: store.AuditSucceeded}}, nil
+}
+
+func (s *stubAudit) VerifyAudit(ctx context.Context) (int64, error) {
+	return s.brokenAt, nil
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"", now.Add(-24 * time.Hour), true},
+		{"2h", now.Add(-2 * time.Hour), true},
+		{"2025-05-31T08:00:00Z", time.Date(2025, 5, 31, 8, 0, 0, 0, time.UTC), true},
+		{"-1h", time.Time{}, false},
+		{"yesterday", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSince(tt.in, now)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestListAudit(t *testing.T) {
+	api := &stubAudit{brokenAt: 5}
+	req := httptest.NewRequest(http.MethodGet, "/audit?since=1h&limit=10", nil)
+	rr := httptest.NewRecorder()
+	NewHandler(api, shared.NewLogger()).ListAudit(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if api.limit != 10 || time.Since(api.since) < 59*time.Minute {
+		t.Errorf("queried since %v limit %d, want an hour ago and 10", api.since, api.limit)
+	}
+	var got AuditLog
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Intact || got.BrokenAt != 5 || len(got.Entries) != 1 {
+		t.Errorf("response = %+v, want one entry and a chain broken at 5", got)
+	}
+
+	rr = httptest.NewRecorder()
+	NewHandler(api, shared.NewLogger()).ListAudit(rr, httptest.NewRequest(http.MethodGet, "/audit?since=yesterday", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("bad since: status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
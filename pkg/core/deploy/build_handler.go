@@ -5,6 +5,7 @@ package deploy
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/dployr-io/dployr/pkg/shared"
@@ -19,8 +20,9 @@ func NewBuildHandler(deployer *Deployer, logger *shared.Logger) *BuildHandler {
 	return &BuildHandler{deployer: deployer, logger: logger}
 }
 
-// HandleBuild is called on build nodes. It executes the build, pushes the image,
-// and returns the image reference in the task result.
+// HandleBuild is called on build nodes. It waits for a build slot, executes
+// the build, pushes the image, and returns the image reference and the time
+// spent queued in the task result.
 func (h *BuildHandler) HandleBuild(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		e := shared.Errors.Request.MethodNotAllowed
@@ -37,6 +39,12 @@ func (h *BuildHandler) HandleBuild(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.deployer.api.Build(r.Context(), &req)
 	if err != nil {
+		if errors.Is(err, ErrBuildSuperseded) {
+			h.logger.Info("build superseded", "name", req.Name)
+			e := shared.Errors.Resource.Conflict
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
 		h.logger.Error("build failed", "error", err)
 		e := shared.Errors.Runtime.InternalServer
 		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
@@ -67,6 +75,16 @@ func (h *BuildHandler) HandlePublish(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.deployer.api.Publish(r.Context(), &req)
 	if err != nil {
 		h.logger.Error("publish failed", "error", err)
+		if errors.Is(err, ErrQueueFull) {
+			e := shared.Errors.Runtime.Unavailable
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+		if errors.Is(err, ErrInvalidDomain) || errors.Is(err, ErrInvalidReplicas) || errors.Is(err, ErrInvalidResources) || errors.Is(err, ErrInvalidSchedule) {
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
 		e := shared.Errors.Runtime.InternalServer
 		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
 		return
@@ -76,3 +94,35 @@ func (h *BuildHandler) HandlePublish(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(resp)
 }
+
+// HandleDeployKey is called on build nodes. It returns the public half of a
+// service's SSH deploy key, generating the key on first use.
+func (h *BuildHandler) HandleDeployKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	var req DeployKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+	if req.Name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	resp, err := h.deployer.api.DeployKey(r.Context(), &req)
+	if err != nil {
+		h.logger.Error("deploy key failed", "error", err, "service_name", req.Name)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, resp)
+}
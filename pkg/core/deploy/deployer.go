@@ -5,12 +5,49 @@ package deploy
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/dployr-io/dployr/pkg/shared"
 	"github.com/dployr-io/dployr/pkg/store"
 )
 
+// ErrQueueFull is returned when the worker queue cannot take another
+// deployment. Callers should retry later rather than wait.
+var ErrQueueFull = errors.New("deployment queue is full, retry shortly")
+
+// ErrNothingToCancel is returned when a service has no queued or running
+// deployment and no build in progress.
+var ErrNothingToCancel = errors.New("no deployment or build in progress")
+
+// ErrReleaseNotFound is returned when a rollback names a release the service
+// does not have, or the service has no earlier release to return to.
+var ErrReleaseNotFound = errors.New("release not found")
+
+// ErrReleaseNotBuilt is returned when a rollback names a release that never
+// produced an image.
+var ErrReleaseNotBuilt = errors.New("release has no built image")
+
+// ErrBuildSuperseded is returned to a build that was still queued when a
+// build of another commit of the same service arrived and took its place.
+var ErrBuildSuperseded = errors.New("build superseded by a newer commit")
+
+// ErrInvalidDomain is returned when a deployment asks for a custom domain
+// that cannot be served.
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// ErrInvalidReplicas is returned when a replica count is out of range or the
+// service type cannot be replicated.
+var ErrInvalidReplicas = errors.New("invalid replica count")
+
+// ErrInvalidResources is returned when requested container limits are
+// malformed or do not fit the cluster's slice.
+var ErrInvalidResources = errors.New("invalid resource limits")
+
+// ErrInvalidSchedule is returned when a job's cron schedule or concurrency
+// policy cannot be parsed, or a schedule is set on a service that is not a job.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
 type Deployer struct {
 	config *shared.Config
 	logger *shared.Logger
@@ -46,7 +83,13 @@ type DeployRequest struct {
 	Secrets     map[string]any  `json:"secrets,omitempty"`
 	Remote      store.RemoteObj `json:"remote,omitempty"`
 	Domain      string          `json:"domain,omitempty"`
+	Domains     []store.Domain  `json:"domains,omitempty"`
+	Replicas    int             `json:"replicas,omitempty"`
+	Resources   store.Resources `json:"resources,omitzero"`
 	HealthCheck string          `json:"health_check,omitempty"`
+	Schedule    string          `json:"schedule,omitempty"`
+	Concurrency string          `json:"concurrency_policy,omitempty"`
+	AutoDeploy  bool            `json:"auto_deploy,omitempty"`
 }
 
 func (dr *DeployRequest) GetRuntimeObj() store.RuntimeObj {
@@ -66,6 +109,7 @@ type DeployResponse struct {
 type BuildRequest struct {
 	DeployRequest
 	CallbackInstance string `json:"callback_instance"`
+	ForceRebuild     bool   `json:"force_rebuild,omitempty"` // build even when the image is cached
 }
 
 type PublishRequest struct {
@@ -73,8 +117,87 @@ type PublishRequest struct {
 	Payload DeployRequest `json:"payload"`
 }
 
+// BuildResponse names the pushed image. Cached is set when an identical
+// image was already in the registry and nothing was built. QueuePosition is
+// where the build entered the node's build queue, 0 if a slot was free, and
+// QueueWaitMs how long it waited for one.
 type BuildResponse struct {
-	Image string `json:"image"`
+	Image         string `json:"image"`
+	Cached        bool   `json:"cached,omitempty"`
+	QueuePosition int    `json:"queue_position,omitempty"`
+	QueueWaitMs   int64  `json:"queue_wait_ms"`
+}
+
+// RollbackRequest selects the release to redeploy. Release may be a release
+// ID or version number; empty means the release before the one now live.
+type RollbackRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Release string `json:"release,omitempty"`
+}
+
+type RollbackResponse struct {
+	DeployResponse
+	Release ReleaseSummary `json:"release"`
+}
+
+// ReleaseSummary is what the API shows of a release. The stored blueprint,
+// with its environment and secrets, is left out.
+type ReleaseSummary struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Version      int          `json:"version"`
+	DeploymentID string       `json:"deployment_id"`
+	Image        string       `json:"image,omitempty"`
+	CommitHash   string       `json:"commit_hash,omitempty"`
+	Status       store.Status `json:"status"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// ReleaseList is a service's release history, newest first.
+type ReleaseList struct {
+	Releases []ReleaseSummary `json:"releases"`
+}
+
+// SummarizeRelease returns the ReleaseSummary of r.
+func SummarizeRelease(r *store.Release) ReleaseSummary {
+	return ReleaseSummary{
+		ID:           r.ID,
+		Name:         r.Name,
+		Version:      r.Version,
+		DeploymentID: r.DeploymentID,
+		Image:        r.Image,
+		CommitHash:   r.CommitHash,
+		Status:       r.Status,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+type CancelRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// CancelResponse reports what was stopped. DeploymentID is set when a queued
+// or running deployment was cancelled, Build when a build was aborted.
+type CancelResponse struct {
+	Name         string `json:"name"`
+	DeploymentID string `json:"deployment_id,omitempty"`
+	Build        bool   `json:"build"`
+}
+
+// DeployKeyRequest asks a build node for a service's SSH deploy key. Rotate
+// replaces an existing key with a new one.
+type DeployKeyRequest struct {
+	Name   string `json:"name" validate:"required"`
+	Rotate bool   `json:"rotate,omitempty"`
+}
+
+// DeployKeyResponse holds the public key to add to the repository as a
+// read-only deploy key. Created is set when the key was generated by this
+// request.
+type DeployKeyResponse struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+	Created   bool   `json:"created"`
 }
 
 type HandleDeployment interface {
@@ -84,4 +207,8 @@ type HandleDeployment interface {
 	GetDeployment(ctx context.Context, id string) (*store.Deployment, error)
 	ListDeployments(ctx context.Context, id string, limit, offset int) ([]*store.Deployment, error)
 	UpdateDeploymentStatus(ctx context.Context, id string, status store.Status) error
+	Rollback(ctx context.Context, req *RollbackRequest) (*RollbackResponse, error)
+	ListReleases(ctx context.Context, name string, limit int) ([]*store.Release, error)
+	Cancel(ctx context.Context, req *CancelRequest) (*CancelResponse, error)
+	DeployKey(ctx context.Context, req *DeployKeyRequest) (*DeployKeyResponse, error)
 }
@@ -5,6 +5,7 @@ package deploy
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -44,6 +45,17 @@ func (h *DeploymentHandler) CreateDeployment(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		h.logger.Error("failed to create deployment", "error", err)
 
+		if errors.Is(err, ErrQueueFull) {
+			e := shared.Errors.Runtime.Unavailable
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+		if errors.Is(err, ErrInvalidDomain) || errors.Is(err, ErrInvalidReplicas) || errors.Is(err, ErrInvalidResources) || errors.Is(err, ErrInvalidSchedule) {
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+
 		switch err.Error() {
 		case string(shared.BadRequest):
 			e := shared.Errors.Request.BadRequest
@@ -118,6 +130,148 @@ func (h *DeploymentHandler) ListDeployments(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (h *DeploymentHandler) RollbackDeployment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("deploy.rollback_deployment request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		shared.WriteError(w, shared.Errors.Request.MethodNotAllowed.HTTPStatus, string(shared.Errors.Request.MethodNotAllowed.Code), shared.Errors.Request.MethodNotAllowed.Message, nil)
+		return
+	}
+
+	var req RollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode request body", "error", err)
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	if req.Name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	resp, err := h.deployer.api.Rollback(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to roll back service", "error", err, "service_name", req.Name)
+		if errors.Is(err, ErrQueueFull) {
+			e := shared.Errors.Runtime.Unavailable
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+		if errors.Is(err, ErrReleaseNotFound) {
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), map[string]any{"resource": "release", "name": req.Name})
+			return
+		}
+		if errors.Is(err, ErrReleaseNotBuilt) {
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+func (h *DeploymentHandler) CancelDeployment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("deploy.cancel_deployment request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		shared.WriteError(w, shared.Errors.Request.MethodNotAllowed.HTTPStatus, string(shared.Errors.Request.MethodNotAllowed.Code), shared.Errors.Request.MethodNotAllowed.Message, nil)
+		return
+	}
+
+	var req CancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode request body", "error", err)
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	if req.Name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	resp, err := h.deployer.api.Cancel(ctx, &req)
+	if err != nil {
+		h.logger.Error("failed to cancel deployment", "error", err, "service_name", req.Name)
+		if errors.Is(err, ErrNothingToCancel) {
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), map[string]any{"resource": "deployment", "name": req.Name})
+			return
+		}
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+func (h *DeploymentHandler) ListReleases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("deploy.list_releases request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		shared.WriteError(w, shared.Errors.Request.MethodNotAllowed.HTTPStatus, string(shared.Errors.Request.MethodNotAllowed.Code), shared.Errors.Request.MethodNotAllowed.Message, nil)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsedLimit := parseLimit(limitStr); parsedLimit > 0 {
+			limit = min(parsedLimit, 100)
+		}
+	}
+
+	releases, err := h.deployer.api.ListReleases(ctx, name, limit)
+	if err != nil {
+		h.logger.Error("failed to list releases", "error", err, "service_name", name)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	summaries := make([]ReleaseSummary, len(releases))
+	for i, r := range releases {
+		summaries[i] = SummarizeRelease(r)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(ReleaseList{Releases: summaries}); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
 func parseLimit(s string) int {
 	v, err := strconv.Atoi(s)
 	if err != nil {
@@ -0,0 +1,6 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jobs models scheduled job runs and provides HTTP handlers for
+// listing a job's run history and triggering a run by hand.
+package jobs
@@ -0,0 +1,97 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+type Handler struct {
+	api    HandleJobs
+	logger *shared.Logger
+}
+
+func NewHandler(api HandleJobs, logger *shared.Logger) *Handler {
+	return &Handler{api: api, logger: logger}
+}
+
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("jobs.list_runs request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	limit := 20
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, 100)
+	}
+
+	runs, err := h.api.ListRuns(ctx, name, limit)
+	if err != nil {
+		h.logger.Error("failed to list job runs", "error", err, "name", name)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, runs)
+}
+
+func (h *Handler) Trigger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("jobs.trigger request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	var req TriggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), "invalid request body", nil)
+		return
+	}
+	if req.Name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	run, err := h.api.Trigger(ctx, req.Name)
+	if err != nil {
+		h.logger.Error("failed to trigger job", "error", err, "name", req.Name)
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), map[string]any{"resource": "job", "name": req.Name})
+		case errors.Is(err, ErrNotScheduled), errors.Is(err, ErrRunInProgress):
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+		default:
+			e := shared.Errors.Runtime.InternalServer
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		}
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusAccepted, run)
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type stubJobs struct {
+	err error
+}
+
+func (s *stubJobs) Trigger(ctx context.Context, name string) (*store.JobRun, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &store.JobRun{ID: "run-1", Name: name, Trigger: store.TriggerManual, Status: store.RunRunning}, nil
+}
+
+func (s *stubJobs) ListRuns(ctx context.Context, name string, limit int) ([]*store.JobRun, error) {
+	return nil, s.err
+}
+
+func trigger(t *testing.T, api HandleJobs, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/jobs/trigger", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	NewHandler(api, shared.NewLogger()).Trigger(rr, req)
+	return rr
+}
+
+func TestTrigger_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		body string
+		want int
+	}{
+		{"started", nil, `{"name":"report"}`, http.StatusAccepted},
+		{"missing name", nil, `{}`, http.StatusBadRequest},
+		{"unknown job", fmt.Errorf("%w: report", ErrJobNotFound), `{"name":"report"}`, http.StatusNotFound},
+		{"not scheduled", fmt.Errorf("%w: report", ErrNotScheduled), `{"name":"report"}`, http.StatusBadRequest},
+		{"forbidden overlap", ErrRunInProgress, `{"name":"report"}`, http.StatusBadRequest},
+		{"other failure", fmt.Errorf("disk full"), `{"name":"report"}`, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := trigger(t, &stubJobs{err: tt.err}, tt.body)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestListRuns_RequiresName(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/jobs/runs", nil)
+	rr := httptest.NewRecorder()
+	NewHandler(&stubJobs{}, shared.NewLogger()).ListRuns(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rr.Code)
+	}
+}
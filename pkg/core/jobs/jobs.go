@@ -0,0 +1,35 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// ErrJobNotFound is returned when no job service has the requested name.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrNotScheduled is returned when triggering a job that was deployed
+// without a schedule. Such jobs run as ordinary services.
+var ErrNotScheduled = errors.New("job has no schedule")
+
+// ErrRunInProgress is returned when a manual trigger would overlap a running
+// job whose concurrency policy is forbid.
+var ErrRunInProgress = errors.New("a run of this job is already in progress")
+
+type HandleJobs interface {
+	// Trigger starts a run of a scheduled job now, honouring its
+	// concurrency policy.
+	Trigger(ctx context.Context, name string) (*store.JobRun, error)
+	// ListRuns returns a job's runs, newest first.
+	ListRuns(ctx context.Context, name string, limit int) ([]*store.JobRun, error)
+}
+
+// TriggerRequest starts a run of a scheduled job outside its schedule.
+type TriggerRequest struct {
+	Name string `json:"name"`
+}
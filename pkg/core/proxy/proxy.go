@@ -4,7 +4,10 @@
 package proxy
 
 import (
+	"slices"
+
 	"github.com/dployr-io/dployr/pkg/core/service"
+	"github.com/dployr-io/dployr/pkg/store"
 )
 
 type TemplateType string
@@ -13,6 +16,7 @@ const (
 	TemplateStatic       TemplateType = "static"
 	TemplateReverseProxy TemplateType = "reverse_proxy"
 	TemplatePHPFastCGI   TemplateType = "php_fastcgi"
+	TemplateMaintenance  TemplateType = "maintenance"
 )
 
 type Proxier struct {
@@ -28,12 +32,40 @@ func NewProxier(a map[string]App, p HandleProxy) *Proxier {
 }
 
 // App describes a proxy by its domain, upstream service, root directory, proxy status and template type.
+// Service names the deployed service that owns the route, if any. An empty TLS
+// mode serves the domain over plain HTTP. Upstreams lists every replica of a
+// scaled-out service (Upstream then holds the first); HealthURI is the path
+// Caddy actively probes on each of them.
 type App struct {
-	Domain   string       `json:"domain"`
-	Upstream string       `json:"upstream"`
-	Root     string       `json:"root,omitempty"`
-	Status   ProxyStatus  `json:"status"`
-	Template TemplateType `json:"template"` // static, reverse_proxy, or php_fastcgi
+	Domain    string        `json:"domain"`
+	Upstream  string        `json:"upstream"`
+	Root      string        `json:"root,omitempty"`
+	Status    ProxyStatus   `json:"status"`
+	Template  TemplateType  `json:"template"` // static, reverse_proxy, or php_fastcgi
+	Service   string        `json:"service,omitempty"`
+	TLS       store.TLSMode `json:"tls,omitempty"`
+	CertFile  string        `json:"cert_file,omitempty"`
+	KeyFile   string        `json:"key_file,omitempty"`
+	Upstreams []string      `json:"upstreams,omitempty"`
+	HealthURI string        `json:"health_uri,omitempty"`
+}
+
+// Maintenance puts domains behind a 503 maintenance page. Every domain is
+// covered when Domains is empty. It only changes how routes are rendered:
+// the routes themselves are kept as they are, so lifting maintenance brings
+// them back unchanged. Page is an HTML file to serve; empty uses the
+// built-in page.
+type Maintenance struct {
+	Domains []string `json:"domains,omitempty"`
+	Page    string   `json:"page,omitempty"`
+}
+
+// Covers reports whether domain is under maintenance.
+func (m *Maintenance) Covers(domain string) bool {
+	if m == nil {
+		return false
+	}
+	return len(m.Domains) == 0 || slices.Contains(m.Domains, domain)
 }
 
 // ProxyStatus describes the current status of the proxy service.
@@ -71,4 +103,11 @@ type HandleProxy interface {
 
 	// Remove deletes apps by domain, regenerates the Caddyfile, and reloads Caddy.
 	Remove(domains []string) error
+
+	// SetMaintenance serves the maintenance page in place of the routes m
+	// covers, or restores every route when m is nil, and reloads Caddy.
+	SetMaintenance(m *Maintenance) error
+
+	// Maintenance returns the maintenance in effect, or nil.
+	Maintenance() *Maintenance
 }
@@ -0,0 +1,7 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package runs models one-off commands run against a deployed service, such
+// as migrations or management tasks, and provides HTTP handlers to start a
+// run, follow its output and list past runs.
+package runs
@@ -0,0 +1,150 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package runs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type Handler struct {
+	api    HandleRuns
+	logger *shared.Logger
+}
+
+func NewHandler(api HandleRuns, logger *shared.Logger) *Handler {
+	return &Handler{api: api, logger: logger}
+}
+
+func (h *Handler) Run(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("runs.run request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	var req RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), "invalid request body", nil)
+		return
+	}
+	if req.Name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+	if len(req.Command) == 0 {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "command"})
+		return
+	}
+
+	run, err := h.api.Run(ctx, req)
+	if err != nil {
+		h.logger.Error("failed to start run", "error", err, "name", req.Name)
+		switch {
+		case errors.Is(err, ErrServiceNotFound):
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), map[string]any{"resource": "service", "name": req.Name})
+		case errors.Is(err, ErrNotRunnable), errors.Is(err, ErrInvalidRun):
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+		default:
+			e := shared.Errors.Runtime.InternalServer
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		}
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusAccepted, run)
+}
+
+func (h *Handler) GetRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("runs.get_run request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "id"})
+		return
+	}
+
+	var offset int64
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), "offset must be a non-negative integer", nil)
+			return
+		}
+		offset = n
+	}
+
+	out, err := h.api.Output(ctx, id, offset)
+	if err != nil {
+		if errors.Is(err, ErrRunNotFound) {
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), map[string]any{"resource": "run", "id": id})
+			return
+		}
+		h.logger.Error("failed to read run", "error", err, "id", id)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, out)
+}
+
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("runs.list_runs request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	limit := 20
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, 100)
+	}
+
+	runs, err := h.api.ListRuns(ctx, name, limit)
+	if err != nil {
+		h.logger.Error("failed to list runs", "error", err, "name", name)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+	if runs == nil {
+		runs = []*store.ServiceRun{}
+	}
+
+	shared.WriteJSON(w, http.StatusOK, runs)
+}
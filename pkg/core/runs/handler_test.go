@@ -0,0 +1,89 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package runs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+type stubRuns struct {
+	err error
+}
+
+func (s *stubRuns) Run(ctx context.Context, req RunRequest) (*store.ServiceRun, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &store.ServiceRun{ID: "run-1", Service: req.Name, Command: req.Command, Status: store.ServiceRunRunning}, nil
+}
+
+func (s *stubRuns) Output(ctx context.Context, id string, offset int64) (*RunOutput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &RunOutput{Run: &store.ServiceRun{ID: id}, Offset: offset}, nil
+}
+
+func (s *stubRuns) ListRuns(ctx context.Context, name string, limit int) ([]*store.ServiceRun, error) {
+	return nil, s.err
+}
+
+func TestRun_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		body string
+		want int
+	}{
+		{"started", nil, `{"name":"api","command":["rake","db:migrate"]}`, http.StatusAccepted},
+		{"missing name", nil, `{"command":["ls"]}`, http.StatusBadRequest},
+		{"missing command", nil, `{"name":"api"}`, http.StatusBadRequest},
+		{"unknown service", fmt.Errorf("%w: api", ErrServiceNotFound), `{"name":"api","command":["ls"]}`, http.StatusNotFound},
+		{"no image", fmt.Errorf("%w: api has no image", ErrNotRunnable), `{"name":"api","command":["ls"]}`, http.StatusBadRequest},
+		{"bad timeout", fmt.Errorf("%w: timeout", ErrInvalidRun), `{"name":"api","command":["ls"],"timeout":"x"}`, http.StatusBadRequest},
+		{"other failure", fmt.Errorf("disk full"), `{"name":"api","command":["ls"]}`, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/services/run", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			NewHandler(&stubRuns{err: tt.err}, shared.NewLogger()).Run(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetRun_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		query string
+		want  int
+	}{
+		{"found", nil, "?id=run-1&offset=120", http.StatusOK},
+		{"missing id", nil, "", http.StatusBadRequest},
+		{"bad offset", nil, "?id=run-1&offset=-1", http.StatusBadRequest},
+		{"unknown run", fmt.Errorf("%w: run-1", ErrRunNotFound), "?id=run-1", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/services/run"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			NewHandler(&stubRuns{err: tt.err}, shared.NewLogger()).GetRun(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package runs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dployr-io/dployr/pkg/core/logs"
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// ErrServiceNotFound is returned when no service has the requested name.
+var ErrServiceNotFound = errors.New("service not found")
+
+// ErrNotRunnable is returned for services that have no container image to
+// run a command in, such as static sites and systemd jobs.
+var ErrNotRunnable = errors.New("service cannot run one-off commands")
+
+// ErrInvalidRun is returned when a run request has no command or an
+// unusable timeout.
+var ErrInvalidRun = errors.New("invalid run request")
+
+// ErrRunNotFound is returned when no run has the requested ID.
+var ErrRunNotFound = errors.New("run not found")
+
+type HandleRuns interface {
+	// Run starts a one-off command against a service and returns once its
+	// run is recorded; the command carries on in the background.
+	Run(ctx context.Context, req RunRequest) (*store.ServiceRun, error)
+	// Output returns a run and the output it wrote from offset on.
+	Output(ctx context.Context, id string, offset int64) (*RunOutput, error)
+	// ListRuns returns a service's runs, newest first.
+	ListRuns(ctx context.Context, name string, limit int) ([]*store.ServiceRun, error)
+}
+
+// RunRequest runs Command in a fresh container of service Name. Timeout is a
+// Go duration; empty means the node default. When StreamID is set the output
+// is also pushed to the base as log chunks under that stream.
+type RunRequest struct {
+	Name     string   `json:"name"`
+	Command  []string `json:"command"`
+	Timeout  string   `json:"timeout,omitempty"`
+	StreamID string   `json:"streamId,omitempty"`
+}
+
+// RunOutput is a run with the output entries it wrote past a byte offset of
+// its log. Offset is where the next read should start; the output is
+// complete once the run has finished and a read returns no entries.
+type RunOutput struct {
+	Run     *store.ServiceRun `json:"run"`
+	Entries []logs.LogEntry   `json:"entries"`
+	Offset  int64             `json:"offset"`
+}
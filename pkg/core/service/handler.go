@@ -5,9 +5,11 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/dployr-io/dployr/pkg/core/deploy"
 	"github.com/dployr-io/dployr/pkg/shared"
 )
 
@@ -209,6 +211,92 @@ func (h *ServiceHandler) IceService(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]any{"status": "iced", "name": name})
 }
 
+func (h *ServiceHandler) ScaleService(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("service.scale_service request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		shared.WriteError(w, shared.Errors.Request.MethodNotAllowed.HTTPStatus, string(shared.Errors.Request.MethodNotAllowed.Code), shared.Errors.Request.MethodNotAllowed.Message, nil)
+		return
+	}
+
+	var req ScaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode request body", "error", err)
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	if req.Name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	if err := h.servicer.api.ScaleService(ctx, req.Name, req.Replicas); err != nil {
+		h.logger.Error("failed to scale service", "error", err, "name", req.Name, "replicas", req.Replicas)
+		if errors.Is(err, deploy.ErrInvalidReplicas) {
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{"name": req.Name, "replicas": req.Replicas})
+}
+
+func (h *ServiceHandler) UpdateService(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("service.update_service request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPatch {
+		shared.WriteError(w, shared.Errors.Request.MethodNotAllowed.HTTPStatus, string(shared.Errors.Request.MethodNotAllowed.Code), shared.Errors.Request.MethodNotAllowed.Message, nil)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "name"})
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("failed to decode request body", "error", err)
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	if err := h.servicer.api.ResizeService(ctx, name, req.Resources); err != nil {
+		h.logger.Error("failed to update service", "error", err, "name", name)
+		if errors.Is(err, deploy.ErrInvalidResources) {
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+			return
+		}
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	service, err := h.servicer.api.GetService(ctx, name)
+	if err != nil || service == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(service)
+}
+
 func parseLimit(s string) int {
 	v, err := strconv.Atoi(s)
 	if err != nil {
@@ -32,6 +32,20 @@ type HandleService interface {
 	SleepService(name string) error
 	WakeService(name string) error
 	IceService(name string) error
+	ScaleService(ctx context.Context, name string, replicas int) error
+	ResizeService(ctx context.Context, name string, r store.Resources) error
+}
+
+// UpdateRequest changes a running service without a rebuild. Only resource
+// limits can be updated this way; zero fields keep their current value.
+type UpdateRequest struct {
+	Resources store.Resources `json:"resources"`
+}
+
+// ScaleRequest sets how many replicas of a deployed service run.
+type ScaleRequest struct {
+	Name     string `json:"name"`
+	Replicas int    `json:"replicas"`
 }
 
 func NewServicer(c *shared.Config, l *shared.Logger, s store.ServiceStore, a HandleService) *Servicer {
@@ -98,13 +98,16 @@ func FromStoreService(s *store.Service) ServiceV1_1 {
 		Type:        string(s.Type),
 		Runtime:     string(s.Runtime),
 		Port:        s.Port,
-		HostPort:    utils.ComputeHostPort(containerName),
+		HostPort:    s.HostPort,
 		EnvVars:     make(map[string]string),
 		Secrets:     []SecretRef{},
 		CreatedAt:   s.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   s.UpdatedAt.Format(time.RFC3339),
 	}
 
+	if svc.HostPort == 0 {
+		svc.HostPort = utils.ComputeHostPort(containerName)
+	}
 	if s.RuntimeVersion != "" {
 		svc.RuntimeVersion = &s.RuntimeVersion
 	}
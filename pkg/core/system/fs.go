@@ -4,9 +4,14 @@
 package system
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrPathDenied is returned for paths the filesystem API never exposes,
+// such as the daemon's secret key and database.
+var ErrPathDenied = errors.New("permission denied: path is protected")
+
 // FSNode represents a filesystem entry with permissions
 type FSNode struct {
 	Path      string    `json:"path"`
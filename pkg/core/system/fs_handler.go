@@ -5,6 +5,7 @@ package system
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -61,6 +62,9 @@ func (h *FSHandler) HandleList(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Error("failed to list directory", "error", err, "path", path)
 		e := shared.Errors.Resource.NotFound
+		if errors.Is(err, ErrPathDenied) {
+			e = shared.Errors.Auth.Forbidden
+		}
 		shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
 		return
 	}
@@ -94,6 +98,9 @@ func (h *FSHandler) HandleRead(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Error("failed to read file", "error", err, "path", path)
 		e := shared.Errors.Resource.NotFound
+		if errors.Is(err, ErrPathDenied) {
+			e = shared.Errors.Auth.Forbidden
+		}
 		shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
 		return
 	}
@@ -264,6 +271,9 @@ func (h *FSHandler) HandleWatch(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Error("failed to watch directory", "error", err, "path", req.Path)
 		e := shared.Errors.Runtime.InternalServer
+		if errors.Is(err, ErrPathDenied) {
+			e = shared.Errors.Auth.Forbidden
+		}
 		shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
 		return
 	}
@@ -233,21 +233,44 @@ type HelloAckV1 struct {
 // Mode represents the current mode.
 // "ready"   – normal operation, syncer active.
 // "updating" – in the middle of an update/installation cycle.
+// "draining" – shutting down; no new tasks are taken while in-flight work
+// finishes.
+// "maintenance" – set by an operator; deployments are held in the queue and
+// the proxy serves a maintenance page.
 type Mode string
 
 const (
-	ModeReady    Mode = "ready"
-	ModeUpdating Mode = "updating"
+	ModeReady       Mode = "ready"
+	ModeUpdating    Mode = "updating"
+	ModeDraining    Mode = "draining"
+	ModeMaintenance Mode = "maintenance"
 )
 
 // ModeStatus describes the current daemon mode.
 type ModeStatus struct {
-	Mode Mode `json:"mode"`
+	Mode        Mode               `json:"mode"`
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"` // set in maintenance mode
+	Shutdown    *ShutdownStatus    `json:"shutdown,omitempty"`    // set while draining
+}
+
+// MaintenanceStatus describes the maintenance window in effect.
+type MaintenanceStatus struct {
+	Domains []string  `json:"domains,omitempty"` // empty when every domain is covered
+	Since   time.Time `json:"since"`
+}
+
+// ShutdownStatus reports how far a graceful shutdown has got.
+type ShutdownStatus struct {
+	Step     string    `json:"step"`           // step in progress
+	Done     []string  `json:"done,omitempty"` // steps finished, in order
+	Deadline time.Time `json:"deadline"`       // when in-flight work is abandoned
 }
 
-// SetModeRequest is used by the node to change the daemon mode.
+// SetModeRequest is used by the node to change the daemon mode. Domains
+// limits maintenance mode's page to those domains; empty covers them all.
 type SetModeRequest struct {
-	Mode Mode `json:"mode"`
+	Mode    Mode     `json:"mode"`
+	Domains []string `json:"domains,omitempty"`
 }
 
 // UpdateBootstrapTokenRequest is used to bootstrap token
@@ -259,6 +259,7 @@ type DiagnosticsInfo struct {
 	Tasks     TasksDiag     `json:"tasks"`
 	Auth      AuthDiag      `json:"auth"`
 	Worker    *WorkerDiag   `json:"worker,omitempty"`
+	Builds    *BuildsDiag   `json:"builds,omitempty"` // build nodes only
 	Cert      *CertDiag     `json:"cert,omitempty"`
 }
 
@@ -289,6 +290,20 @@ type WorkerDiag struct {
 	ActiveJobs    int `json:"active_jobs"`
 }
 
+type BuildsDiag struct {
+	Slots   int               `json:"slots"`
+	Running int               `json:"running"`
+	Queued  []QueuedBuildDiag `json:"queued"` // in the order they will run
+}
+
+type QueuedBuildDiag struct {
+	Service   string `json:"service"`
+	ClusterID string `json:"cluster_id,omitempty"`
+	Commit    string `json:"commit,omitempty"`
+	Position  int    `json:"position"`
+	WaitMs    int64  `json:"wait_ms"`
+}
+
 type CertDiag struct {
 	NotAfter      string `json:"not_after"`
 	DaysRemaining int    `json:"days_remaining"`
@@ -4,5 +4,7 @@
 // Package terminal provides WebSocket-based interactive terminal sessions.
 //
 // Sessions are created via task execution and communicate bidirectionally with clients
-// through a relay endpoint, using PTY for shell interaction.
+// through a relay endpoint, using PTY for shell interaction. Sessions can be
+// recorded in asciicast v2 format; recordings are listed and fetched through
+// the Handler.
 package terminal
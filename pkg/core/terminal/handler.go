@@ -0,0 +1,77 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package terminal
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+type Handler struct {
+	api    HandleRecordings
+	logger *shared.Logger
+}
+
+func NewHandler(api HandleRecordings, logger *shared.Logger) *Handler {
+	return &Handler{api: api, logger: logger}
+}
+
+func (h *Handler) ListRecordings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("terminal.list_recordings request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	recordings, err := h.api.ListRecordings(ctx)
+	if err != nil {
+		h.logger.Error("failed to list terminal recordings", "error", err)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+	if recordings == nil {
+		recordings = []Recording{}
+	}
+
+	shared.WriteJSON(w, http.StatusOK, recordings)
+}
+
+func (h *Handler) GetRecording(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("terminal.get_recording request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodGet {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		e := shared.Errors.Request.MissingParams
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, map[string]any{"param": "id"})
+		return
+	}
+
+	rec, err := h.api.GetRecording(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrRecordingNotFound) {
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), map[string]any{"resource": "recording", "id": id})
+			return
+		}
+		h.logger.Error("failed to read terminal recording", "error", err, "id", id)
+		e := shared.Errors.Runtime.InternalServer
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, rec)
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package terminal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRecordingNotFound is returned when no recording has the requested ID.
+var ErrRecordingNotFound = errors.New("recording not found")
+
+// Recording describes a recorded terminal session. The session itself is
+// kept as an asciicast v2 file alongside this metadata.
+type Recording struct {
+	ID        string     `json:"id"` // the session ID
+	Subject   string     `json:"subject"`
+	Service   string     `json:"service,omitempty"` // empty for a host shell
+	Cols      uint16     `json:"cols"`
+	Rows      uint16     `json:"rows"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // nil while the session is open
+	Bytes     int64      `json:"bytes"`
+	// Truncated is set when the session outgrew the per-recording limit and
+	// later output was dropped.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RecordingCast is a recording together with its asciicast v2 content.
+type RecordingCast struct {
+	Recording
+	Cast string `json:"cast"`
+}
+
+type HandleRecordings interface {
+	// ListRecordings returns the stored recordings, newest first.
+	ListRecordings(ctx context.Context) ([]Recording, error)
+	// GetRecording returns one recording and its asciicast content.
+	GetRecording(ctx context.Context, id string) (*RecordingCast, error)
+}
@@ -5,6 +5,7 @@ package terminal
 
 import (
 	"context"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -31,10 +32,22 @@ type Message struct {
 	Error  string        `json:"error,omitempty"`
 }
 
+// Stream is the terminal of a session that does not run on a host PTY, such
+// as an exec session in a service container.
+type Stream interface {
+	io.ReadWriteCloser
+	Resize(cols, rows uint16) error
+}
+
+// Session is an open terminal. Host sessions run a shell on PTY; sessions
+// targeting a service run inside its container and talk through Exec.
 type Session struct {
 	ID           string
+	Service      string // empty for a host shell
+	Shell        string
 	PTY          *os.File
 	Process      *os.Process
+	Exec         Stream
 	Cols         uint16
 	Rows         uint16
 	CreatedAt    time.Time
@@ -48,11 +61,30 @@ func (s *Session) UpdateActivity() {
 	s.LastActivity = time.Now()
 }
 
+// Read reads terminal output.
+func (s *Session) Read(p []byte) (int, error) {
+	if s.Exec != nil {
+		return s.Exec.Read(p)
+	}
+	return s.PTY.Read(p)
+}
+
+// Write sends input to the terminal.
+func (s *Session) Write(p []byte) (int, error) {
+	if s.Exec != nil {
+		return s.Exec.Write(p)
+	}
+	return s.PTY.Write(p)
+}
+
 func (s *Session) Resize(cols, rows uint16) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.Cols = cols
 	s.Rows = rows
+	if s.Exec != nil {
+		return s.Exec.Resize(cols, rows)
+	}
 	return pty.Setsize(s.PTY, &pty.Winsize{
 		Rows: rows,
 		Cols: cols,
@@ -63,6 +95,10 @@ func (s *Session) Cleanup() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.Exec != nil {
+		s.Exec.Close()
+	}
+
 	if s.Process != nil {
 		s.Process.Kill()
 		s.Process.Wait()
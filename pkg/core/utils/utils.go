@@ -166,8 +166,21 @@ func GetDataDir() string {
 	}
 }
 
-// ComputeHostPort returns the host port that docker.sh assigns to a container.
-// Matches the get_host_port function in docker.sh exactly.
+// GetServiceLogDir returns the directory service runtimes write their output
+// to. The deploy script runs as the dployrd user, so on Unix this lives under
+// its home rather than the data dir.
+func GetServiceLogDir() string {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("PROGRAMDATA"), "dployr", ".dployr", "logs")
+	default:
+		return "/home/dployrd/.dployr/logs"
+	}
+}
+
+// ComputeHostPort returns the hashed host port of a container. The port
+// allocator in internal/ports starts its search here, so a container keeps
+// this port unless another one holds it.
 func ComputeHostPort(containerName string) int {
 	h := md5.Sum([]byte(containerName))
 	hashDec := uint64(h[0])<<24 | uint64(h[1])<<16 | uint64(h[2])<<8 | uint64(h[3])
@@ -175,6 +188,61 @@ func ComputeHostPort(containerName string) int {
 	return int(hashDec%portRange) + 61000
 }
 
+// ServiceLabel is the Docker label that names the service a container runs.
+const ServiceLabel = "dployr.service"
+
+// RunLabel is the Docker label that names the service a one-off run
+// container belongs to. Runs carry it instead of ServiceLabel so they are
+// neither collected into the service log nor mistaken for a replica.
+const RunLabel = "dployr.run"
+
+// ReplicaName returns the container name of replica i of a service. The first
+// replica keeps the bare name, so a single-container service is unchanged.
+func ReplicaName(containerName string, i int) string {
+	if i == 0 {
+		return containerName
+	}
+	return fmt.Sprintf("%s-r%d", containerName, i)
+}
+
+// AlternateHostPort returns the host port a blue/green redeploy should bind the
+// replacement container to while the live one still holds current. It flips
+// between the service's primary port and a secondary slot so two consecutive
+// redeploys never collide.
+func AlternateHostPort(containerName string, current int) int {
+	primary := ComputeHostPort(containerName)
+	if current != primary {
+		return primary
+	}
+	alt := ComputeHostPort(containerName + "-next")
+	if alt == primary {
+		alt = (alt-61000+1)%(64999-61000+1) + 61000
+	}
+	return alt
+}
+
+// NormaliseHealthPath returns a canonical absolute path from raw:
+//   - ""           → "/"
+//   - "foo/bar"    → "/foo/bar"   (A: missing leading slash — added)
+//   - "/foo/bar/"  → "/foo/bar"   (C: trailing slash — stripped, except bare "/")
+//
+// Returns an error if raw looks like a full URL (contains "://").
+func NormaliseHealthPath(raw string) (string, error) {
+	if strings.Contains(raw, "://") {
+		return "", fmt.Errorf("health check path must be a path, not a full URL: %q", raw)
+	}
+	if raw == "" {
+		return "/", nil
+	}
+	if !strings.HasPrefix(raw, "/") {
+		raw = "/" + raw
+	}
+	if len(raw) > 1 {
+		raw = strings.TrimRight(raw, "/")
+	}
+	return raw, nil
+}
+
 // FormatName converts a string to a lowercase URL-safe slug (e.g., "My App v2.0 (Beta)" -> "my-app-v2-0-beta").
 func FormatName(s string) string {
 	s = strings.ToLower(strings.TrimSpace(s))
@@ -62,6 +62,21 @@ func TestComputeHostPort_KnownValue(t *testing.T) {
 	}
 }
 
+func TestAlternateHostPort_Flips(t *testing.T) {
+	name := "my-service"
+	primary := ComputeHostPort(name)
+	secondary := AlternateHostPort(name, primary)
+	if secondary == primary {
+		t.Fatalf("AlternateHostPort(%q, primary) returned the primary port", name)
+	}
+	if secondary < 61000 || secondary > 64999 {
+		t.Errorf("AlternateHostPort = %d, want [61000, 64999]", secondary)
+	}
+	if got := AlternateHostPort(name, secondary); got != primary {
+		t.Errorf("AlternateHostPort(%q, secondary) = %d, want primary %d", name, got, primary)
+	}
+}
+
 func TestFormatBytes(t *testing.T) {
 	cases := []struct {
 		input uint64
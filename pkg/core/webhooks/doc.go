@@ -0,0 +1,6 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhooks models git push webhooks from GitHub, GitLab and Gitea
+// and provides the HTTP handler build nodes receive them on.
+package webhooks
@@ -0,0 +1,66 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+// maxPayloadBytes matches the largest payload GitHub delivers.
+const maxPayloadBytes = 25 << 20
+
+type Handler struct {
+	api    HandleWebhooks
+	logger *shared.Logger
+}
+
+func NewHandler(api HandleWebhooks, logger *shared.Logger) *Handler {
+	return &Handler{api: api, logger: logger}
+}
+
+// HandlePush takes a webhook delivery. It authenticates the request by its
+// signature rather than a bearer token, since git hosts cannot log in.
+func (h *Handler) HandlePush(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.logger.Info("webhooks.push request", "method", r.Method, "path", r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		e := shared.Errors.Request.MethodNotAllowed
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+	if err != nil {
+		e := shared.Errors.Request.BadRequest
+		shared.WriteError(w, e.HTTPStatus, string(e.Code), "failed to read request body", nil)
+		return
+	}
+
+	d, err := h.api.Receive(ctx, r.Header, body)
+	if err != nil {
+		h.logger.Warn("webhook delivery refused", "error", err)
+		switch {
+		case errors.Is(err, ErrDisabled):
+			e := shared.Errors.Resource.NotFound
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+		case errors.Is(err, ErrInvalidSignature):
+			e := shared.Errors.Auth.Unauthorized
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+		case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrInvalidPayload):
+			e := shared.Errors.Request.BadRequest
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), err.Error(), nil)
+		default:
+			e := shared.Errors.Runtime.InternalServer
+			shared.WriteError(w, e.HTTPStatus, string(e.Code), e.Message, nil)
+		}
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusAccepted, d)
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dployr-io/dployr/pkg/shared"
+)
+
+type stubWebhooks struct {
+	err error
+}
+
+func (s *stubWebhooks) Receive(ctx context.Context, header http.Header, body []byte) (*Delivery, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Delivery{Provider: ProviderGitHub, Event: "push", Services: []string{"shop"}}, nil
+}
+
+func TestHandlePush_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		want   int
+	}{
+		{"scheduled", http.MethodPost, nil, http.StatusAccepted},
+		{"wrong method", http.MethodGet, nil, http.StatusMethodNotAllowed},
+		{"disabled", http.MethodPost, ErrDisabled, http.StatusNotFound},
+		{"bad signature", http.MethodPost, ErrInvalidSignature, http.StatusUnauthorized},
+		{"unknown provider", http.MethodPost, ErrUnknownProvider, http.StatusBadRequest},
+		{"bad payload", http.MethodPost, fmt.Errorf("%w: unexpected EOF", ErrInvalidPayload), http.StatusBadRequest},
+		{"other failure", http.MethodPost, fmt.Errorf("database locked"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/webhooks/git", strings.NewReader(`{}`))
+			rr := httptest.NewRecorder()
+			NewHandler(&stubWebhooks{err: tt.err}, shared.NewLogger()).HandlePush(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrDisabled is returned when the node does not take webhooks: it is not a
+// build node or has no WEBHOOK_SECRET.
+var ErrDisabled = errors.New("webhooks are not enabled on this node")
+
+// ErrUnknownProvider is returned when a request carries none of the headers
+// GitHub, GitLab or Gitea send.
+var ErrUnknownProvider = errors.New("unrecognised webhook provider")
+
+// ErrInvalidSignature is returned when a request's signature or token does
+// not match the webhook secret.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrInvalidPayload is returned when a push event's body cannot be parsed.
+var ErrInvalidPayload = errors.New("invalid webhook payload")
+
+// Provider is the git host a webhook came from.
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+	ProviderGitea  Provider = "gitea"
+)
+
+type HandleWebhooks interface {
+	// Receive verifies a delivery and, for a push, schedules builds of the
+	// pushed commit for every service that tracks the branch and opted in.
+	Receive(ctx context.Context, header http.Header, body []byte) (*Delivery, error)
+}
+
+// Push is the part of a push event that decides what gets built. Repo holds
+// every URL the provider gave for the repository.
+type Push struct {
+	Repo    []string
+	Branch  string
+	Commit  string
+	Deleted bool
+}
+
+// Delivery reports what a webhook delivery did. Services lists the services
+// a build was scheduled for; Ignored says why none was, when none was.
+type Delivery struct {
+	Provider Provider `json:"provider"`
+	Event    string   `json:"event"`
+	Branch   string   `json:"branch,omitempty"`
+	Commit   string   `json:"commit,omitempty"`
+	Services []string `json:"services"`
+	Ignored  string   `json:"ignored,omitempty"`
+}
@@ -37,8 +37,9 @@ type Config struct {
 	RegistryURL  string
 	RegistryAuth string
 
-	BuildSlots  int
-	BuildMemory int
+	BuildSlots     int // concurrent builds; further builds queue, taking turns by cluster
+	BuildMemory    int
+	BuildCacheSize int // MB of built images kept as layer cache; 0 removes each image after push
 
 	ContainerMemory  int
 	ContainerCPU     int
@@ -55,6 +56,23 @@ type Config struct {
 	LogMaxFileReadBytes  int64
 	LogMaxStreams        int
 	LogEntryJSONOverhead int64
+	LogRotateBytes       int64 // size at which a collected service log is rotated
+	LogRotateBackups     int   // rotated service logs kept alongside the live one
+
+	TerminalRecord             bool          // record terminal sessions as asciicast files
+	TerminalRecordInput        bool          // also record keystrokes, which may include passwords typed at prompts
+	TerminalRecordingRetention time.Duration // age after which a finished recording is removed
+	TerminalRecordingMaxBytes  int64         // total size recordings are pruned to, oldest first
+
+	RunTimeout    time.Duration // limit on a one-off run that does not ask for one
+	RunMaxTimeout time.Duration // longest limit a one-off run may ask for
+
+	ShutdownGracePeriod time.Duration // time in-flight work gets to finish on shutdown
+
+	MaintenancePage string // HTML file served in maintenance mode; empty uses the built-in page
+
+	WebhookSecret   string        // shared secret git push webhooks are signed with; empty disables them
+	WebhookDebounce time.Duration // quiet period after a push before its build is queued
 }
 
 func LoadConfig() (*Config, error) {
@@ -76,11 +94,12 @@ func LoadConfig() (*Config, error) {
 		WSMaxMessageSize: getEnvAsInt64("WS_MAX_MESSAGE_SIZE", 10*1024*1024),
 		TaskDedupTTL:     getEnvAsPositiveDuration("TASK_DEDUP_TTL", 5*time.Minute),
 
-		Role:         store.NodeRole(getEnv("NODE_ROLE", string(store.NodeRoleInstance))),
-		RegistryURL:  getEnv("REGISTRY_URL", ""),
-		RegistryAuth: getEnv("REGISTRY_AUTH", ""),
-		BuildSlots:   getEnvAsInt("BUILD_SLOTS", 4),
-		BuildMemory:  getEnvAsInt("BUILD_MEMORY", 1536),
+		Role:           store.NodeRole(getEnv("NODE_ROLE", string(store.NodeRoleInstance))),
+		RegistryURL:    getEnv("REGISTRY_URL", ""),
+		RegistryAuth:   getEnv("REGISTRY_AUTH", ""),
+		BuildSlots:     getEnvAsInt("BUILD_SLOTS", 4),
+		BuildMemory:    getEnvAsInt("BUILD_MEMORY", 1536),
+		BuildCacheSize: getEnvAsInt("BUILD_CACHE_SIZE", 10240),
 
 		ContainerMemory:  getEnvAsInt("CONTAINER_MEMORY", 0),
 		ContainerCPU:     getEnvAsInt("CONTAINER_CPU", 0),
@@ -97,6 +116,23 @@ func LoadConfig() (*Config, error) {
 		LogMaxFileReadBytes:  getEnvAsInt64("LOG_MAX_FILE_READ_BYTES", 100*1024*1024),
 		LogMaxStreams:        getEnvAsInt("LOG_MAX_STREAMS", 100),
 		LogEntryJSONOverhead: getEnvAsInt64("LOG_ENTRY_JSON_OVERHEAD", 200),
+		LogRotateBytes:       getEnvAsInt64("LOG_ROTATE_BYTES", 50*1024*1024),
+		LogRotateBackups:     getEnvAsInt("LOG_ROTATE_BACKUPS", 3),
+
+		TerminalRecord:             getEnvAsBool("TERMINAL_RECORD", false),
+		TerminalRecordInput:        getEnvAsBool("TERMINAL_RECORD_INPUT", false),
+		TerminalRecordingRetention: getEnvAsPositiveDuration("TERMINAL_RECORDING_RETENTION", 30*24*time.Hour),
+		TerminalRecordingMaxBytes:  getEnvAsInt64("TERMINAL_RECORDING_MAX_BYTES", 1<<30),
+
+		RunTimeout:    getEnvAsPositiveDuration("RUN_TIMEOUT", 30*time.Minute),
+		RunMaxTimeout: getEnvAsPositiveDuration("RUN_MAX_TIMEOUT", 24*time.Hour),
+
+		ShutdownGracePeriod: getEnvAsPositiveDuration("SHUTDOWN_GRACE_PERIOD", 60*time.Second),
+
+		MaintenancePage: getEnv("MAINTENANCE_PAGE", ""),
+
+		WebhookSecret:   getEnv("WEBHOOK_SECRET", ""),
+		WebhookDebounce: getEnvAsPositiveDuration("WEBHOOK_DEBOUNCE", 10*time.Second),
 	}, nil
 }
 
@@ -184,6 +220,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsInt64(key string, defaultValue int64) int64 {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
@@ -18,6 +18,7 @@ const (
 	CtxUserIDKey    ContextKey = "user_id"
 	CtxRequestIDKey ContextKey = "request_id"
 	CtxTraceIDKey   ContextKey = "trace_id"
+	CtxTaskIDKey    ContextKey = "task_id"
 )
 
 func WithUser(ctx context.Context, id string) context.Context {
@@ -81,6 +82,19 @@ func TraceID(ctx context.Context) string {
 	return ""
 }
 
+// WithTask marks ctx as serving the base task with the given ID.
+func WithTask(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, CtxTaskIDKey, id)
+}
+
+// TaskID returns the ID of the task ctx serves, or "" for direct API calls.
+func TaskID(ctx context.Context) string {
+	if v, ok := ctx.Value(CtxTaskIDKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
 func EnrichContext(ctx context.Context) context.Context {
 	if _, ok := ctx.Value(CtxRequestIDKey).(string); !ok {
 		ctx = WithRequest(ctx, ulid.Make().String())
@@ -30,9 +30,11 @@ const (
 
 	// Resource errors
 	ErrorResourceNotFound ErrorCode = "resource.not_found"
+	ErrorResourceConflict ErrorCode = "resource.conflict"
 
 	// Runtime / internal errors
 	ErrorRuntimeInternalServer      ErrorCode = "runtime.internal_server_error"
+	ErrorRuntimeUnavailable         ErrorCode = "runtime.unavailable"
 	ErrorInstanceRegistrationFailed ErrorCode = "instance.registration_failed"
 )
 
@@ -50,12 +52,14 @@ var Errors = struct {
 	}
 	Resource struct {
 		NotFound ErrorDescriptor
+		Conflict ErrorDescriptor
 	}
 	Instance struct {
 		RegistrationFailed ErrorDescriptor
 	}
 	Runtime struct {
 		InternalServer ErrorDescriptor
+		Unavailable    ErrorDescriptor
 	}
 }{
 	Request: struct {
@@ -76,13 +80,17 @@ var Errors = struct {
 	},
 	Resource: struct {
 		NotFound ErrorDescriptor
+		Conflict ErrorDescriptor
 	}{
 		NotFound: ErrorDescriptor{Code: ErrorResourceNotFound, HTTPStatus: http.StatusNotFound, Message: "Resource not found"},
+		Conflict: ErrorDescriptor{Code: ErrorResourceConflict, HTTPStatus: http.StatusConflict, Message: "Resource conflict"},
 	},
 	Runtime: struct {
 		InternalServer ErrorDescriptor
+		Unavailable    ErrorDescriptor
 	}{
 		InternalServer: ErrorDescriptor{Code: ErrorRuntimeInternalServer, HTTPStatus: http.StatusInternalServerError, Message: "Internal server error"},
+		Unavailable:    ErrorDescriptor{Code: ErrorRuntimeUnavailable, HTTPStatus: http.StatusServiceUnavailable, Message: "Service temporarily unavailable"},
 	},
 	Instance: struct {
 		RegistrationFailed ErrorDescriptor
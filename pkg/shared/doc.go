@@ -6,6 +6,11 @@
 // duplication in other packages, including config for sync intervals and
 // task deduplication TTLs.
 //
+// Everything the Logger writes passes through the redaction helpers in
+// redact.go, which also cover error details and workload snapshots: values
+// under sensitive keys (tokens, secrets, passwords, registry auth) and
+// credentials embedded in strings never reach app.log or base.
+//
 // Config fields related to container builds:
 //
 //   - RegistryURL:  container registry to push images to
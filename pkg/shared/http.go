@@ -26,9 +26,9 @@ func WriteError(w http.ResponseWriter, status int, code, message string, details
 	case nil:
 		// leave d as nil
 	case error:
-		d = v.Error()
+		d = RedactString(v.Error())
 	default:
-		d = v
+		d = Redact(v)
 	}
 	WriteJSON(w, status, ErrorResponse{Error: message, Code: code, Details: d})
 }
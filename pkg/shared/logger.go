@@ -175,18 +175,37 @@ func NewLogger() *Logger {
 			writers = append(writers, os.Stderr)
 		}
 
-		multi := io.MultiWriter(writers...)
-		handler := slog.NewJSONHandler(multi, &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		})
-
-		defaultLogger = &Logger{
-			logger: slog.New(handler),
-		}
+		defaultLogger = NewWriterLogger(io.MultiWriter(writers...))
 	})
 	return defaultLogger
 }
 
+// NewWriterLogger creates a logger that writes structured JSON to w instead of
+// the shared log file. Sensitive values are redacted before they are written,
+// exactly as for NewLogger.
+func NewWriterLogger(w io.Writer) *Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: redactAttr,
+	})
+	return &Logger{logger: slog.New(handler)}
+}
+
+// redactAttr keeps secrets out of every log line: values of sensitive keys
+// are dropped and everything else is scrubbed for embedded credentials.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactString(a.Value.String()))
+	case slog.KindAny:
+		a.Value = slog.AnyValue(Redact(a.Value.Any()))
+	}
+	return a
+}
+
 func getLogFilePath() string {
 	switch runtime.GOOS {
 	case "windows":
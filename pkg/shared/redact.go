@@ -0,0 +1,230 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package shared
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/dployr-io/dployr/pkg/store"
+)
+
+// Redacted replaces sensitive values in logs, error details and snapshots
+// sent to base. It matches the marker used for secrets on service reads.
+const Redacted = store.RedactedSecret
+
+// sensitiveWords mark a key as sensitive when they appear as one of its words,
+// so "token" and "DB_PASSWORD" match but "tokenizer" does not.
+var sensitiveWords = map[string]bool{
+	"token":         true,
+	"secret":        true,
+	"secrets":       true,
+	"password":      true,
+	"passwd":        true,
+	"passphrase":    true,
+	"authorization": true,
+	"bearer":        true,
+	"credential":    true,
+	"credentials":   true,
+	"apikey":        true,
+	"cookie":        true,
+}
+
+// sensitivePairs mark a key as sensitive when two adjacent words form them.
+var sensitivePairs = map[string]bool{
+	"api_key":       true,
+	"access_key":    true,
+	"private_key":   true,
+	"secret_key":    true,
+	"registry_auth": true,
+	"auth_token":    true,
+	"client_secret": true,
+}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9._~+/=-]+`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	urlPassword   = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]*://[^:/\s@]*):[^@\s/]+@`)
+	jsonPair      = regexp.MustCompile(`"([^"\\]+)"\s*:\s*"(?:[^"\\]|\\.)*"`)
+	assignment    = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_.-]*)=("[^"]*"|'[^']*'|[^\s&,;]+)`)
+)
+
+// IsSensitiveKey reports whether a field, header or environment variable
+// named key holds a value that must not be logged or synced. Keys are split
+// into words on case changes and on _ - . so every naming style matches.
+func IsSensitiveKey(key string) bool {
+	words := keyWords(key)
+	for i, w := range words {
+		if sensitiveWords[w] {
+			return true
+		}
+		if i > 0 && sensitivePairs[words[i-1]+"_"+w] {
+			return true
+		}
+	}
+	return false
+}
+
+func keyWords(key string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || r == ' ':
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// Redact returns a copy of v with the values of sensitive keys replaced,
+// descending into maps and slices. A sensitive map such as "secrets" keeps
+// its keys so the output still shows what was set. Strings are scrubbed with
+// RedactString; other values are redacted through their JSON form.
+func Redact(v any) any {
+	switch t := v.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64, json.Number:
+		return v
+	case string:
+		return RedactString(t)
+	case error:
+		return RedactString(t.Error())
+	case []byte:
+		return string(RedactJSON(t))
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			if IsSensitiveKey(k) {
+				out[k] = redactAll(val)
+			} else {
+				out[k] = Redact(val)
+			}
+		}
+		return out
+	case map[string]string:
+		return RedactEnv(t)
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = Redact(val)
+		}
+		return out
+	case []string:
+		out := make([]string, len(t))
+		for i, s := range t {
+			out[i] = RedactString(s)
+		}
+		return out
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var generic any
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return v
+		}
+		return Redact(generic)
+	}
+}
+
+// redactAll replaces every value under a sensitive key, keeping map keys.
+func redactAll(v any) any {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k := range t {
+			out[k] = Redacted
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(t))
+		for k := range t {
+			out[k] = Redacted
+		}
+		return out
+	default:
+		return Redacted
+	}
+}
+
+// RedactEnv returns a copy of env with the values of sensitive variables
+// replaced and credentials scrubbed from the rest, such as the password in a
+// DATABASE_URL.
+func RedactEnv(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		if IsSensitiveKey(k) {
+			out[k] = Redacted
+		} else {
+			out[k] = RedactString(v)
+		}
+	}
+	return out
+}
+
+// RedactJSON redacts a JSON document. Input that does not parse is replaced
+// entirely, since there is no telling what it holds.
+func RedactJSON(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return []byte(Redacted)
+	}
+	out, err := json.Marshal(Redact(v))
+	if err != nil {
+		return []byte(Redacted)
+	}
+	return out
+}
+
+// RedactString scrubs credentials embedded in free text: bearer tokens, JWTs,
+// URL passwords and sensitive "key": "value" or KEY=value pairs. A string that is itself a
+// JSON object or array is redacted structurally.
+func RedactString(s string) string {
+	if trimmed := strings.TrimSpace(s); len(trimmed) > 1 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return string(RedactJSON([]byte(trimmed)))
+	}
+
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Redacted)
+	s = jwtPattern.ReplaceAllString(s, Redacted)
+	s = urlPassword.ReplaceAllString(s, "$1:"+Redacted+"@")
+	s = jsonPair.ReplaceAllStringFunc(s, func(m string) string {
+		key := jsonPair.FindStringSubmatch(m)[1]
+		if !IsSensitiveKey(key) {
+			return m
+		}
+		return `"` + key + `":"` + Redacted + `"`
+	})
+	s = assignment.ReplaceAllStringFunc(s, func(m string) string {
+		key, _, _ := strings.Cut(m, "=")
+		if !IsSensitiveKey(key) {
+			return m
+		}
+		return key + "=" + Redacted
+	})
+	return s
+}
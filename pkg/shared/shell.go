@@ -39,6 +39,7 @@ func ExecWithOptions(ctx context.Context, cmd string, workDir string, useVfox bo
 	}
 
 	c := exec.CommandContext(ctx, shell, shellFlag, full)
+	KillGroupOnCancel(c)
 	var stderr bytes.Buffer
 	c.Stdout = os.Stdout
 	c.Stderr = &stderr
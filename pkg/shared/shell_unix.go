@@ -0,0 +1,21 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package shared
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// KillGroupOnCancel starts c in its own process group and makes context
+// cancellation kill the whole group. Without it only the shell dies and
+// children such as git or docker keep running.
+func KillGroupOnCancel(c *exec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error {
+		return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package shared
+
+import "os/exec"
+
+// KillGroupOnCancel is a no-op on Windows; exec.CommandContext already kills
+// the process on cancellation.
+func KillGroupOnCancel(c *exec.Cmd) {}
@@ -0,0 +1,85 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditOutcome string
+
+const (
+	AuditSucceeded AuditOutcome = "succeeded"
+	AuditDenied    AuditOutcome = "denied" // rejected by a role or scope check
+	AuditFailed    AuditOutcome = "failed"
+)
+
+// AuditEntry records one privileged action taken on the node. Entries form a
+// hash chain: each Hash covers the entry's fields and the previous entry's
+// Hash, so editing or removing a row breaks every hash after it.
+type AuditEntry struct {
+	Seq        int64        `json:"seq" db:"seq"`
+	Time       time.Time    `json:"time" db:"time"`
+	Subject    string       `json:"subject" db:"subject"` // token subject of the caller
+	Role       string       `json:"role,omitempty" db:"role"`
+	TaskID     string       `json:"task_id,omitempty" db:"task_id"`
+	Method     string       `json:"method" db:"method"`
+	Route      string       `json:"route" db:"route"`
+	Status     int          `json:"status" db:"status"`
+	Outcome    AuditOutcome `json:"outcome" db:"outcome"`
+	ArgsDigest string       `json:"args_digest" db:"args_digest"` // sha256 of the request's query and body
+	PrevHash   string       `json:"prev_hash" db:"prev_hash"`
+	Hash       string       `json:"hash" db:"hash"`
+}
+
+// ComputeHash returns the chain hash of e given its PrevHash. Time is hashed
+// at the microsecond precision it is stored with.
+func (e *AuditEntry) ComputeHash() string {
+	fields := []string{
+		strconv.FormatInt(e.Seq, 10),
+		strconv.FormatInt(e.Time.UnixMicro(), 10),
+		e.Subject,
+		e.Role,
+		e.TaskID,
+		e.Method,
+		e.Route,
+		strconv.Itoa(e.Status),
+		string(e.Outcome),
+		e.ArgsDigest,
+		e.PrevHash,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain checks that entries, in sequence order, link up and that
+// each hash matches its contents. It returns the Seq of the first entry that
+// does not, or 0 when the chain is intact. The first entry is trusted to link
+// to whatever preceded it, so a window of the log can be verified on its own.
+func VerifyAuditChain(entries []*AuditEntry) int64 {
+	for i, e := range entries {
+		if i > 0 && (e.PrevHash != entries[i-1].Hash || e.Seq != entries[i-1].Seq+1) {
+			return e.Seq
+		}
+		if e.ComputeHash() != e.Hash {
+			return e.Seq
+		}
+	}
+	return 0
+}
+
+type AuditStore interface {
+	// AppendAudit assigns e its Seq, PrevHash and Hash and stores it.
+	AppendAudit(ctx context.Context, e *AuditEntry) error
+	// ListAudit returns entries recorded at or after since, oldest first.
+	ListAudit(ctx context.Context, since time.Time, limit int) ([]*AuditEntry, error)
+	// VerifyAudit walks the whole chain and returns the Seq of the first
+	// entry that fails verification, or 0 when it is intact.
+	VerifyAudit(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func auditChain(n int) []*AuditEntry {
+	var entries []*AuditEntry
+	prev := ""
+	for i := 1; i <= n; i++ {
+		e := &AuditEntry{
+			Seq:      int64(i),
+			Time:     time.UnixMicro(1750000000000000 + int64(i)),
+			Subject:  "alice",
+			Method:   "POST",
+			Route:    "/system/reboot",
+			Status:   202,
+			Outcome:  AuditSucceeded,
+			PrevHash: prev,
+		}
+		e.Hash = e.ComputeHash()
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	if got := VerifyAuditChain(auditChain(4)); got != 0 {
+		t.Errorf("intact chain broken at %d", got)
+	}
+
+	edited := auditChain(4)
+	edited[2].Subject = "mallory"
+	if got := VerifyAuditChain(edited); got != 3 {
+		t.Errorf("edited entry: broken at %d, want 3", got)
+	}
+
+	removed := auditChain(4)
+	removed = append(removed[:1], removed[2:]...)
+	if got := VerifyAuditChain(removed); got != 3 {
+		t.Errorf("removed entry: broken at %d, want 3", got)
+	}
+
+	// Rehashing an edited entry does not help: the next entry still links to
+	// the original hash.
+	rehashed := auditChain(4)
+	rehashed[1].Outcome = AuditDenied
+	rehashed[1].Hash = rehashed[1].ComputeHash()
+	if got := VerifyAuditChain(rehashed); got != 3 {
+		t.Errorf("rehashed entry: broken at %d, want 3", got)
+	}
+}
@@ -15,6 +15,7 @@ const (
 	StatusInProgress Status = "in_progress"
 	StatusFailed     Status = "failed"
 	StatusCompleted  Status = "completed"
+	StatusCancelled  Status = "cancelled"
 )
 
 type Source string
@@ -34,8 +35,51 @@ type RemoteObj struct {
 	Branch     string `json:"branch" db:"branch"`
 	CommitHash string `json:"commit_hash" db:"commit_hash"`
 	Token      string `json:"token,omitempty" db:"-"`
+	Submodules bool   `json:"submodules,omitempty" db:"submodules"` // fetch submodules recursively
+	LFS        bool   `json:"lfs,omitempty" db:"lfs"`               // pull Git LFS objects
 }
 
+// TLSMode selects how a custom domain gets its certificate.
+type TLSMode string
+
+const (
+	TLSAcme     TLSMode = "acme"     // public certificate issued by an ACME CA
+	TLSInternal TLSMode = "internal" // certificate from Caddy's local CA
+	TLSCustom   TLSMode = "custom"   // user-supplied cert/key pair on the node
+)
+
+// Domain is a host name a service answers on besides its default
+// <name>.dployr.run address. CertFile and KeyFile are paths on the node and
+// are only used with TLSCustom.
+type Domain struct {
+	Host     string  `json:"host"`
+	TLS      TLSMode `json:"tls,omitempty"`
+	CertFile string  `json:"cert_file,omitempty"`
+	KeyFile  string  `json:"key_file,omitempty"`
+}
+
+// MaxReplicas bounds how many containers one service may run.
+const MaxReplicas = 10
+
+// Resources are the limits applied to each container of a service. A zero
+// field falls back to the node-wide CONTAINER_* default, if any.
+type Resources struct {
+	MemoryMB  int `json:"memory_mb,omitempty"`
+	CPU       int `json:"cpu_millicores,omitempty"`
+	Pids      int `json:"pids,omitempty"`
+	StorageGB int `json:"storage_gb,omitempty"`
+}
+
+// ConcurrencyPolicy decides what a scheduled job does when its next run is
+// due while an earlier run is still going.
+type ConcurrencyPolicy string
+
+const (
+	ConcurrencyAllow   ConcurrencyPolicy = "allow"   // start the new run alongside the old one
+	ConcurrencyForbid  ConcurrencyPolicy = "forbid"  // skip the new run
+	ConcurrencyReplace ConcurrencyPolicy = "replace" // stop the old run, then start the new one
+)
+
 type Blueprint struct {
 	Name        string            `json:"name" db:"name"`
 	Desc        string            `json:"description" db:"description"`
@@ -55,6 +99,12 @@ type Blueprint struct {
 	ProjectID   *string           `json:"project_id,omitempty" db:"project_id"`
 	HealthCheck string            `json:"health_check,omitempty" db:"health_check"`
 	ClusterID   string            `json:"cluster_id,omitempty" db:"cluster_id"`
+	Domains     []Domain          `json:"domains,omitempty" db:"-"`
+	Replicas    int               `json:"replicas,omitempty" db:"-"` // 0 and 1 both mean a single container
+	Resources   Resources         `json:"resources,omitzero" db:"-"`
+	Schedule    string            `json:"schedule,omitempty" db:"-"` // cron expression; jobs only
+	Concurrency ConcurrencyPolicy `json:"concurrency_policy,omitempty" db:"-"`
+	AutoDeploy  bool              `json:"auto_deploy,omitempty" db:"-"` // redeploy on pushes to Remote.Branch; remote sources only
 }
 
 type Deployment struct {
@@ -68,9 +118,49 @@ type Deployment struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Release is one entry in a service's append-only deployment history.
+// Deployments are keyed by name and overwritten on every redeploy; releases
+// keep each blueprint and its built image so a previous version can be
+// redeployed without rebuilding.
+type Release struct {
+	ID           string    `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Version      int       `json:"version" db:"version"`
+	DeploymentID string    `json:"deployment_id" db:"deployment_id"`
+	Blueprint    Blueprint `json:"config" db:"config"`
+	Image        string    `json:"image,omitempty" db:"image"`
+	CommitHash   string    `json:"commit_hash,omitempty" db:"commit_hash"`
+	Status       Status    `json:"status" db:"status"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
 type DeploymentStore interface {
 	UpsertDeployment(ctx context.Context, d *Deployment) error
 	GetDeployment(ctx context.Context, id string) (*Deployment, error)
+	GetDeploymentByName(ctx context.Context, name string) (*Deployment, error)
 	ListDeployments(ctx context.Context, limit, offset int) ([]*Deployment, error)
 	UpdateDeploymentStatus(ctx context.Context, id string, status string) error
+	// UpdateDeploymentReplicas sets the replica count in a deployment's
+	// blueprint, leaving the rest of the row as it is.
+	UpdateDeploymentReplicas(ctx context.Context, id string, replicas int) error
+	// UpdateDeploymentResources sets the resource limits in a deployment's
+	// blueprint, leaving the rest of the row as it is.
+	UpdateDeploymentResources(ctx context.Context, id string, r Resources) error
+	// RecordRelease appends r to its service's history, assigning ID and Version.
+	RecordRelease(ctx context.Context, r *Release) error
+	UpdateReleaseStatus(ctx context.Context, id string, status string) error
+	// ListReleases returns a service's releases, newest first.
+	ListReleases(ctx context.Context, name string, limit int) ([]*Release, error)
+	// EnqueueDeployment persists id in the worker queue until DequeueDeployment
+	// and reports whether it was added, false when it was already queued.
+	EnqueueDeployment(ctx context.Context, id string) (bool, error)
+	DequeueDeployment(ctx context.Context, id string) error
+	// ListQueuedDeployments returns queued pending deployments, oldest first.
+	ListQueuedDeployments(ctx context.Context) ([]string, error)
+	// FailInterruptedDeployments fails deployments left in_progress by a
+	// previous run and returns their IDs.
+	FailInterruptedDeployments(ctx context.Context) ([]string, error)
+	// OpenSecrets decrypts blueprint secrets as stored, for use at container start.
+	OpenSecrets(secrets map[string]string) (map[string]string, error)
 }
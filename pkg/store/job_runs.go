@@ -0,0 +1,51 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"time"
+)
+
+type JobRunStatus string
+
+const (
+	RunRunning   JobRunStatus = "running"
+	RunSucceeded JobRunStatus = "succeeded"
+	RunFailed    JobRunStatus = "failed"
+	RunSkipped   JobRunStatus = "skipped"   // due while a run was active under the forbid policy
+	RunCancelled JobRunStatus = "cancelled" // stopped by a newer run under the replace policy, or at shutdown
+)
+
+type JobTrigger string
+
+const (
+	TriggerSchedule JobTrigger = "schedule"
+	TriggerManual   JobTrigger = "manual"
+)
+
+// JobRun is one execution of a job service. ExitCode is nil until the run
+// finishes, and stays nil for runs that never started.
+type JobRun struct {
+	ID         string       `json:"id" db:"id"`
+	Name       string       `json:"name" db:"name"`
+	Trigger    JobTrigger   `json:"trigger" db:"trigger"`
+	Status     JobRunStatus `json:"status" db:"status"`
+	ExitCode   *int         `json:"exit_code,omitempty" db:"exit_code"`
+	Output     string       `json:"output,omitempty" db:"output"` // tail of the run's log
+	StartedAt  time.Time    `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty" db:"finished_at"`
+	DurationMs int64        `json:"duration_ms" db:"duration_ms"`
+}
+
+type JobRunStore interface {
+	// CreateRun records a run, assigning its ID when empty.
+	CreateRun(ctx context.Context, r *JobRun) error
+	// FinishRun stores a finished run's status, exit code, output and duration.
+	FinishRun(ctx context.Context, r *JobRun) error
+	// ListRuns returns a job's runs, newest first.
+	ListRuns(ctx context.Context, name string, limit int) ([]*JobRun, error)
+	// FailInterruptedRuns fails runs left running by a previous daemon.
+	FailInterruptedRuns(ctx context.Context) (int, error)
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPortTaken is returned when a port or container slot is already reserved.
+var ErrPortTaken = errors.New("port already reserved")
+
+// PortReservation is a host port held by one container slot of a service:
+// its own container, an extra replica or its blue/green "-next" slot.
+type PortReservation struct {
+	Port      int       `json:"port" db:"port"`
+	Container string    `json:"container" db:"container"`
+	Service   string    `json:"service" db:"service"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type PortStore interface {
+	// ReservePort records r. It returns ErrPortTaken when r's port or
+	// container already has a reservation.
+	ReservePort(ctx context.Context, r *PortReservation) error
+	// GetPortReservation returns a container's reservation, or nil if it has none.
+	GetPortReservation(ctx context.Context, container string) (*PortReservation, error)
+	// ListPortReservations returns every reservation, lowest port first.
+	ListPortReservations(ctx context.Context) ([]*PortReservation, error)
+	// ReleasePort drops a container's reservation, if it has one.
+	ReleasePort(ctx context.Context, container string) error
+}
@@ -36,6 +36,9 @@ const (
 	TypeJob    ServiceType = "job"
 )
 
+// RedactedSecret stands in for secret values on services read from the store.
+const RedactedSecret = "[redacted]"
+
 type Service struct {
 	ID             string            `json:"id" db:"id"`
 	Name           string            `json:"name" db:"name"`
@@ -47,6 +50,7 @@ type Service struct {
 	RunCmd         string            `json:"run_cmd,omitempty" db:"run_cmd"`
 	BuildCmd       string            `json:"build_cmd,omitempty" db:"build_cmd"`
 	Port           int               `json:"port"`
+	HostPort       int               `json:"host_port,omitempty" db:"host_port"`
 	WorkingDir     string            `json:"working_dir,omitempty" db:"working_dir"`
 	StaticDir      string            `json:"static_dir,omitempty" db:"static_dir"`
 	Image          string            `json:"image,omitempty" db:"image"`
@@ -56,6 +60,12 @@ type Service struct {
 	Branch         string            `json:"branch" db:"remote_branch"`
 	CommitHash     string            `json:"commit_hash" db:"remote_commit_hash"`
 	DeploymentId   string            `json:"-" db:"deployment_id"`
+	Domains        []Domain          `json:"domains,omitempty"`
+	Replicas       int               `json:"replicas,omitempty"`
+	HealthCheck    string            `json:"health_check,omitempty"`
+	Resources      Resources         `json:"resources,omitzero"`
+	Schedule       string            `json:"schedule,omitempty"`
+	Concurrency    ConcurrencyPolicy `json:"concurrency_policy,omitempty"`
 	Blueprint      *Blueprint        `json:"blueprint,omitempty"`
 	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
@@ -0,0 +1,49 @@
+// Copyright 2025 Emmanuel Madehin
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"time"
+)
+
+type ServiceRunStatus string
+
+const (
+	ServiceRunRunning   ServiceRunStatus = "running"
+	ServiceRunSucceeded ServiceRunStatus = "succeeded"
+	ServiceRunFailed    ServiceRunStatus = "failed"
+	ServiceRunTimedOut  ServiceRunStatus = "timed_out" // killed when its timeout ran out
+)
+
+// ServiceRun is a one-off command run in an ephemeral container started from
+// a service's current image. ExitCode is nil until the command exits, and
+// stays nil for runs whose container never started or was killed.
+type ServiceRun struct {
+	ID         string           `json:"id" db:"id"`
+	Service    string           `json:"service" db:"service"`
+	Command    []string         `json:"command" db:"command"`
+	Image      string           `json:"image" db:"image"`
+	Subject    string           `json:"subject,omitempty" db:"subject"` // who started the run
+	Status     ServiceRunStatus `json:"status" db:"status"`
+	ExitCode   *int             `json:"exit_code,omitempty" db:"exit_code"`
+	Error      string           `json:"error,omitempty" db:"error"` // why a run failed without exiting
+	TimeoutMs  int64            `json:"timeout_ms" db:"timeout_ms"`
+	StartedAt  time.Time        `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty" db:"finished_at"`
+	DurationMs int64            `json:"duration_ms" db:"duration_ms"`
+}
+
+type ServiceRunStore interface {
+	// CreateServiceRun records a run, assigning its ID when empty.
+	CreateServiceRun(ctx context.Context, r *ServiceRun) error
+	// FinishServiceRun stores a finished run's status, exit code, error and duration.
+	FinishServiceRun(ctx context.Context, r *ServiceRun) error
+	// GetServiceRun returns a run, or nil if there is none with that ID.
+	GetServiceRun(ctx context.Context, id string) (*ServiceRun, error)
+	// ListServiceRuns returns a service's runs, newest first.
+	ListServiceRuns(ctx context.Context, service string, limit int) ([]*ServiceRun, error)
+	// FailInterruptedServiceRuns fails runs left running by a previous daemon.
+	FailInterruptedServiceRuns(ctx context.Context) (int, error)
+}